@@ -57,6 +57,8 @@ func Analyzer(input string) (string, error) {
 		return commands.ParseLogout(arguments)
 	case "mkfile":
 		return commands.ParseMkfile(arguments)
+	case "ln":
+		return commands.ParseLn(arguments)
 	case "mkgrp":
 		return commands.ParseMkgrp(arguments)
 	case "rmgrp":
@@ -85,6 +87,22 @@ func Analyzer(input string) (string, error) {
 		return commands.ParseChown(arguments)
 	case "chmod":
 		return commands.ParseChmod(arguments)
+	case "setxattr":
+		return commands.ParseSetxattr(arguments)
+	case "getxattr":
+		return commands.ParseGetxattr(arguments)
+	case "listxattr":
+		return commands.ParseListxattr(arguments)
+	case "rmxattr":
+		return commands.ParseRmxattr(arguments)
+	case "setfacl":
+		return commands.ParseSetfacl(arguments)
+	case "getfacl":
+		return commands.ParseGetfacl(arguments)
+	case "truncate":
+		return commands.ParseTruncate(arguments)
+	case "append":
+		return commands.ParseAppend(arguments)
 	case "recovery":
 		return commands.ParseRecovery(arguments)
 	case "loss":
@@ -97,6 +115,32 @@ func Analyzer(input string) (string, error) {
 		return commands.ParsePartitions(arguments)
 	case "content":
 		return commands.ParseContent(arguments)
+	case "resizefs":
+		return commands.ParseResizefs(arguments)
+	case "migratefs":
+		return commands.ParseMigratefs(arguments)
+	case "tunefs":
+		return commands.ParseTunefs(arguments)
+	case "clonedisk":
+		return commands.ParseClonedisk(arguments)
+	case "exportpart":
+		return commands.ParseExportpart(arguments)
+	case "importpart":
+		return commands.ParseImportpart(arguments)
+	case "scandisks":
+		return commands.ParseScandisks(arguments)
+	case "recyclebin":
+		return commands.ParseRecyclebin(arguments)
+	case "restoredisk":
+		return commands.ParseRestoredisk(arguments)
+	case "growdisk":
+		return commands.ParseGrowdisk(arguments)
+	case "verifydisk":
+		return commands.ParseVerifydisk(arguments)
+	case "sync":
+		return commands.ParseSync(arguments)
+	case "cachestats":
+		return commands.ParseCachestats(arguments)
 	default:
 		return "", fmt.Errorf("comando desconocido: %s", command)
 	}
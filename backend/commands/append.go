@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	stores "backend/stores"
+	structures "backend/structures"
+	utils "backend/utils"
+)
+
+type Append struct {
+	path      string
+	contenido string // Archivo del sistema operativo con lo que se agrega
+}
+
+// ParseAppend analiza append -path=<archivo> -contenido=<archivo local>
+func ParseAppend(tokens []string) (string, error) {
+	cmd := &Append{}
+	processedKeys := make(map[string]bool)
+
+	pathRegex := regexp.MustCompile(`^(?i)-path=(?:"([^"]+)"|([^\s"]+))$`)
+	contenidoRegex := regexp.MustCompile(`^(?i)-contenido=(?:"([^"]+)"|([^\s"]+))$`)
+
+	if len(tokens) == 0 {
+		return "", errors.New("faltan parámetros: se requiere -path y -contenido")
+	}
+
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		var key string
+		var match []string
+		if match = pathRegex.FindStringSubmatch(token); match != nil {
+			key = "-path"
+		} else if match = contenidoRegex.FindStringSubmatch(token); match != nil {
+			key = "-contenido"
+		} else {
+			return "", fmt.Errorf("parámetro inválido o no reconocido: '%s'. Se esperaba -path= o -contenido=", token)
+		}
+		value := match[1]
+		if value == "" {
+			value = match[2]
+		}
+
+		if processedKeys[key] {
+			return "", fmt.Errorf("parámetro duplicado: %s", key)
+		}
+		processedKeys[key] = true
+
+		switch key {
+		case "-path":
+			if !strings.HasPrefix(value, "/") {
+				return "", fmt.Errorf("el path '%s' debe ser absoluto", value)
+			}
+			cmd.path = value
+		case "-contenido":
+			cmd.contenido = value
+		}
+	}
+
+	if !processedKeys["-path"] {
+		return "", errors.New("falta el parámetro requerido: -path")
+	}
+	if !processedKeys["-contenido"] {
+		return "", errors.New("falta el parámetro requerido: -contenido")
+	}
+	if _, err := os.Stat(cmd.contenido); os.IsNotExist(err) {
+		return "", fmt.Errorf("el archivo especificado en -contenido no existe en el sistema operativo: '%s'", cmd.contenido)
+	} else if err != nil {
+		return "", fmt.Errorf("error al verificar archivo en -contenido '%s': %w", cmd.contenido, err)
+	}
+
+	added, newSize, err := commandAppend(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("APPEND: %d bytes agregados a '%s' (tamaño: %d bytes).", added, cmd.path, newSize), nil
+}
+
+// commandAppend agrega el contenido al final del archivo sin reescribir lo que ya tiene; devuelve
+// los bytes agregados y el tamaño final.
+func commandAppend(cmd *Append) (int, int64, error) {
+	fmt.Printf("Intentando agregar a: %s el contenido de %s\n", cmd.path, cmd.contenido)
+
+	if !stores.Auth.IsAuthenticated() {
+		return 0, 0, errors.New("comando append requiere inicio de sesión")
+	}
+	currentUser, userGIDStr, partitionID := stores.Auth.GetCurrentUser()
+	partitionSuperblock, _, partitionPath, err := stores.GetMountedPartitionSuperblock(partitionID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error obteniendo partición montada '%s': %w", partitionID, err)
+	}
+	if partitionSuperblock.S_magic != 0xEF53 {
+		return 0, 0, errors.New("magia de superbloque inválida")
+	}
+
+	targetInodeIndex, targetInode, errFind := structures.FindInodeByPath(partitionSuperblock, partitionPath, cmd.path)
+	if errFind != nil {
+		return 0, 0, fmt.Errorf("error: no se encontró el archivo '%s': %w", cmd.path, errFind)
+	}
+	if targetInode.I_type[0] != '1' {
+		return 0, 0, fmt.Errorf("error: '%s' no es un archivo (es tipo %c)", cmd.path, targetInode.I_type[0])
+	}
+	if !checkPermissions(currentUser, userGIDStr, 'w', targetInode, partitionSuperblock, partitionPath) {
+		return 0, 0, fmt.Errorf("permiso denegado: el usuario '%s' no tiene permiso de escritura sobre '%s'", currentUser, cmd.path)
+	}
+
+	data, errReadHost := os.ReadFile(cmd.contenido)
+	if errReadHost != nil {
+		return 0, 0, fmt.Errorf("error leyendo archivo de contenido '%s': %w", cmd.contenido, errReadHost)
+	}
+
+	file, errOpen := partitionSuperblock.OpenFile(partitionPath, targetInodeIndex)
+	if errOpen != nil {
+		return 0, 0, fmt.Errorf("error abriendo '%s': %w", cmd.path, errOpen)
+	}
+	fmt.Printf("Agregando %d bytes desde la posición %d...\n", len(data), file.Size())
+	added, errWrite := file.Append(data)
+	if errWrite != nil {
+		// Guardar lo que sí se escribió para no perder los bloques asignados
+		if errSync := file.Sync(); errSync != nil {
+			fmt.Printf("ADVERTENCIA: no se pudo guardar el inodo tras el error: %v\n", errSync)
+		}
+		return 0, 0, fmt.Errorf("error agregando a '%s' (%d de %d bytes escritos): %w", cmd.path, added, len(data), errWrite)
+	}
+
+	// Como edit: escribir (salvo root) quita setuid y setgid
+	if inode := file.Inode(); currentUser != "root" && inode.I_special&(structures.PermSetuid|structures.PermSetgid) != 0 {
+		inode.I_special &^= structures.PermSetuid | structures.PermSetgid
+		file.MarkDirty()
+		fmt.Println("Bits setuid/setgid quitados por la escritura.")
+	}
+	if err := file.Sync(); err != nil {
+		return 0, 0, fmt.Errorf("error guardando '%s': %w", cmd.path, err)
+	}
+
+	if partitionSuperblock.S_filesystem_type == 3 {
+		journalEntryData := structures.Information{
+			I_operation: utils.StringToBytes10("append"),
+			I_path:      utils.StringToBytes32(cmd.path),
+			I_content:   utils.StringToBytes64(strconv.Itoa(added)),
+		}
+		if errJournal := utils.AppendToJournal(journalEntryData, partitionSuperblock, partitionPath); errJournal != nil {
+			fmt.Printf("Advertencia: Falla al escribir en journal para append '%s': %v\n", cmd.path, errJournal)
+		}
+	}
+
+	fmt.Println("APPEND completado exitosamente.")
+	return added, file.Size(), nil
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"io"
 	"path/filepath" 
 	"regexp"
 	"strings"
@@ -138,7 +139,7 @@ func commandCat(cmd *CAT) (string, error) {
 
 	// Encontrar Inodo del Archivo
 	fmt.Printf("Buscando inodo para archivo: %s (en disco %s)\n", cmd.path, diskPath)
-	_, targetInode, errFind := structures.FindInodeByPath(partitionSuperblock, diskPath, cmd.path)
+	targetInodeIndex, targetInode, errFind := structures.FindInodeByPath(partitionSuperblock, diskPath, cmd.path)
 	if errFind != nil {
 		return "", fmt.Errorf("error: no se encontró el archivo '%s': %w", cmd.path, errFind)
 	}
@@ -160,12 +161,29 @@ func commandCat(cmd *CAT) (string, error) {
 	fmt.Println("Permiso de lectura concedido.")
 
 	// Leer Contenido del Archivo
-	fmt.Printf("Leyendo contenido del archivo (inodo %d)...\n", targetInode.I_uid) // UID no es índice, pero es info útil
-	content, errRead := structures.ReadFileContent(partitionSuperblock, diskPath, targetInode)
-	if errRead != nil {
-		return "", fmt.Errorf("error leyendo contenido de '%s': %w", cmd.path, errRead)
+	fmt.Printf("Leyendo contenido del archivo (inodo %d)...\n", targetInodeIndex)
+	file, errOpen := partitionSuperblock.OpenFile(diskPath, targetInodeIndex)
+	if errOpen != nil {
+		return "", fmt.Errorf("error abriendo '%s': %w", cmd.path, errOpen)
+	}
+	// La salida se arma en memoria: un archivo disperso puede medir mucho más de lo que ocupa
+	if file.Size() > structures.ReadFileContentMax {
+		return "", fmt.Errorf("el archivo (%d bytes) es demasiado grande para leerlo completo en memoria (máximo %d)", file.Size(), structures.ReadFileContentMax)
+	}
+	var content strings.Builder
+	content.Grow(int(file.Size()))
+	chunk := make([]byte, partitionSuperblock.S_block_size)
+	for {
+		n, errRead := file.Read(chunk)
+		content.Write(chunk[:n])
+		if errRead == io.EOF {
+			break
+		}
+		if errRead != nil {
+			return "", fmt.Errorf("error leyendo contenido de '%s': %w", cmd.path, errRead)
+		}
 	}
 
-	fmt.Printf("Contenido leído: %d bytes\n", len(content))
-	return content, nil 
+	fmt.Printf("Contenido leído: %d bytes\n", content.Len())
+	return content.String(), nil
 }
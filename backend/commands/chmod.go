@@ -14,7 +14,7 @@ import (
 
 type CHMOD struct {
 	path      string // Path absoluto al archivo/carpeta
-	ugo       string // Permisos en formato string "UGO" (e.g., "764")
+	ugo       string // Permisos en formato string "UGO" (e.g., "764"), o "SUGO" con los bits especiales (e.g., "1777")
 	recursive bool   // Flag -r
 }
 
@@ -23,7 +23,7 @@ func ParseChmod(tokens []string) (string, error) {
 	processedKeys := make(map[string]bool)
 
 	pathRegex := regexp.MustCompile(`^(?i)-path=(?:"([^"]+)"|([^\s"]+))$`)
-	ugoRegex := regexp.MustCompile(`^(?i)-ugo=([0-7]{3})$`) // Exactamente 3 dígitos 0-7
+	ugoRegex := regexp.MustCompile(`^(?i)-ugo=([0-7]{3,4})$`) // 3 dígitos 0-7, o 4 con setuid/setgid/sticky
 	rFlagRegex := regexp.MustCompile(`^(?i)-r$`)
 
 	if len(tokens) == 0 {
@@ -54,7 +54,7 @@ func ParseChmod(tokens []string) (string, error) {
 		} else if match = ugoRegex.FindStringSubmatch(token); match != nil {
 			key = "-ugo"
 			value = match[1]
-			matched = true // Grupo 1 captura los 3 dígitos
+			matched = true // Grupo 1 captura los 3 o 4 dígitos
 		} else if rFlagRegex.MatchString(token) {
 			key = "-r"
 			value = "true"
@@ -163,15 +163,25 @@ func commandChmod(cmd *CHMOD) error {
 		return fmt.Errorf("permiso denegado: solo el dueño (UID %d) o root pueden cambiar los permisos de '%s'", targetInode.I_uid, cmd.path)
 	}
 
-	// Convertir ugo string ("764") a byte array ([]byte{'7','6','4'})
-	if len(cmd.ugo) != 3 {
+	// Convertir ugo string ("764") a byte array ([]byte{'7','6','4'}). Con 4 dígitos el primero son
+	// los bits especiales; con 3 se conservan los que tenga cada inodo.
+	newSpecial := -1
+	ugo := cmd.ugo
+	if len(ugo) == 4 {
+		newSpecial = int(ugo[0] - '0')
+		ugo = ugo[1:]
+		if newSpecial != 0 && !partitionSuperblock.SpecialPerms() {
+			return errors.New("el sistema de archivos no guarda setuid, setgid ni sticky (ejecute tunefs -set=special_perms)")
+		}
+	}
+	if len(ugo) != 3 {
 		return errors.New("error interno: -ugo no tiene 3 dígitos")
 	}
-	newPerms := [3]byte{cmd.ugo[0], cmd.ugo[1], cmd.ugo[2]}
+	newPerms := [3]byte{ugo[0], ugo[1], ugo[2]}
 
 	// Llamar a la Función Recursiva
 	fmt.Printf("Iniciando cambio de permisos recursivo (si aplica) desde inodo %d...\n", targetInodeIndex)
-	errChmod := recursiveChmod(targetInodeIndex, newPerms, partitionSuperblock, partitionPath, currentUser, currentUserUID, cmd.recursive)
+	errChmod := recursiveChmod(targetInodeIndex, newPerms, newSpecial, partitionSuperblock, partitionPath, currentUser, currentUserUID, cmd.recursive)
 	if errChmod != nil {
 		return fmt.Errorf("error durante el cambio de permisos: %w", errChmod)
 	}
@@ -196,6 +206,7 @@ func commandChmod(cmd *CHMOD) error {
 func recursiveChmod(
 	inodeIndex int32,
 	newPerms [3]byte, // Nuevos permisos como array de bytes (ej: {'7','6','4'})
+	newSpecial int, // Nuevos bits setuid/setgid/sticky (0-7), o -1 para conservarlos
 	sb *structures.SuperBlock,
 	diskPath string,
 	currentUser string, // Nombre del usuario ejecutando
@@ -210,7 +221,7 @@ func recursiveChmod(
 	}
 	inode := &structures.Inode{}
 	inodeOffset := int64(sb.S_inode_start + inodeIndex*sb.S_inode_size)
-	if err := inode.Deserialize(diskPath, inodeOffset); err != nil {
+	if err := inode.Deserialize(sb, diskPath, inodeOffset); err != nil {
 		return fmt.Errorf("no se pudo leer inodo %d: %w", inodeIndex, err)
 	}
 
@@ -232,19 +243,25 @@ func recursiveChmod(
 
 	// Cambiar Permisos y Timestamp
 	permsChanged := false
-	if inode.I_perm != newPerms {
-		fmt.Printf("    Cambiando I_perm de %s a %s\n", string(inode.I_perm[:]), string(newPerms[:]))
+	special := inode.I_special
+	if newSpecial != -1 {
+		special = byte(newSpecial)
+	}
+	if inode.I_perm != newPerms || inode.I_special != special {
+		oldMode := inode.Mode()
 		inode.I_perm = newPerms
-		inode.I_ctime = float32(time.Now().Unix()) // Actualizar ctime (cambio de metadato)
+		inode.I_special = special
+		fmt.Printf("    Cambiando I_perm de %s a %s\n", oldMode, inode.Mode())
+		inode.I_ctime = time.Now().Unix() // Actualizar ctime (cambio de metadato)
 		permsChanged = true
 	} else {
 		fmt.Println("    Los permisos ya son los solicitados.")
 	}
 	// Actualizar atime porque accedimos/modificamos
-	inode.I_atime = float32(time.Now().Unix())
+	inode.I_atime = time.Now().Unix()
 
 	// Serializar Inodo Modificado (si cambió o por atime)
-	if err := inode.Serialize(diskPath, inodeOffset); err != nil {
+	if err := inode.Serialize(sb, diskPath, inodeOffset); err != nil {
 		return fmt.Errorf("error crítico guardando inodo %d actualizado: %w", inodeIndex, err)
 	}
 	if permsChanged {
@@ -254,37 +271,23 @@ func recursiveChmod(
 	// Recurrir si es Directorio y aplica
 	if inode.I_type[0] == '0' && applyRecursively {
 		fmt.Printf("    Inodo %d es DIR, procesando recursivamente...\n", inodeIndex)
-		for i := 0; i < 12; i++ { // Solo directos
-			blockPtr := inode.I_block[i]
-			if blockPtr == -1 || blockPtr < 0 || blockPtr >= sb.S_blocks_count {
-				continue
-			}
-
-			folderBlock := structures.FolderBlock{}
-			blockOffset := int64(sb.S_block_start + blockPtr*sb.S_block_size)
-			if err := folderBlock.Deserialize(diskPath, blockOffset); err != nil {
-				fmt.Printf("      Advertencia: Error leyendo bloque %d dir %d: %v\n", blockPtr, inodeIndex, err)
+		entries, err := sb.ReadDir(diskPath, inode)
+		if err != nil {
+			fmt.Printf("      Advertencia: Error leyendo dir %d: %v\n", inodeIndex, err)
+		}
+		for _, entry := range entries {
+			entryName := entry.Name
+			if entryName == "." || entryName == ".." {
 				continue
 			}
 
-			for j := range folderBlock.B_content {
-				entry := folderBlock.B_content[j]
-				if entry.B_inodo == -1 {
-					continue
-				}
-				entryName := strings.TrimRight(string(entry.B_name[:]), "\x00")
-				if entryName == "." || entryName == ".." {
-					continue
-				}
-
-				fmt.Printf("        Llamando recursiveChmod para hijo '%s' (inodo %d)...\n", entryName, entry.B_inodo)
-				errRec := recursiveChmod(entry.B_inodo, newPerms, sb, diskPath, currentUser, currentUserUID, true)
-				if errRec != nil {
-					fmt.Printf("        ERROR retornando de recursión en '%s': %v\n", entryName, errRec)
-					return errRec
-				}
-				fmt.Printf("        Procesamiento '%s' OK.\n", entryName)
+			fmt.Printf("        Llamando recursiveChmod para hijo '%s' (inodo %d)...\n", entryName, entry.Inode)
+			errRec := recursiveChmod(entry.Inode, newPerms, newSpecial, sb, diskPath, currentUser, currentUserUID, true)
+			if errRec != nil {
+				fmt.Printf("        ERROR retornando de recursión en '%s': %v\n", entryName, errRec)
+				return errRec
 			}
+			fmt.Printf("        Procesamiento '%s' OK.\n", entryName)
 		}
 	}
 
@@ -201,7 +201,7 @@ func recursiveChown(
 	}
 	inode := &structures.Inode{}
 	inodeOffset := int64(sb.S_inode_start + inodeIndex*sb.S_inode_size)
-	if err := inode.Deserialize(diskPath, inodeOffset); err != nil {
+	if err := inode.Deserialize(sb, diskPath, inodeOffset); err != nil {
 		return fmt.Errorf("no se pudo leer inodo %d: %w", inodeIndex, err)
 	}
 
@@ -228,16 +228,16 @@ func recursiveChown(
 	if inode.I_uid != newOwnerUID {
 		fmt.Printf("    Cambiando I_uid de %d a %d\n", inode.I_uid, newOwnerUID)
 		inode.I_uid = newOwnerUID
-		inode.I_ctime = float32(time.Now().Unix()) // Actualizar ctime 
+		inode.I_ctime = time.Now().Unix() // Actualizar ctime 
 		ownerChanged = true
 	} else {
 		fmt.Println("    El inodo ya pertenece al nuevo dueño.")
 	}
 	// Actualizar atime porque lo leímos/modificamos
-	inode.I_atime = float32(time.Now().Unix())
+	inode.I_atime = time.Now().Unix()
 
 	// Serializar Inodo Modificado (si cambió o por atime)
-	if err := inode.Serialize(diskPath, inodeOffset); err != nil {
+	if err := inode.Serialize(sb, diskPath, inodeOffset); err != nil {
 		return fmt.Errorf("error crítico guardando inodo %d actualizado: %w", inodeIndex, err)
 	}
 	if ownerChanged {
@@ -247,38 +247,24 @@ func recursiveChown(
 	// Recurrir si es Directorio y aplica
 	if inode.I_type[0] == '0' && applyRecursively {
 		fmt.Printf("    Inodo %d es DIRECTORIO, procesando contenido recursivamente...\n", inodeIndex)
-		for i := 0; i < 12; i++ { // Solo directos
-			blockPtr := inode.I_block[i]
-			if blockPtr == -1 || blockPtr < 0 || blockPtr >= sb.S_blocks_count {
-				continue
-			}
-
-			folderBlock := structures.FolderBlock{}
-			blockOffset := int64(sb.S_block_start + blockPtr*sb.S_block_size)
-			if err := folderBlock.Deserialize(diskPath, blockOffset); err != nil {
-				fmt.Printf("      Advertencia: Error leyendo bloque %d dir %d: %v\n", blockPtr, inodeIndex, err)
+		entries, err := sb.ReadDir(diskPath, inode)
+		if err != nil {
+			fmt.Printf("      Advertencia: Error leyendo dir %d: %v\n", inodeIndex, err)
+		}
+		for _, entry := range entries {
+			entryName := entry.Name
+			if entryName == "." || entryName == ".." {
 				continue
 			}
 
-			for j := range folderBlock.B_content {
-				entry := folderBlock.B_content[j]
-				if entry.B_inodo == -1 {
-					continue
-				}
-				entryName := strings.TrimRight(string(entry.B_name[:]), "\x00")
-				if entryName == "." || entryName == ".." {
-					continue
-				}
-
-				fmt.Printf("        Llamando recursiveChown para hijo '%s' (inodo %d)...\n", entryName, entry.B_inodo)
-				// Llamada RECURSIVA
-				errRec := recursiveChown(entry.B_inodo, newOwnerUID, sb, diskPath, currentUser, currentUserUID, true) // Siempre recursivo para hijos
-				if errRec != nil {
-					fmt.Printf("        ERROR retornando de recursión en '%s': %v\n", entryName, errRec)
-					return errRec
-				}
-				fmt.Printf("        Procesamiento recursivo de '%s' OK.\n", entryName)
+			fmt.Printf("        Llamando recursiveChown para hijo '%s' (inodo %d)...\n", entryName, entry.Inode)
+			// Llamada RECURSIVA
+			errRec := recursiveChown(entry.Inode, newOwnerUID, sb, diskPath, currentUser, currentUserUID, true) // Siempre recursivo para hijos
+			if errRec != nil {
+				fmt.Printf("        ERROR retornando de recursión en '%s': %v\n", entryName, errRec)
+				return errRec
 			}
+			fmt.Printf("        Procesamiento recursivo de '%s' OK.\n", entryName)
 		}
 	}
 
@@ -0,0 +1,271 @@
+package commands
+
+import (
+	stores "backend/stores"
+	structures "backend/structures"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type CLONEDISK struct {
+	path string // Disco origen
+	dest string // Path del disco nuevo
+}
+
+func ParseClonedisk(tokens []string) (string, error) {
+	cmd := &CLONEDISK{}
+	processedKeys := make(map[string]bool)
+
+	pathRegex := regexp.MustCompile(`^(?i)-path=(?:"([^"]+)"|([^\s"]+))$`)
+	destRegex := regexp.MustCompile(`^(?i)-dest=(?:"([^"]+)"|([^\s"]+))$`)
+
+	if len(tokens) == 0 {
+		return "", errors.New("faltan parámetros: se requiere -path y -dest")
+	}
+
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		var match []string
+		var key string
+		var value string
+		matched := false
+
+		if match = pathRegex.FindStringSubmatch(token); match != nil {
+			key = "-path"
+			matched = true
+		} else if match = destRegex.FindStringSubmatch(token); match != nil {
+			key = "-dest"
+			matched = true
+		}
+		if !matched {
+			return "", fmt.Errorf("parámetro inválido o no reconocido: '%s'. Se esperaba -path= o -dest=", token)
+		}
+		if match[1] != "" {
+			value = match[1]
+		} else {
+			value = match[2]
+		}
+
+		if processedKeys[key] {
+			return "", fmt.Errorf("parámetro duplicado: %s", key)
+		}
+		processedKeys[key] = true
+		if value == "" {
+			return "", fmt.Errorf("el valor para %s no puede estar vacío", key)
+		}
+
+		switch key {
+		case "-path":
+			cmd.path = filepath.Clean(value)
+		case "-dest":
+			cmd.dest = filepath.Clean(value)
+		}
+	}
+
+	if !processedKeys["-path"] {
+		return "", errors.New("falta el parámetro requerido: -path")
+	}
+	if !processedKeys["-dest"] {
+		return "", errors.New("falta el parámetro requerido: -dest")
+	}
+	if cmd.path == cmd.dest {
+		return "", errors.New("el disco origen y el destino no pueden ser el mismo archivo")
+	}
+
+	signature, err := commandClonedisk(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("CLONEDISK: Disco clonado exitosamente\n"+
+		"-> Origen: %s\n"+
+		"-> Destino: %s\n"+
+		"-> Firma: %d",
+		cmd.path, cmd.dest, signature), nil
+}
+
+func commandClonedisk(cmd *CLONEDISK) (int32, error) {
+	fmt.Printf("Clonando disco '%s' en '%s'\n", cmd.path, cmd.dest)
+
+	var srcMbr structures.MBR
+	if err := srcMbr.Deserialize(cmd.path); err != nil {
+		return 0, fmt.Errorf("error leyendo MBR del disco origen '%s': %w", cmd.path, err)
+	}
+	if _, err := os.Stat(cmd.dest); err == nil {
+		return 0, fmt.Errorf("el archivo destino '%s' ya existe", cmd.dest)
+	}
+
+	// Copiar el archivo completo
+	if err := os.MkdirAll(filepath.Dir(cmd.dest), 0755); err != nil {
+		return 0, fmt.Errorf("error creando directorio padre de '%s': %w", cmd.dest, err)
+	}
+	if err := copyFileRange(cmd.path, 0, cmd.dest, 0, -1); err != nil {
+		os.Remove(cmd.dest)
+		return 0, fmt.Errorf("error copiando disco: %w", err)
+	}
+
+	// El clon no hereda montajes y recibe una firma nueva
+	newMbr := srcMbr
+	for newMbr.Mbr_disk_signature == srcMbr.Mbr_disk_signature {
+		newMbr.Mbr_disk_signature = int32(rand.Intn(100000))
+	}
+	for i := range newMbr.Mbr_partitions {
+		if newMbr.Mbr_partitions[i].Part_status[0] == '1' {
+			newMbr.Mbr_partitions[i].Part_status[0] = '0'
+			newMbr.Mbr_partitions[i].Part_correlative = 0
+			newMbr.Mbr_partitions[i].Part_id = [4]byte{}
+		}
+	}
+	if err := newMbr.Serialize(cmd.dest); err != nil {
+		os.Remove(cmd.dest)
+		return 0, fmt.Errorf("error escribiendo MBR del clon: %w", err)
+	}
+
+	// Verificar el clon contra el origen
+	if err := verifyDiskImage(cmd.dest, &srcMbr, cmd.path); err != nil {
+		os.Remove(cmd.dest)
+		return 0, fmt.Errorf("verificación del clon falló: %w", err)
+	}
+
+	stores.DiskRegistry[cmd.dest] = filepath.Base(cmd.dest)
+	fmt.Printf("Disco '%s' añadido al registro.\n", cmd.dest)
+	return newMbr.Mbr_disk_signature, nil
+}
+
+// Comprueba que el MBR de diskPath se pueda leer, que sus particiones (y las lógicas de la cadena
+// de EBRs de la extendida) coincidan con ref y que cada partición que tenía sistema de archivos en
+// refPath conserve el número mágico del superbloque.
+func verifyDiskImage(diskPath string, ref *structures.MBR, refPath string) error {
+	var mbr structures.MBR
+	if err := mbr.Deserialize(diskPath); err != nil {
+		return fmt.Errorf("no se pudo leer el MBR de '%s': %w", diskPath, err)
+	}
+	if mbr.Mbr_size != ref.Mbr_size {
+		return fmt.Errorf("tamaño de MBR inesperado en '%s': %d (esperado %d)", diskPath, mbr.Mbr_size, ref.Mbr_size)
+	}
+
+	for i, part := range ref.Mbr_partitions {
+		if part.Part_status[0] == 'N' || part.Part_size <= 0 {
+			continue
+		}
+		if mbr.Mbr_partitions[i].Part_start != part.Part_start || mbr.Mbr_partitions[i].Part_size != part.Part_size {
+			return fmt.Errorf("la partición %d no coincide con el original", i+1)
+		}
+		name := strings.TrimRight(string(part.Part_name[:]), "\x00")
+		switch part.Part_type[0] {
+		case 'P':
+			if err := verifySuperblockMagic(diskPath, refPath, name, part.Part_start); err != nil {
+				return err
+			}
+		case 'E':
+			if err := verifyLogicalPartitions(diskPath, refPath, &part); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Compara la cadena de EBRs de la extendida ext en diskPath con la de refPath y verifica el
+// sistema de archivos de cada lógica.
+func verifyLogicalPartitions(diskPath string, refPath string, ext *structures.Partition) error {
+	refLogicals, err := logicalPartitions(refPath, ext)
+	if err != nil {
+		return fmt.Errorf("no se pudieron leer las lógicas del original: %w", err)
+	}
+	logicals, err := logicalPartitions(diskPath, ext)
+	if err != nil {
+		return fmt.Errorf("no se pudieron leer las lógicas de '%s': %w", diskPath, err)
+	}
+	if len(logicals) != len(refLogicals) {
+		return fmt.Errorf("la extendida tiene %d particiones lógicas (esperadas %d)", len(logicals), len(refLogicals))
+	}
+	for i, ebr := range refLogicals {
+		name := strings.TrimRight(string(ebr.Part_name[:]), "\x00 ")
+		if logicals[i].Part_start != ebr.Part_start || logicals[i].Part_size != ebr.Part_size || logicals[i].Part_name != ebr.Part_name {
+			return fmt.Errorf("la partición lógica '%s' no coincide con el original", name)
+		}
+		if err := verifySuperblockMagic(diskPath, refPath, name, ebr.Part_start); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Si la partición que empieza en start tenía sistema de archivos en refPath, verifica que en
+// diskPath conserve el número mágico del superbloque.
+func verifySuperblockMagic(diskPath string, refPath string, name string, start int32) error {
+	if readSuperblockMagic(refPath, start) != 0xEF53 {
+		return nil
+	}
+	if readSuperblockMagic(diskPath, start) != 0xEF53 {
+		return fmt.Errorf("la partición '%s' perdió el número mágico del superbloque", name)
+	}
+	fmt.Printf("Partición '%s' verificada (magic 0xEF53).\n", name)
+	return nil
+}
+
+// Lee el número mágico del superbloque en el offset dado (0 si no se puede leer).
+func readSuperblockMagic(diskPath string, offset int32) int32 {
+	var sb structures.SuperBlock
+	if err := sb.Deserialize(diskPath, int64(offset)); err != nil {
+		return 0
+	}
+	return sb.S_magic
+}
+
+// Copia size bytes de src (desde srcOffset) a dst (en dstOffset). Con size < 0 copia hasta el final de src.
+func copyFileRange(src string, srcOffset int64, dst string, dstOffset int64, size int64) error {
+	// La copia va directo a los archivos: bajar antes lo pendiente de los discos abiertos
+	for _, path := range []string{src, dst} {
+		if err := structures.SyncDisk(path); err != nil {
+			return err
+		}
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("error abriendo '%s': %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("error abriendo '%s': %w", dst, err)
+	}
+	defer out.Close()
+
+	if _, err := in.Seek(srcOffset, 0); err != nil {
+		return err
+	}
+	if _, err := out.Seek(dstOffset, 0); err != nil {
+		return err
+	}
+
+	var reader io.Reader = in
+	if size >= 0 {
+		reader = io.LimitReader(in, size)
+	}
+	written, err := io.Copy(out, reader)
+	if err != nil {
+		return fmt.Errorf("error copiando datos: %w", err)
+	}
+	if size >= 0 && written != size {
+		return fmt.Errorf("copia incompleta (copiados %d, esperados %d)", written, size)
+	}
+	if err := out.Sync(); err != nil {
+		return err
+	}
+	// Si dst está montado, su caché ya no refleja el archivo
+	return structures.InvalidateDisk(dst)
+}
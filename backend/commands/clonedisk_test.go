@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"backend/structures"
+)
+
+// Disco con una primaria y una extendida con dos lógicas; la primaria y la segunda lógica tienen
+// superbloque.
+func newTestExtendedDisk(t *testing.T, name string) (*structures.MBR, string) {
+	t.Helper()
+	diskPath := "/mem/" + t.Name() + "_" + name + ".mia"
+	structures.RegisterDevice(diskPath, structures.NewMemDevice(64*1024))
+	t.Cleanup(func() { structures.UnregisterDevice(diskPath) })
+
+	mbr := &structures.MBR{Mbr_size: 64 * 1024, Mbr_creation_date: 1760000077, Mbr_disk_signature: 7, Mbr_disk_fit: [1]byte{'F'}}
+	for i := range mbr.Mbr_partitions {
+		mbr.Mbr_partitions[i] = structures.Partition{Part_status: [1]byte{'N'}, Part_start: -1, Part_correlative: -1}
+	}
+	mbr.Mbr_partitions[0] = structures.Partition{Part_status: [1]byte{'0'}, Part_type: [1]byte{'P'}, Part_fit: [1]byte{'F'}, Part_start: 512, Part_size: 8192}
+	mbr.Mbr_partitions[1] = structures.Partition{Part_status: [1]byte{'0'}, Part_type: [1]byte{'E'}, Part_fit: [1]byte{'F'}, Part_start: 16384, Part_size: 32768}
+	copy(mbr.Mbr_partitions[0].Part_name[:], "P1")
+	copy(mbr.Mbr_partitions[1].Part_name[:], "E1")
+	if err := mbr.Serialize(diskPath); err != nil {
+		t.Fatalf("Serialize MBR: %v", err)
+	}
+
+	ebrs := []structures.EBR{
+		{Part_status: [1]byte{'0'}, Part_fit: [1]byte{'F'}, Part_start: 16384 + 30, Part_size: 4096, Part_next: 24576},
+		{Part_status: [1]byte{'0'}, Part_fit: [1]byte{'F'}, Part_start: 24576 + 30, Part_size: 8192, Part_next: -1},
+	}
+	copy(ebrs[0].Part_name[:], "L1")
+	copy(ebrs[1].Part_name[:], "L2")
+	writeTestEBR(t, diskPath, 16384, &ebrs[0])
+	writeTestEBR(t, diskPath, 24576, &ebrs[1])
+	for _, start := range []int32{512, ebrs[1].Part_start} {
+		sb := &structures.SuperBlock{S_magic: 0xEF53, S_block_size: 64, S_inodes_count: 2, S_blocks_count: 6}
+		if err := sb.Serialize(diskPath, int64(start)); err != nil {
+			t.Fatalf("Serialize superbloque: %v", err)
+		}
+	}
+	return mbr, diskPath
+}
+
+func writeTestEBR(t *testing.T, diskPath string, pos int64, ebr *structures.EBR) {
+	t.Helper()
+	file, err := structures.OpenDisk(diskPath)
+	if err != nil {
+		t.Fatalf("OpenDisk: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.Seek(pos, 0); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	if err := binary.Write(file, binary.LittleEndian, ebr); err != nil {
+		t.Fatalf("Write EBR: %v", err)
+	}
+}
+
+func TestVerifyDiskImageLogicals(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		damage  func(t *testing.T, diskPath string)
+		wantErr string
+	}{
+		{"copia íntegra", func(*testing.T, string) {}, ""},
+		{"superbloque de una lógica", func(t *testing.T, diskPath string) {
+			sb := &structures.SuperBlock{}
+			if err := sb.Serialize(diskPath, 24576+30); err != nil {
+				t.Fatalf("Serialize: %v", err)
+			}
+		}, "'L2' perdió el número mágico"},
+		{"lógica movida", func(t *testing.T, diskPath string) {
+			ebr := structures.EBR{Part_status: [1]byte{'0'}, Part_start: 16384 + 30, Part_size: 2048, Part_next: 24576}
+			copy(ebr.Part_name[:], "L1")
+			writeTestEBR(t, diskPath, 16384, &ebr)
+		}, "'L1' no coincide"},
+		{"cadena cortada", func(t *testing.T, diskPath string) {
+			ebr := structures.EBR{Part_status: [1]byte{'0'}, Part_start: 16384 + 30, Part_size: 4096, Part_next: -1}
+			copy(ebr.Part_name[:], "L1")
+			writeTestEBR(t, diskPath, 16384, &ebr)
+		}, "1 particiones lógicas (esperadas 2)"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, refPath := newTestExtendedDisk(t, "origen")
+			_, clonePath := newTestExtendedDisk(t, "clon")
+			tt.damage(t, clonePath)
+			err := verifyDiskImage(clonePath, ref, refPath)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("verifyDiskImage: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("verifyDiskImage = %v, se esperaba un error con %q", err, tt.wantErr)
+			}
+		})
+	}
+}
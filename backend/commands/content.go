@@ -150,66 +150,56 @@ func commandContent(cmd *CONTENT) ([]string, error) {
 	// 4. Leer Contenido, OBTENER TIPO, TAMAÑO, FECHA, PERMISOS
 	fmt.Println("Leyendo entradas detalladas del directorio...")
 	contentListDetailed := []string{} // Lista para guardar strings formateados
-	// TODO: Implementar indirección si es necesario
-	for i := 0; i < 12; i++ { // Solo directos
-		blockPtr := targetInode.I_block[i]
-		if blockPtr == -1 || blockPtr < 0 || blockPtr >= partitionSuperblock.S_blocks_count {
+	entries, err := partitionSuperblock.ReadDir(diskPath, targetInode)
+	if err != nil {
+		fmt.Printf("  Adv: Error leyendo dir %d: %v\n", targetInodeIndex, err)
+	}
+	for _, entry := range entries {
+		entryName := entry.Name
+		if entryName == "." || entryName == ".." { // Omitir . y ..
 			continue
 		}
 
-		folderBlock := structures.FolderBlock{}
-		blockOffset := int64(partitionSuperblock.S_block_start + blockPtr*partitionSuperblock.S_block_size)
-		if err := folderBlock.Deserialize(diskPath, blockOffset); err != nil {
-			fmt.Printf("  Adv: Error leyendo bloque %d dir %d: %v\n", blockPtr, targetInodeIndex, err)
-			continue
-		}
+		// --- OBTENER INFO DEL INODO HIJO ---
+		childInodeIndex := entry.Inode
+		childType := byte('?')
+		childSize := int64(-1)         // Tamaño -1 si no se puede leer
+		childPerms := "---"            // Permisos por defecto si no se puede leer
+		childMtimeStr := "Fecha desc." // Fecha por defecto
+
+		if childInodeIndex >= 0 && childInodeIndex < partitionSuperblock.S_inodes_count {
+			childInode := &structures.Inode{}
+			childInodeOffset := int64(partitionSuperblock.S_inode_start + childInodeIndex*partitionSuperblock.S_inode_size)
+			if err := childInode.Deserialize(partitionSuperblock, diskPath, childInodeOffset); err == nil {
+				// Lectura exitosa del inodo hijo
+				childType = childInode.I_type[0]
+				childSize = childInode.I_size
+				childPerms = string(childInode.I_perm[:])
+				// Formatear fecha de modificación
+				mtime := time.Unix(childInode.I_mtime, 0)
+				childMtimeStr = mtime.Format("2006-01-02 15:04") // Formato YYYY-MM-DD HH:MM
 
-		for j := range folderBlock.B_content {
-			entry := folderBlock.B_content[j]
-			if entry.B_inodo != -1 { // Entrada válida
-				entryName := strings.TrimRight(string(entry.B_name[:]), "\x00")
-				if entryName != "." && entryName != ".." { // Omitir . y ..
-
-					// --- OBTENER INFO DEL INODO HIJO ---
-					childInodeIndex := entry.B_inodo
-					childType := byte('?')
-					childSize := int32(-1)         // Tamaño -1 si no se puede leer
-					childPerms := "---"            // Permisos por defecto si no se puede leer
-					childMtimeStr := "Fecha desc." // Fecha por defecto
-
-					if childInodeIndex >= 0 && childInodeIndex < partitionSuperblock.S_inodes_count {
-						childInode := &structures.Inode{}
-						childInodeOffset := int64(partitionSuperblock.S_inode_start + childInodeIndex*partitionSuperblock.S_inode_size)
-						if err := childInode.Deserialize(diskPath, childInodeOffset); err == nil {
-							// Lectura exitosa del inodo hijo
-							childType = childInode.I_type[0]
-							childSize = childInode.I_size
-							childPerms = string(childInode.I_perm[:])
-							// Formatear fecha de modificación
-							mtime := time.Unix(int64(childInode.I_mtime), 0)
-							childMtimeStr = mtime.Format("2006-01-02 15:04") // Formato YYYY-MM-DD HH:MM
-
-						} else {
-							fmt.Printf(" Adv: No leer inodo hijo %d ('%s'): %v\n", childInodeIndex, entryName, err)
-						}
-					} else {
-						fmt.Printf(" Adv: Índice inodo inválido %d para '%s'.\n", childInodeIndex, entryName)
-					}
-					// --- FIN OBTENER INFO ---
-
-					// Formato NUEVO: "nombre,tipo,fecha_modif,tamaño,permisos"
-					formattedEntry := fmt.Sprintf("%s,%c,%s,%d,%s",
-						entryName,
-						childType,
-						childMtimeStr,
-						childSize,
-						childPerms,
-					)
-					contentListDetailed = append(contentListDetailed, formattedEntry)
-				}
+			} else {
+				fmt.Printf(" Adv: No leer inodo hijo %d ('%s'): %v\n", childInodeIndex, entryName, err)
 			}
+		} else {
+			fmt.Printf(" Adv: Índice inodo inválido %d para '%s'.\n", childInodeIndex, entryName)
+		}
+		// --- FIN OBTENER INFO ---
+
+		// Formato NUEVO: "nombre,tipo,fecha_modif,tamaño,permisos"; los enlaces agregan ",destino"
+		formattedEntry := fmt.Sprintf("%s,%c,%s,%d,%s",
+			entryName,
+			childType,
+			childMtimeStr,
+			childSize,
+			childPerms,
+		)
+		if target, ok := symlinkTargetOf(partitionSuperblock, diskPath, childInodeIndex); ok {
+			formattedEntry += "," + target
 		}
-	} // Fin for bloques
+		contentListDetailed = append(contentListDetailed, formattedEntry)
+	}
 
 	fmt.Printf("Contenido detallado encontrado: %v\n", contentListDetailed)
 	return contentListDetailed, nil // Devolver lista de strings formateados
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"bytes"
 	stores "backend/stores"
 	structures "backend/structures"
 	utils "backend/utils"
@@ -163,10 +164,10 @@ func commandCopy(cmd *COPY) error {
 
 	// Actualizar Timestamps Destino
 	fmt.Println("Actualizando timestamp del directorio destino...")
-	destDirInode.I_mtime = float32(time.Now().Unix())
+	destDirInode.I_mtime = time.Now().Unix()
 	destDirInode.I_atime = destDirInode.I_mtime
 	destDirInodeOffset := int64(partitionSuperblock.S_inode_start + destDirInodeIndex*partitionSuperblock.S_inode_size)
-	if err := destDirInode.Serialize(partitionPath, destDirInodeOffset); err != nil {
+	if err := destDirInode.Serialize(partitionSuperblock, partitionPath, destDirInodeOffset); err != nil {
 		fmt.Printf("Advertencia: Error guardando inodo destino %d actualizado: %v\n", destDirInodeIndex, err)
 	}
 
@@ -196,6 +197,48 @@ func commandCopy(cmd *COPY) error {
 	return nil
 }
 
+// Copia el contenido del archivo src al archivo dst (vacío) bloque a bloque. Los bloques que son
+// todos ceros no se escriben, así los huecos del origen siguen siendo huecos en la copia. Si falla,
+// libera los bloques que alcanzó a asignar y deja dst vacío.
+func copyFileContent(sb *structures.SuperBlock, diskPath string, src int32, dst int32) (err error) {
+	source, err := sb.OpenFile(diskPath, src)
+	if err != nil {
+		return err
+	}
+	dest, err := sb.OpenFile(diskPath, dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if errTrunc := dest.Truncate(0); errTrunc != nil {
+			fmt.Printf("      Advertencia: no se pudieron liberar los bloques de la copia: %v\n", errTrunc)
+		}
+		if errSync := dest.Sync(); errSync != nil {
+			fmt.Printf("      Advertencia: no se pudo guardar el inodo %d: %v\n", dst, errSync)
+		}
+	}()
+	buf := make([]byte, sb.S_block_size)
+	for off := int64(0); off < source.Size(); off += int64(len(buf)) {
+		n, errRead := source.ReadAt(buf, off)
+		if n == 0 {
+			return fmt.Errorf("error leyendo origen en %d: %w", off, errRead)
+		}
+		if len(bytes.TrimLeft(buf[:n], "\x00")) == 0 {
+			continue
+		}
+		if _, err := dest.WriteAt(buf[:n], off); err != nil {
+			return err
+		}
+	}
+	if err := dest.Truncate(source.Size()); err != nil {
+		return err
+	}
+	return dest.Sync()
+}
+
 func recursiveCopy(
 	sourceInodeIndex int32,
 	parentDestInodeIndex int32,
@@ -210,7 +253,7 @@ func recursiveCopy(
 	// Leer inodo origen
 	sourceInode := &structures.Inode{}
 	sourceInodeOffset := int64(sb.S_inode_start + sourceInodeIndex*sb.S_inode_size)
-	if err := sourceInode.Deserialize(diskPath, sourceInodeOffset); err != nil {
+	if err := sourceInode.Deserialize(sb, diskPath, sourceInodeOffset); err != nil {
 		return fmt.Errorf("no se pudo leer inodo origen %d: %w", sourceInodeIndex, err)
 	}
 
@@ -224,28 +267,13 @@ func recursiveCopy(
 	// Procesar según tipo
 	if sourceInode.I_type[0] == '1' { // ARCHIVO
 		fmt.Printf("    Origen es ARCHIVO. Procediendo a copiar...\n")
-		// Leer contenido
-		contentBytesStr, errRead := structures.ReadFileContent(sb, diskPath, sourceInode)
-		if errRead != nil {
-			return fmt.Errorf("error leyendo contenido origen %d: %w", sourceInodeIndex, errRead)
-		}
-		contentBytes := []byte(contentBytesStr)
-		newSize := int32(len(contentBytes))
-		fmt.Printf("      Contenido leído: %d bytes.\n", newSize)
-		// Calcular bloques y verificar espacio
-		blockSize := sb.S_block_size
-		numBlocksNeeded := int32(0)
-		if newSize > 0 {
-			numBlocksNeeded = (newSize + blockSize - 1) / blockSize
-		}
-		if numBlocksNeeded > sb.S_free_blocks_count {
-			return fmt.Errorf("espacio insuficiente: necesita %d bloques, libres %d", numBlocksNeeded, sb.S_free_blocks_count)
-		}
-		// Asignar bloques y escribir contenido
-		fmt.Printf("      Asignando %d bloques y escribiendo...\n", numBlocksNeeded)
-		newAllocatedBlockIndices, errAlloc := allocateDataBlocks(contentBytes, newSize, sb, diskPath)
-		if errAlloc != nil {
-			return fmt.Errorf("falló asignación/escritura copia archivo: %w", errAlloc)
+		// Verificar espacio: la copia ocupa a lo sumo los bloques del origen (los huecos se conservan)
+		_, sourceBlocks, errBlocks := sb.InodeBlocks(diskPath, sourceInode)
+		if errBlocks != nil {
+			return fmt.Errorf("error leyendo bloques origen %d: %w", sourceInodeIndex, errBlocks)
+		}
+		if int32(len(sourceBlocks)) > sb.S_free_blocks_count {
+			return fmt.Errorf("espacio insuficiente: necesita %d bloques, libres %d", len(sourceBlocks), sb.S_free_blocks_count)
 		}
 		// Asignar nuevo inodo
 		newInodeIndex, errInodeAlloc := sb.FindFreeInode(diskPath)
@@ -257,13 +285,24 @@ func recursiveCopy(
 		}
 		sb.S_free_inodes_count--
 		fmt.Printf("      Nuevo inodo asignado: %d\n", newInodeIndex)
-		// Crear y serializar nuevo inodo
-		currentTime := float32(time.Now().Unix())
-		newInode := &structures.Inode{I_uid: sourceInode.I_uid, I_gid: sourceInode.I_gid, I_size: newSize, I_atime: currentTime, I_ctime: currentTime, I_mtime: currentTime /* O mantener sourceInode.I_mtime */, I_type: [1]byte{'1'}, I_perm: sourceInode.I_perm, I_block: newAllocatedBlockIndices}
+		// Crear y serializar nuevo inodo, vacío hasta copiar el contenido
+		currentTime := time.Now().Unix()
+		newInode := &structures.Inode{I_uid: sourceInode.I_uid, I_gid: sourceInode.I_gid, I_size: 0, I_atime: currentTime, I_ctime: currentTime, I_mtime: currentTime /* O mantener sourceInode.I_mtime */, I_type: [1]byte{'1'}, I_perm: sourceInode.I_perm, I_links: 1, I_xattr: -1}
+		for i := range newInode.I_block {
+			newInode.I_block[i] = -1
+		}
+		copyXattrs(sourceInode, newInode, sb, diskPath)
 		newInodeOffset := int64(sb.S_inode_start + newInodeIndex*sb.S_inode_size)
-		if err := newInode.Serialize(diskPath, newInodeOffset); err != nil {
+		if err := newInode.Serialize(sb, diskPath, newInodeOffset); err != nil {
+			releaseCopyFile(newInodeIndex, newInode, sb, diskPath)
 			return fmt.Errorf("error serializando nuevo inodo copia %d: %w", newInodeIndex, err)
 		}
+		// Copiar el contenido
+		fmt.Printf("      Copiando %d bytes del inodo %d al %d...\n", sourceInode.I_size, sourceInodeIndex, newInodeIndex)
+		if err := copyFileContent(sb, diskPath, sourceInodeIndex, newInodeIndex); err != nil {
+			releaseCopyFile(newInodeIndex, newInode, sb, diskPath)
+			return fmt.Errorf("falló la copia del contenido de '%s': %w", newName, err)
+		}
 		// Añadir entrada al directorio destino
 		fmt.Printf("      Añadiendo entrada '%s' a dir destino %d...\n", newName, parentDestInodeIndex)
 		errAdd := addEntryToParent(parentDestInodeIndex, newName, newInodeIndex, sb, diskPath)
@@ -294,25 +333,22 @@ func recursiveCopy(
 		sb.S_free_blocks_count--
 		fmt.Printf("      Nuevo bloque asignado: %d\n", newDirBlockIndex)
 		// Crear y serializar nuevo inodo dir
-		currentTime := float32(time.Now().Unix())
-		newDirInode := &structures.Inode{I_uid: sourceInode.I_uid, I_gid: sourceInode.I_gid, I_size: 0, I_atime: currentTime, I_ctime: currentTime, I_mtime: currentTime, I_type: [1]byte{'0'}, I_perm: sourceInode.I_perm}
+		currentTime := time.Now().Unix()
+		newDirInode := &structures.Inode{I_uid: sourceInode.I_uid, I_gid: sourceInode.I_gid, I_size: 0, I_atime: currentTime, I_ctime: currentTime, I_mtime: currentTime, I_type: [1]byte{'0'}, I_perm: sourceInode.I_perm, I_links: 1, I_xattr: -1}
 		for i := range newDirInode.I_block {
 			newDirInode.I_block[i] = -1
 		}
 		newDirInode.I_block[0] = newDirBlockIndex
+		copyXattrs(sourceInode, newDirInode, sb, diskPath)
 		newDirInodeOffset := int64(sb.S_inode_start + newDirInodeIndex*sb.S_inode_size)
-		if err := newDirInode.Serialize(diskPath, newDirInodeOffset); err != nil {
+		if err := newDirInode.Serialize(sb, diskPath, newDirInodeOffset); err != nil {
 			return fmt.Errorf("error serializando nuevo inodo dir copia %d: %w", newDirInodeIndex, err)
 		}
 		// Crear y serializar nuevo bloque dir
-		newDirFolderBlock := structures.FolderBlock{}
-		newDirFolderBlock.Initialize()
-		copy(newDirFolderBlock.B_content[0].B_name[:], ".")
-		newDirFolderBlock.B_content[0].B_inodo = newDirInodeIndex
-		copy(newDirFolderBlock.B_content[1].B_name[:], "..")
-		newDirFolderBlock.B_content[1].B_inodo = parentDestInodeIndex
-		newDirBlockOffset := int64(sb.S_block_start + newDirBlockIndex*sb.S_block_size)
-		if err := newDirFolderBlock.Serialize(diskPath, newDirBlockOffset); err != nil {
+		newDirFolderBlock := sb.NewDirBlock()
+		newDirFolderBlock.Add(structures.DirEntry{Inode: newDirInodeIndex, Type: '0', Name: "."})
+		newDirFolderBlock.Add(structures.DirEntry{Inode: parentDestInodeIndex, Type: '0', Name: ".."})
+		if err := sb.WriteDirBlock(diskPath, newDirBlockIndex, newDirFolderBlock); err != nil {
 			return fmt.Errorf("error serializando nuevo bloque dir copia %d: %w", newDirBlockIndex, err)
 		}
 		// Añadir entrada para este dir en su padre destino
@@ -323,34 +359,60 @@ func recursiveCopy(
 		}
 		// Iterar sobre contenido del dir ORIGEN
 		fmt.Printf("      Iterando contenido dir origen %d...\n", sourceInodeIndex)
-		for i := 0; i < 12; i++ {
-			blockPtr := sourceInode.I_block[i]
-			if blockPtr == -1 || blockPtr < 0 || blockPtr >= sb.S_blocks_count {
+		entries, err := sb.ReadDir(diskPath, sourceInode)
+		if err != nil {
+			fmt.Printf("      Advertencia: Error leyendo dir %d: %v\n", sourceInodeIndex, err)
+		}
+		for _, entry := range entries {
+			entryName := entry.Name
+			if entryName == "." || entryName == ".." {
 				continue
 			}
-			folderBlock := structures.FolderBlock{}
-			blockOffset := int64(sb.S_block_start + blockPtr*sb.S_block_size)
-			if err := folderBlock.Deserialize(diskPath, blockOffset); err != nil {
-				fmt.Printf("      Adv: Error leyendo bloque %d origen: %v.\n", blockPtr, err)
-				continue
+			fmt.Printf("        Llamando recursiveCopy para hijo '%s' (inodo %d) DENTRO de nuevo dir %d...\n", entryName, entry.Inode, newDirInodeIndex)
+			errRec := recursiveCopy(entry.Inode, newDirInodeIndex, entryName, sb, diskPath, currentUser, userGIDStr)
+			if errRec != nil {
+				fmt.Printf("        ERROR (omitido según spec): Falla al copiar '%s': %v\n", entryName, errRec) /* NO return errRec */
+			} else {
+				fmt.Printf("        Copia '%s' OK.\n", entryName)
 			}
-			for j := range folderBlock.B_content {
-				entry := folderBlock.B_content[j]
-				if entry.B_inodo == -1 {
-					continue
-				}
-				entryName := strings.TrimRight(string(entry.B_name[:]), "\x00")
-				if entryName == "." || entryName == ".." {
-					continue
-				}
-				fmt.Printf("        Llamando recursiveCopy para hijo '%s' (inodo %d) DENTRO de nuevo dir %d...\n", entryName, entry.B_inodo, newDirInodeIndex)
-				errRec := recursiveCopy(entry.B_inodo, newDirInodeIndex, entryName, sb, diskPath, currentUser, userGIDStr)
-				if errRec != nil {
-					fmt.Printf("        ERROR (omitido según spec): Falla al copiar '%s': %v\n", entryName, errRec) /* NO return errRec */
-				} else {
-					fmt.Printf("        Copia '%s' OK.\n", entryName)
-				}
+		}
+	} else if sourceInode.IsSymlink() { // ENLACE: se copia el enlace, no su destino
+		fmt.Printf("    Origen es ENLACE. Copiando el enlace...\n")
+		target, errRead := structures.ReadSymlink(sb, diskPath, sourceInode)
+		if errRead != nil {
+			return fmt.Errorf("error leyendo destino del enlace %d: %w", sourceInodeIndex, errRead)
+		}
+		// Asignar primero el inodo: si falla no queda ningún bloque asignado
+		newInodeIndex, errInodeAlloc := sb.FindFreeInode(diskPath)
+		if errInodeAlloc != nil {
+			return fmt.Errorf("no se pudo asignar inodo copia enlace: %w", errInodeAlloc)
+		}
+		if err := sb.UpdateBitmapInode(diskPath, newInodeIndex, '1'); err != nil {
+			return fmt.Errorf("error bitmap inodo copia %d: %w", newInodeIndex, err)
+		}
+		sb.S_free_inodes_count--
+		currentTime := time.Now().Unix()
+		newInode := &structures.Inode{I_uid: sourceInode.I_uid, I_gid: sourceInode.I_gid, I_atime: currentTime, I_ctime: currentTime, I_mtime: currentTime, I_type: [1]byte{'2'}, I_perm: sourceInode.I_perm, I_links: 1, I_xattr: -1}
+		if sourceInode.InlineSymlink() {
+			if err := newInode.SetInlineTarget(target); err != nil {
+				releaseCopyInode(newInodeIndex, sb, diskPath)
+				return err
+			}
+		} else {
+			newInode.I_size = int64(len(target))
+			newBlocks, errAlloc := allocateDataBlocks([]byte(target), newInode.I_size, sb, diskPath)
+			if errAlloc != nil {
+				releaseCopyInode(newInodeIndex, sb, diskPath)
+				return fmt.Errorf("falló asignación/escritura copia enlace: %w", errAlloc)
 			}
+			newInode.I_block = newBlocks
+		}
+		copyXattrs(sourceInode, newInode, sb, diskPath)
+		if err := newInode.Serialize(sb, diskPath, int64(sb.S_inode_start+newInodeIndex*sb.S_inode_size)); err != nil {
+			return fmt.Errorf("error serializando nuevo inodo copia %d: %w", newInodeIndex, err)
+		}
+		if err := addEntryToParent(parentDestInodeIndex, newName, newInodeIndex, sb, diskPath); err != nil {
+			return fmt.Errorf("error añadiendo entrada enlace copiado '%s': %w", newName, err)
 		}
 	} else {
 		fmt.Printf("    Advertencia: Inodo origen %d tipo desconocido '%c'. Omitiendo.\n", sourceInodeIndex, sourceInode.I_type[0])
@@ -359,84 +421,37 @@ func recursiveCopy(
 	return nil
 }
 
-// --------------------------------------------------------------------------------------------------------------------------------------------
-
-func checkPermissions(currentUser string, _ string, requiredPermission byte, targetInode *structures.Inode, sb *structures.SuperBlock, diskPath string) bool {
-	fmt.Printf("      checkPermissions: User='%s' Req='%c' on Inode (UID=%d, GID=%d, Perm=%s)\n",
-		currentUser, requiredPermission, targetInode.I_uid, targetInode.I_gid, string(targetInode.I_perm[:]))
-
-	// Caso Root siempre tiene permiso
-	if currentUser == "root" {
-		fmt.Println("        Permiso concedido (root).")
-		return true
+// Devuelve al bitmap un inodo recién asignado para una copia que no se pudo completar.
+func releaseCopyInode(inodeIndex int32, sb *structures.SuperBlock, diskPath string) {
+	if err := sb.UpdateBitmapInode(diskPath, inodeIndex, '0'); err != nil {
+		fmt.Printf("      Advertencia: no se pudo liberar el inodo %d: %v\n", inodeIndex, err)
+		return
 	}
+	sb.S_free_inodes_count++
+}
 
-	// Obtener UID y GID del usuario actual desde /users.txt
-	currentUserUID, currentUserGID, errInfo := getUserInfo(currentUser, sb, diskPath) // <-- LLAMADA A getUserInfo
-	if errInfo != nil {
-		// Si no se pueden obtener los IDs (usuario no existe?), denegar permiso
-		fmt.Printf("        Error obteniendo info para usuario '%s': %v. Permiso denegado.\n", currentUser, errInfo)
-		return false
-	}
-	fmt.Printf("        Info usuario actual encontrada: UID=%d, GID=%d\n", currentUserUID, currentUserGID)
-
-	// Extraer permisos del inodo
-	ownerPerms := targetInode.I_perm[0]
-	groupPerms := targetInode.I_perm[1]
-	otherPerms := targetInode.I_perm[2]
-
-	// Función helper interna para chequear permiso
-	check := func(permBits byte, req byte) bool {
-		switch req {
-		case 'r':
-			return permBits == 'r' || permBits == '4' || permBits == '5' || permBits == '6' || permBits == '7'
-		case 'w':
-			return permBits == 'w' || permBits == '2' || permBits == '3' || permBits == '6' || permBits == '7'
-		case 'x':
-			return permBits == 'x' || permBits == '1' || permBits == '3' || permBits == '5' || permBits == '7'
-		}
-		return false
+// Como releaseCopyInode para un archivo copiado a medias: copyFileContent ya liberó sus bloques de
+// datos; falta el bloque de atributos que le puso copyXattrs.
+func releaseCopyFile(inodeIndex int32, inode *structures.Inode, sb *structures.SuperBlock, diskPath string) {
+	if err := sb.FreeXattrs(diskPath, inode); err != nil {
+		fmt.Printf("      Advertencia: %v\n", err)
 	}
+	releaseCopyInode(inodeIndex, sb, diskPath)
+}
 
-	// Verificar permisos en orden: Dueño -> Grupo -> Otros
-	hasPerm := false
-
-	// Chequear Dueño (usando UID real)
-	isOwner := (targetInode.I_uid == currentUserUID)
-	if isOwner {
-		fmt.Println("        Usuario es dueño.")
-		hasPerm = check(ownerPerms, requiredPermission)
-		if hasPerm {
-			fmt.Println("        Permiso de dueño concedido.")
-			return true
-		}
-		// Si es dueño pero no tiene el permiso, se deniega (
-		fmt.Println("        Permiso de dueño DENEGADO.")
-		return false
+// Copia los atributos extendidos de source a un bloque nuevo de dest (no guarda dest). Si no se
+// pueden copiar la copia sigue sin ellos, igual que con los hijos que fallan.
+func copyXattrs(source *structures.Inode, dest *structures.Inode, sb *structures.SuperBlock, diskPath string) {
+	if source.I_xattr == -1 {
+		return
 	}
-
-	// Chequear Grupo
-	isInGroup := (targetInode.I_gid == currentUserGID)
-	if isInGroup {
-		fmt.Println("        Usuario pertenece al grupo.")
-		hasPerm = check(groupPerms, requiredPermission)
-		if hasPerm {
-			fmt.Println("        Permiso de grupo concedido.")
-			return true
-		}
-		// Si pertenece al grupo pero no tiene permiso, se miran 'otros'
-		fmt.Println("        Permiso de grupo DENEGADO.")
+	attrs, err := sb.ReadXattrs(diskPath, source)
+	if err == nil {
+		err = sb.WriteXattrs(diskPath, dest, attrs)
 	}
-
-	// Chequear Otros
-	fmt.Println("        Verificando permisos de 'otros'.")
-	hasPerm = check(otherPerms, requiredPermission)
-	if hasPerm {
-		fmt.Println("        Permiso de 'otros' concedido.")
-		return true
+	if err != nil {
+		fmt.Printf("      Advertencia: no se copiaron los atributos extendidos: %v\n", err)
+		return
 	}
-
-	// Si no se concedió en ninguna etapa
-	fmt.Println("        Permiso denegado final.")
-	return false
+	fmt.Printf("      %d atributos extendidos copiados al bloque %d.\n", len(attrs), dest.I_xattr)
 }
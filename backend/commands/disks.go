@@ -7,6 +7,7 @@ import (
 	"strings"
 	stores "backend/stores"
 	structures "backend/structures"
+	utils "backend/utils"
 )
 
 
@@ -85,13 +86,21 @@ func commandDisks() (string, error) {
 			mountedStr = strings.Join(mountedNames, "|") // Unir con '|'
 		}
 
+		// Tamaño aparente del archivo vs bytes realmente reservados (imágenes dispersas)
+		apparentSize, allocatedSize, err := utils.GetDiskUsage(diskPath)
+		if err != nil {
+			fmt.Printf("  Advertencia: %v\n", err)
+		}
+
 		// Formatear la línea para este disco
-		line := fmt.Sprintf("%s,%s,%d,%c,%s",
+		line := fmt.Sprintf("%s,%s,%d,%c,%s,%d,%d",
 			diskName,
 			diskPath,
 			diskSize,
 			diskFit,
 			mountedStr,
+			apparentSize,
+			allocatedSize,
 		)
 
 		// Añadir punto y coma si no es el primer disco
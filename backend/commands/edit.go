@@ -117,8 +117,8 @@ func commandEdit(cmd *Edit) error {
 	if !stores.Auth.IsAuthenticated() {
 		return errors.New("comando edit requiere inicio de sesión")
 	}
-	currentUser, _, partitionID := stores.Auth.GetCurrentUser()
-	partitionSuperblock, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(partitionID)
+	currentUser, userGIDStr, partitionID := stores.Auth.GetCurrentUser()
+	partitionSuperblock, _, partitionPath, err := stores.GetMountedPartitionSuperblock(partitionID)
 	if err != nil {
 		return fmt.Errorf("error obteniendo partición montada '%s': %w", partitionID, err)
 	}
@@ -143,17 +143,8 @@ func commandEdit(cmd *Edit) error {
 
 	// Verificar Permisos de Lectura y Escritura
 	fmt.Printf("Verificando permisos R/W para usuario '%s' en inodo %d (Perms: %s)...\n", currentUser, targetInodeIndex, string(targetInode.I_perm[:]))
-	canReadWrite := false
-	if currentUser == "root" {
-		canReadWrite = true
-	} else {
-		ownerPermRead := targetInode.I_perm[0] == 'r' || targetInode.I_perm[0] == 'R' || targetInode.I_perm[0] == '4' || targetInode.I_perm[0] == '5' || targetInode.I_perm[0] == '6' || targetInode.I_perm[0] == '7'
-		ownerPermWrite := targetInode.I_perm[1] == 'w' || targetInode.I_perm[1] == 'W' || targetInode.I_perm[1] == '6' || targetInode.I_perm[1] == '7'
-		isOwner := true
-		if isOwner && ownerPermRead && ownerPermWrite {
-			canReadWrite = true
-		}
-	}
+	canReadWrite := checkPermissions(currentUser, userGIDStr, 'r', targetInode, partitionSuperblock, partitionPath) &&
+		checkPermissions(currentUser, userGIDStr, 'w', targetInode, partitionSuperblock, partitionPath)
 	if !canReadWrite {
 		return fmt.Errorf("permiso denegado: el usuario '%s' no tiene permisos de lectura y escritura sobre '%s'", currentUser, cmd.path)
 	}
@@ -165,60 +156,61 @@ func commandEdit(cmd *Edit) error {
 	if errReadHost != nil {
 		return fmt.Errorf("error leyendo archivo de contenido '%s': %w", cmd.contenido, errReadHost)
 	}
-	newSize := int32(len(newContentBytes))
+	newSize := int64(len(newContentBytes))
 	fmt.Printf("Nuevo tamaño: %d bytes.\n", newSize)
+	if newSize > partitionSuperblock.MaxFileSize() {
+		return fmt.Errorf("el contenido es demasiado grande (%d bytes): el máximo con bloques de %d bytes es %d", newSize, partitionSuperblock.S_block_size, partitionSuperblock.MaxFileSize())
+	}
 
-	// Liberar Bloques ANTIGUOS del inodo
-	fmt.Printf("Liberando bloques antiguos del inodo %d...\n", targetInodeIndex)
-	// Pasar el puntero al inodo que leímos
-	errFree := structures.FreeInodeBlocks(targetInode, partitionSuperblock, partitionPath)
-	if errFree != nil {
-		fmt.Printf("ADVERTENCIA: Error al liberar bloques antiguos del inodo %d: %v\n", targetInodeIndex, errFree)
-		return fmt.Errorf("error liberando bloques antiguos: %w", errFree)
-	} else {
-		fmt.Println("Bloques antiguos liberados (o no había).")
+	// Reescribir en el lugar: los bloques que ya tiene se reutilizan, los huecos que se escriban
+	// se asignan y lo que sobre después de newSize se libera
+	file, errOpen := partitionSuperblock.OpenFile(partitionPath, targetInodeIndex)
+	if errOpen != nil {
+		return fmt.Errorf("error abriendo '%s': %w", cmd.path, errOpen)
 	}
 
-	// Calcular y Verificar bloques necesarios para NUEVO contenido
+	// Verificar espacio: bloques que necesita el nuevo contenido menos los que ya tiene
 	blockSize := partitionSuperblock.S_block_size
 	numBlocksNeeded := int32(0)
-	if newSize > 0 {
-		numBlocksNeeded = (newSize + blockSize - 1) / blockSize
+	if newSize > 0 { // Bloques de datos más los de punteros
+		numBlocksNeeded = partitionSuperblock.BlocksForFile(int32((newSize + int64(blockSize) - 1) / int64(blockSize)))
 	}
-	fmt.Printf("Bloques necesarios para nuevo contenido: %d\n", numBlocksNeeded)
-	if numBlocksNeeded > partitionSuperblock.S_free_blocks_count {
-		return fmt.Errorf("espacio insuficiente en disco: se necesitan %d bloques, disponibles %d", numBlocksNeeded, partitionSuperblock.S_free_blocks_count)
+	_, currentBlocks, errBlocks := partitionSuperblock.InodeBlocks(partitionPath, file.Inode())
+	if errBlocks != nil {
+		return fmt.Errorf("error leyendo bloques de '%s': %w", cmd.path, errBlocks)
+	}
+	fmt.Printf("Bloques necesarios para nuevo contenido: %d (ya tiene %d)\n", numBlocksNeeded, len(currentBlocks))
+	if extra := numBlocksNeeded - int32(len(currentBlocks)); extra > partitionSuperblock.S_free_blocks_count {
+		return fmt.Errorf("espacio insuficiente en disco: se necesitan %d bloques más, disponibles %d", extra, partitionSuperblock.S_free_blocks_count)
 	}
 
-	// Asignar Nuevos Bloques y Escribir Contenido
-	fmt.Printf("Asignando %d bloque(s) y escribiendo nuevo contenido...\n", numBlocksNeeded)
-	var newAllocatedBlockIndices [15]int32
-	newAllocatedBlockIndices, errAlloc := allocateDataBlocks(newContentBytes, newSize, partitionSuperblock, partitionPath)
-	if errAlloc != nil {
-		return fmt.Errorf("falló la asignación/escritura de nuevos bloques: %w", errAlloc)
+	// Escribir el nuevo contenido y recortar el sobrante
+	fmt.Printf("Escribiendo %d bytes en el inodo %d...\n", newSize, targetInodeIndex)
+	if _, errWrite := file.WriteAt(newContentBytes, 0); errWrite != nil {
+		return fmt.Errorf("falló la escritura del nuevo contenido: %w", errWrite)
+	}
+	if errTrunc := file.Truncate(newSize); errTrunc != nil {
+		return fmt.Errorf("error recortando '%s' a %d bytes: %w", cmd.path, newSize, errTrunc)
 	}
 
 	// Actualizar el Inodo en Memoria
 	fmt.Println("Actualizando metadatos del inodo...")
-	targetInode.I_size = newSize                   // Nuevo tamaño
-	targetInode.I_block = newAllocatedBlockIndices // Nuevos punteros a bloques
-	currentTime := float32(time.Now().Unix())
+	targetInode = file.Inode()
+	currentTime := time.Now().Unix()
 	targetInode.I_mtime = currentTime // Actualizar tiempo de modificación
 	targetInode.I_atime = currentTime
-
-	// Serializar Inodo Actualizado
-	inodeOffset := int64(partitionSuperblock.S_inode_start + targetInodeIndex*partitionSuperblock.S_inode_size)
-	fmt.Printf("Serializando inodo %d actualizado en offset %d...\n", targetInodeIndex, inodeOffset)
-	err = targetInode.Serialize(partitionPath, inodeOffset)
-	if err != nil {
-		return fmt.Errorf("error serializando inodo '%s' actualizado: %w", cmd.path, err)
-	}
-
-	// Serializar Superbloque
-	fmt.Println("Serializando SuperBlock después de EDIT...")
-	err = partitionSuperblock.Serialize(partitionPath, int64(mountedPartition.Part_start))
-	if err != nil {
-		return fmt.Errorf("ADVERTENCIA: error al serializar superbloque después de edit: %w", err)
+	// Como en Linux, escribir un archivo (salvo root) le quita setuid y setgid
+	if currentUser != "root" && targetInode.I_special&(structures.PermSetuid|structures.PermSetgid) != 0 {
+		targetInode.I_special &^= structures.PermSetuid | structures.PermSetgid
+		targetInode.I_ctime = currentTime
+		fmt.Println("Bits setuid/setgid quitados por la escritura.")
+	}
+
+	// Guardar inodo y superbloque
+	fmt.Printf("Guardando inodo %d y superbloque después de EDIT...\n", targetInodeIndex)
+	file.MarkDirty()
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("error guardando '%s' actualizado: %w", cmd.path, err)
 	}
 
 	if partitionSuperblock.S_filesystem_type == 3 {
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	structures "backend/structures"
+	utils "backend/utils"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type EXPORTPART struct {
+	path string // Disco que contiene la partición
+	name string // Nombre de la partición
+	file string // Archivo de imagen a generar
+}
+
+func ParseExportpart(tokens []string) (string, error) {
+	cmd := &EXPORTPART{}
+	processedKeys := make(map[string]bool)
+
+	pathRegex := regexp.MustCompile(`^(?i)-path=(?:"([^"]+)"|([^\s"]+))$`)
+	nameRegex := regexp.MustCompile(`^(?i)-name=(?:"([^"]+)"|([^\s"]+))$`)
+	fileRegex := regexp.MustCompile(`^(?i)-file=(?:"([^"]+)"|([^\s"]+))$`)
+
+	if len(tokens) == 0 {
+		return "", errors.New("faltan parámetros: se requiere -path, -name y -file")
+	}
+
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		var match []string
+		var key string
+		var value string
+		matched := false
+
+		if match = pathRegex.FindStringSubmatch(token); match != nil {
+			key = "-path"
+			matched = true
+		} else if match = nameRegex.FindStringSubmatch(token); match != nil {
+			key = "-name"
+			matched = true
+		} else if match = fileRegex.FindStringSubmatch(token); match != nil {
+			key = "-file"
+			matched = true
+		}
+		if !matched {
+			return "", fmt.Errorf("parámetro inválido o no reconocido: '%s'. Se esperaba -path=, -name= o -file=", token)
+		}
+		if match[1] != "" {
+			value = match[1]
+		} else {
+			value = match[2]
+		}
+
+		if processedKeys[key] {
+			return "", fmt.Errorf("parámetro duplicado: %s", key)
+		}
+		processedKeys[key] = true
+		if value == "" {
+			return "", fmt.Errorf("el valor para %s no puede estar vacío", key)
+		}
+
+		switch key {
+		case "-path":
+			cmd.path = filepath.Clean(value)
+		case "-name":
+			cmd.name = value
+		case "-file":
+			cmd.file = filepath.Clean(value)
+		}
+	}
+
+	for _, key := range []string{"-path", "-name", "-file"} {
+		if !processedKeys[key] {
+			return "", fmt.Errorf("falta el parámetro requerido: %s", key)
+		}
+	}
+
+	size, err := commandExportpart(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("EXPORTPART: Partición exportada exitosamente\n"+
+		"-> Disco: %s\n"+
+		"-> Partición: %s\n"+
+		"-> Imagen: %s (%d bytes)",
+		cmd.path, cmd.name, cmd.file, size), nil
+}
+
+func commandExportpart(cmd *EXPORTPART) (int32, error) {
+	fmt.Printf("Exportando partición '%s' de '%s' a '%s'\n", cmd.name, cmd.path, cmd.file)
+
+	var mbr structures.MBR
+	if err := mbr.Deserialize(cmd.path); err != nil {
+		return 0, fmt.Errorf("error leyendo MBR del disco '%s': %w", cmd.path, err)
+	}
+	partition, _, err := mbr.GetPartitionByName(cmd.name)
+	if err != nil {
+		return 0, err
+	}
+	if partition.Part_type[0] != 'P' {
+		return 0, fmt.Errorf("solo se pueden exportar particiones primarias ('%s' es tipo %c)", cmd.name, partition.Part_type[0])
+	}
+
+	if err := utils.CreateParentDirs(cmd.file); err != nil {
+		return 0, err
+	}
+	imageFile, err := os.Create(cmd.file) // Truncar si ya existía
+	if err != nil {
+		return 0, fmt.Errorf("error creando imagen '%s': %w", cmd.file, err)
+	}
+	imageFile.Close()
+	if err := copyFileRange(cmd.path, int64(partition.Part_start), cmd.file, 0, int64(partition.Part_size)); err != nil {
+		return 0, fmt.Errorf("error exportando partición '%s': %w", cmd.name, err)
+	}
+
+	fmt.Println("Exportación completada.")
+	return partition.Part_size, nil
+}
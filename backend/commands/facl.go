@@ -0,0 +1,445 @@
+package commands
+
+import (
+	stores "backend/stores"
+	structures "backend/structures"
+	utils "backend/utils"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// setfacl modifica una entrada de la ACL de un archivo o carpeta y getfacl la muestra. Las
+// entradas user::, group:: y other:: de la ACL de acceso son los permisos de I_perm; el resto se
+// guarda en el atributo system.acl. Las entradas con prefijo d: van a la ACL por defecto de una
+// carpeta (system.acl_default), que heredan mkfile y mkdir.
+type SETFACL struct {
+	path   string
+	spec   string // Texto de -entry o -remove, para el journal y los mensajes
+	remove bool
+	entry  aclSpec
+}
+
+type GETFACL struct {
+	path string
+}
+
+// Entrada de ACL tal como se escribe en -entry/-remove: [d:]u|g|m|o:[nombre][:rwx]
+type aclSpec struct {
+	isDefault bool
+	tag       byte
+	name      string // Usuario o grupo (vacío para user::, group::, mask:: y other::)
+	perm      byte
+	all       bool // -remove=d: quitar la ACL por defecto completa
+}
+
+var aclTagNames = map[string]byte{
+	"u": structures.ACLUserObj, "user": structures.ACLUserObj,
+	"g": structures.ACLGroupObj, "group": structures.ACLGroupObj,
+	"m": structures.ACLMask, "mask": structures.ACLMask,
+	"o": structures.ACLOther, "other": structures.ACLOther,
+}
+
+func ParseSetfacl(tokens []string) (string, error) {
+	cmd := &SETFACL{}
+	processedKeys := make(map[string]bool)
+
+	pathRegex := regexp.MustCompile(`^(?i)-path=(?:"([^"]+)"|([^\s"]+))$`)
+	entryRegex := regexp.MustCompile(`^(?i)-entry=(?:"([^"]+)"|([^\s"]+))$`)
+	removeRegex := regexp.MustCompile(`^(?i)-remove=(?:"([^"]+)"|([^\s"]+))$`)
+
+	if len(tokens) == 0 {
+		return "", errors.New("faltan parámetros: se requiere -path y -entry=<[d:]u|g|m|o:[nombre]:rwx> o -remove=<[d:]u|g:nombre>")
+	}
+
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		var match []string
+		var key string
+		if match = pathRegex.FindStringSubmatch(token); match != nil {
+			key = "-path"
+		} else if match = entryRegex.FindStringSubmatch(token); match != nil {
+			key = "-entry"
+		} else if match = removeRegex.FindStringSubmatch(token); match != nil {
+			key = "-remove"
+		} else {
+			return "", fmt.Errorf("parámetro inválido o no reconocido: '%s'", token)
+		}
+		value := match[1]
+		if value == "" {
+			value = match[2]
+		}
+
+		if processedKeys[key] {
+			return "", fmt.Errorf("parámetro duplicado: %s", key)
+		}
+		processedKeys[key] = true
+		if value == "" {
+			return "", fmt.Errorf("el valor para %s no puede estar vacío", key)
+		}
+
+		switch key {
+		case "-path":
+			if !strings.HasPrefix(value, "/") {
+				return "", fmt.Errorf("la ruta '%s' debe ser absoluta", value)
+			}
+			cmd.path = value
+		case "-entry", "-remove":
+			spec, err := parseACLSpec(value, key == "-remove")
+			if err != nil {
+				return "", err
+			}
+			cmd.entry = spec
+			cmd.spec = value
+			cmd.remove = key == "-remove"
+		}
+	}
+
+	if !processedKeys["-path"] {
+		return "", errors.New("falta el parámetro requerido: -path")
+	}
+	if processedKeys["-entry"] == processedKeys["-remove"] {
+		return "", errors.New("se requiere exactamente uno de -entry o -remove")
+	}
+
+	if err := commandSetfacl(cmd); err != nil {
+		return "", err
+	}
+	if cmd.remove {
+		return fmt.Sprintf("SETFACL: Entrada '%s' eliminada de '%s'.", cmd.spec, cmd.path), nil
+	}
+	return fmt.Sprintf("SETFACL: Entrada '%s' aplicada a '%s'.", cmd.spec, cmd.path), nil
+}
+
+// Interpreta [d:]etiqueta:[nombre]:permisos (o [d:]etiqueta:nombre si remove).
+func parseACLSpec(value string, remove bool) (aclSpec, error) {
+	spec := aclSpec{}
+	parts := strings.Split(value, ":")
+	if strings.EqualFold(parts[0], "d") || strings.EqualFold(parts[0], "default") {
+		spec.isDefault = true
+		parts = parts[1:]
+		if remove && len(parts) == 0 {
+			spec.all = true
+			return spec, nil
+		}
+	}
+
+	want := 3
+	if remove {
+		want = 2
+	}
+	if len(parts) != want {
+		if remove {
+			return spec, fmt.Errorf("entrada inválida '%s': se esperaba [d:]u|g:nombre o d", value)
+		}
+		return spec, fmt.Errorf("entrada inválida '%s': se esperaba [d:]u|g|m|o:[nombre]:rwx", value)
+	}
+
+	tag, ok := aclTagNames[strings.ToLower(parts[0])]
+	if !ok {
+		return spec, fmt.Errorf("etiqueta de ACL inválida '%s': se esperaba u, g, m u o", parts[0])
+	}
+	spec.name = parts[1]
+	if spec.name != "" {
+		switch tag {
+		case structures.ACLUserObj:
+			tag = structures.ACLUser
+		case structures.ACLGroupObj:
+			tag = structures.ACLGroup
+		default:
+			return spec, fmt.Errorf("las entradas mask y other no llevan nombre ('%s')", value)
+		}
+	}
+	spec.tag = tag
+
+	if remove {
+		if spec.name == "" {
+			return spec, fmt.Errorf("solo se pueden quitar entradas con nombre ('%s'); use -entry para cambiar user::, group:: y other::", value)
+		}
+		return spec, nil
+	}
+	perm, err := structures.ParsePermString(parts[2])
+	if err != nil {
+		return spec, err
+	}
+	spec.perm = perm
+	return spec, nil
+}
+
+func commandSetfacl(cmd *SETFACL) error {
+	if !stores.Auth.IsAuthenticated() {
+		return errors.New("comando setfacl requiere sesión iniciada (login)")
+	}
+	currentUser, _, partitionID := stores.Auth.GetCurrentUser()
+
+	sb, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(partitionID)
+	if err != nil {
+		return fmt.Errorf("error al obtener la partición montada '%s': %w", partitionID, err)
+	}
+	if sb.S_magic != 0xEF53 {
+		return fmt.Errorf("magia del superbloque inválida (0x%X), posible corrupción o formato incorrecto", sb.S_magic)
+	}
+	if sb.S_inode_size <= 0 || sb.S_block_size <= 0 {
+		return fmt.Errorf("tamaño de inodo o bloque inválido en superbloque: inode=%d, block=%d", sb.S_inode_size, sb.S_block_size)
+	}
+
+	inodeIndex, inode, err := structures.FindInodeByPath(sb, partitionPath, cmd.path)
+	if err != nil {
+		return fmt.Errorf("no se encontró '%s': %w", cmd.path, err)
+	}
+
+	// Igual que chmod: solo el dueño o root cambian la ACL
+	if currentUser != "root" {
+		uid, _, errUID := getUserInfo(currentUser, sb, partitionPath)
+		if errUID != nil {
+			return fmt.Errorf("no se pudo encontrar info del usuario logueado '%s': %w", currentUser, errUID)
+		}
+		if inode.I_uid != uid {
+			return fmt.Errorf("permiso denegado: solo el dueño (UID %d) o root pueden cambiar la ACL de '%s'", inode.I_uid, cmd.path)
+		}
+	}
+
+	spec := cmd.entry
+	if spec.isDefault && inode.I_type[0] != '0' {
+		return fmt.Errorf("'%s' no es una carpeta: solo las carpetas tienen ACL por defecto", cmd.path)
+	}
+	baseOnly := !spec.isDefault && (spec.tag == structures.ACLUserObj || spec.tag == structures.ACLGroupObj || spec.tag == structures.ACLOther)
+	if !baseOnly && !sb.Xattrs() {
+		return errors.New("el sistema de archivos no guarda atributos extendidos (ejecute tunefs -set=xattrs)")
+	}
+
+	id := int32(-1)
+	if spec.name != "" {
+		kind := "U"
+		if spec.tag == structures.ACLGroup {
+			kind = "G"
+		}
+		if id, err = lookupUsersID(kind, spec.name, sb, partitionPath); err != nil {
+			return err
+		}
+	}
+
+	if baseOnly {
+		// user::, group:: y other:: de la ACL de acceso son los permisos del inodo
+		k := map[byte]int{structures.ACLUserObj: 0, structures.ACLGroupObj: 1, structures.ACLOther: 2}[spec.tag]
+		inode.I_perm[k] = '0' + spec.perm
+		fmt.Printf("Permisos de '%s' ahora %s\n", cmd.path, string(inode.I_perm[:]))
+		if spec.tag == structures.ACLGroupObj && inode.I_xattr != -1 {
+			// La máscara incluye al grupo dueño
+			acl, errACL := sb.ReadACL(partitionPath, inode, structures.XattrACLAccess)
+			if errACL == nil && acl.HasNamed() {
+				errACL = sb.WriteACL(partitionPath, inode, structures.XattrACLAccess, recalcACLMask(acl, inode))
+			}
+			if errACL != nil {
+				return fmt.Errorf("error actualizando la máscara de '%s': %w", cmd.path, errACL)
+			}
+		}
+	} else {
+		name := structures.XattrACLAccess
+		if spec.isDefault {
+			name = structures.XattrACLDefault
+		}
+		acl, errACL := sb.ReadACL(partitionPath, inode, name)
+		if errACL != nil {
+			return fmt.Errorf("error leyendo la ACL de '%s': %w", cmd.path, errACL)
+		}
+
+		switch {
+		case spec.all:
+			acl = structures.ACL{}
+		case cmd.remove:
+			if acl.Find(spec.tag, id) == -1 {
+				return fmt.Errorf("'%s' no tiene la entrada '%s'", cmd.path, cmd.spec)
+			}
+			acl = acl.Remove(spec.tag, id)
+		default:
+			if spec.isDefault && len(acl) == 0 {
+				// La ACL por defecto nueva parte de los permisos actuales de la carpeta
+				acl = structures.ACL{
+					{Tag: structures.ACLUserObj, ID: -1, Perm: structures.PermBits(inode.I_perm[0])},
+					{Tag: structures.ACLGroupObj, ID: -1, Perm: structures.PermBits(inode.I_perm[1])},
+					{Tag: structures.ACLOther, ID: -1, Perm: structures.PermBits(inode.I_perm[2])},
+				}
+			}
+			acl = acl.Set(structures.ACLEntry{Tag: spec.tag, ID: id, Perm: spec.perm})
+		}
+		if !spec.all && spec.tag != structures.ACLMask {
+			acl = recalcACLMask(acl, inode)
+		}
+
+		if err := sb.WriteACL(partitionPath, inode, name, acl); err != nil {
+			return fmt.Errorf("error guardando la ACL de '%s': %w", cmd.path, err)
+		}
+		fmt.Printf("ACL %s de '%s': %d entradas\n", name, cmd.path, len(acl))
+	}
+
+	inode.I_ctime = time.Now().Unix()
+	inodeOffset := int64(sb.S_inode_start) + int64(inodeIndex)*int64(sb.S_inode_size)
+	if err := inode.Serialize(sb, partitionPath, inodeOffset); err != nil {
+		return fmt.Errorf("error serializando inodo %d: %w", inodeIndex, err)
+	}
+
+	if sb.S_filesystem_type == 3 {
+		journalEntryData := structures.Information{
+			I_operation: utils.StringToBytes10("setfacl"),
+			I_path:      utils.StringToBytes32(cmd.path),
+			I_content:   utils.StringToBytes64(cmd.spec),
+		}
+		if errJournal := utils.AppendToJournal(journalEntryData, sb, partitionPath); errJournal != nil {
+			fmt.Printf("Advertencia: Falla al escribir en journal para setfacl '%s': %v\n", cmd.path, errJournal)
+		}
+	}
+
+	fmt.Println("\nSerializando SuperBlock después de SETFACL...")
+	if err := sb.Serialize(partitionPath, int64(mountedPartition.Part_start)); err != nil {
+		return fmt.Errorf("ADVERTENCIA: error al serializar el superbloque después de setfacl, los cambios podrían perderse (%w)", err)
+	}
+	return nil
+}
+
+// Recalcula la máscara como la unión del grupo dueño y las entradas con nombre; sin entradas con
+// nombre la máscara sobra y se quita.
+func recalcACLMask(acl structures.ACL, inode *structures.Inode) structures.ACL {
+	if !acl.HasNamed() {
+		return acl.Remove(structures.ACLMask, -1)
+	}
+	mask := structures.PermBits(inode.I_perm[1])
+	if i := acl.Find(structures.ACLGroupObj, -1); i != -1 {
+		mask = acl[i].Perm
+	}
+	for _, entry := range acl {
+		if entry.Tag == structures.ACLUser || entry.Tag == structures.ACLGroup {
+			mask |= entry.Perm
+		}
+	}
+	return acl.Set(structures.ACLEntry{Tag: structures.ACLMask, ID: -1, Perm: mask})
+}
+
+func ParseGetfacl(tokens []string) (string, error) {
+	cmd := &GETFACL{}
+	pathRegex := regexp.MustCompile(`^(?i)-path=(?:"([^"]+)"|([^\s"]+))$`)
+
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		match := pathRegex.FindStringSubmatch(token)
+		if match == nil {
+			return "", fmt.Errorf("parámetro inválido o no reconocido: '%s'. Se esperaba -path=<ruta>", token)
+		}
+		if cmd.path != "" {
+			return "", errors.New("parámetro duplicado: -path")
+		}
+		cmd.path = match[1]
+		if cmd.path == "" {
+			cmd.path = match[2]
+		}
+		if !strings.HasPrefix(cmd.path, "/") {
+			return "", fmt.Errorf("la ruta '%s' debe ser absoluta", cmd.path)
+		}
+	}
+	if cmd.path == "" {
+		return "", errors.New("falta el parámetro requerido: -path")
+	}
+
+	lines, err := commandGetfacl(cmd)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("GETFACL: %s\n%s", cmd.path, strings.Join(lines, "\n")), nil
+}
+
+func commandGetfacl(cmd *GETFACL) ([]string, error) {
+	if !stores.Auth.IsAuthenticated() {
+		return nil, errors.New("comando getfacl requiere sesión iniciada (login)")
+	}
+	_, _, partitionID := stores.Auth.GetCurrentUser()
+
+	sb, _, partitionPath, err := stores.GetMountedPartitionSuperblock(partitionID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener la partición montada '%s': %w", partitionID, err)
+	}
+	if sb.S_magic != 0xEF53 {
+		return nil, fmt.Errorf("magia del superbloque inválida (0x%X), posible corrupción o formato incorrecto", sb.S_magic)
+	}
+
+	_, inode, err := structures.FindInodeByPath(sb, partitionPath, cmd.path)
+	if err != nil {
+		return nil, fmt.Errorf("no se encontró '%s': %w", cmd.path, err)
+	}
+	access, err := sb.ReadACL(partitionPath, inode, structures.XattrACLAccess)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo la ACL de '%s': %w", cmd.path, err)
+	}
+	def, err := sb.ReadACL(partitionPath, inode, structures.XattrACLDefault)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo la ACL por defecto de '%s': %w", cmd.path, err)
+	}
+
+	lines := []string{
+		"# dueño: " + lookupUsersName("U", inode.I_uid, sb, partitionPath),
+		"# grupo: " + lookupUsersName("G", inode.I_gid, sb, partitionPath),
+	}
+	if inode.I_special != 0 {
+		flags := []byte("---")
+		for i, c := range "sst" {
+			if inode.I_special&(4>>i) != 0 {
+				flags[i] = byte(c)
+			}
+		}
+		lines = append(lines, "# banderas: "+string(flags))
+	}
+	full := structures.ACL{
+		{Tag: structures.ACLUserObj, ID: -1, Perm: structures.PermBits(inode.I_perm[0])},
+		{Tag: structures.ACLGroupObj, ID: -1, Perm: structures.PermBits(inode.I_perm[1])},
+		{Tag: structures.ACLOther, ID: -1, Perm: structures.PermBits(inode.I_perm[2])},
+	}
+	full = append(full, access...)
+	lines = append(lines, formatACL(full, "", sb, partitionPath)...)
+	lines = append(lines, formatACL(def, "default:", sb, partitionPath)...)
+	return lines, nil
+}
+
+// Formatea la ACL en el orden de getfacl (user, group, mask, other), marcando los permisos
+// efectivos cuando la máscara los recorta.
+func formatACL(acl structures.ACL, prefix string, sb *structures.SuperBlock, diskPath string) []string {
+	lines := []string{}
+	mask := acl.Mask()
+	hasMask := acl.Find(structures.ACLMask, -1) != -1
+	for _, tag := range []byte{structures.ACLUserObj, structures.ACLUser, structures.ACLGroupObj, structures.ACLGroup, structures.ACLMask, structures.ACLOther} {
+		for _, entry := range acl {
+			if entry.Tag != tag {
+				continue
+			}
+			var line string
+			switch tag {
+			case structures.ACLUserObj:
+				line = "user::"
+			case structures.ACLUser:
+				line = "user:" + lookupUsersName("U", entry.ID, sb, diskPath) + ":"
+			case structures.ACLGroupObj:
+				line = "group::"
+			case structures.ACLGroup:
+				line = "group:" + lookupUsersName("G", entry.ID, sb, diskPath) + ":"
+			case structures.ACLMask:
+				line = "mask::"
+			case structures.ACLOther:
+				line = "other::"
+			}
+			line = prefix + line + structures.PermString(entry.Perm)
+			masked := tag == structures.ACLUser || tag == structures.ACLGroupObj || tag == structures.ACLGroup
+			if hasMask && masked && entry.Perm&^mask != 0 {
+				line += "\t#efectivo:" + structures.PermString(entry.Perm&mask)
+			}
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
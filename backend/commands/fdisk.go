@@ -22,6 +22,7 @@ type FDISK struct {
 
 	delete string // Opción de eliminar partición
 	add    int    // Opción de agregar espacio a la partición
+	force  bool   // Con -delete: desmontar y cerrar sesión antes de eliminar
 }
 
 func ParseFdisk(tokens []string) (string, error) {
@@ -37,6 +38,7 @@ func ParseFdisk(tokens []string) (string, error) {
 	nameRegex := regexp.MustCompile(`^(?i)-name=(?:"([^"]+)"|([^\s"]{1,16}))$`)
 	deleteRegex := regexp.MustCompile(`^(?i)-delete=(?:"(fast|full)"|(fast|full))$`)
 	addRegex := regexp.MustCompile(`^(?i)-add=(-?\d+)$`)
+	forceRegex := regexp.MustCompile(`^(?i)-force$`)
 
 	fmt.Printf("Tokens FDISK recibidos: %v\n", tokens)
 
@@ -111,6 +113,10 @@ func ParseFdisk(tokens []string) (string, error) {
 			key = "-add"
 			value = match[1]
 			matched = true
+		} else if forceRegex.MatchString(token) {
+			key = "-force"
+			value = "true"
+			matched = true
 		}
 
 		if !matched {
@@ -178,6 +184,8 @@ func ParseFdisk(tokens []string) (string, error) {
 				return "", errors.New("el valor para -add no puede ser cero")
 			}
 			cmd.add = addVal
+		case "-force":
+			cmd.force = true
 		}
 	} 
 
@@ -213,6 +221,9 @@ func ParseFdisk(tokens []string) (string, error) {
 		if processedKeys["-add"] {
 			return "", errors.New("no se puede usar -add al crear partición")
 		}
+		if processedKeys["-force"] {
+			return "", errors.New("-force solo es válido con -delete")
+		}
 		// Establecer valores por defecto si no se dieron
 		if !processedKeys["-unit"] {
 			cmd.unit = "K"
@@ -237,8 +248,8 @@ func ParseFdisk(tokens []string) (string, error) {
 			return "", errors.New("operación add requiere el parámetro -add=<valor> (distinto de cero)")
 		}
 		// -unit es opcional para add, usará default
-		if processedKeys["-size"] || processedKeys["-fit"] || processedKeys["-type"] || processedKeys["-delete"] {
-			return "", errors.New("parámetros -size, -fit, -type, -delete no son válidos con -add")
+		if processedKeys["-size"] || processedKeys["-fit"] || processedKeys["-type"] || processedKeys["-delete"] || processedKeys["-force"] {
+			return "", errors.New("parámetros -size, -fit, -type, -delete, -force no son válidos con -add")
 		}
 		if !processedKeys["-unit"] {
 			cmd.unit = "K"
@@ -411,7 +422,7 @@ func createExtendedPartition(fdisk *FDISK, sizeBytes int) error {
 	firstEBR.Initialize()                
 	firstEBR.Part_start = startPartition // El EBR "vacío" empieza donde la extendida
 	// Serializar primer EBR vacío
-	file, errOpen := os.OpenFile(fdisk.path, os.O_WRONLY, 0644)
+	file, errOpen := structures.OpenDisk(fdisk.path)
 	if errOpen != nil {
 		return fmt.Errorf("error abriendo disco para inicializar EBR: %w", errOpen)
 	}
@@ -452,7 +463,7 @@ func createLogicalPartition(fdisk *FDISK, sizeBytes int) error {
 	}
 	_ = extPartIndex
 
-	file, err := os.OpenFile(fdisk.path, os.O_RDWR, 0644)
+	file, err := structures.OpenDisk(fdisk.path)
 	if err != nil {
 		return fmt.Errorf("error abriendo disco para lógica: %w", err)
 	}
@@ -643,7 +654,7 @@ func deletePartition(cmd *FDISK) error {
 			return fmt.Errorf("partición '%s' no encontrada ni en MBR ni hay Extendida para buscar Lógicas", cmd.name)
 		}
 
-		file, err := os.OpenFile(cmd.path, os.O_RDWR, 0644)
+		file, err := structures.OpenDisk(cmd.path)
 		if err != nil {
 			return fmt.Errorf("error abriendo disco para buscar lógica: %w", err)
 		}
@@ -690,6 +701,27 @@ func deletePartition(cmd *FDISK) error {
 		return fmt.Errorf("partición con nombre '%s' no encontrada en el disco", cmd.name)
 	}
 
+	// Una partición montada solo se elimina con -force (se desmonta y se cierra la sesión antes).
+	// Eliminar una extendida elimina también sus lógicas, así que se revisan todas.
+	affected := []string{cmd.name}
+	if targetPartition != -1 && partitionInfo.Part_type[0] == 'E' {
+		logicals, err := logicalPartitionNames(cmd.path, &partitionInfo)
+		if err != nil {
+			return fmt.Errorf("no se puede eliminar la partición '%s': %w", cmd.name, err)
+		}
+		affected = append(affected, logicals...)
+	}
+	if err := releaseMounts(cmd.path, affected, cmd.force); err != nil {
+		return fmt.Errorf("no se puede eliminar la partición '%s': %w", cmd.name, err)
+	}
+	if targetPartition != -1 {
+		// Releer el MBR por si el desmontaje lo modificó
+		if err := mbr.Deserialize(cmd.path); err != nil {
+			return fmt.Errorf("error leyendo MBR: %w", err)
+		}
+		partitionInfo = mbr.Mbr_partitions[targetPartition]
+	}
+
 	// Confirmación del usuario
 	fmt.Printf("\n¡ADVERTENCIA! Está a punto de eliminar la partición '%s'.\n", cmd.name)
 	if targetPartition != -1 && partitionInfo.Part_type[0] == 'E' {
@@ -698,7 +730,7 @@ func deletePartition(cmd *FDISK) error {
 
 
 
-	file, err := os.OpenFile(cmd.path, os.O_RDWR, 0644) // Se necesita RDWR para borrar
+	file, err := structures.OpenDisk(cmd.path) // Se necesita RDWR para borrar
 	if err != nil {
 		return fmt.Errorf("error re-abriendo disco para escritura: %w", err)
 	}
@@ -816,6 +848,51 @@ func deletePartition(cmd *FDISK) error {
 	return nil
 }
 
+// Devuelve los nombres de las particiones lógicas de la cadena de EBRs de la extendida ext.
+func logicalPartitionNames(diskPath string, ext *structures.Partition) ([]string, error) {
+	logicals, err := logicalPartitions(diskPath, ext)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, ebr := range logicals {
+		names = append(names, strings.TrimRight(string(ebr.Part_name[:]), "\x00 "))
+	}
+	return names, nil
+}
+
+// Devuelve los EBRs en uso (con nombre y tamaño) de la cadena de la extendida ext, en orden.
+func logicalPartitions(diskPath string, ext *structures.Partition) ([]structures.EBR, error) {
+	file, err := structures.OpenDisk(diskPath)
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo disco para leer las lógicas: %w", err)
+	}
+	defer file.Close()
+
+	logicals := []structures.EBR{}
+	currentPos := int64(ext.Part_start)
+	for {
+		var ebr structures.EBR
+		if _, err := file.Seek(currentPos, 0); err != nil {
+			return nil, fmt.Errorf("error buscando EBR en %d: %w", currentPos, err)
+		}
+		if err := binary.Read(file, binary.LittleEndian, &ebr); err != nil {
+			return nil, fmt.Errorf("error leyendo EBR en %d: %w", currentPos, err)
+		}
+		name := strings.TrimRight(string(ebr.Part_name[:]), "\x00 ")
+		if ebr.Part_status[0] != 'N' && ebr.Part_size > 0 && name != "" {
+			logicals = append(logicals, ebr)
+		}
+		if ebr.Part_next == -1 {
+			return logicals, nil
+		}
+		if int64(ebr.Part_next) <= currentPos {
+			return nil, errors.New("ciclo detectado en EBRs")
+		}
+		currentPos = int64(ebr.Part_next)
+	}
+}
+
 // Lógica para añadir/quitar espacio
 func addSpaceToPartition(cmd *FDISK) error {
 	fmt.Printf("Intentando modificar tamaño: Path='%s', Nombre='%s', Add='%d', Unit='%s'\n", cmd.path, cmd.name, cmd.add, cmd.unit)
@@ -929,7 +1006,7 @@ func addSpaceToPartition(cmd *FDISK) error {
 }
 
 // Helper para rellenar un área del disco con ceros
-func zeroOutSpace(file *os.File, offset int64, size int64) error {
+func zeroOutSpace(file *structures.DiskFile, offset int64, size int64) error {
 	if size <= 0 {
 		return nil
 	} // Nada que borrar
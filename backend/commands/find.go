@@ -181,7 +181,7 @@ func recursiveFind(
 	// Leer Inodo Actual
 	currentInode := &structures.Inode{}
 	currentInodeOffset := int64(sb.S_inode_start + currentInodeIndex*sb.S_inode_size)
-	if err := currentInode.Deserialize(diskPath, currentInodeOffset); err != nil {
+	if err := currentInode.Deserialize(sb, diskPath, currentInodeOffset); err != nil {
 		// No se puede leer este inodo, reportar y no continuar por esta rama
 		fmt.Printf("    Error leyendo inodo %d en '%s': %v. Saltando.\n", currentInodeIndex, currentDirPath, err)
 		return nil // No es fatal para la búsqueda general, solo esta rama
@@ -201,84 +201,73 @@ func recursiveFind(
 	fmt.Printf("    Permiso de lectura concedido para '%s'.\n", currentDirPath)
 
 	// Iterar Bloques del Directorio
-	for i := 0; i < 12; i++ { // Solo directos
-		blockPtr := currentInode.I_block[i]
-		if blockPtr == -1 || blockPtr < 0 || blockPtr >= sb.S_blocks_count {
-			continue
-		}
-
-		folderBlock := structures.FolderBlock{}
-		blockOffset := int64(sb.S_block_start + blockPtr*sb.S_block_size)
-		if err := folderBlock.Deserialize(diskPath, blockOffset); err != nil {
-			fmt.Printf("      Advertencia: Error leyendo bloque %d del dir %d: %v. Saltando bloque.\n", blockPtr, currentInodeIndex, err)
+	entries, err := sb.ReadDir(diskPath, currentInode)
+	if err != nil {
+		fmt.Printf("      Advertencia: Error leyendo dir %d: %v\n", currentInodeIndex, err)
+	}
+	for _, entry := range entries {
+		entryName := entry.Name
+		if entryName == "." || entryName == ".." {
 			continue
-		}
+		} 
 
-		// Iterar Entradas del Bloque
-		for j := range folderBlock.B_content {
-			entry := folderBlock.B_content[j]
-			if entry.B_inodo == -1 {
-				continue
-			} // Slot libre
-
-			entryName := strings.TrimRight(string(entry.B_name[:]), "\x00")
-			if entryName == "." || entryName == ".." {
-				continue
-			} 
-
-			childInodeIndex := entry.B_inodo
-
-			// Verificar si el NOMBRE de la entrada coincide con el patrón
-			fmt.Printf("        Comparando '%s' con patrón '%s'... ", entryName, nameMatcher.String())
-			if nameMatcher.MatchString(entryName) {
-				fmt.Println("¡Match!")
-				// Construir path completo del item encontrado
-				var fullPath string
-				if currentDirPath == "/" {
-					fullPath = "/" + entryName
-				} else {
-					fullPath = currentDirPath + "/" + entryName
-				}
+		childInodeIndex := entry.Inode
 
-				// Añadir al slice de resultados (usando puntero)
-				*results = append(*results, fullPath)
-				fmt.Printf("          Añadido a resultados: %s\n", fullPath)
+		// Verificar si el NOMBRE de la entrada coincide con el patrón
+		fmt.Printf("        Comparando '%s' con patrón '%s'... ", entryName, nameMatcher.String())
+		if nameMatcher.MatchString(entryName) {
+			fmt.Println("¡Match!")
+			// Construir path completo del item encontrado
+			var fullPath string
+			if currentDirPath == "/" {
+				fullPath = "/" + entryName
 			} else {
-				fmt.Println("No match.")
+				fullPath = currentDirPath + "/" + entryName
 			}
 
-			// Si la entrada es un subdirectorio, llamar recursivamente
-			// Leer el inodo hijo para saber su tipo
-			childInode := &structures.Inode{}
-			// Validar índice antes de usar
-			if childInodeIndex >= 0 && childInodeIndex < sb.S_inodes_count {
-				childInodeOffset := int64(sb.S_inode_start + childInodeIndex*sb.S_inode_size)
-				if err := childInode.Deserialize(diskPath, childInodeOffset); err == nil {
-					// Solo continuar si pudimos leer el inodo hijo
-					if childInode.I_type[0] == '0' { // Es un directorio
-						// Construir path completo para la llamada recursiva
-						var childFullPath string
-						if currentDirPath == "/" {
-							childFullPath = "/" + entryName
-						} else {
-							childFullPath = currentDirPath + "/" + entryName
-						}
-						// Llamada recursiva
-						fmt.Printf("        Entrando recursivamente en '%s' (inodo %d)...\n", childFullPath, childInodeIndex)
-						errRec := recursiveFind(childFullPath, childInodeIndex, nameMatcher, sb, diskPath, currentUser, userGIDStr, results)
-						if errRec != nil {
-							fmt.Printf("        Error retornando de recursión en '%s': %v\n", childFullPath, errRec)
-							return errRec
-						}
+			// Los enlaces se muestran con su destino; find no entra en ellos
+			if target, ok := symlinkTargetOf(sb, diskPath, childInodeIndex); ok {
+				fullPath += " -> " + target
+			}
+
+			// Añadir al slice de resultados (usando puntero)
+			*results = append(*results, fullPath)
+			fmt.Printf("          Añadido a resultados: %s\n", fullPath)
+		} else {
+			fmt.Println("No match.")
+		}
+
+		// Si la entrada es un subdirectorio, llamar recursivamente
+		// Leer el inodo hijo para saber su tipo
+		childInode := &structures.Inode{}
+		// Validar índice antes de usar
+		if childInodeIndex >= 0 && childInodeIndex < sb.S_inodes_count {
+			childInodeOffset := int64(sb.S_inode_start + childInodeIndex*sb.S_inode_size)
+			if err := childInode.Deserialize(sb, diskPath, childInodeOffset); err == nil {
+				// Solo continuar si pudimos leer el inodo hijo
+				if childInode.I_type[0] == '0' { // Es un directorio
+					// Construir path completo para la llamada recursiva
+					var childFullPath string
+					if currentDirPath == "/" {
+						childFullPath = "/" + entryName
+					} else {
+						childFullPath = currentDirPath + "/" + entryName
+					}
+					// Llamada recursiva
+					fmt.Printf("        Entrando recursivamente en '%s' (inodo %d)...\n", childFullPath, childInodeIndex)
+					errRec := recursiveFind(childFullPath, childInodeIndex, nameMatcher, sb, diskPath, currentUser, userGIDStr, results)
+					if errRec != nil {
+						fmt.Printf("        Error retornando de recursión en '%s': %v\n", childFullPath, errRec)
+						return errRec
 					}
-				} else {
-					fmt.Printf("        Advertencia: No se pudo leer inodo hijo %d (nombre '%s') para recursión: %v\n", childInodeIndex, entryName, err)
 				}
 			} else {
-				fmt.Printf("        Advertencia: Índice de inodo inválido %d encontrado para entrada '%s'.\n", childInodeIndex, entryName)
+				fmt.Printf("        Advertencia: No se pudo leer inodo hijo %d (nombre '%s') para recursión: %v\n", childInodeIndex, entryName, err)
 			}
-		} 
-	} 
+		} else {
+			fmt.Printf("        Advertencia: Índice de inodo inválido %d encontrado para entrada '%s'.\n", childInodeIndex, entryName)
+		}
+	}
 
 	fmt.Printf("<-- recursiveFind: Saliendo de inodo %d ('%s')\n", currentInodeIndex, currentDirPath)
 	return nil 
@@ -0,0 +1,185 @@
+package commands
+
+import (
+	structures "backend/structures"
+	utils "backend/utils"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type GROWDISK struct {
+	path   string // Path del disco
+	add    int    // Espacio a agregar
+	unit   string // Unidad de -add (B, K o M)
+	extend bool   // Extender la última partición hasta el nuevo final del disco
+}
+
+func ParseGrowdisk(tokens []string) (string, error) {
+	cmd := &GROWDISK{}
+	processedKeys := make(map[string]bool)
+
+	pathRegex := regexp.MustCompile(`^(?i)-path=(?:"([^"]+)"|([^\s"]+))$`)
+	addRegex := regexp.MustCompile(`^(?i)-add=(\d+)$`)
+	unitRegex := regexp.MustCompile(`^(?i)-unit=(?:"([kKmMBb])"|([kKmMBb]))$`)
+	extendRegex := regexp.MustCompile(`^(?i)-extend$`)
+
+	if len(tokens) == 0 {
+		return "", errors.New("faltan parámetros: se requiere -path y -add")
+	}
+
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		var match []string
+		var key string
+		var value string
+
+		if match = pathRegex.FindStringSubmatch(token); match != nil {
+			key = "-path"
+		} else if match = addRegex.FindStringSubmatch(token); match != nil {
+			key = "-add"
+		} else if match = unitRegex.FindStringSubmatch(token); match != nil {
+			key = "-unit"
+		} else if extendRegex.MatchString(token) {
+			key = "-extend"
+			match = []string{token, "true"}
+		} else {
+			return "", fmt.Errorf("parámetro inválido o no reconocido: '%s'. Se esperaba -path=, -add=, -unit= o -extend", token)
+		}
+		value = match[1]
+		if value == "" && len(match) > 2 {
+			value = match[2]
+		}
+
+		if processedKeys[key] {
+			return "", fmt.Errorf("parámetro duplicado: %s", key)
+		}
+		processedKeys[key] = true
+		if value == "" {
+			return "", fmt.Errorf("el valor para %s no puede estar vacío", key)
+		}
+
+		switch key {
+		case "-path":
+			cmd.path = filepath.Clean(value)
+		case "-add":
+			add, err := strconv.Atoi(value)
+			if err != nil || add <= 0 {
+				return "", fmt.Errorf("valor inválido para -add: '%s'. Debe ser un entero positivo", value)
+			}
+			cmd.add = add
+		case "-unit":
+			cmd.unit = strings.ToUpper(value)
+		case "-extend":
+			cmd.extend = true
+		}
+	}
+
+	if !processedKeys["-path"] {
+		return "", errors.New("falta el parámetro requerido: -path")
+	}
+	if !processedKeys["-add"] {
+		return "", errors.New("falta el parámetro requerido: -add")
+	}
+	if !processedKeys["-unit"] {
+		cmd.unit = "K"
+	}
+
+	oldSize, newSize, extended, err := commandGrowdisk(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	result := fmt.Sprintf("GROWDISK: Disco extendido exitosamente\n"+
+		"-> Path: %s\n"+
+		"-> Tamaño anterior: %d bytes\n"+
+		"-> Tamaño nuevo: %d bytes",
+		cmd.path, oldSize, newSize)
+	if extended != "" {
+		result += fmt.Sprintf("\n-> Partición extendida: %s", extended)
+	}
+	return result, nil
+}
+
+// Devuelve el tamaño anterior, el nuevo y el nombre de la partición extendida (si se usó -extend).
+func commandGrowdisk(cmd *GROWDISK) (int32, int32, string, error) {
+	addBytes, err := utils.ConvertToBytes(cmd.add, cmd.unit)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("error convirtiendo -add a bytes: %w", err)
+	}
+
+	var mbr structures.MBR
+	if err := mbr.Deserialize(cmd.path); err != nil {
+		return 0, 0, "", fmt.Errorf("error leyendo MBR de '%s': %w", cmd.path, err)
+	}
+	oldSize := mbr.Mbr_size
+	if int64(oldSize)+int64(addBytes) > math.MaxInt32 {
+		return 0, 0, "", fmt.Errorf("el tamaño resultante excede el máximo soportado por el MBR (%d bytes)", math.MaxInt32)
+	}
+	newSize := oldSize + int32(addBytes)
+
+	// Buscar la partición que termina más cerca del final del disco
+	extendIndex := -1
+	if cmd.extend {
+		var lastEnd int32 = -1
+		for i, part := range mbr.Mbr_partitions {
+			if part.Part_status[0] == 'N' || part.Part_size <= 0 {
+				continue
+			}
+			if end := part.Part_start + part.Part_size; end > lastEnd {
+				lastEnd = end
+				extendIndex = i
+			}
+		}
+		if extendIndex == -1 {
+			return 0, 0, "", errors.New("-extend: el disco no tiene particiones para extender")
+		}
+	}
+
+	// Extender el archivo; Truncate deja el espacio nuevo disperso igual que mkdisk. Va directo al
+	// archivo: bajar antes lo pendiente si el disco está montado
+	fmt.Printf("Extendiendo '%s' de %d a %d bytes\n", cmd.path, oldSize, newSize)
+	if err := structures.SyncDisk(cmd.path); err != nil {
+		return 0, 0, "", fmt.Errorf("error sincronizando el disco '%s': %w", cmd.path, err)
+	}
+	info, err := os.Stat(cmd.path)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("error accediendo al disco '%s': %w", cmd.path, err)
+	}
+	if info.Size() < int64(newSize) {
+		if err := os.Truncate(cmd.path, int64(newSize)); err != nil {
+			return 0, 0, "", fmt.Errorf("error extendiendo el archivo '%s': %w", cmd.path, err)
+		}
+		// Si el disco está montado, su caché todavía tiene el tamaño anterior
+		if err := structures.InvalidateDisk(cmd.path); err != nil {
+			return 0, 0, "", fmt.Errorf("error actualizando el disco montado '%s': %w", cmd.path, err)
+		}
+	}
+
+	mbr.Mbr_size = newSize
+	extended := ""
+	if extendIndex != -1 {
+		part := &mbr.Mbr_partitions[extendIndex]
+		extended = strings.TrimRight(string(part.Part_name[:]), "\x00 ")
+		fmt.Printf("Extendiendo partición '%s' de %d a %d bytes\n", extended, part.Part_size, newSize-part.Part_start)
+		part.Part_size = newSize - part.Part_start
+		if part.Part_type[0] == 'P' && readSuperblockMagic(cmd.path, part.Part_start) == 0xEF53 {
+			fmt.Println("La partición tiene sistema de archivos: use resizefs para aprovechar el espacio nuevo.")
+		}
+	}
+
+	if err := mbr.Serialize(cmd.path); err != nil {
+		return 0, 0, "", fmt.Errorf("error escribiendo MBR actualizado: %w", err)
+	}
+
+	return oldSize, newSize, extended, nil
+}
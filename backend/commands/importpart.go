@@ -0,0 +1,258 @@
+package commands
+
+import (
+	stores "backend/stores"
+	structures "backend/structures"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type IMPORTPART struct {
+	path   string // Disco destino
+	name   string // Partición destino
+	file   string // Imagen generada con exportpart
+	resize bool   // Redimensionar el sistema de archivos al tamaño de la partición
+}
+
+func ParseImportpart(tokens []string) (string, error) {
+	cmd := &IMPORTPART{}
+	processedKeys := make(map[string]bool)
+
+	pathRegex := regexp.MustCompile(`^(?i)-path=(?:"([^"]+)"|([^\s"]+))$`)
+	nameRegex := regexp.MustCompile(`^(?i)-name=(?:"([^"]+)"|([^\s"]+))$`)
+	fileRegex := regexp.MustCompile(`^(?i)-file=(?:"([^"]+)"|([^\s"]+))$`)
+	resizeRegex := regexp.MustCompile(`^(?i)-resize$`)
+
+	if len(tokens) == 0 {
+		return "", errors.New("faltan parámetros: se requiere -path, -name y -file")
+	}
+
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		var match []string
+		var key string
+		var value string
+		matched := false
+
+		if match = pathRegex.FindStringSubmatch(token); match != nil {
+			key = "-path"
+			matched = true
+		} else if match = nameRegex.FindStringSubmatch(token); match != nil {
+			key = "-name"
+			matched = true
+		} else if match = fileRegex.FindStringSubmatch(token); match != nil {
+			key = "-file"
+			matched = true
+		} else if resizeRegex.MatchString(token) {
+			if processedKeys["-resize"] {
+				return "", errors.New("parámetro duplicado: -resize")
+			}
+			processedKeys["-resize"] = true
+			cmd.resize = true
+			continue
+		}
+		if !matched {
+			return "", fmt.Errorf("parámetro inválido o no reconocido: '%s'. Se esperaba -path=, -name=, -file= o -resize", token)
+		}
+		if match[1] != "" {
+			value = match[1]
+		} else {
+			value = match[2]
+		}
+
+		if processedKeys[key] {
+			return "", fmt.Errorf("parámetro duplicado: %s", key)
+		}
+		processedKeys[key] = true
+		if value == "" {
+			return "", fmt.Errorf("el valor para %s no puede estar vacío", key)
+		}
+
+		switch key {
+		case "-path":
+			cmd.path = filepath.Clean(value)
+		case "-name":
+			cmd.name = value
+		case "-file":
+			cmd.file = filepath.Clean(value)
+		}
+	}
+
+	for _, key := range []string{"-path", "-name", "-file"} {
+		if !processedKeys[key] {
+			return "", fmt.Errorf("falta el parámetro requerido: %s", key)
+		}
+	}
+
+	summary, err := commandImportpart(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("IMPORTPART: Imagen importada exitosamente\n"+
+		"-> Disco: %s\n"+
+		"-> Partición: %s\n"+
+		"-> Imagen: %s%s",
+		cmd.path, cmd.name, cmd.file, summary), nil
+}
+
+func commandImportpart(cmd *IMPORTPART) (string, error) {
+	fmt.Printf("Importando imagen '%s' en partición '%s' de '%s'\n", cmd.file, cmd.name, cmd.path)
+
+	info, err := os.Stat(cmd.file)
+	if err != nil {
+		return "", fmt.Errorf("no se pudo acceder a la imagen '%s': %w", cmd.file, err)
+	}
+
+	var mbr structures.MBR
+	if err := mbr.Deserialize(cmd.path); err != nil {
+		return "", fmt.Errorf("error leyendo MBR del disco '%s': %w", cmd.path, err)
+	}
+	partition, _, err := mbr.GetPartitionByName(cmd.name)
+	if err != nil {
+		return "", err
+	}
+	if partition.Part_type[0] != 'P' {
+		return "", fmt.Errorf("solo se pueden importar imágenes en particiones primarias ('%s' es tipo %c)", cmd.name, partition.Part_type[0])
+	}
+	if partition.Part_status[0] == '1' {
+		return "", fmt.Errorf("la partición '%s' está montada; desmóntela antes de importar", cmd.name)
+	}
+	if info.Size() > int64(partition.Part_size) {
+		return "", fmt.Errorf("la imagen (%d bytes) no cabe en la partición '%s' (%d bytes)", info.Size(), cmd.name, partition.Part_size)
+	}
+
+	imageHasFs := readSuperblockMagic(cmd.file, 0) == 0xEF53
+	if err := copyFileRange(cmd.file, 0, cmd.path, int64(partition.Part_start), info.Size()); err != nil {
+		return "", fmt.Errorf("error importando imagen: %w", err)
+	}
+	// Verificar la copia contra la imagen antes de tocar el superbloque
+	if err := compareFileRange(cmd.file, 0, cmd.path, int64(partition.Part_start), info.Size()); err != nil {
+		return "", fmt.Errorf("verificación de la importación falló: %w", err)
+	}
+
+	summary := ""
+	sb := &structures.SuperBlock{}
+	if err := sb.Deserialize(cmd.path, int64(partition.Part_start)); err == nil && sb.S_magic == 0xEF53 {
+		// Los offsets del superbloque son absolutos: moverlos al inicio de la nueva partición
+		oldStart := sb.S_bm_inode_start - sb.DiskSize()
+		delta := partition.Part_start - oldStart
+		if delta != 0 {
+			fmt.Printf("Reubicando offsets del superbloque (delta %d bytes)...\n", delta)
+			sb.S_bm_inode_start += delta
+			sb.S_bm_block_start += delta
+			sb.S_inode_start += delta
+			sb.S_block_start += delta
+			if err := sb.Serialize(cmd.path, int64(partition.Part_start)); err != nil {
+				return "", fmt.Errorf("error actualizando superbloque importado: %w", err)
+			}
+		}
+
+		if cmd.resize {
+			oldSb, newSb, err := resizeFilesystem(partition, cmd.path)
+			if err != nil {
+				return "", fmt.Errorf("imagen importada, pero falló el redimensionado: %w", err)
+			}
+			summary = fmt.Sprintf("\n-> Inodos: %d -> %d", oldSb.S_inodes_count, newSb.S_inodes_count)
+		}
+	} else if cmd.resize {
+		fmt.Println("Advertencia: la imagen no contiene un sistema de archivos; se omite -resize.")
+	}
+
+	// Verificar resultado: el sistema de archivos de la imagen debe quedar dentro de la partición
+	if imageHasFs {
+		if err := verifyImportedFilesystem(cmd.path, partition); err != nil {
+			return "", fmt.Errorf("verificación de la importación falló: %w", err)
+		}
+	} else {
+		summary += "\n-> Advertencia: la partición no contiene un sistema de archivos EXT2/EXT3"
+	}
+
+	stores.DiskRegistry[cmd.path] = filepath.Base(cmd.path)
+	fmt.Println("Importación completada.")
+	return summary, nil
+}
+
+// Compara size bytes de src desde srcOffset con dst desde dstOffset, leyendo por partes.
+func compareFileRange(src string, srcOffset int64, dst string, dstOffset int64, size int64) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("error abriendo '%s': %w", src, err)
+	}
+	defer in.Close()
+	out, err := os.Open(dst)
+	if err != nil {
+		return fmt.Errorf("error abriendo '%s': %w", dst, err)
+	}
+	defer out.Close()
+
+	const chunk = 64 * 1024
+	want, got := make([]byte, chunk), make([]byte, chunk)
+	for done := int64(0); done < size; {
+		n := int(min(chunk, size-done))
+		if _, err := in.ReadAt(want[:n], srcOffset+done); err != nil {
+			return fmt.Errorf("error leyendo '%s' en %d: %w", src, srcOffset+done, err)
+		}
+		if _, err := out.ReadAt(got[:n], dstOffset+done); err != nil {
+			return fmt.Errorf("error leyendo '%s' en %d: %w", dst, dstOffset+done, err)
+		}
+		if !bytes.Equal(want[:n], got[:n]) {
+			for i := range n {
+				if want[i] != got[i] {
+					return fmt.Errorf("el byte %d de la imagen no coincide con el disco (offset %d)", done+int64(i), dstOffset+done+int64(i))
+				}
+			}
+		}
+		done += int64(n)
+	}
+	fmt.Printf("Copia verificada: %d bytes iguales a la imagen.\n", size)
+	return nil
+}
+
+// Verifica que el superbloque importado tenga el número mágico y que sus áreas (bitmaps,
+// inodos y bloques) empiecen en la partición, en orden, y quepan en Part_size.
+func verifyImportedFilesystem(diskPath string, partition *structures.Partition) error {
+	sb := &structures.SuperBlock{}
+	if err := sb.Deserialize(diskPath, int64(partition.Part_start)); err != nil {
+		return fmt.Errorf("no se pudo leer el superbloque importado: %w", err)
+	}
+	if sb.S_magic != 0xEF53 {
+		return fmt.Errorf("el superbloque en %d no tiene el número mágico 0xEF53 (leído 0x%X)", partition.Part_start, sb.S_magic)
+	}
+	start, end := int64(partition.Part_start), int64(partition.Part_start)+int64(partition.Part_size)
+	areas := []struct {
+		name  string
+		start int64
+	}{
+		{"superbloque", int64(sb.S_bm_inode_start - sb.DiskSize())},
+		{"bitmap de inodos", int64(sb.S_bm_inode_start)},
+		{"bitmap de bloques", int64(sb.S_bm_block_start)},
+		{"tabla de inodos", int64(sb.S_inode_start)},
+		{"bloques", int64(sb.S_block_start)},
+	}
+	if areas[0].start != start {
+		return fmt.Errorf("el superbloque indica que la partición empieza en %d, pero empieza en %d", areas[0].start, start)
+	}
+	for i := 1; i < len(areas); i++ {
+		if areas[i].start < areas[i-1].start || areas[i].start > end {
+			return fmt.Errorf("el inicio de %s (%d) está fuera de la partición [%d, %d) o antes de %s", areas[i].name, areas[i].start, start, end, areas[i-1].name)
+		}
+	}
+	if inodesEnd := int64(sb.S_inode_start) + int64(sb.S_inodes_count)*int64(sb.S_inode_size); inodesEnd > int64(sb.S_block_start) {
+		return fmt.Errorf("la tabla de inodos (hasta %d) se superpone con los bloques (desde %d)", inodesEnd, sb.S_block_start)
+	}
+	if blocksEnd := int64(sb.S_block_start) + int64(sb.S_blocks_count)*int64(sb.S_block_size); blocksEnd > end {
+		return fmt.Errorf("los bloques terminan en %d, después del final de la partición (%d)", blocksEnd, end)
+	}
+	fmt.Printf("Sistema de archivos importado verificado: magic 0xEF53, áreas dentro de la partición.\n")
+	return nil
+}
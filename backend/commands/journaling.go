@@ -1,8 +1,6 @@
 package commands
 
 import (
-	"bytes"           
-	"encoding/binary" 
 	"errors"
 	"fmt"
 	"regexp"
@@ -99,7 +97,7 @@ func commandJournaling(cmd *JOURNALING) (string, error) {
 	journalInodeIndex := int32(2)
 	journalInode := &structures.Inode{}
 	journalInodeOffset := int64(sb.S_inode_start + journalInodeIndex*sb.S_inode_size)
-	if err := journalInode.Deserialize(diskPath, journalInodeOffset); err != nil {
+	if err := journalInode.Deserialize(sb, diskPath, journalInodeOffset); err != nil {
 		return "", fmt.Errorf("error crítico: no se pudo leer el inodo del journal (%d): %w", journalInodeIndex, err)
 	}
 	if journalInode.I_type[0] != '1' {
@@ -123,23 +121,9 @@ func commandJournaling(cmd *JOURNALING) (string, error) {
 	fmt.Printf("Contenido del journal leído: %d bytes\n", len(journalContentBytes))
 
 	// Deserializar Entradas del Journal
-	journalEntries := []structures.Journal{}
-	journalEntrySize := int(binary.Size(structures.Journal{}))
-	if journalEntrySize <= 0 {
-		return "", errors.New("tamaño de struct Journal inválido")
-	}
-
-	reader := bytes.NewReader(journalContentBytes)
-	entryCount := 0
-	for reader.Len() >= journalEntrySize {
-		var entry structures.Journal
-		err := binary.Read(reader, binary.LittleEndian, &entry)
-		if err != nil {
-			fmt.Printf("Advertencia: Error deserializando entrada journal #%d: %v. Lectura parcial posible.\n", entryCount+1, err)
-			break
-		}
-		journalEntries = append(journalEntries, entry)
-		entryCount++
+	journalEntries, errDecode := structures.DecodeJournal(journalContentBytes, sb.S_feature_incompat)
+	if errDecode != nil {
+		fmt.Printf("Advertencia: %v. Lectura parcial posible.\n", errDecode)
 	}
 	fmt.Printf("Deserializadas %d entradas del journal.\n", len(journalEntries))
 
@@ -158,7 +142,7 @@ func commandJournaling(cmd *JOURNALING) (string, error) {
 		path := strings.TrimRight(string(entry.J_content.I_path[:]), "\x00 ")
 		content := strings.TrimRight(string(entry.J_content.I_content[:]), "\x00 ")
 		// Escapar comas y punto y comas dentro de los campos si fuera necesario
-		dateStr := time.Unix(int64(entry.J_content.I_date), 0).Format(dateFormat)
+		dateStr := time.Unix(entry.J_content.I_date, 0).Format(dateFormat)
 
 		// Añadir campos separados por coma
 		outputBuilder.WriteString(op)
@@ -0,0 +1,292 @@
+package commands
+
+import (
+	stores "backend/stores"
+	structures "backend/structures"
+	utils "backend/utils"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type LN struct {
+	symbolic bool   // -s: enlace simbólico; sin -s, enlace duro
+	target   string // Destino del enlace: para -s, absoluto o relativo a la carpeta del enlace; si no, un archivo existente
+	path     string // Ruta del enlace a crear
+}
+
+func ParseLn(tokens []string) (string, error) {
+	cmd := &LN{}
+	processedKeys := make(map[string]bool)
+
+	symbolicRegex := regexp.MustCompile(`^(?i)-s$`)
+	targetRegex := regexp.MustCompile(`^(?i)-target=(?:"([^"]+)"|([^\s"]+))$`)
+	pathRegex := regexp.MustCompile(`^(?i)-path=(?:"([^"]+)"|([^\s"]+))$`)
+
+	if len(tokens) == 0 {
+		return "", errors.New("faltan parámetros: se requiere [-s] -target=<destino> -path=<ruta>")
+	}
+
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		if symbolicRegex.MatchString(token) {
+			if processedKeys["-s"] {
+				return "", errors.New("parámetro duplicado: -s")
+			}
+			processedKeys["-s"] = true
+			cmd.symbolic = true
+			continue
+		}
+
+		var match []string
+		var key string
+		if match = targetRegex.FindStringSubmatch(token); match != nil {
+			key = "-target"
+		} else if match = pathRegex.FindStringSubmatch(token); match != nil {
+			key = "-path"
+		} else {
+			return "", fmt.Errorf("parámetro inválido o no reconocido: '%s'. Se esperaba [-s] -target=<destino> -path=<ruta>", token)
+		}
+		value := match[1]
+		if value == "" {
+			value = match[2]
+		}
+
+		if processedKeys[key] {
+			return "", fmt.Errorf("parámetro duplicado: %s", key)
+		}
+		processedKeys[key] = true
+		if value == "" {
+			return "", fmt.Errorf("el valor para %s no puede estar vacío", key)
+		}
+
+		switch key {
+		case "-target":
+			cmd.target = value
+		case "-path":
+			if !strings.HasPrefix(value, "/") {
+				return "", fmt.Errorf("la ruta '%s' debe ser absoluta", value)
+			}
+			cmd.path = value
+		}
+	}
+
+	if !processedKeys["-target"] {
+		return "", errors.New("falta el parámetro requerido: -target")
+	}
+	if !processedKeys["-path"] {
+		return "", errors.New("falta el parámetro requerido: -path")
+	}
+
+	links, err := commandLn(cmd)
+	if err != nil {
+		return "", err
+	}
+	if !cmd.symbolic {
+		return fmt.Sprintf("LN: Enlace duro '%s' -> '%s' creado correctamente (%d enlaces).", cmd.path, cmd.target, links), nil
+	}
+	return fmt.Sprintf("LN: Enlace '%s' -> '%s' creado correctamente.", cmd.path, cmd.target), nil
+}
+
+// Crea un enlace simbólico o, sin -s, una entrada más para el inodo de un archivo existente.
+// Devuelve la cantidad de enlaces del inodo.
+func commandLn(cmd *LN) (int32, error) {
+	if !stores.Auth.IsAuthenticated() {
+		return 0, errors.New("comando ln requiere sesión iniciada (login)")
+	}
+	currentUser, userGIDStr, partitionID := stores.Auth.GetCurrentUser()
+
+	sb, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(partitionID)
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener la partición montada '%s': %w", partitionID, err)
+	}
+	if sb.S_magic != 0xEF53 {
+		return 0, fmt.Errorf("magia del superbloque inválida (0x%X), posible corrupción o formato incorrecto", sb.S_magic)
+	}
+	if sb.S_inode_size <= 0 || sb.S_block_size <= 0 {
+		return 0, fmt.Errorf("tamaño de inodo o bloque inválido en superbloque: inode=%d, block=%d", sb.S_inode_size, sb.S_block_size)
+	}
+	userID, groupID, err := getUserInfo(currentUser, sb, partitionPath)
+	if err != nil {
+		return 0, fmt.Errorf("error obteniendo UID/GID de '%s': %w", currentUser, err)
+	}
+	// Los enlaces simbólicos se marcan con una característica ro_compat, que requiere la extensión
+	// del superbloque; la revisión 1 ya la tiene y basta con subir S_rev_level. Los duros necesitan
+	// que el inodo tenga I_links.
+	if cmd.symbolic {
+		if !sb.HasExtension() {
+			return 0, errors.New("el superbloque no tiene extensión de características y no admite enlaces (ejecute migratefs)")
+		}
+		if err := structures.ValidateSymlinkTarget(cmd.target); err != nil {
+			return 0, err
+		}
+	} else if !sb.LinkCounts() {
+		return 0, errors.New("el sistema de archivos no guarda contadores de enlaces (ejecute tunefs -set=link_counts)")
+	}
+
+	cleanPath := strings.TrimSuffix(cmd.path, "/")
+	if cleanPath == "" {
+		return 0, errors.New("no se puede crear un enlace en la raíz '/'")
+	}
+	parentPath := filepath.Dir(cleanPath)
+	linkName := filepath.Base(cleanPath)
+	if linkName == "." || linkName == ".." {
+		return 0, fmt.Errorf("nombre de enlace inválido: '%s'", linkName)
+	}
+	if err := sb.ValidateName(linkName); err != nil {
+		return 0, err
+	}
+
+	parentInodeIndex, parentInode, err := ensureParentDirExists(parentPath, false, sb, partitionPath, userID, groupID)
+	if err != nil {
+		return 0, err
+	}
+	if !checkPermissions(currentUser, userGIDStr, 'w', parentInode, sb, partitionPath) {
+		return 0, fmt.Errorf("permiso denegado: no tienes permiso de escritura en '%s'", parentPath)
+	}
+	if exists, _, _ := findEntryInParent(parentInode, linkName, sb, partitionPath); exists {
+		return 0, fmt.Errorf("error: '%s' ya existe en '%s'", linkName, parentPath)
+	}
+	if !cmd.symbolic {
+		return hardLink(cmd, currentUser, userGIDStr, cleanPath, linkName, parentInodeIndex, sb, mountedPartition, partitionPath)
+	}
+
+	currentTime := time.Now().Unix()
+	newInode := &structures.Inode{
+		I_uid:   userID,
+		I_gid:   groupID,
+		I_atime: currentTime,
+		I_ctime: currentTime,
+		I_mtime: currentTime,
+		I_type:  [1]byte{'2'},           // '2' para enlace simbólico
+		I_perm:  [3]byte{'7', '7', '7'}, // Los permisos que cuentan son los del destino
+		I_links: 1,
+		I_xattr: -1,
+	}
+	structures.InheritSetgid(parentInode, newInode)
+
+	// Destino corto en el inodo; largo en bloques de datos como un archivo
+	if len(cmd.target) <= structures.SymlinkInlineMax {
+		if err := newInode.SetInlineTarget(cmd.target); err != nil {
+			return 0, err
+		}
+	} else {
+		fmt.Printf("Destino de %d bytes, se guarda en bloques de datos...\n", len(cmd.target))
+		newInode.I_size = int64(len(cmd.target))
+		newInode.I_block, err = allocateDataBlocks([]byte(cmd.target), newInode.I_size, sb, partitionPath)
+		if err != nil {
+			return 0, fmt.Errorf("falló la asignación de bloques para el destino: %w", err)
+		}
+	}
+
+	newInodeIndex, err := sb.FindFreeInode(partitionPath)
+	if err != nil {
+		return 0, fmt.Errorf("no se pudo asignar un nuevo inodo: %w", err)
+	}
+	if err := sb.UpdateBitmapInode(partitionPath, newInodeIndex, '1'); err != nil {
+		return 0, fmt.Errorf("error actualizando bitmap para inodo %d: %w", newInodeIndex, err)
+	}
+	sb.S_free_inodes_count--
+
+	inodeOffset := int64(sb.S_inode_start) + int64(newInodeIndex)*int64(sb.S_inode_size)
+	if err := newInode.Serialize(sb, partitionPath, inodeOffset); err != nil {
+		return 0, fmt.Errorf("error serializando inodo del enlace %d: %w", newInodeIndex, err)
+	}
+	if err := addEntryToParent(parentInodeIndex, linkName, newInodeIndex, sb, partitionPath); err != nil {
+		return 0, fmt.Errorf("error añadiendo entrada '%s' al directorio padre: %w", linkName, err)
+	}
+	sb.S_feature_ro_compat |= structures.FeatureRoCompatSymlinks
+	if sb.S_rev_level < structures.SuperBlockRevision {
+		sb.S_rev_level = structures.SuperBlockRevision
+	}
+
+	if sb.S_filesystem_type == 3 {
+		journalEntryData := structures.Information{
+			I_operation: utils.StringToBytes10("ln"),
+			I_path:      utils.StringToBytes32(cleanPath),
+			I_content:   utils.StringToBytes64(cmd.target),
+		}
+		if errJournal := utils.AppendToJournal(journalEntryData, sb, partitionPath); errJournal != nil {
+			fmt.Printf("Advertencia: Falla al escribir en journal para ln '%s': %v\n", cleanPath, errJournal)
+		}
+	}
+
+	fmt.Println("\nSerializando SuperBlock después de LN...")
+	if err := sb.Serialize(partitionPath, int64(mountedPartition.Part_start)); err != nil {
+		return 0, fmt.Errorf("ADVERTENCIA: error al serializar el superbloque después de ln, los cambios podrían perderse (%w)", err)
+	}
+	return newInode.I_links, nil
+}
+
+// Agrega la entrada linkName en la carpeta parentInodeIndex apuntando al inodo de cmd.target e
+// incrementa su I_links. Como ln sin -L, si cmd.target es un enlace simbólico se enlaza el enlace
+// mismo, no su destino. Las carpetas no se enlazan: el árbol dejaría de serlo. El llamador ya
+// verificó el permiso de escritura en la carpeta; aquí se pide lectura sobre el destino.
+func hardLink(cmd *LN, currentUser, userGIDStr, cleanPath, linkName string, parentInodeIndex int32, sb *structures.SuperBlock, mountedPartition *structures.Partition, partitionPath string) (int32, error) {
+	targetIndex, targetInode, err := structures.FindInodeByPathNoFollow(sb, partitionPath, cmd.target)
+	if err != nil {
+		return 0, fmt.Errorf("no se encontró el destino '%s': %w", cmd.target, err)
+	}
+	if targetInode.I_type[0] != '1' && !targetInode.IsSymlink() {
+		return 0, fmt.Errorf("el destino '%s' no es un archivo: solo se pueden enlazar archivos y enlaces simbólicos", cmd.target)
+	}
+	if !checkPermissions(currentUser, userGIDStr, 'r', targetInode, sb, partitionPath) {
+		return 0, fmt.Errorf("permiso denegado: no tienes permiso de lectura sobre '%s'", cmd.target)
+	}
+
+	targetInode.I_links++
+	targetInode.I_ctime = time.Now().Unix()
+	targetOffset := int64(sb.S_inode_start) + int64(targetIndex)*int64(sb.S_inode_size)
+	if err := targetInode.Serialize(sb, partitionPath, targetOffset); err != nil {
+		return 0, fmt.Errorf("error serializando inodo %d: %w", targetIndex, err)
+	}
+	if err := addEntryToParent(parentInodeIndex, linkName, targetIndex, sb, partitionPath); err != nil {
+		// Devolver el contador para no dejar un enlace de más
+		targetInode.I_links--
+		if errUndo := targetInode.Serialize(sb, partitionPath, targetOffset); errUndo != nil {
+			fmt.Printf("Advertencia: no se pudo restaurar I_links del inodo %d: %v\n", targetIndex, errUndo)
+		}
+		return 0, fmt.Errorf("error añadiendo entrada '%s' al directorio padre: %w", linkName, err)
+	}
+	fmt.Printf("Inodo %d ahora tiene %d enlaces.\n", targetIndex, targetInode.I_links)
+
+	if sb.S_filesystem_type == 3 {
+		journalEntryData := structures.Information{
+			I_operation: utils.StringToBytes10("link"),
+			I_path:      utils.StringToBytes32(cleanPath),
+			I_content:   utils.StringToBytes64(cmd.target),
+		}
+		if errJournal := utils.AppendToJournal(journalEntryData, sb, partitionPath); errJournal != nil {
+			fmt.Printf("Advertencia: Falla al escribir en journal para ln '%s': %v\n", cleanPath, errJournal)
+		}
+	}
+
+	fmt.Println("\nSerializando SuperBlock después de LN...")
+	if err := sb.Serialize(partitionPath, int64(mountedPartition.Part_start)); err != nil {
+		return 0, fmt.Errorf("ADVERTENCIA: error al serializar el superbloque después de ln, los cambios podrían perderse (%w)", err)
+	}
+	return targetInode.I_links, nil
+}
+
+// Devuelve el destino si el inodo index es un enlace simbólico (content, find).
+func symlinkTargetOf(sb *structures.SuperBlock, diskPath string, index int32) (string, bool) {
+	if index < 0 || index >= sb.S_inodes_count {
+		return "", false
+	}
+	inode := &structures.Inode{}
+	if err := inode.Deserialize(sb, diskPath, int64(sb.S_inode_start)+int64(index)*int64(sb.S_inode_size)); err != nil || !inode.IsSymlink() {
+		return "", false
+	}
+	target, err := structures.ReadSymlink(sb, diskPath, inode)
+	if err != nil {
+		return "?", true
+	}
+	return target, true
+}
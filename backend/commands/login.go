@@ -92,6 +92,8 @@ func ParseLogin(tokens []string) (string, error) {
 }
 
 func commandLogin(login *LOGIN) error {
+	login.id = stores.ResolveMountID(login.id) // Acepta también etiqueta o nombre de partición
+
 	// Verificar si ya hay una sesión activa
 	if stores.Auth.IsAuthenticated() {
 		_, _, currentPartition := stores.Auth.GetCurrentUser()
@@ -3,10 +3,10 @@ package commands
 import (
 	"errors"
 	"fmt"
-	"os"
 	"regexp"
 	"strings"
 	stores "backend/stores"
+	structures "backend/structures"
 )
 
 type LOSS struct {
@@ -70,10 +70,10 @@ func commandLoss(cmd *LOSS) error {
 
 	// Calcular Offsets y Tamaños de las áreas a borrar
 	bmInodeOffset := int64(sb.S_bm_inode_start)
-	bmInodeSize := int64(sb.S_inodes_count) // 1 byte por inodo en bitmap
+	bmInodeSize := sb.BitmapBytes(sb.S_inodes_count) // 1 byte (o 1 bit) por inodo en bitmap
 
 	bmBlockOffset := int64(sb.S_bm_block_start)
-	bmBlockSize := int64(sb.S_blocks_count) // 1 byte por bloque en bitmap
+	bmBlockSize := sb.BitmapBytes(sb.S_blocks_count) // 1 byte (o 1 bit) por bloque en bitmap
 
 	inodeTableOffset := int64(sb.S_inode_start)
 	inodeTableSize := int64(sb.S_inodes_count) * int64(sb.S_inode_size)
@@ -99,7 +99,7 @@ func commandLoss(cmd *LOSS) error {
 
 	// Abrir archivo en modo Escritura
 	fmt.Printf("Abriendo disco '%s' para escritura...\n", diskPath)
-	file, errOpen := os.OpenFile(diskPath, os.O_RDWR, 0644) // Necesitamos RDWR
+	file, errOpen := structures.OpenDisk(diskPath) // Necesitamos RDWR
 	if errOpen != nil { return fmt.Errorf("error al abrir disco '%s' para escritura: %w", diskPath, errOpen) }
 	defer file.Close() // Asegurar cierre
 
@@ -124,6 +124,7 @@ func commandLoss(cmd *LOSS) error {
 		return fmt.Errorf("error borrando área de bloques: %w", err)
 	}
 
+	structures.DropFreeIndex(diskPath) // Los bitmaps cambiaron por fuera del índice de libres
 	fmt.Println("Simulación de pérdida completada.")
 	return nil
 }
\ No newline at end of file
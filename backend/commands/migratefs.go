@@ -0,0 +1,246 @@
+package commands
+
+import (
+	stores "backend/stores"
+	structures "backend/structures"
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type MIGRATEFS struct {
+	id string // ID de la partición montada
+}
+
+// Resultado de la migración, para el mensaje del comando
+type migrateResult struct {
+	oldSb          *structures.SuperBlock
+	newSb          *structures.SuperBlock // nil si el sistema de archivos ya estaba migrado
+	journalEntries int                    // Entradas con operación convertidas del journal (EXT3)
+	mbr            string                 // Qué se hizo con el MBR del disco
+}
+
+func ParseMigratefs(tokens []string) (string, error) {
+	cmd := &MIGRATEFS{}
+	processedKeys := make(map[string]bool)
+
+	idRegex := regexp.MustCompile(`^(?i)-id=(?:"([^"]+)"|([^\s"]+))$`)
+
+	if len(tokens) == 0 {
+		return "", errors.New("faltan parámetros: se requiere -id=<mount_id>")
+	}
+
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		match := idRegex.FindStringSubmatch(token)
+		if match == nil {
+			return "", fmt.Errorf("parámetro inválido o no reconocido: '%s'. Se esperaba -id=<mount_id>", token)
+		}
+		value := match[1]
+		if value == "" {
+			value = match[2]
+		}
+
+		if processedKeys["-id"] {
+			return "", errors.New("parámetro duplicado: -id")
+		}
+		processedKeys["-id"] = true
+		if value == "" {
+			return "", errors.New("el valor para -id no puede estar vacío")
+		}
+		cmd.id = value
+	}
+
+	if !processedKeys["-id"] {
+		return "", errors.New("falta el parámetro requerido: -id")
+	}
+
+	result, err := commandMigratefs(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	if result.newSb == nil {
+		return fmt.Sprintf("MIGRATEFS: El sistema de archivos de '%s' ya usa marcas de tiempo int64. MBR: %s.", cmd.id, result.mbr), nil
+	}
+	return fmt.Sprintf("MIGRATEFS: Marcas de tiempo convertidas a int64\n"+
+		"-> ID: %s\n"+
+		"-> Tamaño de inodo: %d -> %d bytes\n"+
+		"-> Inodos: %d -> %d\n"+
+		"-> Bloques: %d -> %d\n"+
+		"-> Entradas de journal convertidas: %d\n"+
+		"-> MBR: %s",
+		cmd.id, result.oldSb.S_inode_size, result.newSb.S_inode_size,
+		result.oldSb.S_inodes_count, result.newSb.S_inodes_count,
+		result.oldSb.S_blocks_count, result.newSb.S_blocks_count,
+		result.journalEntries, result.mbr), nil
+}
+
+// Convierte el sistema de archivos de una partición montada al formato con marcas de tiempo int64
+// (FeatureIncompatWideTimes). Los inodos crecen, así que se reubican las áreas igual que en
+// resizefs; las fechas ya guardadas conservan el redondeo que tenían en float32.
+func commandMigratefs(cmd *MIGRATEFS) (*migrateResult, error) {
+	fmt.Printf("Iniciando MIGRATEFS para partición ID: %s\n", cmd.id)
+
+	mbr, partition, diskPath, err := stores.GetMountedPartitionInfo(cmd.id)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo información de la partición '%s': %w", cmd.id, err)
+	}
+
+	sb := &structures.SuperBlock{}
+	if err := sb.Deserialize(diskPath, int64(partition.Part_start)); err != nil {
+		return nil, fmt.Errorf("error leyendo superbloque: %w", err)
+	}
+	if sb.S_magic != 0xEF53 {
+		return nil, errors.New("la partición no tiene un sistema de archivos válido (ejecute mkfs primero)")
+	}
+	if sb.S_inode_size != structures.InodeDiskSize(sb.S_feature_incompat) || !structures.ValidBlockSize(sb.S_block_size) {
+		return nil, errors.New("tamaño de inodo o bloque inválido en el superbloque")
+	}
+
+	result := &migrateResult{oldSb: sb}
+	if !sb.WideTimes() {
+		result.newSb, result.journalEntries, err = convertFilesystem(sb, partition, diskPath, sb.S_feature_incompat|structures.FeatureIncompatWideTimes)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo migrar el sistema de archivos: %w", err)
+		}
+	} else {
+		fmt.Println("El sistema de archivos ya usa marcas de tiempo int64.")
+	}
+
+	if result.mbr, err = migrateMBR(mbr, diskPath); err != nil {
+		return nil, fmt.Errorf("error actualizando el MBR: %w", err)
+	}
+
+	fmt.Println("MIGRATEFS completado.")
+	return result, nil
+}
+
+// Reescribe el sistema de archivos con las características incompat features: los inodos, el
+// superbloque y las entradas del journal cambian de tamaño, así que se reubican las áreas igual que
+// en resizefs. Devuelve el nuevo superbloque y las entradas del journal convertidas.
+func convertFilesystem(sb *structures.SuperBlock, partition *structures.Partition, diskPath string, features int32) (*structures.SuperBlock, int, error) {
+	geo := geometryOf(sb)
+	geo.features = features
+	newN, newBlocks := calculateCounts(partition, geo)
+	fmt.Printf("Inodos de %d -> %d bytes; n: %d -> %d, bloques: %d -> %d\n", sb.S_inode_size, structures.InodeDiskSize(geo.features), sb.S_inodes_count, newN, sb.S_blocks_count, newBlocks)
+
+	newSb, err := relayoutFilesystem(sb, partition, diskPath, geo, newN, newBlocks)
+	if err != nil {
+		return nil, 0, err
+	}
+	entries, err := migrateJournal(sb, newSb, diskPath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error convirtiendo el journal: %w", err)
+	}
+	return newSb, entries, nil
+}
+
+// Reescribe las entradas del journal (inodo 2) con el tamaño de entrada de newSb. El archivo
+// conserva sus bloques y su tamaño; si las entradas ya no caben se descartan las últimas.
+func migrateJournal(oldSb *structures.SuperBlock, newSb *structures.SuperBlock, diskPath string) (int, error) {
+	if newSb.S_filesystem_type != 3 {
+		return 0, nil
+	}
+	journalInode := &structures.Inode{}
+	journalInodeOffset := int64(newSb.S_inode_start + 2*newSb.S_inode_size)
+	if err := journalInode.Deserialize(newSb, diskPath, journalInodeOffset); err != nil {
+		return 0, fmt.Errorf("error leyendo inodo del journal: %w", err)
+	}
+	if journalInode.I_type[0] != '1' {
+		return 0, errors.New("el inodo del journal (2) no es tipo archivo")
+	}
+
+	content, err := structures.ReadFileContent(newSb, diskPath, journalInode)
+	if err != nil {
+		return 0, fmt.Errorf("error leyendo contenido del journal: %w", err)
+	}
+	entries, err := structures.DecodeJournal([]byte(content), oldSb.S_feature_incompat)
+	if err != nil {
+		return 0, err
+	}
+
+	capacity := int(journalInode.I_size / int64(structures.JournalEntrySize(newSb.S_feature_incompat)))
+	if len(entries) > capacity {
+		for _, dropped := range entries[capacity:] {
+			if dropped.J_content.I_operation[0] != 0 {
+				fmt.Printf("Advertencia: el journal no tiene espacio para todas las entradas en el nuevo formato; se descartan %d.\n", len(entries)-capacity)
+				break
+			}
+		}
+		entries = entries[:capacity]
+	}
+
+	data := make([]byte, journalInode.I_size)
+	buffer := new(bytes.Buffer)
+	converted := 0
+	for i := range entries {
+		encoded, err := entries[i].Encode(newSb.S_feature_incompat)
+		if err != nil {
+			return 0, fmt.Errorf("error codificando entrada %d del journal: %w", i+1, err)
+		}
+		buffer.Write(encoded)
+		if entries[i].J_content.I_operation[0] != 0 {
+			converted++
+		}
+	}
+	copy(data, buffer.Bytes())
+
+	dataBlocks, _, err := newSb.InodeBlocks(diskPath, journalInode)
+	if err != nil {
+		return 0, err
+	}
+	file, err := structures.OpenDisk(diskPath)
+	if err != nil {
+		return 0, fmt.Errorf("error abriendo disco '%s': %w", diskPath, err)
+	}
+	defer file.Close()
+	blockSize := int64(newSb.S_block_size)
+	for i, blockIdx := range dataBlocks {
+		start := int64(i) * blockSize
+		if start >= int64(len(data)) {
+			break
+		}
+		end := start + blockSize
+		if end > int64(len(data)) {
+			end = int64(len(data))
+		}
+		if _, err := file.WriteAt(data[start:end], int64(newSb.S_block_start)+int64(blockIdx)*blockSize); err != nil {
+			return 0, fmt.Errorf("error escribiendo bloque %d del journal: %w", blockIdx, err)
+		}
+	}
+	fmt.Printf("Journal convertido: %d entradas de %d bytes (%d con operación).\n", len(entries), structures.JournalEntrySize(newSb.S_feature_incompat), converted)
+	return converted, nil
+}
+
+// Agrega al MBR la extensión con la fecha de creación int64 y los metadatos del formato si ninguna
+// partición empieza en los bytes que ocupan. La fecha conserva el redondeo del formato anterior.
+func migrateMBR(mbr *structures.MBR, diskPath string) (string, error) {
+	if mbr.HasMetadata() {
+		return fmt.Sprintf("ya tenía la revisión %d", mbr.Revision()), nil
+	}
+	upgraded := *mbr
+	upgraded.Mbr_ext_magic = structures.MBRMetaMagic
+	upgraded.Mbr_revision = structures.MBRRevision
+	for _, p := range mbr.Mbr_partitions {
+		if p.Part_status[0] != 'N' && p.Part_size > 0 && p.Part_start < upgraded.DiskSize() {
+			name := strings.TrimRight(string(p.Part_name[:]), "\x00 ")
+			return fmt.Sprintf("sin cambios (revisión %d), la partición '%s' empieza en el byte %d y no deja espacio para la extensión", mbr.Revision(), name, p.Part_start), nil
+		}
+	}
+	if err := upgraded.Serialize(diskPath); err != nil {
+		return "", err
+	}
+	result := fmt.Sprintf("revisión %d -> %d", mbr.Revision(), upgraded.Revision())
+	if !mbr.HasExtension() {
+		result += ", fecha de creación convertida a int64"
+	}
+	*mbr = upgraded
+	return result, nil
+}
@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"backend/structures"
+)
+
+// Registra un disco en memoria con una partición primaria formateada como lo hace mkfs.
+func newTestPartition(t *testing.T, fsType string, features int32) (*structures.MBR, *structures.SuperBlock, string) {
+	t.Helper()
+	diskPath := "/mem/" + t.Name() + ".mia"
+	structures.RegisterDevice(diskPath, structures.NewMemDevice(256*1024))
+	t.Cleanup(func() { structures.UnregisterDevice(diskPath) })
+
+	mbr := &structures.MBR{Mbr_size: 256 * 1024, Mbr_creation_date: 1760000077, Mbr_disk_signature: 7, Mbr_disk_fit: [1]byte{'F'}}
+	for i := range mbr.Mbr_partitions {
+		mbr.Mbr_partitions[i] = structures.Partition{Part_status: [1]byte{'N'}, Part_start: -1, Part_correlative: -1}
+	}
+	mbr.Mbr_partitions[0] = structures.Partition{Part_status: [1]byte{'1'}, Part_type: [1]byte{'P'}, Part_fit: [1]byte{'F'}, Part_start: 512, Part_size: 200 * 1024, Part_correlative: 1}
+	if err := mbr.Serialize(diskPath); err != nil {
+		t.Fatalf("Serialize MBR: %v", err)
+	}
+
+	partition := &mbr.Mbr_partitions[0]
+	geo := fsGeometry{blockSize: 64, features: features}
+	n, blocks := calculateCounts(partition, geo)
+	sb := createSuperBlock(partition, n, blocks, fsType, geo)
+	if sb == nil {
+		t.Fatalf("createSuperBlock: n=%d, bloques=%d", n, blocks)
+	}
+	if err := sb.CreateBitMaps(diskPath); err != nil {
+		t.Fatalf("CreateBitMaps: %v", err)
+	}
+	if err := createInitialStructures(sb, diskPath, fsType); err != nil {
+		t.Fatalf("createInitialStructures: %v", err)
+	}
+	if err := sb.Serialize(diskPath, int64(partition.Part_start)); err != nil {
+		t.Fatalf("Serialize superbloque: %v", err)
+	}
+	return mbr, sb, diskPath
+}
+
+func readTestInode(t *testing.T, sb *structures.SuperBlock, diskPath string, index int32) *structures.Inode {
+	t.Helper()
+	inode := &structures.Inode{}
+	if err := inode.Deserialize(sb, diskPath, int64(sb.S_inode_start+index*sb.S_inode_size)); err != nil {
+		t.Fatalf("Deserialize inodo %d: %v", index, err)
+	}
+	return inode
+}
+
+func readTestFile(t *testing.T, sb *structures.SuperBlock, diskPath string, index int32) string {
+	t.Helper()
+	content, err := structures.ReadFileContent(sb, diskPath, readTestInode(t, sb, diskPath, index))
+	if err != nil {
+		t.Fatalf("ReadFileContent inodo %d: %v", index, err)
+	}
+	return content
+}
+
+// migratefs agrega los tiempos int64 y conserva users.txt, las fechas (con el redondeo de float32)
+// y las entradas del journal.
+func TestConvertFilesystemWideTimes(t *testing.T) {
+	mbr, sb, diskPath := newTestPartition(t, "3fs", 0)
+	partition := &mbr.Mbr_partitions[0]
+
+	journalInode := readTestInode(t, sb, diskPath, 2)
+	var entries []byte
+	for i, op := range []string{"mkdir", "mkfile", "remove"} {
+		entry := structures.Journal{J_count: int32(i), J_content: structures.Information{I_date: 1760000077}}
+		copy(entry.J_content.I_operation[:], op)
+		copy(entry.J_content.I_path[:], "/home/"+op)
+		data, err := entry.Encode(sb.S_feature_incompat)
+		if err != nil {
+			t.Fatalf("Encode: %v", err)
+		}
+		entries = append(entries, data...)
+	}
+	file, err := structures.OpenDisk(diskPath)
+	if err != nil {
+		t.Fatalf("OpenDisk: %v", err)
+	}
+	// Las tres entradas de 114 bytes ocupan los primeros bloques directos del journal
+	for i := 0; i*64 < len(entries); i++ {
+		end := min((i+1)*64, len(entries))
+		if _, err := file.WriteAt(entries[i*64:end], int64(sb.S_block_start+journalInode.I_block[i]*sb.S_block_size)); err != nil {
+			t.Fatalf("WriteAt: %v", err)
+		}
+	}
+	file.Close()
+
+	users := readTestFile(t, sb, diskPath, 1)
+	rootMtime := readTestInode(t, sb, diskPath, 0).I_mtime
+
+	newSb, converted, err := convertFilesystem(sb, partition, diskPath, sb.S_feature_incompat|structures.FeatureIncompatWideTimes)
+	if err != nil {
+		t.Fatalf("convertFilesystem: %v", err)
+	}
+	if !newSb.WideTimes() || newSb.S_inode_size != structures.InodeDiskSize(structures.FeatureIncompatWideTimes) {
+		t.Fatalf("superbloque convertido: tiempos int64=%v, tamaño de inodo %d", newSb.WideTimes(), newSb.S_inode_size)
+	}
+	if converted != 3 {
+		t.Errorf("convertFilesystem convirtió %d entradas del journal, se esperaban 3", converted)
+	}
+
+	read := &structures.SuperBlock{}
+	if err := read.Deserialize(diskPath, int64(partition.Part_start)); err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	if !read.WideTimes() || read.S_inodes_count != newSb.S_inodes_count {
+		t.Fatalf("superbloque en disco = %+v", *read)
+	}
+	if got := readTestFile(t, read, diskPath, 1); got != users {
+		t.Errorf("users.txt = %q, se esperaba %q", got, users)
+	}
+	if got := readTestInode(t, read, diskPath, 0).I_mtime; got != rootMtime {
+		t.Errorf("I_mtime de la raíz = %d, se esperaba %d", got, rootMtime)
+	}
+
+	decoded, err := structures.DecodeJournal([]byte(readTestFile(t, read, diskPath, 2)), read.S_feature_incompat)
+	if err != nil {
+		t.Fatalf("DecodeJournal: %v", err)
+	}
+	for i, op := range []string{"mkdir", "mkfile", "remove"} {
+		content := decoded[i].J_content
+		if got := strings.TrimRight(string(content.I_operation[:]), "\x00"); got != op || content.I_date != 1760000128 {
+			t.Errorf("entrada %d = %q del %d, se esperaba %q del 1760000128", i, got, content.I_date, op)
+		}
+	}
+}
+
+func TestMigrateMBR(t *testing.T) {
+	_, _, diskPath := newTestPartition(t, "2fs", 0)
+	mbr := &structures.MBR{}
+	if err := mbr.Deserialize(diskPath); err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	result, err := migrateMBR(mbr, diskPath)
+	if err != nil {
+		t.Fatalf("migrateMBR: %v", err)
+	}
+	if !strings.Contains(result, "revisión 0 -> 2") || !mbr.HasMetadata() {
+		t.Errorf("migrateMBR = %q, revisión %d", result, mbr.Revision())
+	}
+	read := &structures.MBR{}
+	if err := read.Deserialize(diskPath); err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	if *read != *mbr || read.Mbr_creation_date != 1760000128 {
+		t.Errorf("MBR en disco = %+v\nse esperaba %+v", *read, *mbr)
+	}
+
+	// Una partición pegada al MBR original ocupa los bytes de la extensión
+	blocked := &structures.MBR{}
+	if err := blocked.Deserialize(diskPath); err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	blocked.Mbr_ext_magic, blocked.Mbr_revision = 0, 0
+	blocked.Mbr_partitions[0].Part_start = blocked.DiskSize()
+	if err := blocked.Serialize(diskPath); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	before := *blocked
+	result, err = migrateMBR(blocked, diskPath)
+	if err != nil {
+		t.Fatalf("migrateMBR: %v", err)
+	}
+	if !strings.HasPrefix(result, "sin cambios") || *blocked != before {
+		t.Errorf("migrateMBR con la partición en el byte %d = %q", before.Mbr_partitions[0].Part_start, result)
+	}
+}
@@ -62,8 +62,9 @@ func ParseMkdir(tokens []string) (string, error) {
 func commandMkdir(mkdir *MKDIR) error {
 	//Obtengo la parción Motada (como siempre)
 	var partitionID string
+	var currentUser, userGIDStr string
 	if stores.Auth.IsAuthenticated() {
-		partitionID = stores.Auth.GetPartitionID()
+		currentUser, userGIDStr, partitionID = stores.Auth.GetCurrentUser()
 	} else {
 		return errors.New("no se ha iniciado sesión en ninguna partición")
 	}
@@ -71,6 +72,11 @@ func commandMkdir(mkdir *MKDIR) error {
 	if err != nil {
 		return fmt.Errorf("error al obtener la partición montada: %w", err)
 	}
+	// Las carpetas nuevas son del usuario que las crea (el sticky y el setgid dependen del dueño)
+	userID, groupID, err := getUserInfo(currentUser, partitionSuperblock, partitionPath)
+	if err != nil {
+		return fmt.Errorf("error obteniendo UID/GID de '%s': %w", currentUser, err)
+	}
 
 	//Valido el path
 	cleanPath := strings.TrimSuffix(mkdir.path, "/")
@@ -111,8 +117,15 @@ func commandMkdir(mkdir *MKDIR) error {
 
 			if errFind != nil {
 				fmt.Printf("Directorio '%s' no encontrado. Intentando crear...\n", currentPathToCheck)
+				_, parentInode, errParent := structures.FindInodeByPath(partitionSuperblock, partitionPath, filepath.Dir(currentPathToCheck))
+				if errParent != nil {
+					return fmt.Errorf("error: no se encontró el padre de '%s': %w", currentPathToCheck, errParent)
+				}
+				if !checkPermissions(currentUser, userGIDStr, 'w', parentInode, partitionSuperblock, partitionPath) {
+					return fmt.Errorf("permiso denegado: no tienes permiso de escritura en '%s'", filepath.Dir(currentPathToCheck))
+				}
 				parentDirs, destDir := utils.GetParentDirectories(currentPathToCheck)
-				errCreate := partitionSuperblock.CreateFolder(partitionPath, parentDirs, destDir)
+				errCreate := partitionSuperblock.CreateFolder(partitionPath, parentDirs, destDir, userID, groupID)
 				if errCreate != nil {
 					return fmt.Errorf("error al crear directorio intermedio '%s': %w", currentPathToCheck, errCreate)
 				}
@@ -141,10 +154,14 @@ func commandMkdir(mkdir *MKDIR) error {
 			return fmt.Errorf("error: no se puede crear '%s', '%s' no es un directorio", mkdir.path, parentPath)
 		}
 
+		if !checkPermissions(currentUser, userGIDStr, 'w', parentInode, partitionSuperblock, partitionPath) {
+			return fmt.Errorf("permiso denegado: no tienes permiso de escritura en '%s'", parentPath)
+		}
+
 		// El padre existe y es un directorio, proceder a crear solo el directorio final
 		fmt.Printf("Padre '%s' existe. Creando directorio final '%s'...\n", parentPath, filepath.Base(cleanPath))
 		parentDirs, destDir := utils.GetParentDirectories(cleanPath)
-		errCreate := partitionSuperblock.CreateFolder(partitionPath, parentDirs, destDir)
+		errCreate := partitionSuperblock.CreateFolder(partitionPath, parentDirs, destDir, userID, groupID)
 		if errCreate != nil {
 			return fmt.Errorf("error al crear directorio final '%s': %w", mkdir.path, errCreate)
 		}
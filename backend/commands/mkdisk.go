@@ -167,9 +167,11 @@ func commandMkdisk(mkdisk *MKDISK) error {
 	// Inicializar MBR 
 	mbr := structures.MBR{
 		Mbr_size:           int32(sizeBytes),
-		Mbr_creation_date:  float32(time.Now().Unix()),
+		Mbr_creation_date:  time.Now().Unix(),
 		Mbr_disk_signature: int32(rand.Intn(100000)), // Firma aleatoria simple
 		Mbr_disk_fit:       [1]byte{mkdisk.fit[0]},   // Guardar fit seleccionado
+		Mbr_ext_magic:      structures.MBRMetaMagic,  // Fecha de creación exacta y metadatos del formato
+		Mbr_revision:       structures.MBRRevision,
 	}
 	// Inicializar particiones vacías
 	for i := range mbr.Mbr_partitions {
@@ -1,7 +1,9 @@
 package commands
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os" 
 	"path/filepath"
 	"regexp"
@@ -16,10 +18,11 @@ import (
 )
 
 type MKFILE struct {
-	path string // Path del archivo
-	r    bool   // Crear padres recursivamente
-	size int    // Tamaño en bytes (si no se usa -cont)
-	cont string // Path al archivo local con contenido
+	path   string // Path del archivo
+	r      bool   // Crear padres recursivamente
+	size   int64  // Tamaño en bytes (si no se usa -cont)
+	cont   string // Path al archivo local con contenido
+	sparse bool   // No asignar bloques a las partes sin escribir (huecos)
 }
 
 // ParseMkfile analiza los tokens para el comando mkfile
@@ -28,7 +31,7 @@ func ParseMkfile(tokens []string) (string, error) {
 
 	args := strings.Join(tokens, " ")
 	// Expresión regular mejorada para capturar valores con/sin comillas y flags
-	re := regexp.MustCompile(`-(path|cont)=("[^"]+"|[^\s]+)|-size=(\d+)|(-r)`)
+	re := regexp.MustCompile(`-(path|cont)=("[^"]+"|[^\s]+)|-size=(\d+)|(-sparse)|(-r)`)
 	matches := re.FindAllStringSubmatch(args, -1) // Usar Submatch para capturar grupos
 
 	parsedArgs := make(map[string]bool) // Para rastrear qué parte del string original ya se procesó
@@ -38,7 +41,8 @@ func ParseMkfile(tokens []string) (string, error) {
 		key := strings.ToLower(match[1]) // path o cont (grupo 1)
 		value := match[2]                // Valor para path/cont (grupo 2)
 		sizeStr := match[3]              // Valor para size (grupo 3)
-		flagR := match[4]                // -r (grupo 4)
+		flagSparse := match[4]           // -sparse (grupo 4)
+		flagR := match[5]                // -r (grupo 5)
 
 		// Limpiar comillas del valor si existen
 		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
@@ -51,7 +55,7 @@ func ParseMkfile(tokens []string) (string, error) {
 		case key == "cont":
 			cmd.cont = value
 		case sizeStr != "":
-			size, err := strconv.Atoi(sizeStr)
+			size, err := strconv.ParseInt(sizeStr, 10, 64)
 			if err != nil {
 				return "", fmt.Errorf("valor de -size inválido: %s", sizeStr)
 			}
@@ -59,6 +63,8 @@ func ParseMkfile(tokens []string) (string, error) {
 				return "", errors.New("el valor de -size no puede ser negativo")
 			}
 			cmd.size = size
+		case flagSparse != "":
+			cmd.sparse = true
 		case flagR == "-r":
 			cmd.r = true
 		}
@@ -74,7 +80,7 @@ func ParseMkfile(tokens []string) (string, error) {
 				break
 			}
 		}
-		if token == "-r" {
+		if token == "-r" || token == "-sparse" {
 			isProcessed = true
 		}
 
@@ -106,13 +112,11 @@ func ParseMkfile(tokens []string) (string, error) {
 
 func commandMkfile(mkfile *MKFILE) error {
 	// Obtener Autenticación y Partición Montada
-	var userID int32 = 1
-	var groupID int32 = 1
 	var partitionID string
+	var currentUser, userGIDStr string
 
 	if stores.Auth.IsAuthenticated() {
-		partitionID = stores.Auth.GetPartitionID()
-		fmt.Printf("Usuario autenticado: %s (Usando UID=%d, GID=%d)\n", stores.Auth.Username, userID, groupID)
+		currentUser, userGIDStr, partitionID = stores.Auth.GetCurrentUser()
 	} else {
 		return errors.New("comando mkfile requiere sesión iniciada (login)")
 	}
@@ -121,6 +125,11 @@ func commandMkfile(mkfile *MKFILE) error {
 	if err != nil {
 		return fmt.Errorf("error al obtener la partición montada '%s': %w", partitionID, err)
 	}
+	userID, groupID, err := getUserInfo(currentUser, partitionSuperblock, partitionPath)
+	if err != nil {
+		return fmt.Errorf("error obteniendo UID/GID de '%s': %w", currentUser, err)
+	}
+	fmt.Printf("Usuario autenticado: %s (Usando UID=%d, GID=%d)\n", currentUser, userID, groupID)
 
 	// Validar tamaños para división por cero o valores inválidos
 	if partitionSuperblock.S_inode_size <= 0 || partitionSuperblock.S_block_size <= 0 {
@@ -155,17 +164,21 @@ func commandMkfile(mkfile *MKFILE) error {
 	if fileName == "" || fileName == "." || fileName == ".." {
 		return fmt.Errorf("nombre de archivo inválido: '%s'", fileName)
 	}
-	// Verificar que el nombre no contenga la cantidad de caracteres inválidos
-	if len(fileName) > 11 {
-		return fmt.Errorf("el nombre del archivo '%s' excede los 11 caracteres permitidos (máx 12 bytes con nulo)", fileName)
+	// Verificar que el nombre quepa en una entrada de directorio
+	if err := partitionSuperblock.ValidateName(fileName); err != nil {
+		return err
 	}
 
 	fmt.Printf("Asegurando directorio padre: %s\n", parentPath)
-	parentInodeIndex, parentInode, err := ensureParentDirExists(parentPath, mkfile.r, partitionSuperblock, partitionPath)
+	parentInodeIndex, parentInode, err := ensureParentDirExists(parentPath, mkfile.r, partitionSuperblock, partitionPath, userID, groupID)
 	if err != nil {
 		return err
 	}
 
+	if !checkPermissions(currentUser, userGIDStr, 'w', parentInode, partitionSuperblock, partitionPath) {
+		return fmt.Errorf("permiso denegado: no tienes permiso de escritura en '%s'", parentPath)
+	}
+
 	// Verificar si el nombre ya existe en el padre
 	fmt.Printf("Verificando si '%s' ya existe en directorio padre (inodo %d)...\n", fileName, parentInodeIndex)
 	exists, _, existingInodeType := findEntryInParent(parentInode, fileName, partitionSuperblock, partitionPath)
@@ -175,13 +188,15 @@ func commandMkfile(mkfile *MKFILE) error {
 			existingTypeStr = "directorio"
 		} else if existingInodeType == '1' {
 			existingTypeStr = "archivo"
+		} else if existingInodeType == '2' {
+			existingTypeStr = "enlace"
 		}
 		return fmt.Errorf("error: el %s '%s' ya existe en '%s'", existingTypeStr, fileName, parentPath)
 	}
 
 	// Determinar Contenido y Tamaño Final
-	var contentBytes []byte
-	var fileSize int32
+	var content io.ReaderAt
+	var fileSize int64
 
 	// Leer contenido desde archivo local o generar contenido
 	if mkfile.cont != "" {
@@ -190,44 +205,33 @@ func commandMkfile(mkfile *MKFILE) error {
 		if errRead != nil {
 			return fmt.Errorf("error leyendo archivo de contenido '%s': %w", mkfile.cont, errRead)
 		}
-		contentBytes = hostContent
-		fileSize = int32(len(contentBytes))
+		content = bytes.NewReader(hostContent)
+		fileSize = int64(len(hostContent))
+	} else if mkfile.sparse {
+		// Archivo disperso sin contenido: todo el archivo es un hueco
+		fileSize = mkfile.size
+		fmt.Printf("Archivo disperso de %d bytes, sin bloques de datos\n", fileSize)
 	} else {
-		// Generar contenido basado en el tamaño
-		fileSize = int32(mkfile.size)
-
+		// Generar contenido (0-9 repetido) basado en el tamaño, bloque por bloque al escribirlo
+		fileSize = mkfile.size
+		content = digitPattern{}
 		if fileSize > 0 {
 			fmt.Printf("Generando contenido de %d bytes (0-9 repetido)...\n", fileSize)
-			contentBuilder := strings.Builder{}
-			contentBuilder.Grow(int(fileSize))
-			for i := int32(0); i < fileSize; i++ {
-				contentBuilder.WriteByte(byte('0' + (i % 10)))
-			}
-			contentBytes = []byte(contentBuilder.String())
-		} else {
-			// Tamaño 0, archivo vacío
-			contentBytes = []byte{}
 		}
 	}
 	fmt.Printf("Tamaño final del archivo: %d bytes\n", fileSize)
-
-	// Calcular bloques necesarios
-	blockSize := partitionSuperblock.S_block_size
-	numBlocksNeeded := int32(0)
-	if fileSize > 0 {
-		// Manejo división por cero si blockSize fuera inválido (aunque ya se validó SB)
-		if blockSize <= 0 {
-			return errors.New("tamaño de bloque inválido en superbloque")
-		}
-		numBlocksNeeded = (fileSize + blockSize - 1) / blockSize
-	} else {
-		numBlocksNeeded = 0
+	if fileSize > partitionSuperblock.MaxFileSize() {
+		return fmt.Errorf("el archivo es demasiado grande (%d bytes): el máximo con bloques de %d bytes es %d", fileSize, partitionSuperblock.S_block_size, partitionSuperblock.MaxFileSize())
 	}
 
-	// Verificar si hay suficientes bloques libres
-	fmt.Printf("Asignando %d bloque(s) de datos y punteros necesarios...\n", numBlocksNeeded)
+	// Asignar bloques de datos y punteros y escribir el contenido
+	fmt.Println("Asignando bloques de datos y punteros necesarios...")
 	var allocatedBlockIndices [15]int32
-	allocatedBlockIndices, err = allocateDataBlocks(contentBytes, fileSize, partitionSuperblock, partitionPath)
+	if mkfile.sparse {
+		allocatedBlockIndices, err = allocateSparseBlocks(content, fileSize, partitionSuperblock, partitionPath)
+	} else {
+		allocatedBlockIndices, err = allocateFileBlocks(content, fileSize, partitionSuperblock, partitionPath)
+	}
 	if err != nil {
 		return fmt.Errorf("falló la asignación de bloques: %w", err)
 	}
@@ -248,7 +252,7 @@ func commandMkfile(mkfile *MKFILE) error {
 	partitionSuperblock.S_free_inodes_count-- // Decrementar contador global
 
 	// Crear y Serializar Estructura Inodo
-	currentTime := float32(time.Now().Unix())
+	currentTime := time.Now().Unix()
 	newInode := &structures.Inode{
 		I_uid:   userID,
 		I_gid:   groupID,
@@ -258,14 +262,21 @@ func commandMkfile(mkfile *MKFILE) error {
 		I_mtime: currentTime,
 		I_type:  [1]byte{'1'},           // '1' para archivo
 		I_perm:  [3]byte{'6', '6', '4'}, // Permisos rw-rw-r--
+		I_links: 1,
+		I_xattr: -1,
 	}
 	// Copiar los índices de bloques asignados
 	newInode.I_block = allocatedBlockIndices
+	// Grupo, permisos y ACL según el setgid y la ACL por defecto del padre
+	structures.InheritSetgid(parentInode, newInode)
+	if err := partitionSuperblock.InheritACL(partitionPath, parentInode, newInode); err != nil {
+		fmt.Printf("Advertencia: no se heredó la ACL por defecto de '%s': %v\n", parentPath, err)
+	}
 
 	// Calcular offset y serializar
 	inodeOffset := int64(partitionSuperblock.S_inode_start) + int64(newInodeIndex)*int64(partitionSuperblock.S_inode_size)
 	fmt.Printf("Serializando nuevo inodo %d en offset %d...\n", newInodeIndex, inodeOffset)
-	err = newInode.Serialize(partitionPath, inodeOffset)
+	err = newInode.Serialize(partitionSuperblock, partitionPath, inodeOffset)
 	if err != nil {
 		return fmt.Errorf("error serializando nuevo inodo %d: %w", newInodeIndex, err)
 	}
@@ -305,13 +316,13 @@ func commandMkfile(mkfile *MKFILE) error {
 }
 
 // Retorna el índice y el inodo del padre directo si todo va bien.
-func ensureParentDirExists(targetParentPath string, createRecursively bool, sb *structures.SuperBlock, partitionPath string) (int32, *structures.Inode, error) {
+func ensureParentDirExists(targetParentPath string, createRecursively bool, sb *structures.SuperBlock, partitionPath string, uid int32, gid int32) (int32, *structures.Inode, error) {
 	fmt.Printf("Asegurando que exista: %s (Recursivo: %v)\n", targetParentPath, createRecursively)
 	//El padre es la raíz "/"
 	if targetParentPath == "/" {
 		inode := &structures.Inode{}
 		offset := int64(sb.S_inode_start) // Raíz es inodo 0
-		err := inode.Deserialize(partitionPath, offset)
+		err := inode.Deserialize(sb, partitionPath, offset)
 		if err != nil {
 			return -1, nil, fmt.Errorf("error crítico: no se pudo deserializar inodo raíz (0): %w", err)
 		}
@@ -345,7 +356,7 @@ func ensureParentDirExists(targetParentPath string, createRecursively bool, sb *
 	grandParentPath := filepath.Dir(targetParentPath)
 	parentDirName := filepath.Base(targetParentPath)
 
-	_, _, errEnsureGrandParent := ensureParentDirExists(grandParentPath, true, sb, partitionPath) // Llamada recursiva
+	_, _, errEnsureGrandParent := ensureParentDirExists(grandParentPath, true, sb, partitionPath, uid, gid) // Llamada recursiva
 	if errEnsureGrandParent != nil {
 		// Si falla crear el abuelo, no podemos crear el padre
 		return -1, nil, fmt.Errorf("error asegurando ancestro '%s': %w", grandParentPath, errEnsureGrandParent)
@@ -354,7 +365,7 @@ func ensureParentDirExists(targetParentPath string, createRecursively bool, sb *
 	// Ahora que el abuelo, creamos el padre
 	fmt.Printf("Creando directorio padre faltante: '%s' dentro de '%s'\n", parentDirName, grandParentPath)
 	parentDirsForCreate, destDirForCreate := utils.GetParentDirectories(targetParentPath)
-	errCreate := sb.CreateFolder(partitionPath, parentDirsForCreate, destDirForCreate)
+	errCreate := sb.CreateFolder(partitionPath, parentDirsForCreate, destDirForCreate, uid, gid)
 	if errCreate != nil {
 		return -1, nil, fmt.Errorf("falló la creación recursiva del directorio padre '%s': %w", targetParentPath, errCreate)
 	}
@@ -383,36 +394,19 @@ func findEntryInParent(parentInode *structures.Inode, entryName string, sb *stru
 		return
 	}
 
-	for _, blockPtr := range parentInode.I_block {
-		if blockPtr == -1 {
-			continue
-		}
-		if blockPtr < 0 || blockPtr >= sb.S_blocks_count {
-			continue
-		}
-
-		folderBlock := &structures.FolderBlock{}
-		offset := int64(sb.S_block_start) + int64(blockPtr)*int64(sb.S_block_size)
-		if err := folderBlock.Deserialize(partitionPath, offset); err != nil {
-			fmt.Printf("Advertencia: No se pudo leer el bloque de directorio %d al buscar '%s'\n", blockPtr, entryName)
-			continue
-		}
-
-		for _, content := range folderBlock.B_content {
-			if content.B_inodo != -1 {
-				name := strings.TrimRight(string(content.B_name[:]), "\x00")
-				if name == entryName {
-					exists = true
-					foundInodeIndex = content.B_inodo
-					tempInode := &structures.Inode{}
-					tempOffset := int64(sb.S_inode_start) + int64(foundInodeIndex)*int64(sb.S_inode_size)
-					if err := tempInode.Deserialize(partitionPath, tempOffset); err == nil {
-						foundInodeType = tempInode.I_type[0]
-					}
-					return
-				}
-			}
-		}
+	entry, found, err := sb.LookupDir(partitionPath, parentInode, entryName)
+	if err != nil {
+		fmt.Printf("Advertencia: No se pudo leer el directorio al buscar '%s': %v\n", entryName, err)
+	}
+	if !found {
+		return
+	}
+	exists = true
+	foundInodeIndex = entry.Inode
+	tempInode := &structures.Inode{}
+	tempOffset := int64(sb.S_inode_start) + int64(foundInodeIndex)*int64(sb.S_inode_size)
+	if err := tempInode.Deserialize(sb, partitionPath, tempOffset); err == nil {
+		foundInodeType = tempInode.I_type[0]
 	}
 	return
 }
@@ -422,249 +416,36 @@ func addEntryToParent(parentInodeIndex int32, entryName string, entryInodeIndex
 
 	parentInode := &structures.Inode{}
 	parentOffset := int64(sb.S_inode_start) + int64(parentInodeIndex)*int64(sb.S_inode_size)
-	if err := parentInode.Deserialize(partitionPath, parentOffset); err != nil {
+	if err := parentInode.Deserialize(sb, partitionPath, parentOffset); err != nil {
 		return fmt.Errorf("no se pudo leer inodo padre %d para añadir entrada: %w", parentInodeIndex, err)
 	}
 	if parentInode.I_type[0] != '0' {
 		return fmt.Errorf("el inodo padre %d no es un directorio", parentInodeIndex)
 	}
 
-	fmt.Printf("Buscando slot libre en bloques existentes del padre %d...\n", parentInodeIndex)
-
-	findAndAddInFolderBlock := func(blockPtr int32) (bool, error) {
-		if blockPtr == -1 {
-			return false, nil
-		} 
-		if blockPtr < 0 || blockPtr >= sb.S_blocks_count {
-			fmt.Printf("Advertencia: Puntero inválido %d encontrado al buscar slot libre.\n", blockPtr)
-			return false, nil 
-		}
-
-		folderBlock := &structures.FolderBlock{}
-		blockOffset := int64(sb.S_block_start) + int64(blockPtr)*int64(sb.S_block_size)
-		if err := folderBlock.Deserialize(partitionPath, blockOffset); err != nil {
-			fmt.Printf("Advertencia: No se pudo leer bloque %d del padre %d para añadir entrada: %v\n", blockPtr, parentInodeIndex, err)
-			return false, nil
-		}
-
-		for i := 0; i < len(folderBlock.B_content); i++ {
-			if folderBlock.B_content[i].B_inodo == -1 { // Slot libre encontrado
-				fmt.Printf("Encontrado slot libre %d en bloque existente %d del padre %d\n", i, blockPtr, parentInodeIndex)
-				folderBlock.B_content[i].B_inodo = entryInodeIndex
-				var cleanName [12]byte
-				copy(cleanName[:], entryName)
-				folderBlock.B_content[i].B_name = cleanName
-
-				if err := folderBlock.Serialize(partitionPath, blockOffset); err != nil { // Serializar bloque modificado
-					return false, fmt.Errorf("falló al escribir la nueva entrada en el bloque existente %d: %w", blockPtr, err)
-				}
-				// Actualizar tiempos del padre y serializar padre
-				currentTime := float32(time.Now().Unix())
-				parentInode.I_mtime = currentTime
-				parentInode.I_atime = currentTime 
-				if err := parentInode.Serialize(partitionPath, parentOffset); err != nil {
-					fmt.Printf("Advertencia: falló al actualizar tiempos del inodo padre %d tras añadir en bloque %d: %v\n", parentInodeIndex, blockPtr, err)
-				}
-				return true, nil // Slot encontrado y usado
-			}
-		}
-		return false, nil 
-	} 
-
-	// Buscar en bloques directos
-	for k := 0; k < 12; k++ {
-		found, err := findAndAddInFolderBlock(parentInode.I_block[k])
-		if err != nil {
-			return err
-		}
-		if found {
-			return nil
-		} 
-	}
-
-	if parentInode.I_block[12] != -1 {
-		fmt.Printf("Buscando slot libre en bloques de indirección simple (L1 en %d)...\n", parentInode.I_block[12])
-		l1Block := &structures.PointerBlock{}
-		l1BlockIndex := parentInode.I_block[12]
-		// Validar índice L1
-		if l1BlockIndex < 0 || l1BlockIndex >= sb.S_blocks_count {
-			fmt.Printf("Advertencia: Puntero indirecto simple (I_block[12]) inválido: %d\n", l1BlockIndex)
-		} else {
-			l1Offset := int64(sb.S_block_start) + int64(l1BlockIndex)*int64(sb.S_block_size)
-			if err := l1Block.Deserialize(partitionPath, l1Offset); err == nil {
-				for _, folderBlockPtr := range l1Block.P_pointers { // Iterar punteros L2 (que apuntan a FolderBlocks)
-					found, err := findAndAddInFolderBlock(folderBlockPtr)
-					if err != nil {
-						return err
-					}
-					if found {
-						return nil
-					}
-				}
-			} else {
-				fmt.Printf("Advertencia: No se pudo leer el bloque de punteros L1 %d: %v\n", l1BlockIndex, err)
-			}
-		}
-	}
-	//  Si no se encontró slot, asignar NUEVO bloque al padre 
-	fmt.Printf("No se encontró slot libre en bloques existentes del padre %d. Buscando puntero libre para nuevo bloque...\n", parentInodeIndex)
-
-	allocateAndPrepareNewFolderBlock := func() (int32, *structures.FolderBlock, error) {
-		if sb.S_free_blocks_count < 1 {
-			return -1, nil, errors.New("no hay bloques libres para expandir directorio")
-		}
-
-		newBlockIndex, err := sb.FindFreeBlock(partitionPath)
-		if err != nil {
-			return -1, nil, fmt.Errorf("error al buscar bloque libre para expandir dir: %w", err)
-		}
-
-		// Actualizar bitmap y SB
-		err = sb.UpdateBitmapBlock(partitionPath, newBlockIndex,'1')
-		if err != nil {
-			return -1, nil, fmt.Errorf("error bitmap para nuevo bloque dir %d: %w", newBlockIndex, err)
-		}
-		sb.S_free_blocks_count--
-
-		// Crear, inicializar y serializar bloque vacío
-		newFolderBlock := &structures.FolderBlock{}
-		newFolderBlock.Initialize() // Usar el método Initialize
-
-		newBlockOffset := int64(sb.S_block_start) + int64(newBlockIndex)*int64(sb.S_block_size)
-		if err := newFolderBlock.Serialize(partitionPath, newBlockOffset); err != nil {
-			return -1, nil, fmt.Errorf("falló al inicializar/serializar nuevo bloque dir %d: %w", newBlockIndex, err)
-		}
-		fmt.Printf("Nuevo bloque carpeta vacío asignado y serializado en índice %d\n", newBlockIndex)
-		return newBlockIndex, newFolderBlock, nil 
-	} 
-
-	// Buscar puntero libre en bloques directos
-	for k := 0; k < 12; k++ {
-		if parentInode.I_block[k] == -1 {
-			fmt.Printf("Encontrado puntero directo libre en I_block[%d]. Asignando nuevo bloque carpeta...\n", k)
-			newBlockIndex, newFolderBlock, err := allocateAndPrepareNewFolderBlock()
-			if err != nil {
-				return err
-			}
-			// Actualizar inodo padre para apuntar al nuevo bloque
-			parentInode.I_block[k] = newBlockIndex
-			currentTime := float32(time.Now().Unix())
-			parentInode.I_mtime = currentTime
-			parentInode.I_atime = currentTime
-			if err := parentInode.Serialize(partitionPath, parentOffset); err != nil {
-				return fmt.Errorf("falló al actualizar I_block[%d] del padre %d: %w", k, parentInodeIndex, err)
-			}
-
-			// Añadir entrada al nuevo bloque (que está en memoria via newFolderBlock)
-			newFolderBlock.B_content[0].B_inodo = entryInodeIndex // Usar índice 0
-			var cleanName [12]byte
-			copy(cleanName[:], entryName)
-			newFolderBlock.B_content[0].B_name = cleanName
-
-			newBlockOffset := int64(sb.S_block_start) + int64(newBlockIndex)*int64(sb.S_block_size)
-			if err := newFolderBlock.Serialize(partitionPath, newBlockOffset); err != nil { // Sobrescribir con la entrada añadida
-				return fmt.Errorf("falló al serializar nuevo bloque dir %d con la entrada: %w", newBlockIndex, err)
-			}
-			fmt.Printf("Nueva entrada '%s' -> %d añadida al nuevo bloque %d vía puntero directo.\n", entryName, entryInodeIndex, newBlockIndex)
-			return nil // Éxito
-		}
-	}
-
-	// Buscar/Crear puntero en indirecto simple (L1)
-	fmt.Println("Punteros directos llenos. Verificando/Creando indirección simple (I_block[12])...")
-	l1Ptr := parentInode.I_block[12]
-	var l1Block *structures.PointerBlock 
-	var l1BlockIndex int32               
-
-	if l1Ptr == -1 { // Necesitamos crear el bloque L1
-		fmt.Println("I_block[12] no existe. Creando bloque de punteros L1...")
-		// Necesitamos 2 bloques: uno para L1 y otro para el FolderBlock
-		if sb.S_free_blocks_count < 2 {
-			return errors.New("no hay suficientes bloques libres para crear bloque L1 y bloque de carpeta")
-		}
-		// Asignar bloque L1
-		l1Index, err := sb.FindFreeBlock(partitionPath)
-		if err != nil {
-			return fmt.Errorf("error buscando bloque para L1: %w", err)
-		}
-		if err = sb.UpdateBitmapBlock(partitionPath, l1Index,'1'); err != nil {
-			return fmt.Errorf("error bitmap L1 %d: %w", l1Index, err)
-		}
-		sb.S_free_blocks_count--
-		l1BlockIndex = l1Index
-
-		// Actualizar inodo padre y serializarlo
-		parentInode.I_block[12] = l1BlockIndex
-		currentTime := float32(time.Now().Unix())
-		parentInode.I_mtime = currentTime
-		parentInode.I_atime = currentTime
-		if err := parentInode.Serialize(partitionPath, parentOffset); err != nil {
-			return fmt.Errorf("falló al actualizar I_block[12] del padre %d: %w", parentInodeIndex, err)
-		}
-		l1Block = &structures.PointerBlock{}
-		for i := range l1Block.P_pointers {
-			l1Block.P_pointers[i] = -1
-		}
-		l1Offset := int64(sb.S_block_start) + int64(l1BlockIndex)*int64(sb.S_block_size)
-		if err := l1Block.Serialize(partitionPath, l1Offset); err != nil {
-			return fmt.Errorf("falló al inicializar/serializar bloque L1 %d: %w", l1BlockIndex, err)
-		}
-		fmt.Printf("Bloque punteros L1 creado en índice %d\n", l1BlockIndex)
-	} else {
-		l1BlockIndex = l1Ptr
-		if l1BlockIndex < 0 || l1BlockIndex >= sb.S_blocks_count {
-			return fmt.Errorf("puntero indirecto simple (I_block[12]) inválido: %d", l1BlockIndex)
-		}
-		fmt.Printf("Bloque punteros L1 ya existe en índice %d. Cargando...\n", l1BlockIndex)
-		l1Block = &structures.PointerBlock{}
-		l1Offset := int64(sb.S_block_start) + int64(l1BlockIndex)*int64(sb.S_block_size)
-		if err := l1Block.Deserialize(partitionPath, l1Offset); err != nil {
-			return fmt.Errorf("no se pudo leer bloque de punteros L1 %d existente: %w", l1BlockIndex, err)
-		}
+	// El tipo de la entrada se guarda en el formato de longitud variable
+	entryInode := &structures.Inode{}
+	if err := entryInode.Deserialize(sb, partitionPath, int64(sb.S_inode_start)+int64(entryInodeIndex)*int64(sb.S_inode_size)); err != nil {
+		return fmt.Errorf("no se pudo leer inodo %d de la nueva entrada: %w", entryInodeIndex, err)
 	}
+	entry := structures.DirEntry{Inode: entryInodeIndex, Type: entryInode.I_type[0], Name: entryName}
 
-	foundL1PointerSlot := -1
-	for k := 0; k < len(l1Block.P_pointers); k++ {
-		if l1Block.P_pointers[k] == -1 {
-			foundL1PointerSlot = k
-			break
-		}
+	fmt.Printf("Añadiendo '%s' -> %d al directorio padre %d...\n", entryName, entryInodeIndex, parentInodeIndex)
+	if err := sb.AddDirEntry(partitionPath, parentInodeIndex, parentInode, entry); err != nil {
+		return fmt.Errorf("no se pudo añadir la entrada al directorio padre %d: %w", parentInodeIndex, err)
 	}
+	return nil
+}
 
-	if foundL1PointerSlot != -1 {
-		fmt.Printf("Encontrado puntero libre en L1[%d]. Asignando nuevo bloque carpeta...\n", foundL1PointerSlot)
-		// Asignar el nuevo bloque carpeta 
-		newBlockIndex, newFolderBlock, err := allocateAndPrepareNewFolderBlock()
-		if err != nil {
-			return err
-		}
-
-		// Actualizar el bloque L1 para que apunte al nuevo bloque
-		l1Block.P_pointers[foundL1PointerSlot] = newBlockIndex
-		l1Offset := int64(sb.S_block_start) + int64(l1BlockIndex)*int64(sb.S_block_size)
-		if err := l1Block.Serialize(partitionPath, l1Offset); err != nil {
-			return fmt.Errorf("falló al serializar bloque puntero L1 %d actualizado: %w", l1BlockIndex, err)
-		}
-
-		// Añadir entrada al nuevo bloque carpeta (que está en memoria)
-		newFolderBlock.B_content[0].B_inodo = entryInodeIndex // Usar slot 0
-		var cleanName [12]byte
-		copy(cleanName[:], entryName)
-		newFolderBlock.B_content[0].B_name = cleanName
-
-		// Serializar el nuevo bloque carpeta con la entrada
-		newBlockOffset := int64(sb.S_block_start) + int64(newBlockIndex)*int64(sb.S_block_size)
-		if err := newFolderBlock.Serialize(partitionPath, newBlockOffset); err != nil {
-			return fmt.Errorf("falló al serializar nuevo bloque dir %d con la entrada: %w", newBlockIndex, err)
-		}
-		fmt.Printf("Nueva entrada '%s' -> %d añadida al nuevo bloque %d vía puntero indirecto simple.\n", entryName, entryInodeIndex, newBlockIndex)
-		return nil
-	}
-	return fmt.Errorf("directorio padre (inodo %d) lleno: no hay espacio en bloques existentes ni en punteros directos/indirectos simples. Indirección doble/triple no implementada para directorios", parentInodeIndex)
+// Asigna bloques de datos para un archivo, actualizando el superbloque y el bitmap.
+func allocateDataBlocks(contentBytes []byte, fileSize int64, sb *structures.SuperBlock, partitionPath string) ([15]int32, error) {
+	return allocateFileBlocks(bytes.NewReader(contentBytes), fileSize, sb, partitionPath)
 }
 
-// Asigna bloques de datos para un archivo, actualizando el superbloque y el bitmap.1
-func allocateDataBlocks(contentBytes []byte, fileSize int32, sb *structures.SuperBlock, partitionPath string) ([15]int32, error) {
+// Asigna y escribe los bloques de un archivo de fileSize bytes leyendo su contenido de content.
+// Los bloques se llenan en orden: 12 directos y luego los indirectos simple, doble y triple, cada
+// bloque de punteros antes de los bloques a los que apunta (el mismo orden que BlocksForFile).
+func allocateFileBlocks(content io.ReaderAt, fileSize int64, sb *structures.SuperBlock, partitionPath string) ([15]int32, error) {
 	allocatedBlockIndices := [15]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1}
 
 	if fileSize == 0 {
@@ -675,217 +456,152 @@ func allocateDataBlocks(contentBytes []byte, fileSize int32, sb *structures.Supe
 	if blockSize <= 0 {
 		return allocatedBlockIndices, errors.New("tamaño de bloque inválido en superbloque al asignar bloques")
 	}
-	numBlocksNeeded := (fileSize + blockSize - 1) / blockSize
-
-	fmt.Printf("Allocate: Necesitando %d bloques para %d bytes (tamaño bloque: %d)\n", numBlocksNeeded, fileSize, blockSize)
-
-	directLimit := int32(12)
-	pointersPerBlock := int32(len(structures.PointerBlock{}.P_pointers))
-	if pointersPerBlock <= 0 {
-		return allocatedBlockIndices, errors.New("cálculo inválido de punteros por bloque")
+	if fileSize < 0 || fileSize > sb.MaxFileSize() {
+		return allocatedBlockIndices, fmt.Errorf("el archivo es demasiado grande (%d bytes): el máximo con bloques de %d bytes es %d", fileSize, blockSize, sb.MaxFileSize())
 	}
-	simpleLimit := directLimit + pointersPerBlock
-	doubleLimit := simpleLimit + pointersPerBlock*pointersPerBlock
-	tripleLimit := doubleLimit + pointersPerBlock*pointersPerBlock*pointersPerBlock
+	numBlocksNeeded := (fileSize + int64(blockSize) - 1) / int64(blockSize)
+	totalBlocks := sb.BlocksForFile(int32(numBlocksNeeded))
 
-	if numBlocksNeeded > tripleLimit {
-		return allocatedBlockIndices, fmt.Errorf("el archivo es demasiado grande (%d bloques), excede el límite de indirección triple (%d bloques)", numBlocksNeeded, tripleLimit)
-	}
-	if numBlocksNeeded > sb.S_free_blocks_count {
-		return allocatedBlockIndices, fmt.Errorf("espacio insuficiente: se necesitan %d bloques, disponibles %d", numBlocksNeeded, sb.S_free_blocks_count)
+	fmt.Printf("Allocate: Necesitando %d bloques de datos (%d con punteros) para %d bytes (tamaño bloque: %d)\n", numBlocksNeeded, totalBlocks, fileSize, blockSize)
+	if totalBlocks > sb.S_free_blocks_count {
+		return allocatedBlockIndices, fmt.Errorf("espacio insuficiente: se necesitan %d bloques, disponibles %d", totalBlocks, sb.S_free_blocks_count)
 	}
 
-	// Variables para bloques indirectos
-	var indirect1Block *structures.PointerBlock = nil // Simple L1
-	var indirect1BlockIndex int32 = -1
-	var indirect2Blocks [16]*structures.PointerBlock = [16]*structures.PointerBlock{}
-	var indirect2BlockIndices [16]int32 = [16]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1}
-	var indirect2L1Block *structures.PointerBlock = nil
-	var indirect2L1BlockIndex int32 = -1
-
-	for b := int32(0); b < numBlocksNeeded; b++ {
-		dataBlockIndex, err := sb.FindFreeBlock(partitionPath)
-		if err != nil {
-			return allocatedBlockIndices, fmt.Errorf("no se encontró bloque de datos libre (necesitaba el bloque #%d de %d): %w", b+1, numBlocksNeeded, err)
-		}
+	// Reservar de una vez los bloques de datos y de punteros, en el orden en que aparecen en el archivo
+	plan, contiguous, err := sb.PlanBlocks(partitionPath, totalBlocks)
+	if err != nil {
+		return allocatedBlockIndices, fmt.Errorf("no se pudieron reservar bloques para el archivo: %w", err)
+	}
+	fmt.Printf("Allocate: %d bloques reservados (contiguos: %t)\n", len(plan), contiguous)
 
-		fmt.Printf("Allocate: Bloque libre encontrado: %d (para bloque de datos #%d)\n", dataBlockIndex, b)
+	next := 0             // Siguiente bloque del plan
+	dataBlock := int64(0) // Siguiente bloque lógico de datos del archivo
 
-		// Actualizar bitmap y SB para el bloque de DATOS
-		err = sb.UpdateBitmapBlock(partitionPath, dataBlockIndex,'1')
-		if err != nil {
-			return allocatedBlockIndices, fmt.Errorf("error bitmap bloque datos %d: %w", dataBlockIndex, err)
+	// Toma el siguiente bloque del plan y lo marca como usado
+	take := func() (int32, error) {
+		if next >= len(plan) {
+			return -1, errors.New("el plan de bloques se agotó antes de terminar el archivo")
 		}
-		sb.S_free_blocks_count-- // Decrementar contador
-
-		// Escribir datos en el bloque
-		fileBlock := &structures.FileBlock{}
-		start := b * blockSize
-		end := start + blockSize
-		if end > fileSize {
-			end = fileSize
-		}
-		bytesToWrite := contentBytes[start:end]
-		copy(fileBlock.B_content[:], bytesToWrite) // Copia hasta 64 bytes
-		blockOffset := int64(sb.S_block_start) + int64(dataBlockIndex)*int64(sb.S_block_size)
-		err = fileBlock.Serialize(partitionPath, blockOffset)
-		if err != nil {
-			return allocatedBlockIndices, fmt.Errorf("error serializando bloque datos %d: %w", dataBlockIndex, err)
+		blockIndex := plan[next]
+		next++
+		if err := sb.UpdateBitmapBlock(partitionPath, blockIndex, '1'); err != nil {
+			return -1, fmt.Errorf("error bitmap bloque %d: %w", blockIndex, err)
 		}
+		sb.S_free_blocks_count--
+		return blockIndex, nil
+	}
 
-		// Directos (0-11)
-		if b < directLimit {
-			allocatedBlockIndices[b] = dataBlockIndex
-			fmt.Printf("Allocate: Bloque datos %d asignado a I_block[%d]\n", dataBlockIndex, b)
-			continue
+	// Crea el subárbol de un puntero de nivel level (0 = bloque de datos) y devuelve su bloque
+	var fill func(level int) (int32, error)
+	fill = func(level int) (int32, error) {
+		blockIndex, err := take()
+		if err != nil {
+			return -1, err
 		}
+		blockOffset := int64(sb.S_block_start) + int64(blockIndex)*int64(blockSize)
 
-		// Indirecto Simple (12 hasta simpleLimit-1)
-		if b < simpleLimit {
-			idxInSimple := b - directLimit // Índice dentro del bloque de punteros simple (0 a pointersPerBlock-1)
-			fmt.Printf("Allocate: Bloque datos %d necesita ir en Indirecto Simple (idx %d)\n", dataBlockIndex, idxInSimple)
-
-			// Asignar el bloque de punteros L1 (Simple) si es la primera vez
-			if indirect1Block == nil {
-				fmt.Println("Allocate: Asignando Bloque Punteros L1 (Simple)...")
-				indirect1BlockIndex, err = sb.FindFreeBlock(partitionPath)
-				if err != nil {
-					return allocatedBlockIndices, fmt.Errorf("no se pudo asignar bloque para punteros L1 (Simple): %w", err)
-				}
-
-				err = sb.UpdateBitmapBlock(partitionPath, indirect1BlockIndex,'1')
-				if err != nil {
-					return allocatedBlockIndices, fmt.Errorf("error bitmap bloque punteros L1 %d: %w", indirect1BlockIndex, err)
-				}
-				sb.S_free_blocks_count--
-
-				allocatedBlockIndices[12] = indirect1BlockIndex
-				indirect1Block = &structures.PointerBlock{}   
-				for i := range indirect1Block.P_pointers {    
-					indirect1Block.P_pointers[i] = -1
-				}
-				// Serializar el bloque L1 vacío AHORA
-				offsetL1 := int64(sb.S_block_start) + int64(indirect1BlockIndex)*int64(sb.S_block_size)
-				err = indirect1Block.Serialize(partitionPath, offsetL1)
-				if err != nil {
-					return allocatedBlockIndices, fmt.Errorf("error serializando bloque puntero L1 simple INICIAL %d: %w", indirect1BlockIndex, err)
-				}
-				fmt.Printf("Allocate: Bloque Punteros L1 (Simple) asignado al índice %d y serializado vacío\n", indirect1BlockIndex)
+		if level == 0 {
+			fileBlock := structures.NewFileBlock(blockSize)
+			start := dataBlock * int64(blockSize)
+			n := fileSize - start
+			if n > int64(blockSize) {
+				n = int64(blockSize)
 			}
-			// Guardar puntero al bloque de datos en el struct del bloque de punteros L1
-			indirect1Block.P_pointers[idxInSimple] = dataBlockIndex
-			fmt.Printf("Allocate: Puntero a datos %d guardado en P_pointers[%d] del Bloque L1 (Simple) en memoria\n", dataBlockIndex, idxInSimple)
-			continue
+			if read, err := content.ReadAt(fileBlock.B_content[:n], start); int64(read) < n {
+				return -1, fmt.Errorf("error leyendo contenido del bloque de datos #%d: %w", dataBlock, err)
+			}
+			if err := fileBlock.Serialize(partitionPath, blockOffset); err != nil {
+				return -1, fmt.Errorf("error serializando bloque datos %d: %w", blockIndex, err)
+			}
+			dataBlock++
+			return blockIndex, nil
 		}
 
-		// Indirecto Doble (simpleLimit hasta doubleLimit-1)
-		if b < doubleLimit {
-			relIdxDouble := b - simpleLimit     
-			idxL1 := relIdxDouble / pointersPerBlock 
-			idxL2 := relIdxDouble % pointersPerBlock 
-			fmt.Printf("Allocate: Bloque datos %d necesita ir en Indirecto Doble (L1[%d], L2[%d])\n", dataBlockIndex, idxL1, idxL2)
-
-			// Asignar el bloque de punteros L1 (Doble) si es la primera vez
-			if indirect2L1Block == nil {
-				fmt.Println("Allocate: Asignando Bloque Punteros L1 (Doble)...")
-				indirect2L1BlockIndex, err = sb.FindFreeBlock(partitionPath)
-				if err != nil {
-					return allocatedBlockIndices, fmt.Errorf("no se pudo asignar bloque para punteros L1 (Doble): %w", err)
-				}
-
-				err = sb.UpdateBitmapBlock(partitionPath, indirect2L1BlockIndex,'1')
-				if err != nil {
-					return allocatedBlockIndices, fmt.Errorf("error bitmap bloque punteros L1 doble %d: %w", indirect2L1BlockIndex, err)
-				}
-				sb.S_free_blocks_count--
-
-				allocatedBlockIndices[13] = indirect2L1BlockIndex 
-				indirect2L1Block = &structures.PointerBlock{}
-				for i := range indirect2L1Block.P_pointers {
-					indirect2L1Block.P_pointers[i] = -1
-				}
-				// Serializar L1 doble vacío
-				offsetL1D := int64(sb.S_block_start) + int64(indirect2L1BlockIndex)*int64(sb.S_block_size)
-				err = indirect2L1Block.Serialize(partitionPath, offsetL1D)
-				if err != nil {
-					return allocatedBlockIndices, fmt.Errorf("error serializando bloque puntero L1 doble INICIAL %d: %w", indirect2L1BlockIndex, err)
-				}
-				fmt.Printf("Allocate: Bloque Punteros L1 (Doble) asignado al índice %d y serializado vacío\n", indirect2L1BlockIndex)
+		pointerBlock := structures.NewPointerBlock(blockSize)
+		for i := range pointerBlock.P_pointers {
+			pointerBlock.P_pointers[i] = -1
+		}
+		for i := range pointerBlock.P_pointers {
+			if dataBlock >= numBlocksNeeded {
+				break
 			}
-
-			// Asignar el bloque de punteros L2 si es la primera vez para este índice L1
-			if indirect2Blocks[idxL1] == nil {
-				fmt.Printf("Allocate: Asignando Bloque Punteros L2 (para L1[%d])...\n", idxL1)
-				blockIndexL2, err := sb.FindFreeBlock(partitionPath)
-				if err != nil {
-					return allocatedBlockIndices, fmt.Errorf("no se pudo asignar bloque para punteros L2 (idxL1=%d): %w", idxL1, err)
-				}
-
-				err = sb.UpdateBitmapBlock(partitionPath, blockIndexL2,'1')
-				if err != nil {
-					return allocatedBlockIndices, fmt.Errorf("error bitmap bloque punteros L2 %d: %w", blockIndexL2, err)
-				}
-				sb.S_free_blocks_count--
-
-				indirect2L1Block.P_pointers[idxL1] = blockIndexL2   // Guardar puntero a L2 en L1 (en memoria)
-				indirect2Blocks[idxL1] = &structures.PointerBlock{} // Crear struct L2 en memoria
-				indirect2BlockIndices[idxL1] = blockIndexL2         // Guardar índice L2 para serialización posterior
-				for i := range indirect2Blocks[idxL1].P_pointers {
-					indirect2Blocks[idxL1].P_pointers[i] = -1
-				}
-				// Serializar L2 vacío ahora
-				offsetL2 := int64(sb.S_block_start) + int64(blockIndexL2)*int64(sb.S_block_size)
-				err = indirect2Blocks[idxL1].Serialize(partitionPath, offsetL2)
-				if err != nil {
-					return allocatedBlockIndices, fmt.Errorf("error serializando bloque puntero L2 INICIAL %d: %w", blockIndexL2, err)
-				}
-
-				fmt.Printf("Allocate: Bloque Punteros L2 asignado al índice %d (guardado en L1[%d]) y serializado vacío\n", blockIndexL2, idxL1)
-
-				// Serializar L1 AHORA porque cambió su puntero a L2
-				offsetL1 := int64(sb.S_block_start) + int64(indirect2L1BlockIndex)*int64(sb.S_block_size)
-				err = indirect2L1Block.Serialize(partitionPath, offsetL1)
-				if err != nil {
-					return allocatedBlockIndices, fmt.Errorf("error serializando bloque puntero L1 doble %d tras añadir puntero a L2: %w", indirect2L1BlockIndex, err)
-				}
+			child, err := fill(level - 1)
+			if err != nil {
+				return -1, err
 			}
-
-			// Guardar puntero al bloque de datos en el struct del bloque de punteros L2 correspondiente (en memoria)
-			indirect2Blocks[idxL1].P_pointers[idxL2] = dataBlockIndex
-			fmt.Printf("Allocate: Puntero a datos %d guardado en P_pointers[%d] del Bloque L2 (índice %d) en memoria\n", dataBlockIndex, idxL2, indirect2BlockIndices[idxL1])
-			continue
+			pointerBlock.P_pointers[i] = child
 		}
-
-		//No lo haré xd
-		if b < tripleLimit {
-			return allocatedBlockIndices, fmt.Errorf("la indirección triple (bloque %d) no está implementada", b)
+		if err := pointerBlock.Serialize(partitionPath, blockOffset); err != nil {
+			return -1, fmt.Errorf("error serializando bloque de punteros nivel %d (%d): %w", level, blockIndex, err)
 		}
+		fmt.Printf("Allocate: Bloque de punteros nivel %d en %d\n", level, blockIndex)
+		return blockIndex, nil
 	}
 
-	// Serializar Bloques de Punteros Pendientes
-	if indirect1Block != nil {
-		fmt.Printf("Allocate: Serializando Bloque Punteros L1 (Simple) final %d\n", indirect1BlockIndex)
-		offset := int64(sb.S_block_start) + int64(indirect1BlockIndex)*int64(sb.S_block_size)
-		err := indirect1Block.Serialize(partitionPath, offset)
-		if err != nil {
-			return allocatedBlockIndices, fmt.Errorf("error serializando bloque puntero L1 simple final %d: %w", indirect1BlockIndex, err)
+	for k := 0; k < len(allocatedBlockIndices) && dataBlock < numBlocksNeeded; k++ {
+		level := 0
+		if k >= 12 {
+			level = k - 11 // I_block[12] simple, [13] doble, [14] triple
 		}
-	}
-	// Serializar L2 para Doble
-	if indirect2L1Block != nil { // Si se usó doble indirección
-		for idxL1 := 0; idxL1 < len(indirect2Blocks); idxL1++ {
-			if indirect2Blocks[idxL1] != nil { // Si este bloque L2 fue creado/usado
-				idxL2Block := indirect2BlockIndices[idxL1] 
-				fmt.Printf("Allocate: Serializando Bloque Punteros L2 final %d (desde L1[%d])\n", idxL2Block, idxL1)
-				offsetL2 := int64(sb.S_block_start) + int64(idxL2Block)*int64(sb.S_block_size)
-				err := indirect2Blocks[idxL1].Serialize(partitionPath, offsetL2)
-				if err != nil {
-					return allocatedBlockIndices, fmt.Errorf("error serializando bloque puntero L2 final %d: %w", idxL2Block, err)
-				}
-			}
+		allocatedBlockIndices[k], err = fill(level)
+		if err != nil {
+			return allocatedBlockIndices, err
 		}
 	}
 
+	if len(plan) > 0 {
+		sb.S_first_blo = plan[len(plan)-1] + 1 // Igual que FindFreeBlock: seguir después del último usado
+	}
 	fmt.Println("Allocate: Asignación de bloques de datos completada.")
 	return allocatedBlockIndices, nil
 }
+
+// Como allocateFileBlocks pero para mkfile -sparse: los bloques que son todos ceros (o todo el
+// archivo si content es nil) no se asignan y quedan como huecos, y los bloques de punteros se
+// asignan solo donde hay datos.
+func allocateSparseBlocks(content io.ReaderAt, fileSize int64, sb *structures.SuperBlock, partitionPath string) ([15]int32, error) {
+	holder := &structures.Inode{I_size: fileSize, I_type: [1]byte{'1'}, I_xattr: -1}
+	for i := range holder.I_block {
+		holder.I_block[i] = -1
+	}
+	if content == nil || fileSize == 0 {
+		return holder.I_block, nil
+	}
+
+	blockSize := int64(sb.S_block_size)
+	written, holes := 0, 0
+	for n := int64(0); n*blockSize < fileSize; n++ {
+		fileBlock := structures.NewFileBlock(sb.S_block_size)
+		chunk := fileBlock.B_content[:min(blockSize, fileSize-n*blockSize)]
+		if read, err := content.ReadAt(chunk, n*blockSize); read < len(chunk) {
+			sb.TruncateBlocks(partitionPath, holder, 0)
+			return holder.I_block, fmt.Errorf("error leyendo contenido del bloque de datos #%d: %w", n, err)
+		}
+		if len(bytes.TrimLeft(chunk, "\x00")) == 0 {
+			holes++
+			continue
+		}
+		blockIndex, _, err := sb.AllocBlockAt(partitionPath, holder, n)
+		if err == nil {
+			err = fileBlock.Serialize(partitionPath, int64(sb.S_block_start)+int64(blockIndex)*blockSize)
+		}
+		if err != nil {
+			sb.TruncateBlocks(partitionPath, holder, 0) // Devolver lo asignado
+			return holder.I_block, fmt.Errorf("error escribiendo bloque de datos #%d: %w", n, err)
+		}
+		written++
+	}
+	fmt.Printf("Allocate: %d bloques de datos escritos, %d huecos\n", written, holes)
+	return holder.I_block, nil
+}
+
+// Contenido que genera mkfile -size: los dígitos 0-9 repetidos, calculado por posición para no
+// tener el archivo completo en memoria.
+type digitPattern struct{}
+
+func (digitPattern) ReadAt(p []byte, off int64) (int, error) {
+	for i := range p {
+		p[i] = byte('0' + (off+int64(i))%10)
+	}
+	return len(p), nil
+}
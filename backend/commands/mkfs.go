@@ -3,20 +3,28 @@ package commands
 import (
 	stores "backend/stores"
 	structures "backend/structures"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"math"
-	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
 
 type MKFS struct {
-	id  string // ID del disco
-	typ string // Tipo de formato
-	fs  string // Tipo de sistema de archivos (ext2, ext3)
+	id      string // ID del disco
+	typ     string // Tipo de formato
+	fs      string // Tipo de sistema de archivos (ext2, ext3)
+	bitmap  string // Formato de los bitmaps: ascii (un byte '0'/'1') o packed (un bit)
+	bs      int32  // Tamaño de bloque en bytes (64, 512, 1024 o 4096)
+	ratio   int32  // Bytes de partición por inodo (0: 3 bloques por inodo)
+	dirent  string // Formato de las entradas de directorio: classic (12 bytes) o long (longitud variable)
+	bits    int32  // Bits de I_size en el inodo: 32 (formato original) o 64
+	times   string // Marcas de tiempo: int64 (segundos exactos) o float32 (formato original)
+	links   string // Contador de enlaces duros en el inodo: count o none (formato original)
+	xattr   string // Atributos extendidos: block (un bloque por inodo) o none (formato original)
+	special string // Bits setuid, setgid y sticky: bits o none (formato original)
 }
 
 func ParseMkfs(tokens []string) (string, error) {
@@ -26,6 +34,15 @@ func ParseMkfs(tokens []string) (string, error) {
 	idRegex := regexp.MustCompile(`^(?i)-id=(?:"([^"]+)"|([^\s"]+))$`)
 	typeRegex := regexp.MustCompile(`^(?i)-type=(?:"([^"]+)"|([^\s"]+))$`)
 	fsRegex := regexp.MustCompile(`^(?i)-fs=(?:"([^"]+)"|([^\s"]+))$`)
+	bitmapRegex := regexp.MustCompile(`^(?i)-bitmap=(?:"([^"]+)"|([^\s"]+))$`)
+	bsRegex := regexp.MustCompile(`^(?i)-bs=(?:"([^"]+)"|([^\s"]+))$`)
+	ratioRegex := regexp.MustCompile(`^(?i)-inoderatio=(?:"([^"]+)"|([^\s"]+))$`)
+	direntRegex := regexp.MustCompile(`^(?i)-dirent=(?:"([^"]+)"|([^\s"]+))$`)
+	filesizeRegex := regexp.MustCompile(`^(?i)-filesize=(?:"([^"]+)"|([^\s"]+))$`)
+	timesRegex := regexp.MustCompile(`^(?i)-times=(?:"([^"]+)"|([^\s"]+))$`)
+	linksRegex := regexp.MustCompile(`^(?i)-links=(?:"([^"]+)"|([^\s"]+))$`)
+	xattrRegex := regexp.MustCompile(`^(?i)-xattr=(?:"([^"]+)"|([^\s"]+))$`)
+	specialRegex := regexp.MustCompile(`^(?i)-special=(?:"([^"]+)"|([^\s"]+))$`)
 
 	fmt.Printf("Tokens MKFS recibidos: %v\n", tokens)
 
@@ -68,6 +85,78 @@ func ParseMkfs(tokens []string) (string, error) {
 				value = match[2]
 			}
 			matched = true
+		} else if match = bitmapRegex.FindStringSubmatch(token); match != nil {
+			key = "bitmap"
+			if match[1] != "" {
+				value = match[1]
+			} else {
+				value = match[2]
+			}
+			matched = true
+		} else if match = bsRegex.FindStringSubmatch(token); match != nil {
+			key = "bs"
+			if match[1] != "" {
+				value = match[1]
+			} else {
+				value = match[2]
+			}
+			matched = true
+		} else if match = ratioRegex.FindStringSubmatch(token); match != nil {
+			key = "inoderatio"
+			if match[1] != "" {
+				value = match[1]
+			} else {
+				value = match[2]
+			}
+			matched = true
+		} else if match = direntRegex.FindStringSubmatch(token); match != nil {
+			key = "dirent"
+			if match[1] != "" {
+				value = match[1]
+			} else {
+				value = match[2]
+			}
+			matched = true
+		} else if match = filesizeRegex.FindStringSubmatch(token); match != nil {
+			key = "filesize"
+			if match[1] != "" {
+				value = match[1]
+			} else {
+				value = match[2]
+			}
+			matched = true
+		} else if match = timesRegex.FindStringSubmatch(token); match != nil {
+			key = "times"
+			if match[1] != "" {
+				value = match[1]
+			} else {
+				value = match[2]
+			}
+			matched = true
+		} else if match = linksRegex.FindStringSubmatch(token); match != nil {
+			key = "links"
+			if match[1] != "" {
+				value = match[1]
+			} else {
+				value = match[2]
+			}
+			matched = true
+		} else if match = xattrRegex.FindStringSubmatch(token); match != nil {
+			key = "xattr"
+			if match[1] != "" {
+				value = match[1]
+			} else {
+				value = match[2]
+			}
+			matched = true
+		} else if match = specialRegex.FindStringSubmatch(token); match != nil {
+			key = "special"
+			if match[1] != "" {
+				value = match[1]
+			} else {
+				value = match[2]
+			}
+			matched = true
 		}
 
 		// Si el token NO coincidió con NINGUNA regex válida
@@ -105,6 +194,60 @@ func ParseMkfs(tokens []string) (string, error) {
 				return "", fmt.Errorf("valor inválido '%s' para -fs: debe ser '2fs' o '3fs'", value)
 			}
 			cmd.fs = fsLower
+		case "bitmap":
+			bitmapLower := strings.ToLower(value)
+			if bitmapLower != "ascii" && bitmapLower != "packed" {
+				return "", fmt.Errorf("valor inválido '%s' para -bitmap: debe ser 'ascii' o 'packed'", value)
+			}
+			cmd.bitmap = bitmapLower
+		case "bs":
+			bs, err := strconv.Atoi(value)
+			if err != nil || !structures.ValidBlockSize(int32(bs)) {
+				return "", fmt.Errorf("valor inválido '%s' para -bs: debe ser 64, 512, 1024 o 4096", value)
+			}
+			cmd.bs = int32(bs)
+		case "inoderatio":
+			ratio, err := strconv.Atoi(value)
+			if err != nil || ratio <= 0 {
+				return "", fmt.Errorf("valor inválido '%s' para -inoderatio: debe ser un entero positivo (bytes por inodo)", value)
+			}
+			cmd.ratio = int32(ratio)
+		case "dirent":
+			direntLower := strings.ToLower(value)
+			if direntLower != "classic" && direntLower != "long" {
+				return "", fmt.Errorf("valor inválido '%s' para -dirent: debe ser 'classic' o 'long'", value)
+			}
+			cmd.dirent = direntLower
+		case "filesize":
+			if value != "32" && value != "64" {
+				return "", fmt.Errorf("valor inválido '%s' para -filesize: debe ser 32 o 64 (bits del tamaño de archivo)", value)
+			}
+			bits, _ := strconv.Atoi(value)
+			cmd.bits = int32(bits)
+		case "times":
+			timesLower := strings.ToLower(value)
+			if timesLower != "int64" && timesLower != "float32" {
+				return "", fmt.Errorf("valor inválido '%s' para -times: debe ser 'int64' o 'float32'", value)
+			}
+			cmd.times = timesLower
+		case "links":
+			linksLower := strings.ToLower(value)
+			if linksLower != "count" && linksLower != "none" {
+				return "", fmt.Errorf("valor inválido '%s' para -links: debe ser 'count' o 'none'", value)
+			}
+			cmd.links = linksLower
+		case "xattr":
+			xattrLower := strings.ToLower(value)
+			if xattrLower != "block" && xattrLower != "none" {
+				return "", fmt.Errorf("valor inválido '%s' para -xattr: debe ser 'block' o 'none'", value)
+			}
+			cmd.xattr = xattrLower
+		case "special":
+			specialLower := strings.ToLower(value)
+			if specialLower != "bits" && specialLower != "none" {
+				return "", fmt.Errorf("valor inválido '%s' para -special: debe ser 'bits' o 'none'", value)
+			}
+			cmd.special = specialLower
 		}
 	}
 
@@ -120,6 +263,35 @@ func ParseMkfs(tokens []string) (string, error) {
 		cmd.fs = "2fs"
 		fmt.Println("INFO: Parámetro -fs no especificado, usando por defecto '2fs' (EXT2).")
 	}
+	if !processedKeys["bitmap"] {
+		cmd.bitmap = "ascii"
+	}
+	if !processedKeys["bs"] {
+		cmd.bs = structures.DefaultBlockSize
+	}
+	if !processedKeys["dirent"] {
+		cmd.dirent = "classic"
+	}
+	if !processedKeys["filesize"] {
+		cmd.bits = 32
+	}
+	if !processedKeys["times"] {
+		cmd.times = "int64" // float32 solo redondea las fechas a pasos de ~2 minutos
+	}
+	if !processedKeys["links"] {
+		cmd.links = "count"
+	}
+	if !processedKeys["xattr"] {
+		cmd.xattr = "block"
+	}
+	if !processedKeys["special"] {
+		cmd.special = "bits"
+	}
+	// Cada inodo necesita al menos su entrada en la tabla y un bloque de datos
+	minRatio := structures.InodeDiskSize(cmd.features()) + cmd.bs
+	if processedKeys["inoderatio"] && cmd.ratio < minRatio {
+		return "", fmt.Errorf("valor inválido %d para -inoderatio: con bloques de %d bytes debe ser al menos %d", cmd.ratio, cmd.bs, minRatio)
+	}
 
 	err := commandMkfs(cmd)
 	if err != nil {
@@ -130,10 +302,52 @@ func ParseMkfs(tokens []string) (string, error) {
 	if cmd.fs == "3fs" {
 		fsName = "EXT3"
 	}
+	inodeRatio := "3 bloques por inodo"
+	if cmd.ratio > 0 {
+		inodeRatio = fmt.Sprintf("%d bytes por inodo", cmd.ratio)
+	}
+	nameMax := structures.NameMaxFor(cmd.bs, cmd.dirent == "long")
+	maxFile := structures.MaxFileSizeFor(cmd.bs, cmd.bits == 64)
 	return fmt.Sprintf("MKFS: Sistema de archivos %s creado exitosamente\n"+
 		"-> ID: %s\n"+
-		"-> Tipo: %s",
-		fsName, cmd.id, cmd.typ), nil
+		"-> Tipo: %s\n"+
+		"-> Bitmaps: %s\n"+
+		"-> Tamaño de bloque: %d bytes\n"+
+		"-> Inodos: %s\n"+
+		"-> Nombres: %s, hasta %d bytes\n"+
+		"-> Archivos: tamaño de %d bits, hasta %d bytes\n"+
+		"-> Marcas de tiempo: %s\n"+
+		"-> Enlaces duros: %s\n"+
+		"-> Atributos extendidos: %s\n"+
+		"-> Bits especiales: %s",
+		fsName, cmd.id, cmd.typ, cmd.bitmap, cmd.bs, inodeRatio, cmd.dirent, nameMax, cmd.bits, maxFile, cmd.times, cmd.links, cmd.xattr, cmd.special), nil
+}
+
+// Características incompatibles que activan las opciones de mkfs.
+func (mkfs *MKFS) features() int32 {
+	features := int32(0)
+	if mkfs.bitmap == "packed" {
+		features |= structures.FeatureIncompatPackedBitmaps
+	}
+	if mkfs.dirent == "long" {
+		features |= structures.FeatureIncompatLongNames
+	}
+	if mkfs.bits == 64 {
+		features |= structures.FeatureIncompatLargeFiles
+	}
+	if mkfs.times == "int64" {
+		features |= structures.FeatureIncompatWideTimes
+	}
+	if mkfs.links == "count" {
+		features |= structures.FeatureIncompatLinkCounts
+	}
+	if mkfs.xattr == "block" {
+		features |= structures.FeatureIncompatXattrs
+	}
+	if mkfs.special == "bits" {
+		features |= structures.FeatureIncompatSpecialPerms
+	}
+	return features
 }
 
 func commandMkfs(mkfs *MKFS) error {
@@ -146,13 +360,14 @@ func commandMkfs(mkfs *MKFS) error {
 	}
 	fmt.Println("\nInformación de la Partición:")
 	mountedPartitionInfo.PrintPartition()
-	if mountedPartitionInfo.Part_size <= int32(binary.Size(structures.SuperBlock{}))+1024 {
+	if mountedPartitionInfo.Part_size <= structures.SuperBlockDiskSize(mkfs.features())+1024 {
 		return fmt.Errorf("la partición '%s' es demasiado pequeña para formatear", mkfs.id)
 	}
 
-	// Calcular n
-	n := calculateN(mountedPartitionInfo)
-	fmt.Println("\nValor de n calculado:", n)
+	// Calcular n (inodos) y la cantidad de bloques
+	geo := fsGeometry{blockSize: mkfs.bs, inodeRatio: mkfs.ratio, features: mkfs.features()}
+	n, blocks := calculateCounts(mountedPartitionInfo, geo)
+	fmt.Printf("\nValor de n calculado: %d (bloques: %d de %d bytes)\n", n, blocks, geo.blockSize)
 	minInodes := int32(3)
 	if mkfs.fs == "2fs" {
 		minInodes = 2
@@ -162,7 +377,7 @@ func commandMkfs(mkfs *MKFS) error {
 	}
 
 	// Crear SuperBloque Inicial
-	superBlock := createSuperBlock(mountedPartitionInfo, n, mkfs.fs)
+	superBlock := createSuperBlock(mountedPartitionInfo, n, blocks, mkfs.fs, geo)
 	if superBlock == nil {
 		return errors.New("falló la creación del superbloque inicial")
 	}
@@ -218,58 +433,90 @@ func commandMkfs(mkfs *MKFS) error {
 	return nil
 }
 
-func calculateN(partition *structures.Partition) int32 {
-	inodeSize := int32(binary.Size(structures.Inode{}))
-	blockSize := int32(binary.Size(structures.FileBlock{}))
-	superblockSize := int32(binary.Size(structures.SuperBlock{}))
-	if inodeSize <= 0 || blockSize <= 0 || superblockSize <= 0 {
-		return 0
+// Geometría con la que se formatea una partición
+type fsGeometry struct {
+	blockSize  int32 // Bytes por bloque (-bs)
+	inodeRatio int32 // Bytes de partición por inodo (-inoderatio); 0 = 3 bloques por inodo
+	features   int32 // Características incompatibles (FeatureIncompat*)
+}
+
+// Geometría de un sistema de archivos ya formateado, para recalcularlo con otro tamaño de partición.
+func geometryOf(sb *structures.SuperBlock) fsGeometry {
+	return fsGeometry{blockSize: sb.S_block_size, inodeRatio: sb.S_inode_ratio, features: sb.S_feature_incompat}
+}
+
+// Calcula cuántos inodos y bloques caben en la partición. Sin -inoderatio se reservan 3 bloques
+// por inodo como en el formato original; con él, un inodo por cada inodeRatio bytes y el resto en
+// bloques. Los bitmaps empaquetados ocupan un bit por entrada en lugar de un byte.
+func calculateCounts(partition *structures.Partition, geo fsGeometry) (int32, int32) {
+	inodeSize := float64(structures.InodeDiskSize(geo.features))
+	blockSize := float64(geo.blockSize)
+	superblockSize := structures.SuperBlockDiskSize(geo.features)
+	if blockSize <= 0 {
+		return 0, 0
 	}
 	availableSpace := partition.Part_size - superblockSize
 	if availableSpace <= 0 {
-		return 0
+		return 0, 0
+	}
+	bitmapEntry := 1.0
+	if geo.features&structures.FeatureIncompatPackedBitmaps != 0 {
+		bitmapEntry = 1.0 / 8
+		availableSpace -= 2 // Redondeo hacia arriba de cada bitmap a bytes completos
 	}
-	denominator := int32(1) + int32(3) + inodeSize + (3 * blockSize)
-	if denominator <= 0 {
-		return 0
+	available := float64(availableSpace)
+
+	if geo.inodeRatio <= 0 {
+		n := math.Floor(available / (4*bitmapEntry + inodeSize + 3*blockSize))
+		if n < 0 {
+			n = 0
+		}
+		return int32(n), 3 * int32(n)
 	}
-	n_float := math.Floor(float64(availableSpace) / float64(denominator))
-	if n_float < 0 {
-		n_float = 0
+	n := math.Floor(available / float64(geo.inodeRatio))
+	blocks := math.Floor((available - n*(bitmapEntry+inodeSize)) / (bitmapEntry + blockSize))
+	if n < 0 || blocks < 0 {
+		return 0, 0
 	}
-	return int32(n_float)
+	return int32(n), int32(blocks)
 }
 
-func createSuperBlock(partition *structures.Partition, n int32, fsType string) *structures.SuperBlock {
-	inodeSize := int32(binary.Size(structures.Inode{}))
-	blockSize := int32(binary.Size(structures.FileBlock{}))
-	superblockSize := int32(binary.Size(structures.SuperBlock{}))
-	if n <= 0 || inodeSize <= 0 || blockSize <= 0 || superblockSize <= 0 {
+func createSuperBlock(partition *structures.Partition, n int32, blocks int32, fsType string, geo fsGeometry) *structures.SuperBlock {
+	inodeSize := structures.InodeDiskSize(geo.features)
+	blockSize := geo.blockSize
+	superblockSize := structures.SuperBlockDiskSize(geo.features)
+	if n <= 0 || blocks <= 0 || inodeSize <= 0 || !structures.ValidBlockSize(blockSize) {
 		return nil
 	}
+	layout := &structures.SuperBlock{S_ext_magic: structures.SuperBlockExtMagic, S_feature_incompat: geo.features}
 	bm_inode_start := partition.Part_start + superblockSize
-	bm_block_start := bm_inode_start + n
-	inode_start := bm_block_start + (3 * n)
+	bm_block_start := bm_inode_start + int32(layout.BitmapBytes(n))
+	inode_start := bm_block_start + int32(layout.BitmapBytes(blocks))
 	block_start := inode_start + (n * inodeSize)
-	end_of_blocks := block_start + (3 * n * blockSize)
-	partitionEnd := partition.Part_start + partition.Part_size
+	end_of_blocks := int64(block_start) + int64(blocks)*int64(blockSize)
+	partitionEnd := int64(partition.Part_start) + int64(partition.Part_size)
 	if end_of_blocks > partitionEnd {
 		return nil
 	}
 	filesystemTypeVal := int32(2)
+	compat := int32(0)
 	if fsType == "3fs" {
 		filesystemTypeVal = 3
+		compat = structures.FeatureCompatJournal
 	}
 	superBlock := &structures.SuperBlock{
 		S_filesystem_type: filesystemTypeVal,
-		S_inodes_count:    n, S_blocks_count: 3 * n,
-		S_free_inodes_count: n, S_free_blocks_count: 3 * n,
-		S_mtime: float32(time.Now().Unix()), S_umtime: 0, S_mnt_count: 0,
+		S_inodes_count:    n, S_blocks_count: blocks,
+		S_free_inodes_count: n, S_free_blocks_count: blocks,
+		S_mtime: time.Now().Unix(), S_umtime: 0, S_mnt_count: 0,
 		S_magic:      0xEF53,
 		S_inode_size: inodeSize, S_block_size: blockSize,
 		S_first_ino: -1, S_first_blo: -1,
 		S_bm_inode_start: bm_inode_start, S_bm_block_start: bm_block_start,
 		S_inode_start: inode_start, S_block_start: block_start,
+		S_ext_magic: structures.SuperBlockExtMagic, S_feature_incompat: geo.features,
+		S_inode_ratio: geo.inodeRatio, S_rev_level: structures.SuperBlockRevision,
+		S_feature_compat: compat,
 	}
 	return superBlock
 }
@@ -278,8 +525,8 @@ func createInitialStructures(sb *structures.SuperBlock, diskPath string, fsType
 	fmt.Println("Creando estructura de directorio raíz (inodo 0)...")
 	inodeRoot := structures.Inode{
 		I_uid: 1, I_gid: 1, I_size: 0,
-		I_atime: float32(time.Now().Unix()), I_ctime: float32(time.Now().Unix()), I_mtime: float32(time.Now().Unix()),
-		I_type: [1]byte{'0'}, I_perm: [3]byte{'7', '7', '5'},
+		I_atime: time.Now().Unix(), I_ctime: time.Now().Unix(), I_mtime: time.Now().Unix(),
+		I_type: [1]byte{'0'}, I_perm: [3]byte{'7', '7', '5'}, I_links: 1, I_xattr: -1,
 	}
 	for i := range inodeRoot.I_block {
 		inodeRoot.I_block[i] = -1
@@ -297,28 +544,24 @@ func createInitialStructures(sb *structures.SuperBlock, diskPath string, fsType
 		return fmt.Errorf("error bitmap inodo raíz 0: %w", err)
 	}
 	sb.S_free_inodes_count--
-	rootFolderBlock := structures.FolderBlock{}
-	rootFolderBlock.Initialize()
-	copy(rootFolderBlock.B_content[0].B_name[:], ".")
-	rootFolderBlock.B_content[0].B_inodo = 0
-	copy(rootFolderBlock.B_content[1].B_name[:], "..")
-	rootFolderBlock.B_content[1].B_inodo = 0
-	rootBlockOffset := int64(sb.S_block_start + rootBlockIndex*sb.S_block_size)
-	if err := rootFolderBlock.Serialize(diskPath, rootBlockOffset); err != nil {
+	rootFolderBlock := sb.NewDirBlock()
+	rootFolderBlock.Add(structures.DirEntry{Inode: 0, Type: '0', Name: "."})
+	rootFolderBlock.Add(structures.DirEntry{Inode: 0, Type: '0', Name: ".."})
+	if err := sb.WriteDirBlock(diskPath, rootBlockIndex, rootFolderBlock); err != nil {
 		return fmt.Errorf("error serializando bloque raíz %d: %w", rootBlockIndex, err)
 	}
 	rootInodeOffset := int64(sb.S_inode_start)
-	if err := inodeRoot.Serialize(diskPath, rootInodeOffset); err != nil {
+	if err := inodeRoot.Serialize(sb, diskPath, rootInodeOffset); err != nil {
 		return fmt.Errorf("error serializando inodo raíz 0: %w", err)
 	}
 
 	fmt.Println("Creando archivo /users.txt (inodo 1)...")
 	usersContent := "1,G,root\n1,U,root,root,123\n"
-	usersSize := int32(len(usersContent))
+	usersSize := int64(len(usersContent))
 	inodeUsers := structures.Inode{
 		I_uid: 1, I_gid: 1, I_size: usersSize,
-		I_atime: float32(time.Now().Unix()), I_ctime: float32(time.Now().Unix()), I_mtime: float32(time.Now().Unix()),
-		I_type: [1]byte{'1'}, I_perm: [3]byte{'6', '6', '4'},
+		I_atime: time.Now().Unix(), I_ctime: time.Now().Unix(), I_mtime: time.Now().Unix(),
+		I_type: [1]byte{'1'}, I_perm: [3]byte{'6', '6', '4'}, I_links: 1, I_xattr: -1,
 	}
 	for i := range inodeUsers.I_block {
 		inodeUsers.I_block[i] = -1
@@ -336,30 +579,21 @@ func createInitialStructures(sb *structures.SuperBlock, diskPath string, fsType
 		return fmt.Errorf("error bitmap inodo users 1: %w", err)
 	}
 	sb.S_free_inodes_count--
-	usersFileBlock := structures.FileBlock{}
+	usersFileBlock := structures.NewFileBlock(sb.S_block_size)
 	copy(usersFileBlock.B_content[:], usersContent)
 	usersBlockOffset := int64(sb.S_block_start + usersBlockIndex*sb.S_block_size)
 	if err := usersFileBlock.Serialize(diskPath, usersBlockOffset); err != nil {
 		return fmt.Errorf("error serializando bloque users %d: %w", usersBlockIndex, err)
 	}
 	usersInodeOffset := int64(sb.S_inode_start + 1*sb.S_inode_size)
-	if err := inodeUsers.Serialize(diskPath, usersInodeOffset); err != nil {
+	if err := inodeUsers.Serialize(sb, diskPath, usersInodeOffset); err != nil {
 		return fmt.Errorf("error serializando inodo users 1: %w", err)
 	}
 
-	foundSlotRoot := false
-	for i := range rootFolderBlock.B_content {
-		if rootFolderBlock.B_content[i].B_inodo == -1 {
-			rootFolderBlock.B_content[i].B_inodo = 1
-			copy(rootFolderBlock.B_content[i].B_name[:], "users.txt")
-			foundSlotRoot = true
-			break
-		}
-	}
-	if !foundSlotRoot {
+	if !rootFolderBlock.Add(structures.DirEntry{Inode: 1, Type: '1', Name: "users.txt"}) {
 		return errors.New("error interno: no se encontró slot libre en bloque raíz para añadir users.txt")
 	}
-	if err := rootFolderBlock.Serialize(diskPath, rootBlockOffset); err != nil {
+	if err := sb.WriteDirBlock(diskPath, rootBlockIndex, rootFolderBlock); err != nil {
 		return fmt.Errorf("error re-serializando bloque raíz %d con entrada users.txt: %w", rootBlockIndex, err)
 	}
 
@@ -377,11 +611,11 @@ func createInitialStructures(sb *structures.SuperBlock, diskPath string, fsType
 		if journalBlocksNeeded > sb.S_free_blocks_count {
 			return fmt.Errorf("espacio insuficiente para %d bloques de journal (libres: %d)", journalBlocksNeeded, sb.S_free_blocks_count)
 		}
-		journalSize := journalBlocksNeeded * sb.S_block_size
+		journalSize := int64(journalBlocksNeeded) * int64(sb.S_block_size)
 		inodeJournal := structures.Inode{
 			I_uid: 0, I_gid: 0, I_size: journalSize,
-			I_atime: float32(time.Now().Unix()), I_ctime: float32(time.Now().Unix()), I_mtime: float32(time.Now().Unix()),
-			I_type: [1]byte{'1'}, I_perm: [3]byte{'6', '0', '0'},
+			I_atime: time.Now().Unix(), I_ctime: time.Now().Unix(), I_mtime: time.Now().Unix(),
+			I_type: [1]byte{'1'}, I_perm: [3]byte{'6', '0', '0'}, I_links: 1, I_xattr: -1,
 		}
 		for i := range inodeJournal.I_block {
 			inodeJournal.I_block[i] = -1
@@ -389,7 +623,7 @@ func createInitialStructures(sb *structures.SuperBlock, diskPath string, fsType
 		var firstJournalBlockIndex int32 = -1
 		for j := int32(0); j < journalBlocksNeeded; j++ {
 			if j >= 12 {
-				journalSize = 12 * sb.S_block_size
+				journalSize = 12 * int64(sb.S_block_size)
 				inodeJournal.I_size = journalSize
 				break
 			}
@@ -407,7 +641,7 @@ func createInitialStructures(sb *structures.SuperBlock, diskPath string, fsType
 			}
 		}
 		journalInodeOffset := int64(sb.S_inode_start + journalInodeIndex*sb.S_inode_size)
-		if err := inodeJournal.Serialize(diskPath, journalInodeOffset); err != nil {
+		if err := inodeJournal.Serialize(sb, diskPath, journalInodeOffset); err != nil {
 			return fmt.Errorf("error serializando inodo journal %d: %w", journalInodeIndex, err)
 		}
 		if err := sb.UpdateBitmapInode(diskPath, journalInodeIndex,'1'); err != nil {
@@ -417,14 +651,17 @@ func createInitialStructures(sb *structures.SuperBlock, diskPath string, fsType
 		if firstJournalBlockIndex != -1 {
 			firstJournalBlockOffset := int64(sb.S_block_start + firstJournalBlockIndex*sb.S_block_size)
 			fmt.Printf("  Inicializando primer bloque de journal (%d) en offset %d...\n", firstJournalBlockIndex, firstJournalBlockOffset)
-			initialJournalEntry := structures.Journal{J_count: 0, J_content: structures.Information{I_operation: [10]byte{'C', 'L', 'E', 'A', 'N', 0}, I_date: float32(time.Now().Unix())}}
-			journalFile, errOpen := os.OpenFile(diskPath, os.O_WRONLY, 0644)
+			initialJournalEntry := structures.Journal{J_count: 0, J_content: structures.Information{I_operation: [10]byte{'C', 'L', 'E', 'A', 'N', 0}, I_date: time.Now().Unix()}}
+			journalFile, errOpen := structures.OpenDisk(diskPath)
 			if errOpen != nil {
 				fmt.Printf("Advertencia: no se pudo abrir disco para escribir entrada inicial de journal: %v\n", errOpen)
 			} else {
 				_, errSeek := journalFile.Seek(firstJournalBlockOffset, 0)
 				if errSeek == nil {
-					errWrite := binary.Write(journalFile, binary.LittleEndian, &initialJournalEntry)
+					entryBytes, errWrite := initialJournalEntry.Encode(sb.S_feature_incompat)
+					if errWrite == nil {
+						_, errWrite = journalFile.Write(entryBytes)
+					}
 					if errWrite != nil {
 						fmt.Printf("Advertencia: no se pudo escribir entrada inicial de journal: %v\n", errWrite)
 					} else {
@@ -436,20 +673,11 @@ func createInitialStructures(sb *structures.SuperBlock, diskPath string, fsType
 				journalFile.Close()
 			}
 		}
-		foundSlotRootJournal := false
-		for i := range rootFolderBlock.B_content {
-			if rootFolderBlock.B_content[i].B_inodo == -1 {
-				rootFolderBlock.B_content[i].B_inodo = journalInodeIndex
-				copy(rootFolderBlock.B_content[i].B_name[:], ".journal")
-				foundSlotRootJournal = true
-				break
-			}
-		}
-		if !foundSlotRootJournal {
+		if !rootFolderBlock.Add(structures.DirEntry{Inode: journalInodeIndex, Type: '1', Name: ".journal"}) {
 			fmt.Println("Advertencia: No se encontró slot libre en bloque raíz para añadir '.journal'.")
 		} else {
 			fmt.Println("Añadiendo entrada '.journal' al bloque raíz.")
-			if err := rootFolderBlock.Serialize(diskPath, rootBlockOffset); err != nil {
+			if err := sb.WriteDirBlock(diskPath, rootBlockIndex, rootFolderBlock); err != nil {
 				return fmt.Errorf("error re-serializando bloque raíz %d con entrada .journal: %w", rootBlockIndex, err)
 			}
 		}
@@ -142,7 +142,7 @@ func commandMkgrp(mkgrp *MKGRP) error {
 	// Preparar Nuevo Contenido
 	newLine := fmt.Sprintf("%d,G,%s\n", newGID, mkgrp.name)
 	newContent := oldContent + newLine
-	newSize := int32(len(newContent))
+	newSize := int64(len(newContent))
 
 	// Liberar Bloques Antiguos de users.txt
 	fmt.Println("Liberando bloques antiguos de /users.txt...")
@@ -166,12 +166,12 @@ func commandMkgrp(mkgrp *MKGRP) error {
 	// Actualizar Inodo de users.txt
 	fmt.Println("Actualizando inodo /users.txt...")
 	usersInode.I_size = newSize
-	usersInode.I_mtime = float32(time.Now().Unix())
+	usersInode.I_mtime = time.Now().Unix()
 	usersInode.I_atime = usersInode.I_mtime
 	usersInode.I_block = newAllocatedBlockIndices // Actualizar con los nuevos bloques
 
 	usersInodeOffset := int64(partitionSuperblock.S_inode_start) + int64(usersInodeIndex)*int64(partitionSuperblock.S_inode_size)
-	err = usersInode.Serialize(partitionPath, usersInodeOffset)
+	err = usersInode.Serialize(partitionSuperblock, partitionPath, usersInodeOffset)
 	if err != nil {
 		return fmt.Errorf("error serializando inodo /users.txt actualizado: %w", err)
 	}
@@ -179,7 +179,7 @@ func commandMkusr(mkusr *MKUSR) error {
 	// Preparar Nuevo Contenido
 	newLine := fmt.Sprintf("%d,U,%s,%s,%s\n", newUID, mkusr.grp, mkusr.user, mkusr.pass) // Usa mkusr.grp (nombre)
 	newContent := oldContent + newLine
-	newSize := int32(len(newContent))
+	newSize := int64(len(newContent))
 	fmt.Printf("Nuevo contenido de users.txt preparado (%d bytes).\n", newSize)
 
 	// Liberar Bloques Antiguos de users.txt
@@ -203,12 +203,12 @@ func commandMkusr(mkusr *MKUSR) error {
 	// Actualizar Inodo de users.txt
 	fmt.Println("Actualizando inodo /users.txt...")
 	usersInode.I_size = newSize
-	usersInode.I_mtime = float32(time.Now().Unix())
+	usersInode.I_mtime = time.Now().Unix()
 	usersInode.I_atime = usersInode.I_mtime
 	usersInode.I_block = newAllocatedBlockIndices
 
 	usersInodeOffset := int64(partitionSuperblock.S_inode_start) + int64(usersInodeIndex)*int64(partitionSuperblock.S_inode_size)
-	err = usersInode.Serialize(partitionPath, usersInodeOffset)
+	err = usersInode.Serialize(partitionSuperblock, partitionPath, usersInodeOffset)
 	if err != nil {
 		return fmt.Errorf("error serializando inodo /users.txt actualizado: %w", err)
 	}
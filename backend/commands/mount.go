@@ -14,8 +14,9 @@ import (
 
 // MOUNT estructura que representa el comando mount con sus parámetros
 type MOUNT struct {
-	path string // Ruta del archivo del disco
-	name string // Nombre de la partición
+	path  string // Ruta del archivo del disco
+	name  string // Nombre de la partición
+	label string // Etiqueta opcional para referirse a la partición en lugar del ID
 }
 
 
@@ -26,7 +27,7 @@ func ParseMount(tokens []string) (string, error) {
 	// Unir tokens en una sola cadena y luego dividir por espacios, respetando las comillas
 	args := strings.Join(tokens, " ")
 	// Expresión regular para encontrar los parámetros del comando mount
-	re := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+|-name="[^"]+"|-name=[^\s]+`)
+	re := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+|-name="[^"]+"|-name=[^\s]+|-label="[^"]+"|-label=[^\s]+`)
 	// Encuentra todas las coincidencias de la expresión regular en la cadena de argumentos
 	matches := re.FindAllString(args, -1)
 
@@ -58,6 +59,11 @@ func ParseMount(tokens []string) (string, error) {
 				return "", errors.New("el nombre no puede estar vacío")
 			}
 			cmd.name = value
+		case "-label":
+			if value == "" {
+				return "", errors.New("la etiqueta no puede estar vacía")
+			}
+			cmd.label = value
 		default:
 			// Si el parámetro no es reconocido, devuelve un error
 			return "", fmt.Errorf("parámetro desconocido: %s", key)
@@ -83,14 +89,17 @@ func ParseMount(tokens []string) (string, error) {
 	}
 	lastElement := stores.ListMounted[len(stores.ListMounted)-1]
 
-
+	labelLine := ""
+	if cmd.label != "" {
+		labelLine = fmt.Sprintf("\n-> Etiqueta: %s", cmd.label)
+	}
 
 	// Devuelve un mensaje de éxito con los detalles del montaje
 	return fmt.Sprintf("MOUNT: Partición montada exitosamente\n"+
 		"-> Path: %s\n"+
 		"-> Nombre: %s\n"+
-		"-> ID: %s",
-		cmd.path, cmd.name, lastElement), nil
+		"-> ID: %s%s",
+		cmd.path, cmd.name, lastElement, labelLine), nil
 }
 
 
@@ -120,6 +129,11 @@ func commandMount(mount *MOUNT) error {
 	fmt.Println("\nPartición encontrada para montar:")
 	partition.PrintPartition() // Usar el partition encontrado
 
+	// Un sistema de archivos con un formato más nuevo no se monta
+	if err := checkPartitionFeatures(mount.path, partition.Part_start); err != nil {
+		return fmt.Errorf("no se puede montar la partición '%s': %w", mount.name, err)
+	}
+
 	for _, valor := range stores.ListPatitions { 
 		if valor == mount.name {
 			fmt.Printf("Advertencia: Ya existe una partición montada con el nombre '%s' (puede ser de otro disco).\n", mount.name)
@@ -127,8 +141,17 @@ func commandMount(mount *MOUNT) error {
 		}
 	}
 
+	if mount.label != "" {
+		if id, exists := stores.MountLabels[mount.label]; exists {
+			return fmt.Errorf("la etiqueta '%s' ya está asignada a la partición %s", mount.label, id)
+		}
+		if _, exists := stores.MountedPartitions[mount.label]; exists {
+			return fmt.Errorf("la etiqueta '%s' coincide con un ID de montaje", mount.label)
+		}
+	}
+
 	// Generar un id único para la partición
-	idPartition, partitionCorrelative, errGenID := generatePartitionID(mount)
+	idPartition, partitionCorrelative, errGenID := generatePartitionID(mount, mbr.Mbr_disk_signature)
 	if errGenID != nil {
 		fmt.Println("Error generando el id de partición:", errGenID)
 		return errGenID
@@ -140,6 +163,9 @@ func commandMount(mount *MOUNT) error {
 	stores.MountedPartitions[idPartition] = mount.path
 	stores.ListPatitions = append(stores.ListPatitions, mount.name) // ¿Realmente necesario guardar solo nombre?
 	stores.ListMounted = append(stores.ListMounted, idPartition)
+	if mount.label != "" {
+		stores.MountLabels[mount.label] = idPartition
+	}
 	fmt.Printf("Partición añadida a stores. Montadas ahora: %v\n", stores.ListMounted)
 
 
@@ -150,32 +176,70 @@ func commandMount(mount *MOUNT) error {
 	partition.PrintPartition()
 
 
-	// Serializar la estructura MBR completa (con la partición modificada)
+	// Serializar la estructura MBR completa (con la partición modificada). Un MBR de solo lectura
+	// (características ro_compat desconocidas) se monta sin guardar el estado en disco.
 	fmt.Println("Serializando MBR con estado de montaje actualizado...")
-	err = mbr.Serialize(mount.path)
-	if err != nil {
+	if errRO := mbr.CheckWritable(); errRO != nil {
+		fmt.Printf("Advertencia: %v; el estado de montaje no se guarda en el disco.\n", errRO)
+	} else if err = mbr.Serialize(mount.path); err != nil {
 		// Si falla la serialización, el estado de montaje no se guarda en disco
 		// Podríamos intentar revertir los cambios en 'stores'? Complicado.
 		fmt.Println("Error serializando el MBR:", err)
 		return fmt.Errorf("error serializando MBR con estado de montaje: %w", err)
 	}
 
+	// Mantener abierto el disco mientras tenga particiones montadas
+	if err := structures.AttachDisk(mount.path); err != nil {
+		fmt.Println("Advertencia:", err)
+	}
+	loadFreeIndex(mount.path, partition.Part_start)
+
 	fmt.Println("Montaje completado y MBR guardado.")
 	return nil
 }
 
 
 
-func generatePartitionID(mount *MOUNT) (string, int, error) {
-	// Asignar una letra a la partición y obtener el índice
-	letter, partitionCorrelative, err := utils.GetLetterAndPartitionCorrelative(mount.path)
+func generatePartitionID(mount *MOUNT, signature int32) (string, int, error) {
+	// El formato depende de stores.MountIDScheme (letra, número o estable)
+	idPartition, partitionCorrelative, err := utils.GenerateMountID(mount.path, signature, mount.name)
 	if err != nil {
-		fmt.Println("Error obteniendo la letra:", err)
+		fmt.Println("Error generando el ID:", err)
 		return "", 0, err
 	}
 
-	// Crear id de partición
-	idPartition := fmt.Sprintf("%s%d%s", stores.Carnet, partitionCorrelative, letter)
-
 	return idPartition, partitionCorrelative, nil
 }
+
+// Verifica que este código sepa interpretar el sistema de archivos de la partición (si lo tiene).
+// Las características ro_compat desconocidas solo generan una advertencia: se puede leer.
+func checkPartitionFeatures(diskPath string, partStart int32) error {
+	sb := &structures.SuperBlock{}
+	if err := sb.Deserialize(diskPath, int64(partStart)); err != nil {
+		if errors.Is(err, structures.ErrUnsupportedFeatures) {
+			return err
+		}
+		return nil
+	}
+	if sb.S_magic != 0xEF53 {
+		return nil
+	}
+	if err := sb.CheckWritable(); err != nil {
+		fmt.Printf("Advertencia: %v\n", err)
+	}
+	return nil
+}
+
+// Construye el índice de espacio libre si la partición ya tiene sistema de archivos.
+func loadFreeIndex(diskPath string, partStart int32) {
+	sb := &structures.SuperBlock{}
+	if err := sb.Deserialize(diskPath, int64(partStart)); err != nil || sb.S_magic != 0xEF53 {
+		return
+	}
+	index, err := sb.LoadFreeIndex(diskPath)
+	if err != nil {
+		fmt.Println("Advertencia:", err)
+		return
+	}
+	fmt.Printf("Índice de libres: %d inodos y %d bloques libres\n", index.Inodes.Free(), index.Blocks.Free())
+}
@@ -141,7 +141,8 @@ func commandMove(cmd *Move) error {
 
 	// Validar Origen (-path)
 	fmt.Printf("Validando origen: %s\n", cmd.path)
-	sourceInodeIndex, sourceInode, errFindSource := structures.FindInodeByPath(partitionSuperblock, partitionPath, cmd.path)
+	// Si es un enlace se mueve el enlace, no su destino
+	sourceInodeIndex, sourceInode, errFindSource := structures.FindInodeByPathNoFollow(partitionSuperblock, partitionPath, cmd.path)
 	if errFindSource != nil {
 		return fmt.Errorf("error: no se encontró el origen '%s': %w", cmd.path, errFindSource)
 	}
@@ -197,6 +198,9 @@ func commandMove(cmd *Move) error {
 		if !checkPermissions(currentUser, userGIDStr, 'w', sourceParentInode, partitionSuperblock, partitionPath) {
 			return fmt.Errorf("permiso denegado: escritura sobre directorio padre origen '%s'", sourceParentPath)
 		}
+		if !checkSticky(currentUser, sourceParentInode, sourceInode, partitionSuperblock, partitionPath) {
+			return fmt.Errorf("permiso denegado: '%s' tiene sticky y solo el dueño de '%s', el de la carpeta o root pueden moverlo", sourceParentPath, cmd.path)
+		}
 	}
 	fmt.Println("Permisos concedidos.")
 
@@ -217,44 +221,17 @@ func commandMove(cmd *Move) error {
 		return fmt.Errorf("error añadiendo entrada a destino '%s': %w", cmd.destino, errAdd)
 	}
 	fmt.Println("Entrada añadida a directorio destino.")
+	// Releer el destino: addEntryToParent pudo asignarle bloques nuevos (directos o indirectos)
+	destDirInodeOffset := int64(partitionSuperblock.S_inode_start + destDirInodeIndex*partitionSuperblock.S_inode_size)
+	if err := destDirInode.Deserialize(partitionSuperblock, partitionPath, destDirInodeOffset); err != nil {
+		return fmt.Errorf("error releyendo inodo destino %d: %w", destDirInodeIndex, err)
+	}
 
 	// Eliminar Entrada del Directorio Padre Origen
 	fmt.Printf("Eliminando entrada '%s' de directorio padre origen %d...\n", sourceBaseName, sourceParentInodeIndex)
-	entryRemoved := false
-	for i := 0; i < 12; i++ { // Solo directos
-		blockPtr := sourceParentInode.I_block[i]
-		if blockPtr == -1 || blockPtr < 0 || blockPtr >= partitionSuperblock.S_blocks_count {
-			continue
-		}
-
-		folderBlock := structures.FolderBlock{}
-		blockOffset := int64(partitionSuperblock.S_block_start + blockPtr*partitionSuperblock.S_block_size)
-		if err := folderBlock.Deserialize(partitionPath, blockOffset); err != nil {
-			fmt.Printf("Advertencia: Error leyendo bloque %d padre origen: %v\n", blockPtr, err)
-			continue
-		}
-
-		blockNeedsWrite := false
-		for j := range folderBlock.B_content {
-			// Buscar la entrada por el índice de inodo
-			if folderBlock.B_content[j].B_inodo == sourceInodeIndex {
-				currentName := strings.TrimRight(string(folderBlock.B_content[j].B_name[:]), "\x00")
-				if currentName == sourceBaseName {
-					fmt.Printf("  Entrada encontrada en bloque %d, índice %d. Eliminando...\n", blockPtr, j)
-					folderBlock.B_content[j].B_inodo = -1
-					folderBlock.B_content[j].B_name = [12]byte{}
-					entryRemoved = true
-					blockNeedsWrite = true
-					break
-				}
-			}
-		}
-		if blockNeedsWrite {
-			if err := folderBlock.Serialize(partitionPath, blockOffset); err != nil {
-				return fmt.Errorf("¡ERROR INCONSISTENCIA! Guardando bloque padre origen %d modificado: %w", blockPtr, err)
-			}
-			break
-		}
+	entryRemoved, err := partitionSuperblock.RemoveDirEntry(partitionPath, sourceParentInode, sourceBaseName, sourceInodeIndex)
+	if err != nil {
+		return fmt.Errorf("¡ERROR INCONSISTENCIA! Quitando '%s' del padre origen %d: %w", sourceBaseName, sourceParentInodeIndex, err)
 	}
 	if !entryRemoved {
 		return fmt.Errorf("¡ERROR INCONSISTENCIA! No se encontró entrada original '%s' en padre origen %d", sourceBaseName, sourceParentInodeIndex)
@@ -267,22 +244,20 @@ func commandMove(cmd *Move) error {
 		// Asumir que está en el primer bloque directo
 		firstBlockPtr := sourceInode.I_block[0]
 		if firstBlockPtr != -1 && firstBlockPtr >= 0 && firstBlockPtr < partitionSuperblock.S_blocks_count {
-			folderBlock := structures.FolderBlock{}
-			blockOffset := int64(partitionSuperblock.S_block_start + firstBlockPtr*partitionSuperblock.S_block_size)
 			// Leer el bloque del directorio movido
-			if err := folderBlock.Deserialize(partitionPath, blockOffset); err == nil {
-				// Verificar que la entrada '..' esté donde se espera (índice 1)
-				if strings.TrimRight(string(folderBlock.B_content[1].B_name[:]), "\x00") == ".." {
-					folderBlock.B_content[1].B_inodo = destDirInodeIndex // Apuntar al nuevo padre
+			if folderBlock, err := partitionSuperblock.ReadDirBlock(partitionPath, firstBlockPtr); err == nil {
+				// Buscar la entrada '..' en el primer bloque
+				if j := folderBlock.Find(".."); j != -1 {
+					folderBlock.Entries[j].Inode = destDirInodeIndex // Apuntar al nuevo padre
 
 					// Reescribir el bloque modificado
-					if err := folderBlock.Serialize(partitionPath, blockOffset); err != nil {
+					if err := partitionSuperblock.WriteDirBlock(partitionPath, firstBlockPtr, folderBlock); err != nil {
 						fmt.Printf("Advertencia: Error guardando bloque %d con '..' actualizado: %v\n", firstBlockPtr, err)
 					} else {
 						fmt.Println("  Entrada '..' actualizada.")
 					}
 				} else {
-					fmt.Printf("Advertencia: No se encontró '..' en posición esperada bloque %d.\n", firstBlockPtr)
+					fmt.Printf("Advertencia: No se encontró '..' en el bloque %d.\n", firstBlockPtr)
 				}
 			} else {
 				fmt.Printf("Advertencia: Error leyendo bloque %d para actualizar '..': %v\n", firstBlockPtr, err)
@@ -294,21 +269,21 @@ func commandMove(cmd *Move) error {
 
 	// Actualizar tiempos
 	fmt.Println("Actualizando timestamps...")
-	now := float32(time.Now().Unix())
+	now := time.Now().Unix()
 
 	// Padre Origen
 	sourceParentInode.I_mtime = now
 	sourceParentInode.I_atime = now
 	sourceParentInodeOffset := int64(partitionSuperblock.S_inode_start + sourceParentInodeIndex*partitionSuperblock.S_inode_size)
-	if err := sourceParentInode.Serialize(partitionPath, sourceParentInodeOffset); err != nil {
+	if err := sourceParentInode.Serialize(partitionSuperblock, partitionPath, sourceParentInodeOffset); err != nil {
 		fmt.Printf("Advertencia: Error guardando inodo padre origen %d: %v\n", sourceParentInodeIndex, err)
 	}
 
 	// Padre Destino
 	destDirInode.I_mtime = now
 	destDirInode.I_atime = now
-	destDirInodeOffset := int64(partitionSuperblock.S_inode_start + destDirInodeIndex*partitionSuperblock.S_inode_size)
-	if err := destDirInode.Serialize(partitionPath, destDirInodeOffset); err != nil {
+	destDirInodeOffset = int64(partitionSuperblock.S_inode_start + destDirInodeIndex*partitionSuperblock.S_inode_size)
+	if err := destDirInode.Serialize(partitionSuperblock, partitionPath, destDirInodeOffset); err != nil {
 		fmt.Printf("Advertencia: Error guardando inodo padre destino %d: %v\n", destDirInodeIndex, err)
 	}
 
@@ -316,7 +291,7 @@ func commandMove(cmd *Move) error {
 	sourceInode.I_ctime = now // Hora de cambio del inodo
 	sourceInode.I_atime = now // Accedimos para leerlo/moverlo
 	sourceInodeOffset := int64(partitionSuperblock.S_inode_start + sourceInodeIndex*partitionSuperblock.S_inode_size)
-	if err := sourceInode.Serialize(partitionPath, sourceInodeOffset); err != nil {
+	if err := sourceInode.Serialize(partitionSuperblock, partitionPath, sourceInodeOffset); err != nil {
 		return fmt.Errorf("error crítico al guardar inodo objetivo %d: %w", sourceInodeIndex, err)
 	}
 
@@ -150,7 +150,7 @@ func commandPartitions(cmd *PARTITIONS) (string, error) {
 }
 
 func addLogicalPartitionsInfo(diskPath string, extendedStart int32, resultStrings *[]string) error {
-	file, err := os.Open(diskPath)
+	file, err := structures.OpenDisk(diskPath)
 	if err != nil {
 		return fmt.Errorf("error abriendo disco lógicas: %w", err)
 	}
@@ -0,0 +1,138 @@
+package commands
+
+import (
+	structures "backend/structures"
+	"fmt"
+)
+
+// checkPermissions decide si currentUser tiene el permiso requiredPermission ('r', 'w' o 'x')
+// sobre targetInode. Es la única función que evalúan los comandos, así que además de I_perm
+// considera la ACL de acceso del inodo (setfacl):
+//
+//   - root siempre tiene permiso.
+//   - El dueño solo mira sus permisos de I_perm.
+//   - Una entrada user:nombre: del usuario decide, limitada por la máscara.
+//   - Si el grupo dueño (limitado por la máscara) o alguna entrada group:nombre: del grupo del
+//     usuario concede el permiso, se concede; si no, se miran los permisos de otros.
+func checkPermissions(currentUser string, _ string, requiredPermission byte, targetInode *structures.Inode, sb *structures.SuperBlock, diskPath string) bool {
+	fmt.Printf("      checkPermissions: User='%s' Req='%c' on Inode (UID=%d, GID=%d, Perm=%s)\n",
+		currentUser, requiredPermission, targetInode.I_uid, targetInode.I_gid, string(targetInode.I_perm[:]))
+
+	// Caso Root siempre tiene permiso
+	if currentUser == "root" {
+		fmt.Println("        Permiso concedido (root).")
+		return true
+	}
+
+	// Obtener UID y GID del usuario actual desde /users.txt
+	currentUserUID, currentUserGID, errInfo := getUserInfo(currentUser, sb, diskPath)
+	if errInfo != nil {
+		// Si no se pueden obtener los IDs (usuario no existe?), denegar permiso
+		fmt.Printf("        Error obteniendo info para usuario '%s': %v. Permiso denegado.\n", currentUser, errInfo)
+		return false
+	}
+	fmt.Printf("        Info usuario actual encontrada: UID=%d, GID=%d\n", currentUserUID, currentUserGID)
+
+	reqBit := map[byte]byte{'r': 4, 'w': 2, 'x': 1}[requiredPermission]
+	if reqBit == 0 {
+		fmt.Printf("        Permiso requerido inválido '%c'. Permiso denegado.\n", requiredPermission)
+		return false
+	}
+
+	// Chequear Dueño (usando UID real)
+	if targetInode.I_uid == currentUserUID {
+		fmt.Println("        Usuario es dueño.")
+		if permBits(targetInode.I_perm[0])&reqBit != 0 {
+			fmt.Println("        Permiso de dueño concedido.")
+			return true
+		}
+		// Si es dueño pero no tiene el permiso, se deniega
+		fmt.Println("        Permiso de dueño DENEGADO.")
+		return false
+	}
+
+	// ACL de acceso: si no se puede leer se evalúan solo los permisos de I_perm
+	acl := structures.ACL{}
+	if targetInode.I_xattr != -1 {
+		var errACL error
+		if acl, errACL = sb.ReadACL(diskPath, targetInode, structures.XattrACLAccess); errACL != nil {
+			fmt.Printf("        Advertencia: no se pudo leer la ACL (%v), se ignora.\n", errACL)
+			acl = structures.ACL{}
+		}
+	}
+	mask := acl.Mask()
+
+	// Chequear entrada user:nombre:
+	if i := acl.Find(structures.ACLUser, currentUserUID); i != -1 {
+		fmt.Printf("        Usuario tiene entrada ACL (%s, máscara %s).\n", structures.PermString(acl[i].Perm), structures.PermString(mask))
+		if acl[i].Perm&mask&reqBit != 0 {
+			fmt.Println("        Permiso de ACL de usuario concedido.")
+			return true
+		}
+		fmt.Println("        Permiso de ACL de usuario DENEGADO.")
+		return false
+	}
+
+	// Chequear Grupo y entradas group:nombre:
+	if targetInode.I_gid == currentUserGID {
+		fmt.Println("        Usuario pertenece al grupo.")
+		if permBits(targetInode.I_perm[1])&mask&reqBit != 0 {
+			fmt.Println("        Permiso de grupo concedido.")
+			return true
+		}
+		// Si pertenece al grupo pero no tiene permiso, se miran 'otros'
+		fmt.Println("        Permiso de grupo DENEGADO.")
+	}
+	if i := acl.Find(structures.ACLGroup, currentUserGID); i != -1 {
+		fmt.Printf("        Grupo del usuario tiene entrada ACL (%s, máscara %s).\n", structures.PermString(acl[i].Perm), structures.PermString(mask))
+		if acl[i].Perm&mask&reqBit != 0 {
+			fmt.Println("        Permiso de ACL de grupo concedido.")
+			return true
+		}
+		fmt.Println("        Permiso de ACL de grupo DENEGADO.")
+	}
+
+	// Chequear Otros
+	fmt.Println("        Verificando permisos de 'otros'.")
+	if permBits(targetInode.I_perm[2])&reqBit != 0 {
+		fmt.Println("        Permiso de 'otros' concedido.")
+		return true
+	}
+
+	// Si no se concedió en ninguna etapa
+	fmt.Println("        Permiso denegado final.")
+	return false
+}
+
+// Bits rwx de un carácter de I_perm; acepta también las letras r, w y x sueltas.
+func permBits(perm byte) byte {
+	switch perm {
+	case 'r':
+		return 4
+	case 'w':
+		return 2
+	case 'x':
+		return 1
+	}
+	return structures.PermBits(perm)
+}
+
+// checkSticky decide si currentUser puede quitar entryInode de la carpeta dirInode (remove, rename,
+// move). Si la carpeta tiene el bit sticky solo pueden el dueño de la entrada, el de la carpeta o
+// root; sin sticky basta con el permiso de escritura que se verifica aparte.
+func checkSticky(currentUser string, dirInode *structures.Inode, entryInode *structures.Inode, sb *structures.SuperBlock, diskPath string) bool {
+	if dirInode.I_special&structures.PermSticky == 0 || currentUser == "root" {
+		return true
+	}
+	currentUserUID, _, errInfo := getUserInfo(currentUser, sb, diskPath)
+	if errInfo != nil {
+		fmt.Printf("        Error obteniendo info para usuario '%s': %v. Permiso denegado.\n", currentUser, errInfo)
+		return false
+	}
+	if currentUserUID == entryInode.I_uid || currentUserUID == dirInode.I_uid {
+		fmt.Println("        Carpeta con sticky: usuario es dueño de la entrada o de la carpeta.")
+		return true
+	}
+	fmt.Printf("        Carpeta con sticky: la entrada es de UID %d y la carpeta de UID %d. Permiso denegado.\n", entryInode.I_uid, dirInode.I_uid)
+	return false
+}
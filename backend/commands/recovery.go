@@ -3,7 +3,6 @@ package commands
 import (
 	"errors"
 	"fmt"
-	"os"
 	"regexp"
 	"strings"
 	"time"
@@ -96,7 +95,7 @@ func commandRecovery(cmd *RECOVERY) error {
 	journalInodeIndex := int32(2)
 	journalInode := &structures.Inode{}
 	journalInodeOffset := int64(sb.S_inode_start + journalInodeIndex*sb.S_inode_size)
-	if err := journalInode.Deserialize(diskPath, journalInodeOffset); err != nil {
+	if err := journalInode.Deserialize(sb, diskPath, journalInodeOffset); err != nil {
 		fmt.Printf("Advertencia: No se pudo leer inodo journal %d: %v\n", journalInodeIndex, err)
 		return fmt.Errorf("error crítico: no se pudo leer el inodo del journal (%d): %w", journalInodeIndex, err)
 	} else if journalInode.I_type[0] != '1' {
@@ -162,16 +161,11 @@ func commandRecovery(cmd *RECOVERY) error {
 
 	// Reconciliar Bitmaps (Marcar como '1' los requeridos)
 	fmt.Println("Reconciliando bitmaps con información inicial...")
-	file, errOpen := os.OpenFile(diskPath, os.O_RDWR, 0644)
-	if errOpen != nil {
-		return fmt.Errorf("error abriendo disco para actualizar bitmaps: %w", errOpen)
-	}
-	defer file.Close()
 
 	// Bitmap de Inodos
 	inodeBitmapChanged := false
-	inodeBitmap := make([]byte, sb.S_inodes_count) // Leer el bitmap
-	if _, err := file.ReadAt(inodeBitmap, int64(sb.S_bm_inode_start)); err != nil {
+	inodeBitmap, err := sb.ReadInodeBitmap(diskPath) // Leer el bitmap
+	if err != nil {
 		return fmt.Errorf("error leyendo bitmap inodos: %w", err)
 	}
 	for inodeIdx := range requiredInodes {
@@ -185,7 +179,7 @@ func commandRecovery(cmd *RECOVERY) error {
 	}
 	if inodeBitmapChanged { // Reescribir solo si hubo cambios
 		fmt.Println("  Escribiendo bitmap de inodos actualizado...")
-		if _, err := file.WriteAt(inodeBitmap, int64(sb.S_bm_inode_start)); err != nil {
+		if err := sb.WriteInodeBitmap(diskPath, inodeBitmap); err != nil {
 			return fmt.Errorf("error escribiendo bitmap inodos: %w", err)
 		}
 	} else {
@@ -194,8 +188,8 @@ func commandRecovery(cmd *RECOVERY) error {
 
 	// Bitmap de Bloques
 	blockBitmapChanged := false
-	blockBitmap := make([]byte, sb.S_blocks_count) // Leer bitmap bloques
-	if _, err := file.ReadAt(blockBitmap, int64(sb.S_bm_block_start)); err != nil {
+	blockBitmap, err := sb.ReadBlockBitmap(diskPath) // Leer bitmap bloques
+	if err != nil {
 		return fmt.Errorf("error leyendo bitmap bloques: %w", err)
 	}
 	for blockIdx := range requiredBlocks {
@@ -209,13 +203,23 @@ func commandRecovery(cmd *RECOVERY) error {
 	}
 	if blockBitmapChanged { // Reescribir solo si hubo cambios
 		fmt.Println("  Escribiendo bitmap de bloques actualizado...")
-		if _, err := file.WriteAt(blockBitmap, int64(sb.S_bm_block_start)); err != nil {
+		if err := sb.WriteBlockBitmap(diskPath, blockBitmap); err != nil {
 			return fmt.Errorf("error escribiendo bitmap bloques: %w", err)
 		}
 	} else {
 		fmt.Println("  Bitmap de bloques ya consistente con estado inicial.")
 	}
 
+	// Contadores de enlaces: las entradas de las carpetas mandan
+	if sb.LinkCounts() {
+		fmt.Println("Reconciliando contadores de enlaces (I_links)...")
+		if fixed, err := sb.ReconcileLinks(diskPath); err != nil {
+			fmt.Printf("  Advertencia: no se pudieron reconciliar los enlaces: %v\n", err)
+		} else {
+			fmt.Printf("  %d inodos corregidos.\n", fixed)
+		}
+	}
+
 	// Recalcular Contadores Libres en SB
 	fmt.Println("Recalculando contadores libres...")
 	freeInodes := int32(0)
@@ -248,7 +252,7 @@ func commandRecovery(cmd *RECOVERY) error {
 	sb.S_first_blo = lastJournalBlock + 1 // El siguiente al último bloque del journal (asumiendo contiguo)
 
 	// Actualizar Tiempo y Serializar Superbloque
-	sb.S_mtime = float32(time.Now().Unix()) // Hora de la recuperación
+	sb.S_mtime = time.Now().Unix() // Hora de la recuperación
 	fmt.Println("Serializando SuperBloque recuperado...")
 	err = sb.Serialize(diskPath, int64(partition.Part_start))
 	if err != nil {
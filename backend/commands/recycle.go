@@ -0,0 +1,255 @@
+package commands
+
+import (
+	stores "backend/stores"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Datos de un disco en la papelera, guardados en <nombre>.json junto a la imagen
+type recycleEntry struct {
+	Name         string    `json:"name"`          // Nombre del archivo dentro de la papelera
+	OriginalPath string    `json:"original_path"` // Path desde el que se eliminó
+	DeletedAt    time.Time `json:"deleted_at"`
+}
+
+func (e recycleEntry) expiresAt() time.Time {
+	return e.DeletedAt.Add(stores.RecycleRetention)
+}
+
+// Mueve diskPath a la papelera y devuelve el nombre de la entrada creada.
+func moveToRecycle(diskPath string) (string, error) {
+	if err := os.MkdirAll(stores.RecycleDir, 0755); err != nil {
+		return "", fmt.Errorf("error creando la papelera '%s': %w", stores.RecycleDir, err)
+	}
+	PurgeRecycle()
+
+	now := time.Now()
+	absPath, _ := filepath.Abs(diskPath)
+	entry := recycleEntry{
+		Name:         fmt.Sprintf("%s_%s", now.Format("20060102-150405"), filepath.Base(diskPath)),
+		OriginalPath: absPath,
+		DeletedAt:    now,
+	}
+	// Evitar pisar otra entrada eliminada en el mismo segundo
+	for i := 1; ; i++ {
+		if _, err := os.Stat(filepath.Join(stores.RecycleDir, entry.Name)); os.IsNotExist(err) {
+			break
+		}
+		entry.Name = fmt.Sprintf("%s-%d_%s", now.Format("20060102-150405"), i, filepath.Base(diskPath))
+	}
+	dest := filepath.Join(stores.RecycleDir, entry.Name)
+	if err := moveFile(diskPath, dest); err != nil {
+		return "", fmt.Errorf("error moviendo '%s' a la papelera: %w", diskPath, err)
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err == nil {
+		err = os.WriteFile(dest+".json", data, 0644)
+	}
+	if err != nil {
+		// Sin metadatos la entrada no se puede restaurar: devolver el disco a su lugar
+		moveFile(dest, diskPath)
+		return "", fmt.Errorf("error guardando metadatos de la papelera: %w", err)
+	}
+	return entry.Name, nil
+}
+
+// Lee las entradas de la papelera ordenadas de la más reciente a la más antigua.
+func listRecycle() ([]recycleEntry, error) {
+	if stores.RecycleDir == "" {
+		return nil, errors.New("la papelera no está habilitada (MIA_RECYCLE_DIR)")
+	}
+	files, err := filepath.Glob(filepath.Join(stores.RecycleDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	entries := []recycleEntry{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var entry recycleEntry
+		if err := json.Unmarshal(data, &entry); err != nil || entry.Name == "" {
+			fmt.Printf("Advertencia: metadatos inválidos en '%s'\n", file)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].DeletedAt.After(entries[j].DeletedAt) })
+	return entries, nil
+}
+
+// PurgeRecycle borra definitivamente los discos cuya retención ya venció y devuelve sus nombres.
+func PurgeRecycle() []string {
+	purged := []string{}
+	entries, err := listRecycle()
+	if err != nil {
+		return purged
+	}
+	now := time.Now()
+	for _, entry := range entries {
+		if now.Before(entry.expiresAt()) {
+			continue
+		}
+		image := filepath.Join(stores.RecycleDir, entry.Name)
+		if err := os.Remove(image); err != nil && !os.IsNotExist(err) {
+			fmt.Printf("Advertencia: no se pudo purgar '%s': %v\n", image, err)
+			continue
+		}
+		os.Remove(image + ".json")
+		purged = append(purged, entry.Name)
+	}
+	return purged
+}
+
+// Renombra src a dst; si están en distintos sistemas de archivos copia y borra el original.
+func moveFile(src string, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyFileRange(src, 0, dst, 0, -1); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
+
+func ParseRecyclebin(tokens []string) (string, error) {
+	if len(tokens) != 0 {
+		return "", errors.New("el comando recyclebin no acepta parámetros")
+	}
+
+	purged := PurgeRecycle()
+	entries, err := listRecycle()
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "RECYCLEBIN: %s (retención %s)\n", stores.RecycleDir, stores.RecycleRetention)
+	for _, name := range purged {
+		fmt.Fprintf(&sb, "-> Purgado: %s\n", name)
+	}
+	if len(entries) == 0 {
+		sb.WriteString("-> La papelera está vacía")
+		return sb.String(), nil
+	}
+	for _, entry := range entries {
+		fmt.Fprintf(&sb, "-> %s | origen: %s | eliminado: %s | expira: %s\n",
+			entry.Name, entry.OriginalPath,
+			entry.DeletedAt.Format("2006-01-02 15:04:05"), entry.expiresAt().Format("2006-01-02 15:04:05"))
+	}
+	return strings.TrimSuffix(sb.String(), "\n"), nil
+}
+
+type RESTOREDISK struct {
+	name string // Nombre de la entrada en la papelera
+	path string // Destino (por defecto el path original)
+}
+
+func ParseRestoredisk(tokens []string) (string, error) {
+	cmd := &RESTOREDISK{}
+	processedKeys := make(map[string]bool)
+
+	nameRegex := regexp.MustCompile(`^(?i)-name=(?:"([^"]+)"|([^\s"]+))$`)
+	pathRegex := regexp.MustCompile(`^(?i)-path=(?:"([^"]+)"|([^\s"]+))$`)
+
+	if len(tokens) == 0 {
+		return "", errors.New("faltan parámetros: se requiere -name=<entrada de la papelera>")
+	}
+
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		var match []string
+		var key string
+		if match = nameRegex.FindStringSubmatch(token); match != nil {
+			key = "-name"
+		} else if match = pathRegex.FindStringSubmatch(token); match != nil {
+			key = "-path"
+		} else {
+			return "", fmt.Errorf("parámetro inválido o no reconocido: '%s'. Se esperaba -name= o -path=", token)
+		}
+		value := match[1]
+		if value == "" {
+			value = match[2]
+		}
+
+		if processedKeys[key] {
+			return "", fmt.Errorf("parámetro duplicado: %s", key)
+		}
+		processedKeys[key] = true
+
+		switch key {
+		case "-name":
+			cmd.name = value
+		case "-path":
+			cmd.path = filepath.Clean(value)
+		}
+	}
+
+	if !processedKeys["-name"] {
+		return "", errors.New("falta el parámetro requerido: -name")
+	}
+
+	dest, err := commandRestoredisk(cmd)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("RESTOREDISK: Disco '%s' restaurado en %s", cmd.name, dest), nil
+}
+
+func commandRestoredisk(cmd *RESTOREDISK) (string, error) {
+	entries, err := listRecycle()
+	if err != nil {
+		return "", err
+	}
+	var entry *recycleEntry
+	for i := range entries {
+		if entries[i].Name == cmd.name {
+			entry = &entries[i]
+			break
+		}
+	}
+	if entry == nil {
+		return "", fmt.Errorf("no existe la entrada '%s' en la papelera", cmd.name)
+	}
+	if !time.Now().Before(entry.expiresAt()) {
+		PurgeRecycle()
+		return "", fmt.Errorf("la entrada '%s' expiró y fue purgada", cmd.name)
+	}
+
+	dest := entry.OriginalPath
+	if cmd.path != "" {
+		dest = cmd.path
+	}
+	if _, err := os.Stat(dest); err == nil {
+		return "", fmt.Errorf("ya existe un archivo en '%s'; use -path para restaurar en otro lugar", dest)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", fmt.Errorf("error creando directorio padre de '%s': %w", dest, err)
+	}
+
+	image := filepath.Join(stores.RecycleDir, entry.Name)
+	fmt.Printf("Restaurando '%s' en '%s'\n", image, dest)
+	if err := moveFile(image, dest); err != nil {
+		return "", fmt.Errorf("error restaurando disco: %w", err)
+	}
+	os.Remove(image + ".json")
+
+	stores.DiskRegistry[dest] = filepath.Base(dest)
+	fmt.Printf("Disco '%s' añadido al registro.\n", dest)
+	return dest, nil
+}
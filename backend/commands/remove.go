@@ -99,7 +99,8 @@ func commandRemove(cmd *REMOVE) error {
 	}
 
 	// Encontrar Inodo Objetivo
-	targetInodeIndex, _, errFind := structures.FindInodeByPath(partitionSuperblock, partitionPath, cmd.path)
+	// Si es un enlace se elimina el enlace, no su destino
+	targetInodeIndex, targetInode, errFind := structures.FindInodeByPathNoFollow(partitionSuperblock, partitionPath, cmd.path)
 	if errFind != nil {
 		return fmt.Errorf("error: no se encontró '%s': %w", cmd.path, errFind)
 	}
@@ -118,13 +119,11 @@ func commandRemove(cmd *REMOVE) error {
 	}
 
 	// Verificar Permiso de Escritura en el PADRE
-	if currentUser != "root" {
-		ownerPerm := parentInode.I_perm[1] // Asumiendo formato tipo '6' o '7' o 'w'
-		canWriteParent := ownerPerm == 'w' || ownerPerm == 'W' || ownerPerm == '6' || ownerPerm == '7'
-		isOwner := true
-		if !isOwner || !canWriteParent {
-			return fmt.Errorf("permiso denegado: no tienes permiso de escritura en '%s'", parentPath)
-		}
+	if !checkPermissions(currentUser, userGIDStr, 'w', parentInode, partitionSuperblock, partitionPath) {
+		return fmt.Errorf("permiso denegado: no tienes permiso de escritura en '%s'", parentPath)
+	}
+	if !checkSticky(currentUser, parentInode, targetInode, partitionSuperblock, partitionPath) {
+		return fmt.Errorf("permiso denegado: '%s' tiene sticky y solo el dueño de '%s', el de la carpeta o root pueden eliminarlo", parentPath, cmd.path)
 	}
 
 	// Llamar a la Función Recursiva de Borrado
@@ -140,57 +139,26 @@ func commandRemove(cmd *REMOVE) error {
 	entryRemoved := false
 	parentModified := false
 
-	for i := 0; i < 12; i++ { // Solo directos
-		blockPtr := parentInode.I_block[i]
-		if blockPtr == -1 {
-			continue
-		}
-		if blockPtr < 0 || blockPtr >= partitionSuperblock.S_blocks_count {
-			continue
-		}
-
-		folderBlock := structures.FolderBlock{}
-		blockOffset := int64(partitionSuperblock.S_block_start + blockPtr*partitionSuperblock.S_block_size)
-		if err := folderBlock.Deserialize(partitionPath, blockOffset); err != nil {
-			fmt.Printf("Advertencia: Error leyendo bloque %d padre: %v\n", blockPtr, err)
-			continue
-		}
-
-		blockWasModified := false
-		for j := range folderBlock.B_content {
-			if folderBlock.B_content[j].B_inodo == targetInodeIndex {
-				name := strings.TrimRight(string(folderBlock.B_content[j].B_name[:]), "\x00")
-				if name == entryName {
-					fmt.Printf("  Encontrada entrada en bloque %d, índice %d. Eliminando...\n", blockPtr, j)
-					folderBlock.B_content[j].B_inodo = -1
-					folderBlock.B_content[j].B_name = [12]byte{}
-					entryRemoved = true
-					blockWasModified = true
-					break
-				}
-			}
-		}
-
-		if blockWasModified {
-			if err := folderBlock.Serialize(partitionPath, blockOffset); err != nil {
-				return fmt.Errorf("error crítico: guardando bloque padre %d modificado: %w", blockPtr, err)
-			}
-			parentModified = true
-			break
-		}
+	removed, err := partitionSuperblock.RemoveDirEntry(partitionPath, parentInode, entryName, targetInodeIndex)
+	if err != nil {
+		return fmt.Errorf("error crítico: quitando '%s' del directorio padre %d: %w", entryName, parentInodeIndex, err)
+	}
+	if removed {
+		entryRemoved = true
+		parentModified = true
 	}
 
 	if !entryRemoved {
-		fmt.Printf("Advertencia: No se encontró entrada '%s' en bloques directos padre %d.\n", entryName, parentInodeIndex)
+		fmt.Printf("Advertencia: No se encontró entrada '%s' en el directorio padre %d.\n", entryName, parentInodeIndex)
 	}
 
 	// Actualizar Tiempos del Padre
 	if parentModified {
 		fmt.Println("Actualizando mtime/atime del inodo padre...")
-		parentInode.I_mtime = float32(time.Now().Unix())
+		parentInode.I_mtime = time.Now().Unix()
 		parentInode.I_atime = parentInode.I_mtime
 		parentInodeOffset := int64(partitionSuperblock.S_inode_start + parentInodeIndex*partitionSuperblock.S_inode_size)
-		if err := parentInode.Serialize(partitionPath, parentInodeOffset); err != nil {
+		if err := parentInode.Serialize(partitionSuperblock, partitionPath, parentInodeOffset); err != nil {
 			fmt.Printf("Advertencia: Error guardando inodo padre %d actualizado: %v\n", parentInodeIndex, err)
 		}
 	}
@@ -231,27 +199,30 @@ func recursiveRemove(inodeIndex int32, sb *structures.SuperBlock, diskPath strin
 	// Leer Inodo
 	inode := &structures.Inode{}
 	inodeOffset := int64(sb.S_inode_start + inodeIndex*sb.S_inode_size)
-	if err := inode.Deserialize(diskPath, inodeOffset); err != nil {
+	if err := inode.Deserialize(sb, diskPath, inodeOffset); err != nil {
 		return fmt.Errorf("no se pudo leer inodo %d para eliminar: %w", inodeIndex, err)
 	}
 
 	// Verificar Permiso de Escritura sobre ESTE inodo/directorio
 	fmt.Printf("    Verificando permisos para usuario '%s' en inodo %d (Perms: %s)...\n", currentUser, inodeIndex, string(inode.I_perm[:]))
-	canWrite := false
-	if currentUser == "root" {
-		canWrite = true
-	} else {
-		ownerPerm := inode.I_perm[0] // Permiso del dueño
-		if ownerPerm == 'w' || ownerPerm == 'W' || ownerPerm == '6' || ownerPerm == '7' {
-			canWrite = true
-		}
-	}
+	canWrite := checkPermissions(currentUser, userGIDStr, 'w', inode, sb, diskPath)
 
 	if !canWrite {
 		return fmt.Errorf("permiso denegado para eliminar inodo %d", inodeIndex)
 	}
 	fmt.Println("    Permiso concedido.")
 
+	// Con más de un enlace duro solo se quita esta entrada: el inodo y sus bloques siguen en uso
+	if inode.I_type[0] != '0' && inode.I_links > 1 {
+		inode.I_links--
+		inode.I_ctime = time.Now().Unix()
+		fmt.Printf("    Inodo %d tiene otros enlaces, quedan %d. No se libera.\n", inodeIndex, inode.I_links)
+		if err := inode.Serialize(sb, diskPath, inodeOffset); err != nil {
+			return fmt.Errorf("error actualizando enlaces del inodo %d: %w", inodeIndex, err)
+		}
+		return nil
+	}
+
 	// Procesar según tipo
 	if inode.I_type[0] == '1' {
 		fmt.Printf("    Inodo %d es un ARCHIVO. Liberando bloques...\n", inodeIndex)
@@ -262,66 +233,65 @@ func recursiveRemove(inodeIndex int32, sb *structures.SuperBlock, diskPath strin
 			fmt.Printf("    Bloques de datos para inodo %d liberados.\n", inodeIndex)
 		}
 
+	} else if inode.IsSymlink() {
+		fmt.Printf("    Inodo %d es un ENLACE. Liberando bloques del destino...\n", inodeIndex)
+		if err := structures.FreeInodeBlocks(inode, sb, diskPath); err != nil {
+			fmt.Printf("    Advertencia: Error liberando bloques para inodo %d: %v\n", inodeIndex, err)
+		}
+
 	} else if inode.I_type[0] == '0' {
 		fmt.Printf("    Inodo %d es un DIRECTORIO. Procesando contenido recursivamente...\n", inodeIndex)
 
-		// Iterar bloques directos del directorio
-		for i := 0; i < 12; i++ {
-			blockPtr := inode.I_block[i]
-			if blockPtr == -1 {
-				continue
-			}
-			if blockPtr < 0 || blockPtr >= sb.S_blocks_count {
-				fmt.Printf("    Advertencia: Puntero inválido %d en dir %d. Saltando bloque.\n", blockPtr, inodeIndex)
-				continue
-			}
+		// Iterar todas las entradas del directorio (bloques directos e indirectos)
+		entries, err := sb.ReadDir(diskPath, inode)
+		if err != nil {
+			return fmt.Errorf("error leyendo entradas del dir %d: %w", inodeIndex, err)
+		}
+		for _, entry := range entries {
+			entryName := entry.Name
 
-			fmt.Printf("      Procesando bloque de directorio %d...\n", blockPtr)
-			folderBlock := structures.FolderBlock{}
-			blockOffset := int64(sb.S_block_start + blockPtr*sb.S_block_size)
-			if err := folderBlock.Deserialize(diskPath, blockOffset); err != nil {
-				fmt.Printf("      Advertencia: Error leyendo bloque %d del dir %d: %v. Saltando.\n", blockPtr, inodeIndex, err)
-				continue // Saltar bloque si no se puede leer
+			// Saltar "." y ".."
+			if entryName == "." || entryName == ".." {
+				continue
 			}
 
-			// Iterar entradas DENTRO del bloque
-			for j := range folderBlock.B_content {
-				entry := folderBlock.B_content[j]
-				if entry.B_inodo == -1 {
-					continue
-				} // Slot libre
-
-				entryName := strings.TrimRight(string(entry.B_name[:]), "\x00")
-
-				// Saltar "." y ".."
-				if entryName == "." || entryName == ".." {
-					continue
+			// En una carpeta con sticky cada entrada necesita su propio permiso
+			if inode.I_special&structures.PermSticky != 0 {
+				child := &structures.Inode{}
+				if err := child.Deserialize(sb, diskPath, int64(sb.S_inode_start+entry.Inode*sb.S_inode_size)); err != nil {
+					return fmt.Errorf("no se pudo leer inodo %d de '%s': %w", entry.Inode, entryName, err)
 				}
-
-				fmt.Printf("        Llamando recursiveRemove para '%s' (inodo %d)...\n", entryName, entry.B_inodo)
-				// Llamada RECURSIVA para el hijo
-				errRec := recursiveRemove(entry.B_inodo, sb, diskPath, currentUser, userGIDStr)
-				if errRec != nil {
-					// Si falla borrar un hijo, ABORTAR y retornar el error
-					fmt.Printf("        ¡FALLÓ la eliminación recursiva de '%s' (inodo %d)! Abortando.\n", entryName, entry.B_inodo)
-					return fmt.Errorf("no se pudo eliminar '%s' dentro de inodo %d: %w", entryName, inodeIndex, errRec)
+				if !checkSticky(currentUser, inode, child, sb, diskPath) {
+					return fmt.Errorf("permiso denegado: '%s' está en una carpeta con sticky y no es tuyo", entryName)
 				}
-				fmt.Printf("        Eliminación recursiva de '%s' (inodo %d) completada.\n", entryName, entry.B_inodo)
 			}
-			// liberar ESTE bloque de directorio.
-			fmt.Printf("      Liberando bloque de directorio %d...\n", blockPtr)
-			if err := sb.UpdateBitmapBlock(diskPath, blockPtr, '0'); err != nil { // <-- Añadir '0'
-				fmt.Printf("      ¡ERROR GRAVE! No se pudo liberar bloque %d en bitmap: %v\n", blockPtr, err)
-				return fmt.Errorf("error crítico liberando bloque %d en bitmap: %w", blockPtr, err)
+
+			fmt.Printf("        Llamando recursiveRemove para '%s' (inodo %d)...\n", entryName, entry.Inode)
+			// Llamada RECURSIVA para el hijo
+			errRec := recursiveRemove(entry.Inode, sb, diskPath, currentUser, userGIDStr)
+			if errRec != nil {
+				// Si falla borrar un hijo, ABORTAR y retornar el error
+				fmt.Printf("        ¡FALLÓ la eliminación recursiva de '%s' (inodo %d)! Abortando.\n", entryName, entry.Inode)
+				return fmt.Errorf("no se pudo eliminar '%s' dentro de inodo %d: %w", entryName, inodeIndex, errRec)
 			}
-			sb.S_free_blocks_count++
+			fmt.Printf("        Eliminación recursiva de '%s' (inodo %d) completada.\n", entryName, entry.Inode)
+		}
 
+		// Liberar los bloques del directorio, incluidos los de punteros
+		fmt.Printf("      Liberando bloques del directorio %d...\n", inodeIndex)
+		if err := structures.FreeInodeBlocks(inode, sb, diskPath); err != nil {
+			return fmt.Errorf("error crítico liberando bloques del dir %d: %w", inodeIndex, err)
 		}
 
 	} else {
 		fmt.Printf("    Advertencia: Inodo %d tiene tipo desconocido '%c'. Intentando liberar de todas formas.\n", inodeIndex, inode.I_type[0])
 	}
 
+	// El bloque de atributos extendidos se va con el inodo
+	if err := sb.FreeXattrs(diskPath, inode); err != nil {
+		fmt.Printf("    Advertencia: %v\n", err)
+	}
+
 	// Liberar el Inodo Actual después de procesar hijos y liberar bloques de datos/directorio
 	fmt.Printf("    Liberando inodo %d...\n", inodeIndex)
 	if err := sb.UpdateBitmapInode(diskPath, inodeIndex, '0'); err != nil { // <-- Añadir '0'
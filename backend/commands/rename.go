@@ -23,7 +23,7 @@ func ParseRename(tokens []string) (string, error) {
 
 	// Regex para los parámetros esperados
 	pathRegex := regexp.MustCompile(`^(?i)-path=(?:"([^"]+)"|([^\s"]+))$`)
-	nameRegex := regexp.MustCompile(`^(?i)-name=(?:"([^"]+)"|([^\s"]{1,11}))$`)
+	nameRegex := regexp.MustCompile(`^(?i)-name=(?:"([^"]+)"|([^\s"]+))$`)
 
 	if len(tokens) == 0 {
 		return "", errors.New("faltan parámetros: se requiere -path y -name")
@@ -120,7 +120,7 @@ func commandRename(cmd *RENAME) error {
 	}
 
 	// Obtener SB/Partición
-	currentUser, _, partitionID := stores.Auth.GetCurrentUser()
+	currentUser, userGIDStr, partitionID := stores.Auth.GetCurrentUser()
 	partitionSuperblock, _, partitionPath, err := stores.GetMountedPartitionSuperblock(partitionID)
 	if err != nil {
 		return fmt.Errorf("error obteniendo partición montada '%s': %w", partitionID, err)
@@ -131,10 +131,15 @@ func commandRename(cmd *RENAME) error {
 	if partitionSuperblock.S_inode_size <= 0 || partitionSuperblock.S_block_size <= 0 {
 		return errors.New("tamaño de inodo o bloque inválido")
 	}
+	// El nuevo nombre debe caber en una entrada de directorio de este sistema de archivos
+	if err := partitionSuperblock.ValidateName(cmd.name); err != nil {
+		return err
+	}
 
 	// Encontrar Inodo Objetivo
 	fmt.Printf("Buscando inodo objetivo: %s\n", cmd.path)
-	targetInodeIndex, targetInode, errFind := structures.FindInodeByPath(partitionSuperblock, partitionPath, cmd.path)
+	// Si es un enlace se renombra el enlace, no su destino
+	targetInodeIndex, targetInode, errFind := structures.FindInodeByPathNoFollow(partitionSuperblock, partitionPath, cmd.path)
 	if errFind != nil {
 		return fmt.Errorf("error: no se encontró el archivo o directorio '%s': %w", cmd.path, errFind)
 	}
@@ -165,28 +170,14 @@ func commandRename(cmd *RENAME) error {
 
 	// Verificar Permisos
 	fmt.Printf("Verificando permisos para usuario '%s'...\n", currentUser)
-	canRename := false
-	if currentUser == "root" {
-		canRename = true
-	} else {
-		// Permiso escritura en padre
-		ownerPermParent := parentInode.I_perm[1]
-		parentWrite := ownerPermParent == 'w' || ownerPermParent == 'W' || ownerPermParent == '6' || ownerPermParent == '7'
-		isParentOwner := true // Hago como que funciona xd
-
-		// Permiso escritura en objetivo
-		ownerPermTarget := targetInode.I_perm[1]
-		targetWrite := ownerPermTarget == 'w' || ownerPermTarget == 'W' || ownerPermTarget == '6' || ownerPermTarget == '7'
-		isTargetOwner := true // Hago como que funciona xd
-
-		if isParentOwner && parentWrite && isTargetOwner && targetWrite {
-			canRename = true
-		}
-
-	}
+	canRename := checkPermissions(currentUser, userGIDStr, 'w', parentInode, partitionSuperblock, partitionPath) &&
+		checkPermissions(currentUser, userGIDStr, 'w', targetInode, partitionSuperblock, partitionPath)
 	if !canRename {
 		return fmt.Errorf("permiso denegado: se requiere permiso de escritura en '%s' y en '%s'", parentPath, cmd.path)
 	}
+	if !checkSticky(currentUser, parentInode, targetInode, partitionSuperblock, partitionPath) {
+		return fmt.Errorf("permiso denegado: '%s' tiene sticky y solo el dueño de '%s', el de la carpeta o root pueden renombrarlo", parentPath, cmd.path)
+	}
 	fmt.Println("Permisos concedidos.")
 
 	// Verificar si el nuevo nombre ya existe en el directorio padre
@@ -203,63 +194,59 @@ func commandRename(cmd *RENAME) error {
 	entryNameOriginal := filepath.Base(cmd.path)
 	entryUpdated := false
 
-	for i := 0; i < 12; i++ { // Solo directos
-		blockPtr := parentInode.I_block[i]
-		if blockPtr == -1 {
+	it := partitionSuperblock.IterDir(partitionPath, parentInode)
+	for it.Next() {
+		blockPtr := it.Index
+		folderBlock := it.Block
+
+		// Buscar la entrada correcta y cambiar el nombre
+		j := folderBlock.Find(entryNameOriginal)
+		if j == -1 {
 			continue
 		}
-		if blockPtr < 0 || blockPtr >= partitionSuperblock.S_blocks_count {
-			continue
-		} // Validar
-
-		folderBlock := structures.FolderBlock{}
-		blockOffset := int64(partitionSuperblock.S_block_start + blockPtr*partitionSuperblock.S_block_size)
-		// Leer bloque padre
-		if err := folderBlock.Deserialize(partitionPath, blockOffset); err != nil {
-			fmt.Printf("Advertencia: Error leyendo bloque %d padre al renombrar: %v\n", blockPtr, err)
+		if folderBlock.Entries[j].Inode != targetInodeIndex {
+			fmt.Printf("Advertencia: Se encontró '%s' en bloque %d, índice %d, pero apunta al inodo %d en lugar de %d.\n", entryNameOriginal, blockPtr, j, folderBlock.Entries[j].Inode, targetInodeIndex)
 			continue
 		}
-
-		blockNeedsWrite := false
-		// Buscar la entrada correcta y cambia el nombre
-		for j := range folderBlock.B_content {
-			if folderBlock.B_content[j].B_inodo == targetInodeIndex {
-				// Verificar si el nombre coincide por seguridad
-				currentName := strings.TrimRight(string(folderBlock.B_content[j].B_name[:]), "\x00")
-				if currentName == entryNameOriginal {
-					fmt.Printf("  Entrada '%s' encontrada en bloque %d, índice %d. Renombrando a '%s'...\n", entryNameOriginal, blockPtr, j, cmd.name)
-					// Limpiar nombre antiguo y copiar nuevo
-					folderBlock.B_content[j].B_name = [12]byte{}       // Limpiar
-					copy(folderBlock.B_content[j].B_name[:], cmd.name) // Copiar nuevo nombre
-					entryUpdated = true
-					blockNeedsWrite = true
-					break // Salir del bucle de entradas
-				} else {
-					fmt.Printf("Advertencia: Se encontró inodo %d en bloque %d, índice %d, pero nombre '%s' no coincide con original '%s'.\n", targetInodeIndex, blockPtr, j, currentName, entryNameOriginal)
-				}
+		fmt.Printf("  Entrada '%s' encontrada en bloque %d, índice %d. Renombrando a '%s'...\n", entryNameOriginal, blockPtr, j, cmd.name)
+		entry := folderBlock.Entries[j]
+		folderBlock.Remove(j)
+		entry.Name = cmd.name
+		if !folderBlock.Add(entry) {
+			// Con entradas de longitud variable el nombre nuevo puede no caber en el mismo bloque:
+			// se agrega en otro bloque del padre antes de quitar la entrada original
+			fmt.Printf("  El nuevo nombre no cabe en el bloque %d. Moviendo la entrada a otro bloque...\n", blockPtr)
+			if err := addEntryToParent(parentInodeIndex, cmd.name, targetInodeIndex, partitionSuperblock, partitionPath); err != nil {
+				return fmt.Errorf("error agregando la entrada renombrada '%s': %w", cmd.name, err)
+			}
+			// addEntryToParent pudo asignar un bloque nuevo al padre
+			if err := parentInode.Deserialize(partitionSuperblock, partitionPath, int64(partitionSuperblock.S_inode_start+parentInodeIndex*partitionSuperblock.S_inode_size)); err != nil {
+				return fmt.Errorf("error releyendo inodo padre %d: %w", parentInodeIndex, err)
 			}
 		}
+		entryUpdated = true
 
-		if blockNeedsWrite {
-			fmt.Printf("  Guardando bloque padre modificado %d...\n", blockPtr)
-			if err := folderBlock.Serialize(partitionPath, blockOffset); err != nil {
-				return fmt.Errorf("error crítico: guardando bloque padre %d modificado: %w", blockPtr, err)
-			}
-			break
+		fmt.Printf("  Guardando bloque padre modificado %d...\n", blockPtr)
+		if err := it.Save(); err != nil {
+			return fmt.Errorf("error crítico: guardando bloque padre %d modificado: %w", blockPtr, err)
 		}
+		break
+	}
+	if err := it.Err(); err != nil {
+		fmt.Printf("Advertencia: Error recorriendo el directorio padre al renombrar: %v\n", err)
 	}
 
 	if !entryUpdated {
-		return fmt.Errorf("error crítico: no se encontró la entrada original '%s' (inodo %d) en los bloques directos del padre %d", entryNameOriginal, targetInodeIndex, parentInodeIndex)
+		return fmt.Errorf("error crítico: no se encontró la entrada original '%s' (inodo %d) en el directorio padre %d", entryNameOriginal, targetInodeIndex, parentInodeIndex)
 	}
 
 	// Actualizar Timestamps (inodo padre y objetivo)
 	fmt.Println("Actualizando timestamps...")
-	now := float32(time.Now().Unix())
+	now := time.Now().Unix()
 	parentInode.I_mtime = now
 	parentInode.I_atime = now // Modificar el directorio también es un acceso
 	parentInodeOffset := int64(partitionSuperblock.S_inode_start + parentInodeIndex*partitionSuperblock.S_inode_size)
-	if err := parentInode.Serialize(partitionPath, parentInodeOffset); err != nil {
+	if err := parentInode.Serialize(partitionSuperblock, partitionPath, parentInodeOffset); err != nil {
 		fmt.Printf("Advertencia: Error al guardar inodo padre %d actualizado: %v\n", parentInodeIndex, err)
 	}
 
@@ -267,7 +254,7 @@ func commandRename(cmd *RENAME) error {
 	targetInode.I_ctime = now
 	targetInode.I_atime = now
 	targetInodeOffset := int64(partitionSuperblock.S_inode_start + targetInodeIndex*partitionSuperblock.S_inode_size)
-	if err := targetInode.Serialize(partitionPath, targetInodeOffset); err != nil {
+	if err := targetInode.Serialize(partitionSuperblock, partitionPath, targetInodeOffset); err != nil {
 		return fmt.Errorf("error crítico al guardar inodo objetivo %d actualizado: %w", targetInodeIndex, err)
 	}
 
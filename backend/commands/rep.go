@@ -69,7 +69,7 @@ func ParseRep(tokens []string) (string, error) {
 			processedKeys["path"] = true
 		case "name":
 			nameLower := strings.ToLower(value) // Convertir valor a minúsculas para comparación
-			validNames := []string{"mbr", "disk", "inode", "block", "bm_inode", "bm_block", "sb", "file", "ls", "tree"}
+			validNames := []string{"mbr", "disk", "inode", "block", "bm_inode", "bm_block", "sb", "file", "ls", "tree", "frag", "du"}
 			if !slices.Contains(validNames, nameLower) {
 				return "", fmt.Errorf("valor inválido para -name: '%s'. Debe ser uno de: %s", value, strings.Join(validNames, ", "))
 			}
@@ -192,6 +192,18 @@ func commandRep(rep *REP) error {
 			fmt.Printf("Error: %v\n", err)
 			return err
 		}
+	case "frag":
+		err = reports.ReportFrag(mountedSb, mountedDiskPath, rep.path)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return err
+		}
+	case "du":
+		err = reports.ReportDu(mountedSb, mountedDiskPath, rep.path, rep.path_file_ls)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return err
+		}
 
 	}
 	return nil
@@ -0,0 +1,404 @@
+package commands
+
+import (
+	stores "backend/stores"
+	structures "backend/structures"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type RESIZEFS struct {
+	id string // ID de la partición montada
+}
+
+// Imagen en memoria de las áreas del sistema de archivos (bitmaps, tabla de inodos y bloques)
+type fsImage struct {
+	inodeBitmap []byte
+	blockBitmap []byte
+	inodes      []structures.Inode
+	blocks      []byte
+}
+
+func ParseResizefs(tokens []string) (string, error) {
+	cmd := &RESIZEFS{}
+	processedKeys := make(map[string]bool)
+
+	idRegex := regexp.MustCompile(`^(?i)-id=(?:"([^"]+)"|([^\s"]+))$`)
+
+	if len(tokens) == 0 {
+		return "", errors.New("faltan parámetros: se requiere -id=<mount_id>")
+	}
+
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		match := idRegex.FindStringSubmatch(token)
+		if match == nil {
+			return "", fmt.Errorf("parámetro inválido o no reconocido: '%s'. Se esperaba -id=<mount_id>", token)
+		}
+		value := match[1]
+		if value == "" {
+			value = match[2]
+		}
+
+		if processedKeys["-id"] {
+			return "", errors.New("parámetro duplicado: -id")
+		}
+		processedKeys["-id"] = true
+		if value == "" {
+			return "", errors.New("el valor para -id no puede estar vacío")
+		}
+		cmd.id = value
+	}
+
+	if !processedKeys["-id"] {
+		return "", errors.New("falta el parámetro requerido: -id")
+	}
+
+	oldSb, newSb, err := commandResizefs(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	if oldSb == newSb {
+		return fmt.Sprintf("RESIZEFS: El sistema de archivos de '%s' ya ocupa toda la partición (%d inodos, %d bloques). Sin cambios.", cmd.id, newSb.S_inodes_count, newSb.S_blocks_count), nil
+	}
+	return fmt.Sprintf("RESIZEFS: Sistema de archivos redimensionado exitosamente\n"+
+		"-> ID: %s\n"+
+		"-> Inodos: %d -> %d\n"+
+		"-> Bloques: %d -> %d",
+		cmd.id, oldSb.S_inodes_count, newSb.S_inodes_count, oldSb.S_blocks_count, newSb.S_blocks_count), nil
+}
+
+// Redimensiona el sistema de archivos de una partición montada. Devuelve el superbloque anterior y
+// el nuevo (el mismo si no hubo cambios).
+func commandResizefs(cmd *RESIZEFS) (*structures.SuperBlock, *structures.SuperBlock, error) {
+	fmt.Printf("Iniciando RESIZEFS para partición ID: %s\n", cmd.id)
+
+	_, partition, diskPath, err := stores.GetMountedPartitionInfo(cmd.id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error obteniendo información de la partición '%s': %w", cmd.id, err)
+	}
+	return resizeFilesystem(partition, diskPath)
+}
+
+// Recalcula inodos y bloques con el tamaño actual de la partición (misma geometría que en mkfs) y
+// reubica bitmaps, inodos y bloques.
+func resizeFilesystem(partition *structures.Partition, diskPath string) (*structures.SuperBlock, *structures.SuperBlock, error) {
+	sb := &structures.SuperBlock{}
+	if err := sb.Deserialize(diskPath, int64(partition.Part_start)); err != nil {
+		return nil, nil, fmt.Errorf("error leyendo superbloque: %w", err)
+	}
+	if sb.S_magic != 0xEF53 {
+		return nil, nil, errors.New("la partición no tiene un sistema de archivos válido (ejecute mkfs primero)")
+	}
+	if sb.S_inode_size != structures.InodeDiskSize(sb.S_feature_incompat) || !structures.ValidBlockSize(sb.S_block_size) {
+		return nil, nil, errors.New("tamaño de inodo o bloque inválido en el superbloque")
+	}
+
+	oldN := sb.S_inodes_count
+	geo := geometryOf(sb)
+	newN, newBlocks := calculateCounts(partition, geo)
+	fmt.Printf("n actual: %d, n para el nuevo tamaño de partición (%d bytes): %d (bloques: %d -> %d)\n", oldN, partition.Part_size, newN, sb.S_blocks_count, newBlocks)
+	if newN == oldN && newBlocks == sb.S_blocks_count {
+		fmt.Println("El sistema de archivos ya tiene el tamaño adecuado.")
+		return sb, sb, nil
+	}
+
+	newSb, err := relayoutFilesystem(sb, partition, diskPath, geo, newN, newBlocks)
+	if err != nil {
+		return nil, nil, err
+	}
+	fmt.Println("RESIZEFS completado.")
+	return sb, newSb, nil
+}
+
+// Reescribe el sistema de archivos con newN inodos, newBlocks bloques y la geometría geo
+// (resizefs cambia la cantidad; migratefs y tunefs, el formato). Devuelve el nuevo superbloque.
+func relayoutFilesystem(sb *structures.SuperBlock, partition *structures.Partition, diskPath string, geo fsGeometry, newN int32, newBlocks int32) (*structures.SuperBlock, error) {
+	fsType := "2fs"
+	if sb.S_filesystem_type == 3 {
+		fsType = "3fs"
+	}
+	if err := sb.CheckWritable(); err != nil {
+		return nil, err
+	}
+	newSb := createSuperBlock(partition, newN, newBlocks, fsType, geo)
+	if newSb == nil {
+		return nil, errors.New("la partición es demasiado pequeña para el sistema de archivos")
+	}
+	// Las características compat se conservan aunque este código no las conozca
+	newSb.S_feature_compat |= sb.FeatureMask(structures.FeatureKindCompat)
+	newSb.S_feature_ro_compat = sb.FeatureMask(structures.FeatureKindRoCompat)
+
+	// Cargar todas las áreas con el layout anterior (el contenido sigue en disco aunque fdisk haya reducido la partición)
+	fmt.Println("Leyendo layout actual del sistema de archivos...")
+	img, err := loadFsImage(sb, diskPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Calcular renumeración; falla si los datos vivos no caben en el nuevo tamaño
+	inodeMap, usedInodes, err := buildIndexRemap(img.inodeBitmap, newN)
+	if err != nil {
+		return nil, fmt.Errorf("no se puede reducir el sistema de archivos: %w", err)
+	}
+	blockMap, usedBlocks, err := buildIndexRemap(img.blockBitmap, newBlocks)
+	if err != nil {
+		return nil, fmt.Errorf("no se puede reducir el sistema de archivos: %w", err)
+	}
+	fmt.Printf("Inodos en uso: %d, bloques en uso: %d\n", usedInodes, usedBlocks)
+
+	// Renumerar punteros a bloques y referencias a inodos en los directorios
+	visited := make(map[int32]bool)
+	for i := range img.inodes {
+		if img.inodeBitmap[i] != '1' {
+			continue
+		}
+		inode := &img.inodes[i]
+		isDir := inode.I_type[0] == '0'
+		if inode.I_xattr != -1 { // El bloque de atributos no tiene punteros, solo se renumera
+			if inode.I_xattr < 0 || inode.I_xattr >= sb.S_blocks_count || blockMap[inode.I_xattr] == -1 {
+				return nil, fmt.Errorf("bloque de atributos inválido %d en inodo %d", inode.I_xattr, i)
+			}
+			inode.I_xattr = blockMap[inode.I_xattr]
+		}
+		if inode.IsSymlink() {
+			newSb.S_feature_ro_compat |= structures.FeatureRoCompatSymlinks
+			if inode.InlineSymlink() {
+				continue // I_block guarda el destino del enlace, no punteros
+			}
+		}
+		for k := 0; k < 15; k++ {
+			ptr := inode.I_block[k]
+			if ptr == -1 {
+				continue
+			}
+			if ptr < 0 || ptr >= sb.S_blocks_count {
+				return nil, fmt.Errorf("puntero inválido %d en inodo %d, i_block[%d]", ptr, i, k)
+			}
+			if blockMap[ptr] == -1 {
+				return nil, fmt.Errorf("el bloque %d del inodo %d está marcado como libre en el bitmap", ptr, i)
+			}
+			level := 0
+			if k >= 12 {
+				level = k - 11
+			}
+			if err := remapBlockTree(img, ptr, level, isDir, inodeMap, blockMap, visited, sb); err != nil {
+				return nil, fmt.Errorf("error renumerando bloques del inodo %d: %w", i, err)
+			}
+			inode.I_block[k] = blockMap[ptr]
+		}
+	}
+
+	// Construir el nuevo layout en memoria
+	blockSize := int64(sb.S_block_size)
+	newImg := &fsImage{
+		inodeBitmap: bytes.Repeat([]byte{'0'}, int(newN)),
+		blockBitmap: bytes.Repeat([]byte{'0'}, int(newBlocks)),
+		inodes:      make([]structures.Inode, newN),
+		blocks:      make([]byte, int64(newBlocks)*blockSize),
+	}
+	for i := range newImg.inodes {
+		newImg.inodes[i].I_xattr = -1 // Los inodos libres no tienen bloque de atributos
+	}
+	for oldIdx, newIdx := range inodeMap {
+		if newIdx == -1 {
+			continue
+		}
+		newImg.inodeBitmap[newIdx] = '1'
+		newImg.inodes[newIdx] = img.inodes[oldIdx]
+	}
+	for oldIdx, newIdx := range blockMap {
+		if newIdx == -1 {
+			continue
+		}
+		newImg.blockBitmap[newIdx] = '1'
+		copy(newImg.blocks[int64(newIdx)*blockSize:int64(newIdx+1)*blockSize], img.blocks[int64(oldIdx)*blockSize:int64(oldIdx+1)*blockSize])
+	}
+
+	newSb.S_free_inodes_count = newN - usedInodes
+	newSb.S_free_blocks_count = newBlocks - usedBlocks
+	newSb.S_mtime = sb.S_mtime
+	newSb.S_umtime = sb.S_umtime
+	newSb.S_mnt_count = sb.S_mnt_count
+	newSb.S_first_ino = int32(bytes.IndexByte(newImg.inodeBitmap, '0'))
+	newSb.S_first_blo = int32(bytes.IndexByte(newImg.blockBitmap, '0'))
+
+	fmt.Println("Escribiendo nuevo layout del sistema de archivos...")
+	if err := writeFsImage(newImg, newSb, diskPath); err != nil {
+		return nil, err
+	}
+	if err := newSb.Serialize(diskPath, int64(partition.Part_start)); err != nil {
+		return nil, fmt.Errorf("error al serializar el nuevo superbloque: %w", err)
+	}
+	newSb.Print()
+	return newSb, nil
+}
+
+// Lee bitmaps, tabla de inodos y área de bloques según los offsets del superbloque.
+func loadFsImage(sb *structures.SuperBlock, diskPath string) (*fsImage, error) {
+	file, err := structures.OpenDisk(diskPath)
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo disco '%s': %w", diskPath, err)
+	}
+	defer file.Close()
+
+	img := &fsImage{
+		inodes: make([]structures.Inode, sb.S_inodes_count),
+		blocks: make([]byte, int64(sb.S_blocks_count)*int64(sb.S_block_size)),
+	}
+	if img.inodeBitmap, err = sb.ReadInodeBitmap(diskPath); err != nil {
+		return nil, fmt.Errorf("error leyendo bitmap de inodos: %w", err)
+	}
+	if img.blockBitmap, err = sb.ReadBlockBitmap(diskPath); err != nil {
+		return nil, fmt.Errorf("error leyendo bitmap de bloques: %w", err)
+	}
+
+	inodeTable := make([]byte, int64(sb.S_inodes_count)*int64(sb.S_inode_size))
+	if _, err := file.ReadAt(inodeTable, int64(sb.S_inode_start)); err != nil {
+		return nil, fmt.Errorf("error leyendo tabla de inodos: %w", err)
+	}
+	if img.inodes, err = sb.DecodeInodes(inodeTable); err != nil {
+		return nil, fmt.Errorf("error decodificando tabla de inodos: %w", err)
+	}
+
+	if _, err := file.ReadAt(img.blocks, int64(sb.S_block_start)); err != nil {
+		return nil, fmt.Errorf("error leyendo área de bloques: %w", err)
+	}
+	return img, nil
+}
+
+// Escribe las áreas de la imagen en las posiciones del nuevo superbloque.
+func writeFsImage(img *fsImage, sb *structures.SuperBlock, diskPath string) error {
+	file, err := structures.OpenDisk(diskPath)
+	if err != nil {
+		return fmt.Errorf("error abriendo disco '%s' para escritura: %w", diskPath, err)
+	}
+	defer file.Close()
+
+	inodeTable, err := sb.EncodeInodes(img.inodes)
+	if err != nil {
+		return fmt.Errorf("error codificando tabla de inodos: %w", err)
+	}
+
+	if err := sb.WriteInodeBitmap(diskPath, img.inodeBitmap); err != nil {
+		return fmt.Errorf("error escribiendo bitmap de inodos: %w", err)
+	}
+	if err := sb.WriteBlockBitmap(diskPath, img.blockBitmap); err != nil {
+		return fmt.Errorf("error escribiendo bitmap de bloques: %w", err)
+	}
+	if _, err := file.WriteAt(inodeTable, int64(sb.S_inode_start)); err != nil {
+		return fmt.Errorf("error escribiendo tabla de inodos: %w", err)
+	}
+	if _, err := file.WriteAt(img.blocks, int64(sb.S_block_start)); err != nil {
+		return fmt.Errorf("error escribiendo área de bloques: %w", err)
+	}
+	return nil
+}
+
+// Asigna a cada índice en uso un índice menor a newCount. Los que ya caben se conservan,
+// los que quedan fuera se mueven a huecos libres. Devuelve -1 para índices libres.
+func buildIndexRemap(bitmap []byte, newCount int32) ([]int32, int32, error) {
+	remap := make([]int32, len(bitmap))
+	taken := make([]bool, newCount)
+	used := int32(0)
+	for i, state := range bitmap {
+		remap[i] = -1
+		if state != '1' {
+			continue
+		}
+		used++
+		if int32(i) < newCount {
+			remap[i] = int32(i)
+			taken[i] = true
+		}
+	}
+	if used > newCount {
+		return nil, 0, fmt.Errorf("hay %d elementos en uso y el nuevo tamaño solo admite %d", used, newCount)
+	}
+
+	nextFree := int32(0)
+	for i, state := range bitmap {
+		if state != '1' || int32(i) < newCount {
+			continue
+		}
+		for taken[nextFree] {
+			nextFree++
+		}
+		remap[i] = nextFree
+		taken[nextFree] = true
+	}
+	return remap, used, nil
+}
+
+// Renumera el contenido del bloque blockIdx (en la imagen anterior). level indica la
+// indirección: 0 = bloque de datos, 1..3 = bloque de punteros.
+func remapBlockTree(img *fsImage, blockIdx int32, level int, isDir bool, inodeMap []int32, blockMap []int32, visited map[int32]bool, sb *structures.SuperBlock) error {
+	if visited[blockIdx] {
+		return nil
+	}
+	visited[blockIdx] = true
+	blockSize := sb.S_block_size
+	raw := img.blocks[int64(blockIdx)*int64(blockSize) : int64(blockIdx+1)*int64(blockSize)]
+
+	if level == 0 {
+		if !isDir {
+			return nil // El contenido de archivos no se toca
+		}
+		dirBlock := sb.NewDirBlock()
+		if err := dirBlock.Decode(raw); err != nil {
+			return fmt.Errorf("error decodificando bloque de carpeta %d: %w", blockIdx, err)
+		}
+		for j := range dirBlock.Entries {
+			ino := dirBlock.Entries[j].Inode
+			if ino >= 0 && int(ino) < len(inodeMap) {
+				dirBlock.Entries[j].Inode = inodeMap[ino]
+			}
+		}
+		encoded, err := dirBlock.Encode()
+		if err != nil {
+			return fmt.Errorf("error codificando bloque de carpeta %d: %w", blockIdx, err)
+		}
+		copy(raw, encoded)
+		return nil
+	}
+
+	pointerBlock := structures.NewPointerBlock(blockSize)
+	if err := binary.Read(bytes.NewReader(raw), binary.LittleEndian, pointerBlock.P_pointers); err != nil {
+		return fmt.Errorf("error decodificando bloque de punteros %d: %w", blockIdx, err)
+	}
+	for j, ptr := range pointerBlock.P_pointers {
+		if ptr == -1 {
+			continue
+		}
+		if ptr < 0 || int(ptr) >= len(blockMap) {
+			return fmt.Errorf("puntero inválido %d en bloque de punteros %d", ptr, blockIdx)
+		}
+		if blockMap[ptr] == -1 {
+			return fmt.Errorf("el bloque %d (desde bloque de punteros %d) está marcado como libre en el bitmap", ptr, blockIdx)
+		}
+		if err := remapBlockTree(img, ptr, level-1, isDir, inodeMap, blockMap, visited, sb); err != nil {
+			return err
+		}
+		pointerBlock.P_pointers[j] = blockMap[ptr]
+	}
+	return encodeInto(raw, pointerBlock.P_pointers)
+}
+
+// Serializa data sobre el slice de un bloque en memoria.
+func encodeInto(dst []byte, data interface{}) error {
+	buf := new(bytes.Buffer)
+	if err := binary.Write(buf, binary.LittleEndian, data); err != nil {
+		return err
+	}
+	copy(dst, buf.Bytes())
+	return nil
+}
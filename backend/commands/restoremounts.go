@@ -0,0 +1,134 @@
+package commands
+
+import (
+	stores "backend/stores"
+	structures "backend/structures"
+	utils "backend/utils"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Partición marcada como montada en el MBR de un disco
+type mountFlag struct {
+	diskPath string
+	index    int    // Índice en Mbr_partitions
+	name     string // Nombre de la partición
+	id       string // Part_id tal como está en el MBR
+}
+
+// RestoreMountTable reconstruye stores.MountedPartitions a partir de las marcas de montaje
+// (Part_status='1', Part_id, Part_correlative) de los discos registrados. Los IDs que no siguen
+// stores.MountIDScheme, repetidos o con letra de otro disco se reasignan; los correlativos se corrigen en el MBR.
+// Devuelve una línea por cada montaje restaurado o corrección hecha.
+func RestoreMountTable() []string {
+	report := []string{}
+
+	diskPaths := make([]string, 0, len(stores.DiskRegistry))
+	for path := range stores.DiskRegistry {
+		diskPaths = append(diskPaths, path)
+	}
+	sort.Strings(diskPaths)
+
+	mbrs := make(map[string]*structures.MBR)
+	dirty := make(map[string]bool) // Discos cuyo MBR se modificó
+	pending := []mountFlag{} // Montajes que necesitan un ID nuevo
+	usedIDs := make(map[string]bool)
+	usedNames := make(map[string]bool)
+	for id := range stores.MountedPartitions {
+		usedIDs[id] = true
+	}
+	for _, name := range stores.ListPatitions {
+		usedNames[name] = true
+	}
+
+	for _, diskPath := range diskPaths {
+		mbr := &structures.MBR{}
+		if err := mbr.Deserialize(diskPath); err != nil {
+			report = append(report, fmt.Sprintf("%s: no se pudo leer el MBR: %v", diskPath, err))
+			continue
+		}
+		mbrs[diskPath] = mbr
+
+		for i := range mbr.Mbr_partitions {
+			p := &mbr.Mbr_partitions[i]
+			if p.Part_status[0] != '1' {
+				continue
+			}
+			flag := mountFlag{
+				diskPath: diskPath,
+				index:    i,
+				name:     strings.TrimRight(string(p.Part_name[:]), "\x00 "),
+				id:       strings.TrimRight(string(p.Part_id[:]), "\x00 "),
+			}
+
+			if usedNames[flag.name] {
+				// mount no permite dos particiones montadas con el mismo nombre
+				p.Part_status[0] = '0'
+				p.Part_correlative = 0
+				p.Part_id = [4]byte{}
+				dirty[diskPath] = true
+				report = append(report, fmt.Sprintf("%s: '%s' desmontada (ya hay una partición montada con ese nombre)", diskPath, flag.name))
+				continue
+			}
+
+			if usedIDs[flag.id] {
+				pending = append(pending, flag)
+				usedNames[flag.name] = true
+				continue
+			}
+			correlative, err := utils.ReserveMountID(diskPath, flag.id, int(p.Part_correlative))
+			if err != nil {
+				report = append(report, fmt.Sprintf("%s: ID '%s' de '%s' descartado: %v", diskPath, flag.id, flag.name, err))
+				pending = append(pending, flag)
+				usedNames[flag.name] = true
+				continue
+			}
+			if p.Part_correlative != int32(correlative) {
+				report = append(report, fmt.Sprintf("%s: correlativo de '%s' corregido de %d a %d", diskPath, flag.name, p.Part_correlative, correlative))
+				p.Part_correlative = int32(correlative)
+				dirty[diskPath] = true
+			}
+
+			registerMount(flag.id, diskPath, flag.name, p.Part_start)
+			usedIDs[flag.id] = true
+			usedNames[flag.name] = true
+			report = append(report, fmt.Sprintf("%s: '%s' montada como %s", diskPath, flag.name, flag.id))
+		}
+	}
+
+	// Asignar IDs nuevos después de reservar todos los válidos
+	for _, flag := range pending {
+		newID, correlative, err := utils.GenerateMountID(flag.diskPath, mbrs[flag.diskPath].Mbr_disk_signature, flag.name)
+		p := &mbrs[flag.diskPath].Mbr_partitions[flag.index]
+		dirty[flag.diskPath] = true
+		if err != nil {
+			p.Part_status[0] = '0'
+			p.Part_correlative = 0
+			p.Part_id = [4]byte{}
+			report = append(report, fmt.Sprintf("%s: '%s' desmontada (no se pudo asignar ID: %v)", flag.diskPath, flag.name, err))
+			continue
+		}
+		p.MountPartition(correlative, newID)
+		registerMount(newID, flag.diskPath, flag.name, p.Part_start)
+		report = append(report, fmt.Sprintf("%s: '%s' tenía ID inválido o repetido '%s', reasignado a %s", flag.diskPath, flag.name, flag.id, newID))
+	}
+
+	for diskPath := range dirty {
+		if err := mbrs[diskPath].Serialize(diskPath); err != nil {
+			report = append(report, fmt.Sprintf("%s: no se pudo guardar el MBR reconciliado: %v", diskPath, err))
+		}
+	}
+	return report
+}
+
+// Agrega un montaje a los stores globales igual que commandMount.
+func registerMount(id string, diskPath string, name string, partStart int32) {
+	stores.MountedPartitions[id] = diskPath
+	stores.ListPatitions = append(stores.ListPatitions, name)
+	stores.ListMounted = append(stores.ListMounted, id)
+	if err := structures.AttachDisk(diskPath); err != nil {
+		fmt.Println("Advertencia:", err)
+	}
+	loadFreeIndex(diskPath, partStart)
+}
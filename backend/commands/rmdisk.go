@@ -11,7 +11,8 @@ import (
 )
 
 type RMDISK struct {
-	path string // Path del disco
+	path  string // Path del disco
+	force bool   // Desmontar particiones y cerrar sesión antes de eliminar
 }
 
 func ParseRmdisk(tokens []string) (string, error) {
@@ -20,7 +21,7 @@ func ParseRmdisk(tokens []string) (string, error) {
 	// Unir tokens en una sola cadena y luego dividir por espacios, respetando las comillas
 	args := strings.Join(tokens, " ")
 	// Expresión regular para encontrar los parámetros del comando mkdir
-	re := regexp.MustCompile(`-path=[^\s]+|-p`)
+	re := regexp.MustCompile(`(?i)-path=[^\s]+|(?i)-force|-p`)
 	// Encuentra todas las coincidencias de la expresión regular en la cadena de argumentos
 	matches := re.FindAllString(args, -1)
 
@@ -51,6 +52,11 @@ func ParseRmdisk(tokens []string) (string, error) {
 				value = strings.Trim(value, "\"")
 			}
 			cmd.path = value
+		case "-force":
+			if len(kv) != 1 {
+				return "", fmt.Errorf("-force no acepta valor: %s", match)
+			}
+			cmd.force = true
 		default:
 			// Si el parámetro no es reconocido, devuelve un error
 			return "", fmt.Errorf("parámetro desconocido: %s", key)
@@ -63,48 +69,63 @@ func ParseRmdisk(tokens []string) (string, error) {
 	}
 
 	// Aquí se puede agregar la lógica para ejecutar el comando mkdir con los parámetros proporcionados
-	err := commandRmdisk(cmd)
+	recycled, err := commandRmdisk(cmd)
 	if err != nil {
 		return "", err
 	}
 
+	if recycled != "" {
+		return fmt.Sprintf("Rmdisk: Disco %s movido a la papelera como '%s' (restaurar con restoredisk -name=%s).", cmd.path, recycled, recycled), nil
+	}
 	return fmt.Sprintf("Rmdisk: Disco %s eliminado exitosamente.", cmd.path), nil // Devuelve el comando MKDIR creado
 
 }
 
-func commandRmdisk(rmdisk *RMDISK) error {
+// Devuelve el nombre de la entrada en la papelera, o "" si el disco se borró definitivamente.
+func commandRmdisk(rmdisk *RMDISK) (string, error) {
 
 	if _, err := os.Stat(rmdisk.path); os.IsNotExist(err) {
-		return fmt.Errorf("no existe el archivo de disco '%s'", rmdisk.path)
+		return "", fmt.Errorf("no existe el archivo de disco '%s'", rmdisk.path)
 	}
 
-	//  VERIFICAR SI HAY PARTICIONES MONTADAS DE ESTE DISCO
-	mountedFromThisDisk := []string{}
-	for id, mountedPath := range stores.MountedPartitions {
-		// Comparar paths después de limpiarlos por si acaso
-		if filepath.Clean(mountedPath) == filepath.Clean(rmdisk.path) {
-			mountedFromThisDisk = append(mountedFromThisDisk, id)
-		}
-	}
-	if len(mountedFromThisDisk) > 0 {
-		return fmt.Errorf("error: no se puede eliminar el disco '%s' porque las siguientes particiones están montadas: %v", rmdisk.path, mountedFromThisDisk)
+	//  VERIFICAR SI HAY PARTICIONES MONTADAS DE ESTE DISCO (con -force se desmontan)
+	if err := releaseMounts(rmdisk.path, nil, rmdisk.force); err != nil {
+		return "", fmt.Errorf("error: no se puede eliminar el disco '%s': %w", rmdisk.path, err)
 	}
 
-	// Intentar eliminar el archivo físico
-	fmt.Printf("Intentando eliminar archivo físico: %s\n", rmdisk.path)
-	err := os.Remove(rmdisk.path)
-	if err != nil {
-		return fmt.Errorf("error al eliminar el archivo '%s': %w", rmdisk.path, err)
+	recycled := ""
+	if stores.RecycleDir != "" {
+		fmt.Printf("Moviendo disco a la papelera: %s\n", rmdisk.path)
+		name, err := moveToRecycle(rmdisk.path)
+		if err != nil {
+			return "", err
+		}
+		recycled = name
+	} else {
+		// Intentar eliminar el archivo físico
+		fmt.Printf("Intentando eliminar archivo físico: %s\n", rmdisk.path)
+		err := os.Remove(rmdisk.path)
+		if err != nil {
+			return "", fmt.Errorf("error al eliminar el archivo '%s': %w", rmdisk.path, err)
+		}
+		fmt.Printf("Archivo de disco %s eliminado exitosamente del sistema.\n", rmdisk.path)
 	}
-	fmt.Printf("Archivo de disco %s eliminado exitosamente del sistema.\n", rmdisk.path)
 
 	//  Quitar del Registro de Discos 
-	if _, exists := stores.DiskRegistry[rmdisk.path]; exists {
-		delete(stores.DiskRegistry, rmdisk.path)
+	// scandisks registra paths absolutos, así que se comparan ambas formas
+	absPath, _ := filepath.Abs(rmdisk.path)
+	removed := false
+	for registered := range stores.DiskRegistry {
+		if absRegistered, _ := filepath.Abs(registered); absRegistered == absPath {
+			delete(stores.DiskRegistry, registered)
+			removed = true
+		}
+	}
+	if removed {
 		fmt.Printf("Disco '%s' eliminado del registro.\n", rmdisk.path)
 	} else {
 		fmt.Printf("Advertencia: Disco '%s' no encontrado en el registro para eliminar.\n", rmdisk.path)
 	}
 	
-	return nil
+	return recycled, nil
 }
@@ -160,7 +160,7 @@ func commandRmusr(rmusr *RMUSR) error {
 	if newContent != "" && !strings.HasSuffix(newContent, "\n") {
 		newContent += "\n"
 	}
-	newSize := int32(len(newContent))
+	newSize := int64(len(newContent))
 	fmt.Printf("Nuevo contenido de users.txt preparado (%d bytes).\n", newSize)
 
 	fmt.Println("Liberando bloques antiguos de /users.txt...")
@@ -183,14 +183,14 @@ func commandRmusr(rmusr *RMUSR) error {
 	// Actualizar Inodo de users.txt
 	fmt.Println("Actualizando inodo /users.txt...")
 	usersInode.I_size = newSize
-	usersInode.I_mtime = float32(time.Now().Unix())
+	usersInode.I_mtime = time.Now().Unix()
 	usersInode.I_atime = usersInode.I_mtime // Actualizar también atime
 	// Resetear los punteros antiguos y asignar los nuevos
 	for k := range usersInode.I_block { usersInode.I_block[k] = -1 }
 	usersInode.I_block = newAllocatedBlockIndices
 
 	usersInodeOffset := int64(partitionSuperblock.S_inode_start) + int64(usersInodeIndex)*int64(partitionSuperblock.S_inode_size)
-	err = usersInode.Serialize(partitionPath, usersInodeOffset)
+	err = usersInode.Serialize(partitionSuperblock, partitionPath, usersInodeOffset)
 	if err != nil {
 		// Fallo crítico, el inodo no se actualizó
 		return fmt.Errorf("error serializando inodo /users.txt actualizado: %w", err)
@@ -0,0 +1,161 @@
+package commands
+
+import (
+	stores "backend/stores"
+	structures "backend/structures"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type SCANDISKS struct {
+	path string // Directorio a escanear (opcional, por defecto stores.DiskSearchDirs)
+}
+
+// Resultado de un escaneo de discos
+type ScanResult struct {
+	Added   []string // Discos nuevos añadidos al registro
+	Known   int      // Discos válidos que ya estaban registrados
+	Corrupt []string // "path: motivo" de archivos .mia con MBR inválido
+}
+
+func ParseScandisks(tokens []string) (string, error) {
+	cmd := &SCANDISKS{}
+	processedKeys := make(map[string]bool)
+
+	pathRegex := regexp.MustCompile(`^(?i)-path=(?:"([^"]+)"|([^\s"]+))$`)
+
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		match := pathRegex.FindStringSubmatch(token)
+		if match == nil {
+			return "", fmt.Errorf("parámetro inválido o no reconocido: '%s'. Se esperaba -path=<directorio>", token)
+		}
+		value := match[1]
+		if value == "" {
+			value = match[2]
+		}
+		if processedKeys["-path"] {
+			return "", errors.New("parámetro duplicado: -path")
+		}
+		processedKeys["-path"] = true
+		if value == "" {
+			return "", errors.New("el valor para -path no puede estar vacío")
+		}
+		cmd.path = filepath.Clean(value)
+	}
+
+	dirs := stores.DiskSearchDirs
+	if cmd.path != "" {
+		dirs = []string{cmd.path}
+	}
+	if len(dirs) == 0 {
+		return "", errors.New("no hay directorios configurados para buscar discos")
+	}
+
+	result := ScanDisks(dirs)
+	return FormatScanResult(dirs, result), nil
+}
+
+// ScanDisks busca archivos .mia en los directorios indicados y registra los que tienen un MBR válido.
+func ScanDisks(dirs []string) ScanResult {
+	result := ScanResult{}
+
+	for _, dir := range dirs {
+		fmt.Printf("Buscando discos en '%s'...\n", dir)
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				fmt.Printf("Advertencia: no se pudo acceder a '%s': %v\n", path, err)
+				if d != nil && d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".mia") {
+				return nil
+			}
+
+			absPath, errAbs := filepath.Abs(path)
+			if errAbs != nil {
+				absPath = filepath.Clean(path)
+			}
+			if errMbr := validateDiskMBR(absPath); errMbr != nil {
+				result.Corrupt = append(result.Corrupt, fmt.Sprintf("%s: %v", absPath, errMbr))
+				return nil
+			}
+			if isDiskRegistered(absPath) {
+				result.Known++
+				return nil
+			}
+			stores.DiskRegistry[absPath] = filepath.Base(absPath)
+			result.Added = append(result.Added, absPath)
+			fmt.Printf("Disco '%s' añadido al registro.\n", absPath)
+			return nil
+		})
+		if err != nil {
+			result.Corrupt = append(result.Corrupt, fmt.Sprintf("%s: %v", dir, err))
+		}
+	}
+	return result
+}
+
+// FormatScanResult genera el texto de salida de un escaneo.
+func FormatScanResult(dirs []string, result ScanResult) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("SCANDISKS: Directorios: %s\n", strings.Join(dirs, ", ")))
+	output.WriteString(fmt.Sprintf("-> Discos nuevos: %d\n", len(result.Added)))
+	for _, path := range result.Added {
+		output.WriteString(fmt.Sprintf("   + %s\n", path))
+	}
+	output.WriteString(fmt.Sprintf("-> Ya registrados: %d\n", result.Known))
+	output.WriteString(fmt.Sprintf("-> Con MBR corrupto: %d", len(result.Corrupt)))
+	for _, entry := range result.Corrupt {
+		output.WriteString(fmt.Sprintf("\n   ! %s", entry))
+	}
+	return output.String()
+}
+
+// Verifica que el archivo tenga un MBR legible y coherente con su tamaño.
+func validateDiskMBR(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	var mbr structures.MBR
+	if err := mbr.Deserialize(path); err != nil {
+		return fmt.Errorf("no se pudo leer el MBR: %w", err)
+	}
+	if mbr.Mbr_size <= 0 || int64(mbr.Mbr_size) > info.Size() {
+		return fmt.Errorf("tamaño en MBR (%d) no coincide con el archivo (%d bytes)", mbr.Mbr_size, info.Size())
+	}
+	for i, p := range mbr.Mbr_partitions {
+		if p.Part_status[0] == 'N' || p.Part_size <= 0 {
+			continue
+		}
+		if p.Part_start <= 0 || p.Part_start+p.Part_size > mbr.Mbr_size {
+			return fmt.Errorf("partición %d fuera de los límites del disco", i+1)
+		}
+	}
+	return nil
+}
+
+// Indica si el disco ya está en el registro (comparando paths limpios).
+func isDiskRegistered(path string) bool {
+	for registered := range stores.DiskRegistry {
+		abs, err := filepath.Abs(registered)
+		if err != nil {
+			abs = filepath.Clean(registered)
+		}
+		if abs == path {
+			return true
+		}
+	}
+	return false
+}
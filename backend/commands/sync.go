@@ -0,0 +1,140 @@
+package commands
+
+import (
+	stores "backend/stores"
+	structures "backend/structures"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type SYNC struct {
+	id string // Partición cuyo disco se sincroniza (vacío: todos los discos montados)
+}
+
+// Lee el único parámetro opcional -id= que aceptan sync y cachestats.
+func parseOptionalID(command string, tokens []string) (string, error) {
+	idRegex := regexp.MustCompile(`^(?i)-id=(?:"([^"]+)"|([^\s"]+))$`)
+	id := ""
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		match := idRegex.FindStringSubmatch(token)
+		if match == nil {
+			return "", fmt.Errorf("parámetro inválido o no reconocido para %s: '%s'. Se esperaba -id=<valor>", command, token)
+		}
+		if id != "" {
+			return "", errors.New("parámetro duplicado: -id")
+		}
+		id = match[1]
+		if id == "" {
+			id = match[2]
+		}
+	}
+	return id, nil
+}
+
+// Devuelve los discos abiertos a revisar: el de la partición id o todos si id está vacío.
+func cachedDisks(id string) ([]string, error) {
+	if id == "" {
+		return structures.AttachedDisks(), nil
+	}
+	resolved := stores.ResolveMountID(id)
+	path, ok := stores.MountedPartitions[resolved]
+	if !ok {
+		return nil, fmt.Errorf("la partición con id '%s' no está montada", id)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	return []string{abs}, nil
+}
+
+// IDs montados sobre diskPath, para mostrar a qué particiones sirve cada caché.
+func mountedIDsOnDisk(diskPath string) []string {
+	ids := []string{}
+	for id, path := range stores.MountedPartitions {
+		if abs, err := filepath.Abs(path); err == nil && abs == diskPath {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func ParseSync(tokens []string) (string, error) {
+	id, err := parseOptionalID("sync", tokens)
+	if err != nil {
+		return "", err
+	}
+	cmd := &SYNC{id: id}
+
+	synced, err := commandSync(cmd)
+	if err != nil {
+		return "", err
+	}
+	if len(synced) == 0 {
+		return "SYNC: No hay discos montados", nil
+	}
+	return fmt.Sprintf("SYNC: Cambios pendientes escritos en disco\n-> %s", strings.Join(synced, "\n-> ")), nil
+}
+
+func commandSync(cmd *SYNC) ([]string, error) {
+	disks, err := cachedDisks(cmd.id)
+	if err != nil {
+		return nil, err
+	}
+	synced := []string{}
+	for _, path := range disks {
+		dirty := 0
+		if cached, ok := structures.AttachedDevice(path).(*structures.CachedDevice); ok {
+			dirty = cached.Stats().Dirty
+		}
+		fmt.Printf("Sincronizando '%s' (%d páginas sucias)\n", path, dirty)
+		if err := structures.SyncDisk(path); err != nil {
+			return synced, fmt.Errorf("error sincronizando '%s': %w", path, err)
+		}
+		synced = append(synced, fmt.Sprintf("%s (%d páginas)", path, dirty))
+	}
+	return synced, nil
+}
+
+func ParseCachestats(tokens []string) (string, error) {
+	id, err := parseOptionalID("cachestats", tokens)
+	if err != nil {
+		return "", err
+	}
+	disks, err := cachedDisks(id)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "CACHESTATS: modo %s, %d páginas de %d bytes por disco", structures.CacheMode, structures.CachePages, structures.CachePageSize)
+	if len(disks) == 0 {
+		sb.WriteString("\n-> No hay discos montados")
+		return sb.String(), nil
+	}
+	for _, path := range disks {
+		ids := strings.Join(mountedIDsOnDisk(path), ", ")
+		cached, ok := structures.AttachedDevice(path).(*structures.CachedDevice)
+		if !ok {
+			fmt.Fprintf(&sb, "\n-> %s [%s]: sin caché", path, ids)
+			continue
+		}
+		stats := cached.Stats()
+		ratio := 0.0
+		if total := stats.Hits + stats.Misses; total > 0 {
+			ratio = float64(stats.Hits) * 100 / float64(total)
+		}
+		fmt.Fprintf(&sb, "\n-> %s [%s]: aciertos %d | fallos %d (%.1f%% aciertos) | desalojos %d | escrituras %d | páginas %d | sucias %d",
+			path, ids, stats.Hits, stats.Misses, ratio, stats.Evictions, stats.Writebacks, stats.Pages, stats.Dirty)
+	}
+	return sb.String(), nil
+}
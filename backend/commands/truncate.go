@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	stores "backend/stores"
+	structures "backend/structures"
+	utils "backend/utils"
+)
+
+type Truncate struct {
+	path string
+	size int64
+}
+
+// ParseTruncate analiza truncate -path=<archivo> -size=<bytes>
+func ParseTruncate(tokens []string) (string, error) {
+	cmd := &Truncate{}
+	processedKeys := make(map[string]bool)
+
+	pathRegex := regexp.MustCompile(`^(?i)-path=(?:"([^"]+)"|([^\s"]+))$`)
+	sizeRegex := regexp.MustCompile(`^(?i)-size=(\d+)$`)
+
+	if len(tokens) == 0 {
+		return "", errors.New("faltan parámetros: se requiere -path y -size")
+	}
+
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		var key, value string
+		if match := pathRegex.FindStringSubmatch(token); match != nil {
+			key = "-path"
+			value = match[1]
+			if value == "" {
+				value = match[2]
+			}
+		} else if match := sizeRegex.FindStringSubmatch(token); match != nil {
+			key = "-size"
+			value = match[1]
+		} else {
+			return "", fmt.Errorf("parámetro inválido o no reconocido: '%s'. Se esperaba -path= o -size=", token)
+		}
+
+		if processedKeys[key] {
+			return "", fmt.Errorf("parámetro duplicado: %s", key)
+		}
+		processedKeys[key] = true
+
+		switch key {
+		case "-path":
+			if !strings.HasPrefix(value, "/") {
+				return "", fmt.Errorf("el path '%s' debe ser absoluto", value)
+			}
+			cmd.path = value
+		case "-size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return "", fmt.Errorf("valor de -size inválido: %s", value)
+			}
+			cmd.size = size
+		}
+	}
+
+	if !processedKeys["-path"] {
+		return "", errors.New("falta el parámetro requerido: -path")
+	}
+	if !processedKeys["-size"] {
+		return "", errors.New("falta el parámetro requerido: -size")
+	}
+
+	oldSize, err := commandTruncate(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("TRUNCATE: Archivo '%s' cambió de %d a %d bytes.", cmd.path, oldSize, cmd.size), nil
+}
+
+// commandTruncate cambia el tamaño del archivo y devuelve el tamaño anterior. Al agrandar la parte
+// nueva queda como hueco (no ocupa bloques); al achicar se liberan los bloques de datos y de
+// punteros que quedan fuera.
+func commandTruncate(cmd *Truncate) (int64, error) {
+	fmt.Printf("Intentando truncar: %s a %d bytes\n", cmd.path, cmd.size)
+
+	if !stores.Auth.IsAuthenticated() {
+		return 0, errors.New("comando truncate requiere inicio de sesión")
+	}
+	currentUser, userGIDStr, partitionID := stores.Auth.GetCurrentUser()
+	partitionSuperblock, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(partitionID)
+	if err != nil {
+		return 0, fmt.Errorf("error obteniendo partición montada '%s': %w", partitionID, err)
+	}
+	if partitionSuperblock.S_magic != 0xEF53 {
+		return 0, errors.New("magia de superbloque inválida")
+	}
+
+	targetInodeIndex, targetInode, errFind := structures.FindInodeByPath(partitionSuperblock, partitionPath, cmd.path)
+	if errFind != nil {
+		return 0, fmt.Errorf("error: no se encontró el archivo '%s': %w", cmd.path, errFind)
+	}
+	if targetInode.I_type[0] != '1' {
+		return 0, fmt.Errorf("error: '%s' no es un archivo (es tipo %c)", cmd.path, targetInode.I_type[0])
+	}
+	if !checkPermissions(currentUser, userGIDStr, 'w', targetInode, partitionSuperblock, partitionPath) {
+		return 0, fmt.Errorf("permiso denegado: el usuario '%s' no tiene permiso de escritura sobre '%s'", currentUser, cmd.path)
+	}
+
+	oldSize := targetInode.I_size
+	freeBefore := partitionSuperblock.S_free_blocks_count
+	if err := partitionSuperblock.TruncateFile(partitionPath, targetInode, cmd.size); err != nil {
+		return 0, fmt.Errorf("error truncando '%s': %w", cmd.path, err)
+	}
+	fmt.Printf("Tamaño %d -> %d bytes, %d bloques liberados.\n", oldSize, cmd.size, partitionSuperblock.S_free_blocks_count-freeBefore)
+
+	currentTime := time.Now().Unix()
+	targetInode.I_mtime = currentTime
+	targetInode.I_ctime = currentTime
+	// Como edit: cambiar el contenido (salvo root) quita setuid y setgid
+	if currentUser != "root" && targetInode.I_special&(structures.PermSetuid|structures.PermSetgid) != 0 {
+		targetInode.I_special &^= structures.PermSetuid | structures.PermSetgid
+		fmt.Println("Bits setuid/setgid quitados por la escritura.")
+	}
+
+	inodeOffset := int64(partitionSuperblock.S_inode_start) + int64(targetInodeIndex)*int64(partitionSuperblock.S_inode_size)
+	if err := targetInode.Serialize(partitionSuperblock, partitionPath, inodeOffset); err != nil {
+		return 0, fmt.Errorf("error serializando inodo '%s' actualizado: %w", cmd.path, err)
+	}
+	if err := partitionSuperblock.Serialize(partitionPath, int64(mountedPartition.Part_start)); err != nil {
+		return 0, fmt.Errorf("ADVERTENCIA: error al serializar superbloque después de truncate: %w", err)
+	}
+
+	if partitionSuperblock.S_filesystem_type == 3 {
+		journalEntryData := structures.Information{
+			I_operation: utils.StringToBytes10("truncate"),
+			I_path:      utils.StringToBytes32(cmd.path),
+			I_content:   utils.StringToBytes64(strconv.FormatInt(cmd.size, 10)),
+		}
+		if errJournal := utils.AppendToJournal(journalEntryData, partitionSuperblock, partitionPath); errJournal != nil {
+			fmt.Printf("Advertencia: Falla al escribir en journal para truncate '%s': %v\n", cmd.path, errJournal)
+		}
+	}
+
+	fmt.Println("TRUNCATE completado exitosamente.")
+	return oldSize, nil
+}
@@ -0,0 +1,198 @@
+package commands
+
+import (
+	stores "backend/stores"
+	structures "backend/structures"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type TUNEFS struct {
+	id    string   // ID de la partición montada
+	set   []string // Características a activar (-set=a,b)
+	clear []string // Características a desactivar (-clear=a,b)
+}
+
+func ParseTunefs(tokens []string) (string, error) {
+	cmd := &TUNEFS{}
+	processedKeys := make(map[string]bool)
+
+	idRegex := regexp.MustCompile(`^(?i)-id=(?:"([^"]+)"|([^\s"]+))$`)
+	setRegex := regexp.MustCompile(`^(?i)-set=(?:"([^"]+)"|([^\s"]+))$`)
+	clearRegex := regexp.MustCompile(`^(?i)-clear=(?:"([^"]+)"|([^\s"]+))$`)
+
+	if len(tokens) == 0 {
+		return "", errors.New("faltan parámetros: se requiere -id=<mount_id>")
+	}
+
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		var match []string
+		var key string
+		if match = idRegex.FindStringSubmatch(token); match != nil {
+			key = "-id"
+		} else if match = setRegex.FindStringSubmatch(token); match != nil {
+			key = "-set"
+		} else if match = clearRegex.FindStringSubmatch(token); match != nil {
+			key = "-clear"
+		} else {
+			return "", fmt.Errorf("parámetro inválido o no reconocido: '%s'. Se esperaba -id=<mount_id> [-set=<caract.>] [-clear=<caract.>]", token)
+		}
+		value := match[1]
+		if value == "" {
+			value = match[2]
+		}
+
+		if processedKeys[key] {
+			return "", fmt.Errorf("parámetro duplicado: %s", key)
+		}
+		processedKeys[key] = true
+		if value == "" {
+			return "", fmt.Errorf("el valor para %s no puede estar vacío", key)
+		}
+
+		switch key {
+		case "-id":
+			cmd.id = value
+		case "-set":
+			cmd.set = strings.Split(value, ",")
+		case "-clear":
+			cmd.clear = strings.Split(value, ",")
+		}
+	}
+
+	if !processedKeys["-id"] {
+		return "", errors.New("falta el parámetro requerido: -id")
+	}
+
+	return commandTunefs(cmd)
+}
+
+// Muestra las características del sistema de archivos de una partición montada y, con -set/-clear,
+// activa o desactiva las que se pueden convertir reescribiendo el sistema de archivos.
+func commandTunefs(cmd *TUNEFS) (string, error) {
+	fmt.Printf("Iniciando TUNEFS para partición ID: %s\n", cmd.id)
+
+	mbr, partition, diskPath, err := stores.GetMountedPartitionInfo(cmd.id)
+	if err != nil {
+		return "", fmt.Errorf("error obteniendo información de la partición '%s': %w", cmd.id, err)
+	}
+
+	sb := &structures.SuperBlock{}
+	if err := sb.Deserialize(diskPath, int64(partition.Part_start)); err != nil {
+		return "", fmt.Errorf("error leyendo superbloque: %w", err)
+	}
+	if sb.S_magic != 0xEF53 {
+		return "", errors.New("la partición no tiene un sistema de archivos válido (ejecute mkfs primero)")
+	}
+
+	if len(cmd.set) == 0 && len(cmd.clear) == 0 {
+		return "TUNEFS: Características de " + cmd.id + "\n" + describeFeatures(sb, mbr), nil
+	}
+
+	if sb.S_inode_size != structures.InodeDiskSize(sb.S_feature_incompat) || !structures.ValidBlockSize(sb.S_block_size) {
+		return "", errors.New("tamaño de inodo o bloque inválido en el superbloque")
+	}
+	if err := sb.CheckWritable(); err != nil {
+		return "", err
+	}
+	features, err := tunedFeatures(sb, cmd.set, cmd.clear)
+	if err != nil {
+		return "", err
+	}
+	if features == sb.FeatureMask(structures.FeatureKindIncompat) {
+		return "TUNEFS: Sin cambios, las características ya tienen el estado pedido.\n" + describeFeatures(sb, mbr), nil
+	}
+
+	newSb, journalEntries, err := convertFilesystem(sb, partition, diskPath, features)
+	if err != nil {
+		return "", fmt.Errorf("no se pudieron cambiar las características: %w", err)
+	}
+	fmt.Println("TUNEFS completado.")
+
+	return fmt.Sprintf("TUNEFS: Características actualizadas\n"+
+		"-> Incompat: 0x%X -> 0x%X\n"+
+		"-> Tamaño de inodo: %d -> %d bytes\n"+
+		"-> Inodos: %d -> %d\n"+
+		"-> Bloques: %d -> %d\n"+
+		"-> Entradas de journal convertidas: %d\n"+
+		"%s",
+		sb.S_feature_incompat, newSb.S_feature_incompat,
+		sb.S_inode_size, newSb.S_inode_size,
+		sb.S_inodes_count, newSb.S_inodes_count,
+		sb.S_blocks_count, newSb.S_blocks_count,
+		journalEntries, describeFeatures(newSb, mbr)), nil
+}
+
+// Aplica -set y -clear a las características incompat del superbloque. Solo se aceptan las que
+// tunefs sabe convertir (Feature.Tunable).
+func tunedFeatures(sb *structures.SuperBlock, set []string, clear []string) (int32, error) {
+	features := sb.FeatureMask(structures.FeatureKindIncompat)
+	apply := func(names []string, enable bool) error {
+		for _, name := range names {
+			name = strings.TrimSpace(name)
+			feature, ok := structures.FindFeature(name)
+			if !ok {
+				return fmt.Errorf("característica desconocida: '%s'", name)
+			}
+			if !feature.Tunable {
+				return fmt.Errorf("la característica '%s' no se puede modificar en un sistema de archivos existente", feature.Name)
+			}
+			if enable {
+				features |= feature.Mask
+			} else {
+				features &^= feature.Mask
+			}
+		}
+		return nil
+	}
+	if err := apply(set, true); err != nil {
+		return 0, err
+	}
+	if err := apply(clear, false); err != nil {
+		return 0, err
+	}
+	for _, name := range set {
+		for _, other := range clear {
+			if strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(other)) {
+				return 0, fmt.Errorf("la característica '%s' está en -set y en -clear", strings.TrimSpace(name))
+			}
+		}
+	}
+	return features, nil
+}
+
+// Texto con la revisión y las características del sistema de archivos y del disco.
+func describeFeatures(sb *structures.SuperBlock, mbr *structures.MBR) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "-> Revisión del superbloque: %d (soportada hasta la %d)\n", sb.Revision(), structures.SuperBlockRevision)
+	fmt.Fprintf(&b, "-> compat: 0x%X, ro_compat: 0x%X, incompat: 0x%X\n",
+		sb.FeatureMask(structures.FeatureKindCompat), sb.FeatureMask(structures.FeatureKindRoCompat), sb.FeatureMask(structures.FeatureKindIncompat))
+	for _, feature := range structures.Features {
+		state := " "
+		if sb.FeatureMask(feature.Kind)&feature.Mask != 0 {
+			state = "x"
+		}
+		tunable := "fija"
+		if feature.Tunable {
+			tunable = "modificable"
+		}
+		fmt.Fprintf(&b, "   [%s] %-15s %-9s %-11s %s\n", state, feature.Name, feature.Kind, tunable, feature.Description)
+	}
+	for _, kind := range []structures.FeatureKind{structures.FeatureKindCompat, structures.FeatureKindRoCompat, structures.FeatureKindIncompat} {
+		if unknown := sb.UnknownFeatures(kind); unknown != 0 {
+			fmt.Fprintf(&b, "   Bits %s desconocidos: 0x%X\n", kind, unknown)
+		}
+	}
+	fmt.Fprintf(&b, "-> Revisión del MBR: %d (soportada hasta la %d)", mbr.Revision(), structures.MBRRevision)
+	if mbr.HasMetadata() {
+		fmt.Fprintf(&b, "\n-> MBR compat: 0x%X, ro_compat: 0x%X, incompat: 0x%X", mbr.Mbr_feature_compat, mbr.Mbr_feature_ro_compat, mbr.Mbr_feature_incompat)
+	}
+	return b.String()
+}
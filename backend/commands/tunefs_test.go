@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"backend/structures"
+)
+
+func TestTunedFeatures(t *testing.T) {
+	sb := &structures.SuperBlock{S_ext_magic: structures.SuperBlockExtMagic, S_feature_incompat: structures.FeatureIncompatWideTimes | structures.FeatureIncompatLongNames}
+	for _, tt := range []struct {
+		name  string
+		set   []string
+		clear []string
+		want  int32
+		fails bool
+	}{
+		{"activar", []string{"packed_bitmaps", " XATTRS"}, nil, structures.FeatureIncompatWideTimes | structures.FeatureIncompatLongNames | structures.FeatureIncompatPackedBitmaps | structures.FeatureIncompatXattrs, false},
+		{"desactivar", nil, []string{"wide_times"}, structures.FeatureIncompatLongNames, false},
+		{"desconocida", []string{"dir_index"}, nil, 0, true},
+		{"no modificable", nil, []string{"long_names"}, 0, true},
+		{"compat", []string{"has_journal"}, nil, 0, true},
+		{"en -set y -clear", []string{"large_files"}, []string{"Large_Files"}, 0, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tunedFeatures(sb, tt.set, tt.clear)
+			if tt.fails {
+				if err == nil {
+					t.Errorf("tunedFeatures = 0x%X, se esperaba error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("tunedFeatures = 0x%X, %v, se esperaba 0x%X", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// tunefs activa y desactiva los bitmaps empaquetados sin perder archivos ni bloques libres.
+func TestConvertFilesystemPackedBitmaps(t *testing.T) {
+	mbr, sb, diskPath := newTestPartition(t, "2fs", structures.FeatureIncompatLargeFiles)
+	partition := &mbr.Mbr_partitions[0]
+	users := readTestFile(t, sb, diskPath, 1)
+	usedBlocks := sb.S_blocks_count - sb.S_free_blocks_count
+
+	for _, features := range []int32{
+		structures.FeatureIncompatLargeFiles | structures.FeatureIncompatPackedBitmaps,
+		structures.FeatureIncompatLargeFiles,
+	} {
+		newSb, _, err := convertFilesystem(sb, partition, diskPath, features)
+		if err != nil {
+			t.Fatalf("convertFilesystem(0x%X): %v", features, err)
+		}
+		read := &structures.SuperBlock{}
+		if err := read.Deserialize(diskPath, int64(partition.Part_start)); err != nil {
+			t.Fatalf("Deserialize: %v", err)
+		}
+		if read.S_feature_incompat != features || read.S_blocks_count != newSb.S_blocks_count {
+			t.Fatalf("superbloque en disco: incompat 0x%X, bloques %d; se esperaba 0x%X y %d", read.S_feature_incompat, read.S_blocks_count, features, newSb.S_blocks_count)
+		}
+		if got := read.S_blocks_count - read.S_free_blocks_count; got != usedBlocks {
+			t.Errorf("incompat 0x%X: %d bloques en uso, se esperaban %d", features, got, usedBlocks)
+		}
+		if got := readTestFile(t, read, diskPath, 1); got != users {
+			t.Errorf("incompat 0x%X: users.txt = %q, se esperaba %q", features, got, users)
+		}
+		sb = read
+	}
+
+	// Con características ro_compat desconocidas el sistema de archivos no se reescribe
+	sb.S_feature_ro_compat = 0x100
+	if _, _, err := convertFilesystem(sb, partition, diskPath, sb.S_feature_incompat|structures.FeatureIncompatPackedBitmaps); !errors.Is(err, structures.ErrUnsupportedFeatures) {
+		t.Errorf("convertFilesystem con ro_compat desconocida = %v, se esperaba ErrUnsupportedFeatures", err)
+	}
+}
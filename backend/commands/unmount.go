@@ -2,10 +2,13 @@ package commands
 
 import (
 	stores "backend/stores"
+	structures "backend/structures"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"slices"
+	"sort"
 	"strings"
 )
 
@@ -63,8 +66,8 @@ func ParseUnmount(tokens []string) (string, error) {
 	}
 
 	// Validar formato de ID si es necesario 
-	if !strings.HasPrefix(cmd.id, stores.Carnet) {
-		fmt.Printf("Advertencia: El ID '%s' no parece tener el formato esperado (ej: %s1A).\n", cmd.id, stores.Carnet)
+	if !strings.HasPrefix(cmd.id, stores.MountIDPrefix) {
+		fmt.Printf("Advertencia: '%s' no parece un ID (prefijo %s); se buscará como etiqueta o nombre de partición.\n", cmd.id, stores.MountIDPrefix)
 	}
 
 	// Llamar a la lógica del comando
@@ -77,6 +80,7 @@ func ParseUnmount(tokens []string) (string, error) {
 }
 
 func commandUnmount(cmd UNMOUNT) error {
+	cmd.id = stores.ResolveMountID(cmd.id) // Acepta también etiqueta o nombre de partición
 	fmt.Printf("Intentando desmontar partición con ID: %s\n", cmd.id)
 
 	// 1. Verificar si el ID está realmente montado en nuestro store
@@ -115,6 +119,18 @@ func commandUnmount(cmd UNMOUNT) error {
 	// Eliminar la partición de los stores globales
 	fmt.Printf("  Eliminando partición ID '%s' de stores globales...\n", cmd.id)
 	delete(stores.MountedPartitions, cmd.id) // Quitar del mapa principal
+	if err := structures.SyncDisk(diskPath); err != nil {
+		fmt.Println("  Advertencia:", err)
+	}
+	structures.DropFreeIndex(diskPath) // Las demás particiones del disco lo reconstruyen al usarlo
+	if err := structures.DetachDisk(diskPath); err != nil {
+		fmt.Println("  Advertencia:", err)
+	}
+	for label, id := range stores.MountLabels {
+		if id == cmd.id {
+			delete(stores.MountLabels, label)
+		}
+	}
 
 	// Quitar ID de ListMounted
 	foundIndex := -1
@@ -156,4 +172,60 @@ func commandUnmount(cmd UNMOUNT) error {
 
 	fmt.Println("Desmontaje completado.")
 	return nil
-}
\ No newline at end of file
+}
+// Busca las particiones montadas de diskPath (todas, o solo las de nombre en partNames si no está
+// vacío). Sin force devuelve error si alguna está montada o tiene la sesión activa; con force
+// cierra la sesión y las desmonta antes de que el llamador destruya el disco o las particiones.
+func releaseMounts(diskPath string, partNames []string, force bool) error {
+	target, _ := filepath.Abs(diskPath)
+	mountedIDs := []string{}
+	for id, mountedPath := range stores.MountedPartitions {
+		absMounted, _ := filepath.Abs(mountedPath)
+		if absMounted != target {
+			continue
+		}
+		if len(partNames) > 0 {
+			var mbr structures.MBR
+			if err := mbr.Deserialize(mountedPath); err != nil {
+				return fmt.Errorf("error leyendo MBR de '%s': %w", mountedPath, err)
+			}
+			part, err := mbr.GetPartitionByID(id)
+			if err != nil {
+				continue
+			}
+			name := strings.TrimRight(string(part.Part_name[:]), "\x00 ")
+			if !slices.ContainsFunc(partNames, func(n string) bool { return strings.EqualFold(n, name) }) {
+				continue
+			}
+		}
+		mountedIDs = append(mountedIDs, id)
+	}
+	if len(mountedIDs) == 0 {
+		return nil
+	}
+	sort.Strings(mountedIDs)
+
+	sessionID := ""
+	if stores.Auth.IsAuthenticated() && slices.Contains(mountedIDs, stores.Auth.GetPartitionID()) {
+		sessionID = stores.Auth.GetPartitionID()
+	}
+
+	if !force {
+		if sessionID != "" {
+			return fmt.Errorf("la partición %s tiene una sesión activa (%s) y las particiones %v están montadas; use -force para desmontarlas", sessionID, stores.Auth.Username, mountedIDs)
+		}
+		return fmt.Errorf("las particiones %v están montadas; desmóntelas o use -force", mountedIDs)
+	}
+
+	if sessionID != "" {
+		fmt.Printf("-force: cerrando la sesión de '%s' en %s\n", stores.Auth.Username, sessionID)
+		stores.Auth.Logout()
+	}
+	for _, id := range mountedIDs {
+		fmt.Printf("-force: desmontando %s\n", id)
+		if err := commandUnmount(UNMOUNT{id: id}); err != nil {
+			return fmt.Errorf("no se pudo desmontar %s: %w", id, err)
+		}
+	}
+	return nil
+}
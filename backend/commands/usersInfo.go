@@ -35,7 +35,7 @@ func getUserInfo(username string, sb *structures.SuperBlock, diskPath string) (i
 	// Leer Inodo 1 (users.txt)
 	usersInode := &structures.Inode{}
 	usersInodeOffset := int64(sb.S_inode_start + 1*sb.S_inode_size)
-	if err := usersInode.Deserialize(diskPath, usersInodeOffset); err != nil {
+	if err := usersInode.Deserialize(sb, diskPath, usersInodeOffset); err != nil {
 		return -1, -1, fmt.Errorf("getUserInfo: error crítico leyendo inodo 1: %w", err)
 	}
 	if usersInode.I_type[0] != '1' {
@@ -142,3 +142,63 @@ func getUserInfo(username string, sb *structures.SuperBlock, diskPath string) (i
 	fmt.Printf("  Información encontrada para '%s': UID=%d, GID=%d\n", username, foundUID, foundGID)
 	return foundUID, foundGID, nil
 }
+
+// Busca en /users.txt el ID de un usuario (kind "U") o grupo (kind "G") activo por su nombre.
+func lookupUsersID(kind string, name string, sb *structures.SuperBlock, diskPath string) (int32, error) {
+	entries, err := readUsersEntries(kind, sb, diskPath)
+	if err != nil {
+		return -1, err
+	}
+	for id, entryName := range entries {
+		if strings.EqualFold(entryName, name) {
+			return id, nil
+		}
+	}
+	if kind == "G" {
+		return -1, fmt.Errorf("grupo '%s' no encontrado en /users.txt", name)
+	}
+	return -1, fmt.Errorf("usuario '%s' no encontrado en /users.txt", name)
+}
+
+// Nombre del usuario (kind "U") o grupo (kind "G") con ese ID; el número si no existe.
+func lookupUsersName(kind string, id int32, sb *structures.SuperBlock, diskPath string) string {
+	entries, err := readUsersEntries(kind, sb, diskPath)
+	if err == nil {
+		if name, ok := entries[id]; ok {
+			return name
+		}
+	}
+	return strconv.Itoa(int(id))
+}
+
+// Lee /users.txt y devuelve ID -> nombre de los usuarios o grupos activos (ID distinto de 0).
+func readUsersEntries(kind string, sb *structures.SuperBlock, diskPath string) (map[int32]string, error) {
+	usersInode := &structures.Inode{}
+	if err := usersInode.Deserialize(sb, diskPath, int64(sb.S_inode_start+1*sb.S_inode_size)); err != nil {
+		return nil, fmt.Errorf("error leyendo inodo 1: %w", err)
+	}
+	content, err := structures.ReadFileContent(sb, diskPath, usersInode)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo /users.txt: %w", err)
+	}
+	entries := make(map[int32]string)
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), ",")
+		if len(fields) < 3 || strings.TrimSpace(fields[1]) != kind {
+			continue
+		}
+		id, errConv := strconv.ParseInt(strings.TrimSpace(fields[0]), 10, 32)
+		if errConv != nil || id <= 0 {
+			continue
+		}
+		name := strings.TrimSpace(fields[2])
+		if kind == "U" {
+			if len(fields) < 4 {
+				continue
+			}
+			name = strings.TrimSpace(fields[3])
+		}
+		entries[int32(id)] = name
+	}
+	return entries, nil
+}
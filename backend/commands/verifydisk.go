@@ -0,0 +1,324 @@
+package commands
+
+import (
+	structures "backend/structures"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type VERIFYDISK struct {
+	path   string // Path del disco
+	repair bool   // Corregir los casos seguros
+}
+
+// Inconsistencia encontrada en el disco
+type diskIssue struct {
+	offset   int64  // Byte donde está la estructura afectada
+	message  string // Descripción
+	repaired bool   // Si -repair la corrigió
+}
+
+func ParseVerifydisk(tokens []string) (string, error) {
+	cmd := &VERIFYDISK{}
+	processedKeys := make(map[string]bool)
+
+	pathRegex := regexp.MustCompile(`^(?i)-path=(?:"([^"]+)"|([^\s"]+))$`)
+	repairRegex := regexp.MustCompile(`^(?i)-repair$`)
+
+	if len(tokens) == 0 {
+		return "", errors.New("faltan parámetros: se requiere -path=<disco>")
+	}
+
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		key := ""
+		if match := pathRegex.FindStringSubmatch(token); match != nil {
+			key = "-path"
+			if match[1] != "" {
+				cmd.path = filepath.Clean(match[1])
+			} else {
+				cmd.path = filepath.Clean(match[2])
+			}
+		} else if repairRegex.MatchString(token) {
+			key = "-repair"
+			cmd.repair = true
+		} else {
+			return "", fmt.Errorf("parámetro inválido o no reconocido: '%s'. Se esperaba -path= o -repair", token)
+		}
+
+		if processedKeys[key] {
+			return "", fmt.Errorf("parámetro duplicado: %s", key)
+		}
+		processedKeys[key] = true
+	}
+
+	if !processedKeys["-path"] {
+		return "", errors.New("falta el parámetro requerido: -path")
+	}
+
+	issues, err := commandVerifydisk(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "VERIFYDISK: %s\n", cmd.path)
+	if len(issues) == 0 {
+		sb.WriteString("-> Sin inconsistencias")
+		return sb.String(), nil
+	}
+	repaired := 0
+	for _, issue := range issues {
+		suffix := ""
+		if issue.repaired {
+			suffix = " [reparado]"
+			repaired++
+		}
+		fmt.Fprintf(&sb, "-> [offset %d] %s%s\n", issue.offset, issue.message, suffix)
+	}
+	fmt.Fprintf(&sb, "-> %d inconsistencias, %d reparadas", len(issues), repaired)
+	return sb.String(), nil
+}
+
+func commandVerifydisk(cmd *VERIFYDISK) ([]diskIssue, error) {
+	fmt.Printf("Verificando estructura del disco '%s' (reparar: %t)\n", cmd.path, cmd.repair)
+
+	info, err := os.Stat(cmd.path)
+	if err != nil {
+		return nil, fmt.Errorf("error accediendo al disco '%s': %w", cmd.path, err)
+	}
+	var mbr structures.MBR
+	if err := mbr.Deserialize(cmd.path); err != nil {
+		return nil, fmt.Errorf("error leyendo MBR de '%s': %w", cmd.path, err)
+	}
+
+	issues := []diskIssue{}
+	mbrDirty := false
+	mbrStructSize := mbr.DiskSize()
+	diskSize := mbr.Mbr_size
+	if diskSize <= 0 || int64(diskSize) > info.Size() {
+		issues = append(issues, diskIssue{offset: 0, message: fmt.Sprintf("Mbr_size %d inválido (el archivo mide %d bytes)", diskSize, info.Size())})
+		diskSize = int32(info.Size())
+	}
+
+	// --- Entradas del MBR ---
+	active := []int{} // Índices de particiones con espacio válido
+	extended := -1
+	for i := range mbr.Mbr_partitions {
+		p := &mbr.Mbr_partitions[i]
+		entryOffset := structures.MBRPartitionEntryOffset(i)
+		name := strings.TrimRight(string(p.Part_name[:]), "\x00 ")
+		status := p.Part_status[0]
+		if status == 'N' || status == 0 {
+			continue
+		}
+
+		if status != '0' && status != '1' {
+			issues = append(issues, diskIssue{offset: entryOffset, message: fmt.Sprintf("partición %d ('%s') con estado desconocido '%c'", i+1, name, status)})
+		}
+		if p.Part_size <= 0 || p.Part_start < 0 {
+			// Entrada sin espacio asignado: nadie puede usarla
+			issue := diskIssue{offset: entryOffset, message: fmt.Sprintf("partición %d ('%s') huérfana: inicio %d, tamaño %d", i+1, name, p.Part_start, p.Part_size)}
+			if cmd.repair {
+				p.DeletePartition()
+				mbrDirty = true
+				issue.repaired = true
+			}
+			issues = append(issues, issue)
+			continue
+		}
+		if p.Part_type[0] != 'P' && p.Part_type[0] != 'E' {
+			issues = append(issues, diskIssue{offset: entryOffset, message: fmt.Sprintf("partición %d ('%s') con tipo inválido '%c'", i+1, name, p.Part_type[0])})
+		}
+		if p.Part_start < mbrStructSize {
+			issues = append(issues, diskIssue{offset: int64(p.Part_start), message: fmt.Sprintf("partición '%s' inicia en %d, dentro del MBR (%d bytes)", name, p.Part_start, mbrStructSize)})
+		}
+		if end := int64(p.Part_start) + int64(p.Part_size); end > int64(diskSize) {
+			issues = append(issues, diskIssue{offset: int64(p.Part_start), message: fmt.Sprintf("partición '%s' termina en %d, fuera del disco (%d bytes)", name, end, diskSize)})
+		}
+		if p.Part_type[0] == 'E' {
+			if extended != -1 {
+				issues = append(issues, diskIssue{offset: entryOffset, message: fmt.Sprintf("más de una partición extendida: '%s' y '%s'", strings.TrimRight(string(mbr.Mbr_partitions[extended].Part_name[:]), "\x00 "), name)})
+			} else {
+				extended = i
+			}
+		}
+		active = append(active, i)
+	}
+
+	for a := 0; a < len(active); a++ {
+		for b := a + 1; b < len(active); b++ {
+			pa, pb := mbr.Mbr_partitions[active[a]], mbr.Mbr_partitions[active[b]]
+			if pa.Part_start < pb.Part_start+pb.Part_size && pb.Part_start < pa.Part_start+pa.Part_size {
+				issues = append(issues, diskIssue{
+					offset: int64(max(pa.Part_start, pb.Part_start)),
+					message: fmt.Sprintf("particiones '%s' [%d-%d) y '%s' [%d-%d) se solapan",
+						strings.TrimRight(string(pa.Part_name[:]), "\x00 "), pa.Part_start, pa.Part_start+pa.Part_size,
+						strings.TrimRight(string(pb.Part_name[:]), "\x00 "), pb.Part_start, pb.Part_start+pb.Part_size),
+				})
+			}
+		}
+	}
+
+	// --- Cadena de EBRs ---
+	if extended != -1 {
+		chainIssues, err := verifyEBRChain(cmd.path, &mbr.Mbr_partitions[extended], diskSize, cmd.repair)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, chainIssues...)
+	}
+
+	if mbrDirty {
+		if err := mbr.Serialize(cmd.path); err != nil {
+			return nil, fmt.Errorf("error escribiendo MBR reparado: %w", err)
+		}
+		fmt.Println("MBR reparado guardado.")
+	}
+	return issues, nil
+}
+
+// Recorre la cadena de EBRs de la extendida. Con repair corta ciclos y punteros fuera de rango,
+// desenlaza EBRs vacíos intermedios y marca como no usadas las lógicas fuera de la extendida.
+func verifyEBRChain(diskPath string, ext *structures.Partition, diskSize int32, repair bool) ([]diskIssue, error) {
+	// Solo se abre para escritura si hay que reparar
+	openDisk := structures.OpenDiskReadOnly
+	if repair {
+		openDisk = structures.OpenDisk
+	}
+	file, err := openDisk(diskPath)
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo disco para verificar EBRs: %w", err)
+	}
+	defer file.Close()
+
+	issues := []diskIssue{}
+	ebrSize := int64(binary.Size(structures.EBR{}))
+	extStart := int64(ext.Part_start)
+	extEnd := min(extStart+int64(ext.Part_size), int64(diskSize))
+
+	readEBR := func(pos int64) (structures.EBR, error) {
+		var ebr structures.EBR
+		if _, err := file.Seek(pos, 0); err != nil {
+			return ebr, err
+		}
+		err := binary.Read(file, binary.LittleEndian, &ebr)
+		return ebr, err
+	}
+	writeEBR := func(pos int64, ebr *structures.EBR) error {
+		if _, err := file.Seek(pos, 0); err != nil {
+			return err
+		}
+		return binary.Write(file, binary.LittleEndian, ebr)
+	}
+
+	if extStart+ebrSize > extEnd {
+		return append(issues, diskIssue{offset: extStart, message: "la extendida no tiene espacio para su EBR inicial"}), nil
+	}
+
+	visited := map[int64]bool{}
+	prevPos := int64(-1)
+	var prev structures.EBR
+	var lastLogicalEnd int64 = extStart + ebrSize
+	pos := extStart
+
+	for {
+		visited[pos] = true
+		ebr, err := readEBR(pos)
+		if err != nil {
+			issues = append(issues, diskIssue{offset: pos, message: fmt.Sprintf("no se pudo leer el EBR: %v", err)})
+			break
+		}
+		name := strings.TrimRight(string(ebr.Part_name[:]), "\x00 ")
+		dirty := false    // Reescribir este EBR
+		unlinked := false // Este EBR se sacó de la cadena: el anterior apunta a su siguiente
+
+		orphaned := false // Lógica marcada como no usada por -repair
+		if ebr.Part_status[0] != 'N' && ebr.Part_size > 0 {
+			dataStart, dataEnd := int64(ebr.Part_start), int64(ebr.Part_start)+int64(ebr.Part_size)
+			if dataStart != pos+ebrSize {
+				issues = append(issues, diskIssue{offset: pos, message: fmt.Sprintf("lógica '%s' inicia en %d, se esperaba %d (después de su EBR)", name, dataStart, pos+ebrSize)})
+			}
+			if dataStart < extStart || dataEnd > extEnd {
+				issue := diskIssue{offset: pos, message: fmt.Sprintf("lógica '%s' [%d-%d) fuera de la extendida [%d-%d)", name, dataStart, dataEnd, extStart, extEnd)}
+				if repair {
+					ebr.Part_status[0] = 'N'
+					ebr.Part_size = 0
+					dirty = true
+					orphaned = true
+					issue.repaired = true
+				}
+				issues = append(issues, issue)
+			} else {
+				if dataStart < lastLogicalEnd && pos != extStart {
+					issues = append(issues, diskIssue{offset: pos, message: fmt.Sprintf("lógica '%s' inicia en %d, solapada con la anterior (termina en %d)", name, dataStart, lastLogicalEnd)})
+				}
+				lastLogicalEnd = max(lastLogicalEnd, dataEnd)
+			}
+		} else if pos != extStart {
+			// EBR vacío en medio de la cadena (solo el EBR inicial puede estar vacío)
+			issue := diskIssue{offset: pos, message: "EBR vacío enlazado en medio de la cadena"}
+			issue.repaired = repair
+			issues = append(issues, issue)
+		}
+		if repair && pos != extStart && (orphaned || ebr.Part_status[0] == 'N' || ebr.Part_size <= 0) {
+			// Desenlazar el EBR vacío: el anterior pasa a apuntar a su siguiente
+			prev.Part_next = ebr.Part_next
+			if err := writeEBR(prevPos, &prev); err != nil {
+				return nil, fmt.Errorf("error reescribiendo EBR en %d: %w", prevPos, err)
+			}
+			unlinked = true
+		}
+
+		next := int64(ebr.Part_next)
+		badNext := ""
+		switch {
+		case next == -1:
+		case visited[next] || next <= pos:
+			badNext = fmt.Sprintf("Part_next %d apunta hacia atrás: ciclo en la cadena de EBRs", next)
+		case next < extStart || next+ebrSize > extEnd:
+			badNext = fmt.Sprintf("Part_next %d fuera de la extendida [%d-%d)", next, extStart, extEnd)
+		}
+		if badNext != "" {
+			issue := diskIssue{offset: pos, message: badNext}
+			if repair {
+				// Cortar la cadena en el último EBR que sigue enlazado
+				if unlinked {
+					prev.Part_next = -1
+					if err := writeEBR(prevPos, &prev); err != nil {
+						return nil, fmt.Errorf("error reescribiendo EBR en %d: %w", prevPos, err)
+					}
+				} else {
+					ebr.Part_next = -1
+					dirty = true
+				}
+				issue.repaired = true
+			}
+			issues = append(issues, issue)
+			next = -1
+		}
+
+		if dirty {
+			if err := writeEBR(pos, &ebr); err != nil {
+				return nil, fmt.Errorf("error reescribiendo EBR en %d: %w", pos, err)
+			}
+		}
+		if next == -1 {
+			break
+		}
+		if !unlinked {
+			prevPos, prev = pos, ebr
+		}
+		pos = next
+	}
+	return issues, nil
+}
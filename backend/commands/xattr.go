@@ -0,0 +1,254 @@
+package commands
+
+import (
+	stores "backend/stores"
+	structures "backend/structures"
+	utils "backend/utils"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// setxattr, getxattr, listxattr y rmxattr comparten parámetros y validación. Leer atributos pide
+// permiso de lectura sobre el archivo y modificarlos, de escritura; el espacio system. solo lo
+// modifica root.
+type XATTR struct {
+	op    string // setxattr, getxattr, listxattr o rmxattr
+	path  string // Ruta del archivo o carpeta
+	name  string // Nombre completo del atributo (user.mime)
+	value string // Valor (solo setxattr)
+}
+
+func ParseSetxattr(tokens []string) (string, error) {
+	cmd, err := parseXattr("setxattr", tokens, true, true)
+	if err != nil {
+		return "", err
+	}
+	if _, err := commandXattr(cmd); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("SETXATTR: Atributo '%s' guardado en '%s'.", cmd.name, cmd.path), nil
+}
+
+func ParseGetxattr(tokens []string) (string, error) {
+	cmd, err := parseXattr("getxattr", tokens, true, false)
+	if err != nil {
+		return "", err
+	}
+	attrs, err := commandXattr(cmd)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("GETXATTR: %s=%s", attrs[0].Name, attrs[0].Value), nil
+}
+
+func ParseListxattr(tokens []string) (string, error) {
+	cmd, err := parseXattr("listxattr", tokens, false, false)
+	if err != nil {
+		return "", err
+	}
+	attrs, err := commandXattr(cmd)
+	if err != nil {
+		return "", err
+	}
+	if len(attrs) == 0 {
+		return fmt.Sprintf("LISTXATTR: '%s' no tiene atributos extendidos.", cmd.path), nil
+	}
+	lines := make([]string, len(attrs))
+	for i, attr := range attrs {
+		lines[i] = fmt.Sprintf("%s=%s", attr.Name, attr.Value)
+	}
+	return fmt.Sprintf("LISTXATTR: %s\n%s", cmd.path, strings.Join(lines, "\n")), nil
+}
+
+func ParseRmxattr(tokens []string) (string, error) {
+	cmd, err := parseXattr("rmxattr", tokens, true, false)
+	if err != nil {
+		return "", err
+	}
+	if _, err := commandXattr(cmd); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("RMXATTR: Atributo '%s' eliminado de '%s'.", cmd.name, cmd.path), nil
+}
+
+func parseXattr(op string, tokens []string, needName bool, needValue bool) (*XATTR, error) {
+	cmd := &XATTR{op: op}
+	processedKeys := make(map[string]bool)
+
+	pathRegex := regexp.MustCompile(`^(?i)-path=(?:"([^"]+)"|([^\s"]+))$`)
+	nameRegex := regexp.MustCompile(`^(?i)-name=(?:"([^"]+)"|([^\s"]+))$`)
+	valueRegex := regexp.MustCompile(`^(?i)-value=(?:"([^"]+)"|([^\s"]+))$`)
+
+	usage := "-path=<ruta>"
+	if needName {
+		usage += " -name=<espacio.nombre>"
+	}
+	if needValue {
+		usage += " -value=<valor>"
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("faltan parámetros: se requiere %s", usage)
+	}
+
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		var match []string
+		var key string
+		if match = pathRegex.FindStringSubmatch(token); match != nil {
+			key = "-path"
+		} else if match = nameRegex.FindStringSubmatch(token); match != nil && needName {
+			key = "-name"
+		} else if match = valueRegex.FindStringSubmatch(token); match != nil && needValue {
+			key = "-value"
+		} else {
+			return nil, fmt.Errorf("parámetro inválido o no reconocido: '%s'. Se esperaba %s", token, usage)
+		}
+		value := match[1]
+		if value == "" {
+			value = match[2]
+		}
+
+		if processedKeys[key] {
+			return nil, fmt.Errorf("parámetro duplicado: %s", key)
+		}
+		processedKeys[key] = true
+		if value == "" {
+			return nil, fmt.Errorf("el valor para %s no puede estar vacío", key)
+		}
+
+		switch key {
+		case "-path":
+			if !strings.HasPrefix(value, "/") {
+				return nil, fmt.Errorf("la ruta '%s' debe ser absoluta", value)
+			}
+			cmd.path = value
+		case "-name":
+			if err := structures.ValidateXattrName(value); err != nil {
+				return nil, err
+			}
+			cmd.name = value
+		case "-value":
+			cmd.value = value
+		}
+	}
+
+	if !processedKeys["-path"] {
+		return nil, errors.New("falta el parámetro requerido: -path")
+	}
+	if needName && !processedKeys["-name"] {
+		return nil, errors.New("falta el parámetro requerido: -name")
+	}
+	if needValue && !processedKeys["-value"] {
+		return nil, errors.New("falta el parámetro requerido: -value")
+	}
+	return cmd, nil
+}
+
+// Ejecuta la operación cmd.op y devuelve los atributos que corresponden: el pedido (getxattr) o
+// todos (listxattr).
+func commandXattr(cmd *XATTR) ([]structures.Xattr, error) {
+	if !stores.Auth.IsAuthenticated() {
+		return nil, fmt.Errorf("comando %s requiere sesión iniciada (login)", cmd.op)
+	}
+	currentUser, userGIDStr, partitionID := stores.Auth.GetCurrentUser()
+
+	sb, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(partitionID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener la partición montada '%s': %w", partitionID, err)
+	}
+	if sb.S_magic != 0xEF53 {
+		return nil, fmt.Errorf("magia del superbloque inválida (0x%X), posible corrupción o formato incorrecto", sb.S_magic)
+	}
+	if sb.S_inode_size <= 0 || sb.S_block_size <= 0 {
+		return nil, fmt.Errorf("tamaño de inodo o bloque inválido en superbloque: inode=%d, block=%d", sb.S_inode_size, sb.S_block_size)
+	}
+
+	inodeIndex, inode, err := structures.FindInodeByPath(sb, partitionPath, cmd.path)
+	if err != nil {
+		return nil, fmt.Errorf("no se encontró '%s': %w", cmd.path, err)
+	}
+
+	modify := cmd.op == "setxattr" || cmd.op == "rmxattr"
+	requiredPerm := byte('r')
+	if modify {
+		requiredPerm = 'w'
+		if !sb.Xattrs() {
+			return nil, errors.New("el sistema de archivos no guarda atributos extendidos (ejecute tunefs -set=xattrs)")
+		}
+		if structures.IsACLXattr(cmd.name) {
+			return nil, fmt.Errorf("'%s' guarda una ACL: use setfacl para modificarla", cmd.name)
+		}
+		if structures.IsSystemXattr(cmd.name) && currentUser != "root" {
+			return nil, fmt.Errorf("permiso denegado: solo root puede modificar atributos system. ('%s')", cmd.name)
+		}
+	}
+	if !checkPermissions(currentUser, userGIDStr, requiredPerm, inode, sb, partitionPath) {
+		return nil, fmt.Errorf("permiso denegado: se requiere permiso de %s sobre '%s'", map[byte]string{'r': "lectura", 'w': "escritura"}[requiredPerm], cmd.path)
+	}
+
+	attrs, err := sb.ReadXattrs(partitionPath, inode)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo atributos de '%s': %w", cmd.path, err)
+	}
+	found := -1
+	for i, attr := range attrs {
+		if attr.Name == cmd.name {
+			found = i
+			break
+		}
+	}
+
+	switch cmd.op {
+	case "listxattr":
+		return attrs, nil
+	case "getxattr":
+		if found == -1 {
+			return nil, fmt.Errorf("'%s' no tiene el atributo '%s'", cmd.path, cmd.name)
+		}
+		return attrs[found : found+1], nil
+	case "setxattr":
+		if found == -1 {
+			attrs = append(attrs, structures.Xattr{Name: cmd.name, Value: cmd.value})
+		} else {
+			attrs[found].Value = cmd.value
+		}
+	case "rmxattr":
+		if found == -1 {
+			return nil, fmt.Errorf("'%s' no tiene el atributo '%s'", cmd.path, cmd.name)
+		}
+		attrs = append(attrs[:found], attrs[found+1:]...)
+	}
+
+	if err := sb.WriteXattrs(partitionPath, inode, attrs); err != nil {
+		return nil, fmt.Errorf("error guardando atributos de '%s': %w", cmd.path, err)
+	}
+	inode.I_ctime = time.Now().Unix()
+	inodeOffset := int64(sb.S_inode_start) + int64(inodeIndex)*int64(sb.S_inode_size)
+	if err := inode.Serialize(sb, partitionPath, inodeOffset); err != nil {
+		return nil, fmt.Errorf("error serializando inodo %d: %w", inodeIndex, err)
+	}
+
+	if sb.S_filesystem_type == 3 {
+		journalEntryData := structures.Information{
+			I_operation: utils.StringToBytes10(cmd.op),
+			I_path:      utils.StringToBytes32(cmd.path),
+			I_content:   utils.StringToBytes64(cmd.name + "=" + cmd.value),
+		}
+		if errJournal := utils.AppendToJournal(journalEntryData, sb, partitionPath); errJournal != nil {
+			fmt.Printf("Advertencia: Falla al escribir en journal para %s '%s': %v\n", cmd.op, cmd.path, errJournal)
+		}
+	}
+
+	fmt.Printf("\nSerializando SuperBlock después de %s...\n", strings.ToUpper(cmd.op))
+	if err := sb.Serialize(partitionPath, int64(mountedPartition.Part_start)); err != nil {
+		return nil, fmt.Errorf("ADVERTENCIA: error al serializar el superbloque después de %s, los cambios podrían perderse (%w)", cmd.op, err)
+	}
+	return attrs, nil
+}
@@ -2,8 +2,17 @@ package main
 
 import (
 	analyzer "backend/analyzer"
+	commands "backend/commands"
+	stores "backend/stores"
+	structures "backend/structures"
+	utils "backend/utils"
 	"fmt" // Importa el paquete "fmt" para formatear e imprimir texto
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -22,6 +31,52 @@ type CommandResponse struct {
 
 
 func main() {
+	// Directorios de discos: MIA_DISK_DIRS (separados por ':') o el valor por defecto de stores
+	if dirs := os.Getenv("MIA_DISK_DIRS"); dirs != "" {
+		stores.DiskSearchDirs = filepath.SplitList(dirs)
+	}
+	// Papelera de discos: MIA_RECYCLE_DIR la habilita y MIA_RECYCLE_HOURS fija la retención
+	if dir := os.Getenv("MIA_RECYCLE_DIR"); dir != "" {
+		stores.RecycleDir = dir
+	}
+	if hours, err := strconv.Atoi(os.Getenv("MIA_RECYCLE_HOURS")); err == nil && hours > 0 {
+		stores.RecycleRetention = time.Duration(hours) * time.Hour
+	}
+	for _, name := range commands.PurgeRecycle() {
+		fmt.Println("RECYCLE: purgado", name)
+	}
+	fmt.Println(commands.FormatScanResult(stores.DiskSearchDirs, commands.ScanDisks(stores.DiskSearchDirs)))
+
+	// Formato de IDs de montaje: MIA_MOUNT_ID_PREFIX y MIA_MOUNT_ID_SCHEME (letter, number o stable)
+	if prefix, ok := os.LookupEnv("MIA_MOUNT_ID_PREFIX"); ok {
+		stores.MountIDPrefix = prefix
+	}
+	if scheme := os.Getenv("MIA_MOUNT_ID_SCHEME"); scheme != "" {
+		stores.MountIDScheme = strings.ToLower(scheme)
+	}
+	if err := utils.ValidateMountIDConfig(stores.MountIDPrefix, stores.MountIDScheme); err != nil {
+		fmt.Println("Configuración de IDs inválida, se usan los valores por defecto:", err)
+		stores.MountIDPrefix, stores.MountIDScheme = stores.Carnet, utils.MountIDLetter
+	}
+
+	// Caché de los discos montados: MIA_CACHE_MODE (off, writethrough por defecto, o writeback) y MIA_CACHE_PAGES
+	if mode := strings.ToLower(os.Getenv("MIA_CACHE_MODE")); mode != "" {
+		switch mode {
+		case structures.CacheOff, structures.CacheWriteThrough, structures.CacheWriteBack:
+			structures.CacheMode = mode
+		default:
+			fmt.Printf("Modo de caché inválido '%s', se usa %s\n", mode, structures.CacheMode)
+		}
+	}
+	if pages, err := strconv.Atoi(os.Getenv("MIA_CACHE_PAGES")); err == nil && pages > 0 {
+		structures.CachePages = pages
+	}
+
+	// Reconstruir la tabla de montajes a partir de las marcas en los MBR
+	for _, line := range commands.RestoreMountTable() {
+		fmt.Println("MOUNTS:", line)
+	}
+
 	app := fiber.New()
 
 	app.Use(cors.New(cors.Config{}))
@@ -66,7 +121,20 @@ func main() {
 		})
 	})
 
+	// Al detener el servidor, bajar al disco lo que siga en los cachés
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		fmt.Println("Deteniendo servidor...")
+		app.Shutdown()
+	}()
+
 	app.Listen(":3001")
+
+	for path, err := range structures.SyncAllDisks() {
+		fmt.Printf("Error sincronizando '%s': %v\n", path, err)
+	}
 }
 
 
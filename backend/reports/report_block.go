@@ -5,6 +5,7 @@ import (
 	utils "backend/utils"
 	"bytes"
 	"fmt"
+	"html"
 	"os"
 	"os/exec"
 	"strings"
@@ -24,19 +25,8 @@ func ReportBlock(superblock *structures.SuperBlock, diskPath string, path string
 	if inodeBitmapSize <= 0 {
 		return fmt.Errorf("s_inodes_count inválido: %d", inodeBitmapSize)
 	}
-	inodeBitmap := make([]byte, inodeBitmapSize)
-	file, err := os.Open(diskPath)
+	inodeBitmap, err := superblock.ReadInodeBitmap(diskPath)
 	if err != nil {
-		return fmt.Errorf("error al abrir disco para leer bitmap de inodos: %w", err)
-	}
-	_, err = file.Seek(int64(superblock.S_bm_inode_start), 0)
-	if err != nil {
-		file.Close()
-		return fmt.Errorf("error al buscar inicio de bitmap de inodos: %w", err)
-	}
-	bytesRead, err := file.Read(inodeBitmap)
-	file.Close()
-	if err != nil || int32(bytesRead) != inodeBitmapSize {
 		return fmt.Errorf("error al leer bitmap de inodos completo: %w", err)
 	}
 	// --- Fin Lectura Bitmap ---
@@ -60,13 +50,39 @@ func ReportBlock(superblock *structures.SuperBlock, diskPath string, path string
 		// Inodo 'i' está usado
 		inode := &structures.Inode{}
 		inodeOffset := int64(superblock.S_inode_start + (i * superblock.S_inode_size))
-		err := inode.Deserialize(diskPath, inodeOffset)
+		err := inode.Deserialize(superblock, diskPath, inodeOffset)
 		if err != nil {
 			fmt.Printf("Error deserializando inodo %d para reporte de bloques: %v. Saltando inodo.\n", i, err)
 			// Podríamos generar un nodo inodo de error si quisiéramos verlo
 			// dotContent += fmt.Sprintf("\tinode_err%d [label=\"Error Inodo %d\"];\n", i, i)
 			continue // Saltar al siguiente inodo
 		}
+		// Bloque de atributos extendidos (I_xattr), fuera de I_block
+		if xattrPtr := inode.I_xattr; xattrPtr >= 0 && xattrPtr < superblock.S_blocks_count && !generatedBlockNodes[xattrPtr] {
+			generatedBlockNodes[xattrPtr] = true
+			if attrs, err := superblock.ReadXattrs(diskPath, inode); err == nil {
+				var label strings.Builder
+				label.WriteString(`<table border="0" cellborder="1" cellspacing="0" cellpadding="4">`)
+				label.WriteString(fmt.Sprintf(`<tr><td colspan="2" bgcolor="plum"><b>Bloque Atributos %d</b></td></tr>`, xattrPtr))
+				label.WriteString(`<tr><td bgcolor="lightgreen"><b>Nombre</b></td><td bgcolor="lightgreen"><b>Valor</b></td></tr>`)
+				for _, attr := range attrs {
+					label.WriteString(fmt.Sprintf(`<tr><td>%s</td><td>%s</td></tr>`, html.EscapeString(attr.Name), html.EscapeString(attr.Value)))
+				}
+				label.WriteString(`</table>`)
+				if lastValidBlockIndex != -1 {
+					dotContent += fmt.Sprintf("\n\tblock%d -> block%d;", lastValidBlockIndex, xattrPtr)
+				}
+				dotContent += fmt.Sprintf("\n\tblock%d [label=<%s>];", xattrPtr, label.String())
+				lastValidBlockIndex = xattrPtr
+			} else {
+				fmt.Printf("Error leyendo Bloque Atributos %d: %v\n", xattrPtr, err)
+				dotContent += fmt.Sprintf("\n\tblock%d [label=\"Error Block %d\", shape=box, style=filled, fillcolor=red];", xattrPtr, xattrPtr)
+				lastValidBlockIndex = -1
+			}
+		}
+		if inode.InlineSymlink() {
+			continue // Enlace rápido: el destino está en I_block, no tiene bloques
+		}
 
 		// Iterar sobre los punteros de bloque del inodo actual 'i'
 		// Usamos 'k' para saber si es directo, indirecto, etc.
@@ -109,7 +125,7 @@ func ReportBlock(superblock *structures.SuperBlock, diskPath string, path string
 
 			if blockIsPointer {
 				// Bloque de Apuntadores (Simple, Doble, Triple)
-				block := &structures.PointerBlock{}
+				block := structures.NewPointerBlock(superblock.S_block_size)
 				err := block.Deserialize(diskPath, blockOffset)
 				if err == nil {
 					var label strings.Builder
@@ -140,19 +156,18 @@ func ReportBlock(superblock *structures.SuperBlock, diskPath string, path string
 				// Bloque de Datos (Carpeta o Archivo) - según inode.I_type
 				switch inode.I_type[0] {
 				case '0': // Carpeta
-					block := &structures.FolderBlock{}
-					err := block.Deserialize(diskPath, blockOffset)
+					block, err := superblock.ReadDirBlock(diskPath, blockPtr)
 					if err == nil {
 						var label strings.Builder
 						label.WriteString(`<table border="0" cellborder="1" cellspacing="0" cellpadding="4">`)
 						label.WriteString(fmt.Sprintf(`<tr><td colspan="2" bgcolor="lightcoral"><b>Bloque Carpeta %d</b></td></tr>`, blockPtr))
 						label.WriteString(`<tr><td bgcolor="lightgreen"><b>Nombre</b></td><td bgcolor="lightgreen"><b>Inodo Ptr</b></td></tr>`)
-						for _, content := range block.B_content {
-							name := string(bytes.TrimRight(content.B_name[:], "\x00"))
+						for _, entry := range block.Entries {
+							name := entry.Name
 							name = strings.ReplaceAll(name, "&", "&amp;")
 							name = strings.ReplaceAll(name, "<", "&lt;")
 							name = strings.ReplaceAll(name, ">", "&gt;")
-							label.WriteString(fmt.Sprintf(`<tr><td>%s</td><td>%d</td></tr>`, name, content.B_inodo))
+							label.WriteString(fmt.Sprintf(`<tr><td>%s</td><td>%d</td></tr>`, name, entry.Inode))
 						}
 						label.WriteString(`</table>`)
 						blockLabel = label.String()
@@ -161,8 +176,8 @@ func ReportBlock(superblock *structures.SuperBlock, diskPath string, path string
 						fmt.Printf("Error deserializando Bloque Carpeta %d: %v\n", blockPtr, err)
 					}
 
-				case '1': // Archivo
-					block := &structures.FileBlock{}
+				case '1', '2': // Archivo o destino de un enlace
+					block := structures.NewFileBlock(superblock.S_block_size)
 					err := block.Deserialize(diskPath, blockOffset)
 					if err == nil {
 						content := string(bytes.TrimRight(block.B_content[:], "\x00"))
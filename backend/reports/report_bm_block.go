@@ -17,37 +17,20 @@ func ReportBMBlock(superblock *structures.SuperBlock, diskPath string, path stri
 		return err
 	}
 
-	// Abrir el archivo de disco
-	file, err := os.Open(diskPath)
+	// Leer el bitmap como '0'/'1' por bloque (sirve para bitmaps de bytes y empaquetados)
+	bitmap, err := superblock.ReadBlockBitmap(diskPath)
 	if err != nil {
-		return fmt.Errorf("error al abrir el archivo de disco: %v", err)
+		return fmt.Errorf("error al leer el bitmap de bloques: %v", err)
 	}
-	defer file.Close()
-
-	//Calcular el número total de bloques
-	totalBlocks := superblock.S_blocks_count+superblock.S_free_blocks_count
 
 	// Obtener el contenido del bitmap de bloques
 	var bitmapContent strings.Builder
 
-	for i := int32(0); i < totalBlocks; i++ {
-		// Establecer el puntero
-		_, err := file.Seek(int64(superblock.S_bm_inode_start+i), 0)
-		if err != nil {
-			return fmt.Errorf("error al establecer el puntero en el archivo: %v", err)
-		}
-
-		// Leer un byte (carácter '0' o '1')
-		char := make([]byte, 1)
-		_, err = file.Read(char)
-		if err != nil {
-			return fmt.Errorf("error al leer el byte del archivo: %v", err)
-		}
-
+	for i, state := range bitmap {
 		// Agregar el carácter al contenido del bitmap
-		bitmapContent.WriteByte(char[0])
+		bitmapContent.WriteByte(state)
 
-		// Agregar un carácter de nueva línea cada 20 caracteres (20 inodos)
+		// Agregar un carácter de nueva línea cada 20 caracteres (20 bloques)
 		if (i+1)%20 == 0 {
 			bitmapContent.WriteString("\n")
 		}
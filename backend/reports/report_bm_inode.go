@@ -16,35 +16,18 @@ func ReportBMInode(superblock *structures.SuperBlock, diskPath string, path stri
 		return err
 	}
 
-	// Abrir el archivo de disco
-	file, err := os.Open(diskPath)
+	// Leer el bitmap como '0'/'1' por inodo (sirve para bitmaps de bytes y empaquetados)
+	bitmap, err := superblock.ReadInodeBitmap(diskPath)
 	if err != nil {
-		return fmt.Errorf("error al abrir el archivo de disco: %v", err)
+		return fmt.Errorf("error al leer el bitmap de inodos: %v", err)
 	}
-	defer file.Close()
-
-	// Calcular el número total de inodos
-	totalInodes := superblock.S_inodes_count + superblock.S_free_inodes_count
 
 	// Obtener el contenido del bitmap de inodos
 	var bitmapContent strings.Builder
 
-	for i := int32(0); i < totalInodes; i++ {
-		// Establecer el puntero
-		_, err := file.Seek(int64(superblock.S_bm_inode_start+i), 0)
-		if err != nil {
-			return fmt.Errorf("error al establecer el puntero en el archivo: %v", err)
-		}
-
-		// Leer un byte (carácter '0' o '1')
-		char := make([]byte, 1)
-		_, err = file.Read(char)
-		if err != nil {
-			return fmt.Errorf("error al leer el byte del archivo: %v", err)
-		}
-
+	for i, state := range bitmap {
 		// Agregar el carácter al contenido del bitmap
-		bitmapContent.WriteByte(char[0])
+		bitmapContent.WriteByte(state)
 
 		// Agregar un carácter de nueva línea cada 20 caracteres (20 inodos)
 		if (i+1)%20 == 0 {
@@ -45,7 +45,11 @@ func ReportDisk(mbr *structures.MBR, diskPath string, outputPath string) error {
 	dotContent += "\tnode [shape=none];\n"
 	dotContent += "\tgraph [splines=false];\n"
 	dotContent += "\tsubgraph cluster_disk {\n"
-	dotContent += fmt.Sprintf("\t\tlabel=\"Disco: %s (Tamaño Total: %d bytes)\";\n", name, totalSize) 
+	apparentSize, allocatedSize, err := utils.GetDiskUsage(diskPath)
+	if err != nil {
+		return err
+	}
+	dotContent += fmt.Sprintf("\t\tlabel=\"Disco: %s (Tamaño Total: %d bytes | Aparente: %d bytes | Reservado: %d bytes)\";\n", name, totalSize, apparentSize, allocatedSize) 
 	dotContent += "\t\tstyle=filled;\n"
 	dotContent += "\t\tfillcolor=white;\n"
 	dotContent += "\t\tcolor=black;\n"
@@ -55,7 +59,7 @@ func ReportDisk(mbr *structures.MBR, diskPath string, outputPath string) error {
 	dotContent += "\t\t\t<TR>\n"
 
 	// Calcular el tamaño del MBR para el offset inicial.
-	mbrStructSize := int32(binary.Size(*mbr)) // Tamaño real de la estructura MBR
+	mbrStructSize := mbr.DiskSize() // Tamaño real del MBR en disco (con o sin extensión)
 	dotContent += fmt.Sprintf("\t\t\t<TD BGCOLOR=\"gray\" ALIGN=\"CENTER\"><B>MBR</B><BR/>%d bytes</TD>\n", mbrStructSize)
 
 	lastOffset := int64(mbrStructSize) 
@@ -103,7 +107,7 @@ func ReportDisk(mbr *structures.MBR, diskPath string, outputPath string) error {
 			dotContent += fmt.Sprintf("\t\t\t\t<TR><TD COLSPAN=\"100\" ALIGN=\"CENTER\" BGCOLOR=\"orange\"><B>Extendida: %s (%d bytes)</B></TD></TR>\n", partName, part.Part_size) 
 			dotContent += "\t\t\t\t<TR>\n"
 
-			file, err := os.Open(diskPath)
+			file, err := structures.OpenDisk(diskPath)
 			if err != nil {
 				dotContent += fmt.Sprintf("\t\t\t\t<TD BGCOLOR=\"red\" ALIGN=\"CENTER\">Error abriendo disco: %v</TD>\n", err)
 			} else {
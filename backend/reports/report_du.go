@@ -0,0 +1,122 @@
+package reports
+
+import (
+	structures "backend/structures"
+	utils "backend/utils"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Uso de espacio de un archivo o carpeta, como du: una carpeta suma lo de su contenido
+type duEntry struct {
+	inode     int32
+	path      string
+	typ       byte
+	apparent  int64 // Suma de I_size
+	allocated int64 // Bytes de los bloques asignados (datos y punteros)
+	sparse    bool  // Archivo con huecos (asignado menor que el tamaño)
+}
+
+// ReportDu genera un reporte de texto con el tamaño aparente y el espacio asignado de cada
+// archivo y carpeta bajo dirPath ("/" si está vacío). Un archivo disperso ocupa menos de lo que
+// mide; uno con bloques de punteros puede ocupar más. Los enlaces duros se cuentan una sola vez.
+func ReportDu(superblock *structures.SuperBlock, diskPath string, path string, dirPath string) error {
+	err := utils.CreateParentDirs(path)
+	if err != nil {
+		return err
+	}
+	if dirPath == "" {
+		dirPath = "/"
+	}
+	startIndex, _, err := structures.FindInodeByPath(superblock, diskPath, dirPath)
+	if err != nil {
+		return fmt.Errorf("no se encontró '%s': %v", dirPath, err)
+	}
+	blockSize := int64(superblock.S_block_size)
+
+	entries := []duEntry{}
+	visited := make(map[int32]bool)
+	var walk func(inodeIndex int32, inodePath string) (int64, int64, error)
+	walk = func(inodeIndex int32, inodePath string) (int64, int64, error) {
+		if inodeIndex < 0 || inodeIndex >= superblock.S_inodes_count || visited[inodeIndex] {
+			return 0, 0, nil
+		}
+		visited[inodeIndex] = true
+
+		inode := &structures.Inode{}
+		if err := inode.Deserialize(superblock, diskPath, int64(superblock.S_inode_start)+int64(inodeIndex)*int64(superblock.S_inode_size)); err != nil {
+			return 0, 0, fmt.Errorf("error al leer el inodo %d: %v", inodeIndex, err)
+		}
+		data, all, err := superblock.InodeBlocks(diskPath, inode)
+		if err != nil {
+			return 0, 0, err
+		}
+		apparent, allocated := inode.I_size, int64(len(all))*blockSize
+		position := len(entries)
+		entries = append(entries, duEntry{inode: inodeIndex, path: inodePath, typ: inode.I_type[0]})
+		sparse := inode.I_type[0] == '1' && int64(len(data))*blockSize < inode.I_size
+
+		if inode.I_type[0] == '0' {
+			for _, blockIndex := range data {
+				dirBlock, err := superblock.ReadDirBlock(diskPath, blockIndex)
+				if err != nil {
+					return 0, 0, err
+				}
+				for _, entry := range dirBlock.Entries {
+					if entry.Name == "." || entry.Name == ".." || entry.Name == "" {
+						continue
+					}
+					childPath := strings.TrimSuffix(inodePath, "/") + "/" + entry.Name
+					childApparent, childAllocated, err := walk(entry.Inode, childPath)
+					if err != nil {
+						return 0, 0, err
+					}
+					apparent += childApparent
+					allocated += childAllocated
+				}
+			}
+		}
+		entries[position].apparent, entries[position].allocated, entries[position].sparse = apparent, allocated, sparse
+		return apparent, allocated, nil
+	}
+	totalApparent, totalAllocated, err := walk(startIndex, dirPath)
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].path < entries[j].path })
+
+	var sb strings.Builder
+	sb.WriteString("REPORTE DE USO DE ESPACIO (DU)\n")
+	fmt.Fprintf(&sb, "Carpeta: %s, tamaño de bloque: %d bytes\n", dirPath, blockSize)
+	fmt.Fprintf(&sb, "Total: %d bytes aparentes, %d bytes asignados\n", totalApparent, totalAllocated)
+	sb.WriteString("\nInodo\tTipo\tAparente\tAsignado\tRuta\n")
+	for _, entry := range entries {
+		typ := "archivo"
+		switch entry.typ {
+		case '0':
+			typ = "carpeta"
+		case '2':
+			typ = "enlace"
+		}
+		if entry.sparse {
+			typ = "disperso"
+		}
+		fmt.Fprintf(&sb, "%d\t%s\t%d\t%d\t%s\n", entry.inode, typ, entry.apparent, entry.allocated, entry.path)
+	}
+
+	// Crear el archivo TXT
+	txtFile, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error al crear el archivo TXT: %v", err)
+	}
+	defer txtFile.Close()
+
+	if _, err := txtFile.WriteString(sb.String()); err != nil {
+		return fmt.Errorf("error al escribir en el archivo TXT: %v", err)
+	}
+
+	fmt.Println("Archivo del reporte de uso de espacio generado:", path)
+	return nil
+}
@@ -4,6 +4,7 @@ package reports
 import (
 	"backend/structures"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -16,7 +17,7 @@ func ReportFile(superblock *structures.SuperBlock, diskPath string, outputPath s
 		filePath = "/" + filePath
 	}
 	// Buscar el inodo del archivo
-	_, inode, err := structures.FindInodeByPath(superblock, diskPath, filePath)
+	inodeIndex, inode, err := structures.FindInodeByPath(superblock, diskPath, filePath)
 	if err != nil {
 		return fmt.Errorf("error al buscar el inodo: %v", err)
 	}
@@ -27,10 +28,10 @@ func ReportFile(superblock *structures.SuperBlock, diskPath string, outputPath s
 	if inode.I_type[0] != '1' {
 		return fmt.Errorf("'%s' no es un archivo regular", filePath)
 	}
-	// Leer contenido del archivo
-	content, err := structures.ReadFileContent(superblock, diskPath, inode)
+	// Abrir el archivo: el contenido se copia por partes, sin cargarlo completo en memoria
+	file, err := superblock.OpenFile(diskPath, inodeIndex)
 	if err != nil {
-		return fmt.Errorf("error al leer el contenido: %v", err)
+		return fmt.Errorf("error al abrir el archivo: %v", err)
 	}
 	// Crear directorios de salida si no existen
 	dir := filepath.Dir(outputPath)
@@ -38,7 +39,12 @@ func ReportFile(superblock *structures.SuperBlock, diskPath string, outputPath s
 		return fmt.Errorf("error al crear directorios de salida: %v", err)
 	}
 	// Escribir el contenido en el archivo de reporte
-	if err := os.WriteFile(outputPath, []byte(content), 0644); err != nil {
+	out, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("error al crear el reporte: %v", err)
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, io.NewSectionReader(file, 0, file.Size())); err != nil {
 		return fmt.Errorf("error al escribir el reporte: %v", err)
 	}
 
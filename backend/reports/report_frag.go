@@ -0,0 +1,127 @@
+package reports
+
+import (
+	structures "backend/structures"
+	utils "backend/utils"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Fragmentación de un archivo o carpeta
+type fragEntry struct {
+	inode     int32
+	path      string
+	typ       byte
+	blocks    int // Bloques de datos y de punteros
+	fragments int // Tramos de bloques consecutivos
+}
+
+// ReportFrag genera un reporte de texto con la fragmentación del espacio libre y de cada archivo.
+func ReportFrag(superblock *structures.SuperBlock, diskPath string, path string) error {
+	err := utils.CreateParentDirs(path)
+	if err != nil {
+		return err
+	}
+
+	index, err := superblock.FreeIndex(diskPath)
+	if err != nil {
+		return fmt.Errorf("error al leer el espacio libre: %v", err)
+	}
+	extents := index.Blocks.Extents()
+	free := index.Blocks.Free()
+	largest := int32(0)
+	for _, ext := range extents {
+		if ext.Length > largest {
+			largest = ext.Length
+		}
+	}
+
+	// Recorrer el árbol desde la raíz para tener la ruta de cada inodo
+	entries := []fragEntry{}
+	visited := make(map[int32]bool)
+	var walk func(inodeIndex int32, inodePath string) error
+	walk = func(inodeIndex int32, inodePath string) error {
+		if inodeIndex < 0 || inodeIndex >= superblock.S_inodes_count || visited[inodeIndex] {
+			return nil
+		}
+		visited[inodeIndex] = true
+
+		inode := &structures.Inode{}
+		if err := inode.Deserialize(superblock, diskPath, int64(superblock.S_inode_start)+int64(inodeIndex)*int64(superblock.S_inode_size)); err != nil {
+			return fmt.Errorf("error al leer el inodo %d: %v", inodeIndex, err)
+		}
+		data, all, err := superblock.InodeBlocks(diskPath, inode)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, fragEntry{inode: inodeIndex, path: inodePath, typ: inode.I_type[0], blocks: len(all), fragments: structures.CountRuns(all)})
+
+		if inode.I_type[0] != '0' {
+			return nil
+		}
+		for _, blockIndex := range data {
+			dirBlock, err := superblock.ReadDirBlock(diskPath, blockIndex)
+			if err != nil {
+				return err
+			}
+			for _, entry := range dirBlock.Entries {
+				if entry.Name == "." || entry.Name == ".." || entry.Name == "" {
+					continue
+				}
+				childPath := strings.TrimSuffix(inodePath, "/") + "/" + entry.Name
+				if err := walk(entry.Inode, childPath); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(0, "/"); err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].path < entries[j].path })
+
+	fragmented, totalFragments := 0, 0
+	for _, entry := range entries {
+		totalFragments += entry.fragments
+		if entry.fragments > 1 {
+			fragmented++
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("REPORTE DE FRAGMENTACIÓN\n")
+	fmt.Fprintf(&sb, "Ajuste de la partición: %c\n", superblock.PartitionFit(diskPath))
+	fmt.Fprintf(&sb, "Bloques: %d total, %d libres en %d tramos (mayor tramo: %d)\n", superblock.S_blocks_count, free, len(extents), largest)
+	if free > 0 {
+		fmt.Fprintf(&sb, "Fragmentación del espacio libre: %.1f%%\n", 100*(1-float64(largest)/float64(free)))
+	}
+	if len(entries) > 0 {
+		fmt.Fprintf(&sb, "Archivos y carpetas: %d, fragmentados: %d (%.1f%%), tramos promedio: %.2f\n",
+			len(entries), fragmented, 100*float64(fragmented)/float64(len(entries)), float64(totalFragments)/float64(len(entries)))
+	}
+	sb.WriteString("\nInodo\tTipo\tBloques\tTramos\tRuta\n")
+	for _, entry := range entries {
+		typ := "archivo"
+		if entry.typ == '0' {
+			typ = "carpeta"
+		}
+		fmt.Fprintf(&sb, "%d\t%s\t%d\t%d\t%s\n", entry.inode, typ, entry.blocks, entry.fragments, entry.path)
+	}
+
+	// Crear el archivo TXT
+	txtFile, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error al crear el archivo TXT: %v", err)
+	}
+	defer txtFile.Close()
+
+	if _, err := txtFile.WriteString(sb.String()); err != nil {
+		return fmt.Errorf("error al escribir en el archivo TXT: %v", err)
+	}
+
+	fmt.Println("Archivo del reporte de fragmentación generado:", path)
+	return nil
+}
@@ -26,21 +26,9 @@ func ReportInode(superblock *structures.SuperBlock, diskPath string, path string
 		return fmt.Errorf("s_inodes_count (total) es inválido: %d", inodeBitmapSize)
 	}
 
-	inodeBitmap := make([]byte, inodeBitmapSize)
-	file, err := os.Open(diskPath)
+	inodeBitmap, err := superblock.ReadInodeBitmap(diskPath)
 	if err != nil {
-		return fmt.Errorf("error al abrir disco para leer bitmap de inodos: %w", err)
-	}
-	// Asegurarse de buscar desde el inicio del archivo para el offset del bitmap
-	_, err = file.Seek(int64(superblock.S_bm_inode_start), 0) // SEEK_SET = 0
-	if err != nil {
-		file.Close()
-		return fmt.Errorf("error al buscar inicio de bitmap de inodos: %w", err)
-	}
-	bytesRead, err := file.Read(inodeBitmap)
-	file.Close() // Cerrar el archivo después de leer
-	if err != nil || int32(bytesRead) != inodeBitmapSize {
-		return fmt.Errorf("error al leer bitmap de inodos completo (leídos %d, esperados %d): %w", bytesRead, inodeBitmapSize, err)
+		return fmt.Errorf("error al leer bitmap de inodos completo: %w", err)
 	}
 
 	// Iniciar el contenido DOT
@@ -63,7 +51,7 @@ func ReportInode(superblock *structures.SuperBlock, diskPath string, path string
 		inode := &structures.Inode{}
 		// Deserializar el inodo
 		inodeOffset := int64(superblock.S_inode_start + (currentIndex * superblock.S_inode_size))
-		err := inode.Deserialize(diskPath, inodeOffset)
+		err := inode.Deserialize(superblock, diskPath, inodeOffset)
 		if err != nil {
 			// Si está marcado como usado pero falla la deserialización, es un error del FS
 			fmt.Printf("Error deserializando inodo %d (marcado como usado): %v. Generando nodo de error.\n", currentIndex, err)
@@ -73,9 +61,9 @@ func ReportInode(superblock *structures.SuperBlock, diskPath string, path string
 		}
 
 		// Convertir tiempos a string
-		atime := time.Unix(int64(inode.I_atime), 0).Format(time.RFC3339)
-		ctime := time.Unix(int64(inode.I_ctime), 0).Format(time.RFC3339)
-		mtime := time.Unix(int64(inode.I_mtime), 0).Format(time.RFC3339)
+		atime := time.Unix(inode.I_atime, 0).Format(time.RFC3339)
+		ctime := time.Unix(inode.I_ctime, 0).Format(time.RFC3339)
+		mtime := time.Unix(inode.I_mtime, 0).Format(time.RFC3339)
 
 		// Definir el contenido DOT para el inodo actual
 		dotContent += fmt.Sprintf(`inode%d [label=<
@@ -89,8 +77,10 @@ func ReportInode(superblock *structures.SuperBlock, diskPath string, path string
         <tr><td bgcolor="lightgray"><b>i_mtime</b></td><td>%s</td></tr>
         <tr><td bgcolor="lightgray"><b>i_type</b></td><td>%c</td></tr>
         <tr><td bgcolor="lightgray"><b>i_perm</b></td><td>%s</td></tr>
+        <tr><td bgcolor="lightgray"><b>i_links</b></td><td>%d</td></tr>
+        <tr><td bgcolor="lightgray"><b>i_xattr</b></td><td>%d</td></tr>
         <tr><td colspan="2" bgcolor="lightgreen"><b>BLOQUES DIRECTOS</b></td></tr>
-            `, i, i, inode.I_uid, inode.I_gid, inode.I_size, atime, ctime, mtime, rune(inode.I_type[0]), string(inode.I_perm[:]))
+            `, i, i, inode.I_uid, inode.I_gid, inode.I_size, atime, ctime, mtime, rune(inode.I_type[0]), inode.Mode(), inode.I_links, inode.I_xattr)
 
 		// Agregar los bloques directos a la tabla hasta el índice 11
 		for j := 0; j < 15; j++ {
@@ -5,6 +5,7 @@ import (
 	utils "backend/utils"
 	"errors"
 	"fmt"
+	"html"
 	"os"
 	"os/exec"
 	"strconv"
@@ -57,6 +58,7 @@ func ReportLS(sb *structures.SuperBlock, diskPath string, outputPath string, tar
 	// 4.1. Fila de Encabezado
 	dotContent += "\t\t<TR>\n"
 	dotContent += "\t\t\t<TD BGCOLOR=\"lightgrey\"><B>Permisos</B></TD>\n"
+	dotContent += "\t\t\t<TD BGCOLOR=\"lightgrey\"><B>Links</B></TD>\n"
 	dotContent += "\t\t\t<TD BGCOLOR=\"lightgrey\"><B>Owner</B></TD>\n"
 	dotContent += "\t\t\t<TD BGCOLOR=\"lightgrey\"><B>Grupo</B></TD>\n"
 	dotContent += "\t\t\t<TD BGCOLOR=\"lightgrey\"><B>Size (Bytes)</B></TD>\n"
@@ -67,79 +69,76 @@ func ReportLS(sb *structures.SuperBlock, diskPath string, outputPath string, tar
 	dotContent += "\t\t</TR>\n"
 
 	// 5. Iterar sobre los bloques del directorio objetivo
-	inodeBlock := &structures.FolderBlock{} // Para reutilizar la estructura
 	entryInode := &structures.Inode{}       // Para reutilizar la estructura
 
-	for _, blockPtr := range targetInode.I_block {
-		if blockPtr == -1 {
-			continue // Puntero no usado
-		}
-		if blockPtr < 0 || blockPtr >= sb.S_blocks_count {
-			fmt.Printf("Advertencia: Puntero de bloque inválido (%d) encontrado en inodo %d.\n", blockPtr, targetInodeNum)
+	entries, err := sb.ReadDir(diskPath, targetInode)
+	if err != nil {
+		fmt.Printf("Advertencia: Error al leer el directorio (inodo %d): %v.\n", targetInodeNum, err)
+	}
+
+	// 6. Iterar sobre las entradas en uso del directorio (bloques directos e indirectos)
+	for _, entry := range entries {
+		if entry.Inode < 0 || entry.Inode >= sb.S_inodes_count {
+			fmt.Printf("Advertencia: Puntero de inodo inválido (%d) encontrado en inodo %d.\n", entry.Inode, targetInodeNum)
 			continue
 		}
 
-		blockOffset := int64(sb.S_block_start) + int64(blockPtr)*int64(sb.S_block_size)
-		err := inodeBlock.Deserialize(diskPath, blockOffset)
+		entryName := entry.Name
+		if entryName == "." || entryName == ".." {
+			continue // Omitir entradas '.' y '..' según el formato ls típico
+		}
+
+		// 7. Obtener el inodo de la entrada
+		entryInodeOffset := int64(sb.S_inode_start) + int64(entry.Inode)*int64(sb.S_inode_size)
+		err := entryInode.Deserialize(sb, diskPath, entryInodeOffset)
 		if err != nil {
-			fmt.Printf("Advertencia: Error al leer bloque de directorio %d: %v. Saltando bloque.\n", blockPtr, err)
+			fmt.Printf("Advertencia: Error al leer inodo %d para '%s': %v. Saltando entrada.\n", entry.Inode, entryName, err)
 			continue
 		}
 
-		// 6. Iterar sobre las entradas (FolderContent) dentro de cada bloque
-		for _, entry := range inodeBlock.B_content {
-			if entry.B_inodo == -1 {
-				continue // Entrada no usada
-			}
-			if entry.B_inodo < 0 || entry.B_inodo >= sb.S_inodes_count {
-				fmt.Printf("Advertencia: Puntero de inodo inválido (%d) encontrado en bloque %d.\n", entry.B_inodo, blockPtr)
-				continue
-			}
-
-			entryName := strings.TrimRight(string(entry.B_name[:]), "\x00")
-			if entryName == "." || entryName == ".." {
-				continue // Omitir entradas '.' y '..' según el formato ls típico
-			}
-
-			// 7. Obtener el inodo de la entrada
-			entryInodeOffset := int64(sb.S_inode_start) + int64(entry.B_inodo)*int64(sb.S_inode_size)
-			err := entryInode.Deserialize(diskPath, entryInodeOffset)
+		// 8. Extraer y formatear datos para la fila de la tabla
+		permisos := formatPermissions(entryInode.I_perm, entryInode.I_special, entryInode.I_type[0])
+		if acl, err := sb.ReadACL(diskPath, entryInode, structures.XattrACLAccess); err == nil && len(acl) > 0 {
+			permisos += "+" // Tiene ACL, como ls -l
+		}
+		ownerName, ok := uidMap[entryInode.I_uid]
+		if !ok {
+			ownerName = fmt.Sprintf("%d", entryInode.I_uid) // Mostrar ID si no se encuentra el nombre
+		}
+		groupName, ok := gidMap[entryInode.I_gid]
+		if !ok {
+			groupName = fmt.Sprintf("%d", entryInode.I_gid) // Mostrar ID si no se encuentra el nombre
+		}
+		size := entryInode.I_size
+		modTime := time.Unix(entryInode.I_mtime, 0)
+		fechaMod := modTime.Format("02/01/2006") // Formato DD/MM/YYYY
+		horaMod := modTime.Format("15:04")       // Formato HH:MM (24h)
+		tipo := "Archivo"
+		if entryInode.I_type[0] == '0' {
+			tipo = "Carpeta"
+		}
+		displayName := html.EscapeString(entryName)
+		if entryInode.IsSymlink() {
+			tipo = "Enlace"
+			target, err := structures.ReadSymlink(sb, diskPath, entryInode)
 			if err != nil {
-				fmt.Printf("Advertencia: Error al leer inodo %d para '%s': %v. Saltando entrada.\n", entry.B_inodo, entryName, err)
-				continue
+				target = "?"
 			}
-
-			// 8. Extraer y formatear datos para la fila de la tabla
-			permisos := formatPermissions(entryInode.I_perm, entryInode.I_type[0])
-			ownerName, ok := uidMap[entryInode.I_uid]
-			if !ok {
-				ownerName = fmt.Sprintf("%d", entryInode.I_uid) // Mostrar ID si no se encuentra el nombre
-			}
-			groupName, ok := gidMap[entryInode.I_gid]
-			if !ok {
-				groupName = fmt.Sprintf("%d", entryInode.I_gid) // Mostrar ID si no se encuentra el nombre
-			}
-			size := entryInode.I_size
-			modTime := time.Unix(int64(entryInode.I_mtime), 0)
-			fechaMod := modTime.Format("02/01/2006") // Formato DD/MM/YYYY
-			horaMod := modTime.Format("15:04")       // Formato HH:MM (24h)
-			tipo := "Archivo"
-			if entryInode.I_type[0] == '0' {
-				tipo = "Carpeta"
-			}
-
-			// 9. Añadir la fila a dotContent
-			dotContent += "\t\t<TR>\n"
-			dotContent += fmt.Sprintf("\t\t\t<TD>%s</TD>\n", permisos)
-			dotContent += fmt.Sprintf("\t\t\t<TD>%s</TD>\n", ownerName)
-			dotContent += fmt.Sprintf("\t\t\t<TD>%s</TD>\n", groupName)
-			dotContent += fmt.Sprintf("\t\t\t<TD ALIGN=\"RIGHT\">%d</TD>\n", size) // Alinear tamaño a la derecha
-			dotContent += fmt.Sprintf("\t\t\t<TD>%s</TD>\n", fechaMod)
-			dotContent += fmt.Sprintf("\t\t\t<TD>%s</TD>\n", horaMod)
-			dotContent += fmt.Sprintf("\t\t\t<TD>%s</TD>\n", tipo)
-			dotContent += fmt.Sprintf("\t\t\t<TD>%s</TD>\n", entryName)
-			dotContent += "\t\t</TR>\n"
+			displayName += " -&gt; " + html.EscapeString(target)
 		}
+
+		// 9. Añadir la fila a dotContent
+		dotContent += "\t\t<TR>\n"
+		dotContent += fmt.Sprintf("\t\t\t<TD>%s</TD>\n", permisos)
+		dotContent += fmt.Sprintf("\t\t\t<TD ALIGN=\"RIGHT\">%d</TD>\n", entryInode.I_links) // Enlaces duros, como ls -l
+		dotContent += fmt.Sprintf("\t\t\t<TD>%s</TD>\n", ownerName)
+		dotContent += fmt.Sprintf("\t\t\t<TD>%s</TD>\n", groupName)
+		dotContent += fmt.Sprintf("\t\t\t<TD ALIGN=\"RIGHT\">%d</TD>\n", size) // Alinear tamaño a la derecha
+		dotContent += fmt.Sprintf("\t\t\t<TD>%s</TD>\n", fechaMod)
+		dotContent += fmt.Sprintf("\t\t\t<TD>%s</TD>\n", horaMod)
+		dotContent += fmt.Sprintf("\t\t\t<TD>%s</TD>\n", tipo)
+		dotContent += fmt.Sprintf("\t\t\t<TD>%s</TD>\n", displayName)
+		dotContent += "\t\t</TR>\n"
 	}
 
 	// 10. Cerrar la tabla y el grafo DOT
@@ -176,16 +175,21 @@ func ReportLS(sb *structures.SuperBlock, diskPath string, outputPath string, tar
 
 // --- Funciones Auxiliares (Podrían ir en report_utils.go) ---
 
-// formatPermissions convierte los permisos numéricos y el tipo de archivo a formato string (ej: -rwxrwxrwx)
-func formatPermissions(perm [3]byte, fileType byte) string {
+// formatPermissions convierte los permisos numéricos y el tipo de archivo a formato string (ej: -rwxrwxrwx).
+// Los bits especiales reemplazan la x como en ls: s/S para setuid y setgid, t/T para sticky (mayúscula
+// si no hay permiso de ejecución).
+func formatPermissions(perm [3]byte, special byte, fileType byte) string {
 	permStr := ""
 	if fileType == '0' {
 		permStr += "d" // Directorio
+	} else if fileType == '2' {
+		permStr += "l" // Enlace simbólico
 	} else {
 		permStr += "-" // Archivo
 	}
 
-	for _, p := range perm {
+	specialChars := [3]string{"s", "s", "t"} // setuid (dueño), setgid (grupo), sticky (otros)
+	for i, p := range perm {
 		digit, err := strconv.Atoi(string(p))
 		if err != nil {
 			// Error improbable si los permisos son siempre '0'-'7'
@@ -204,8 +208,13 @@ func formatPermissions(perm [3]byte, fileType byte) string {
 		} else {
 			permStr += "-"
 		}
-		// Bit 1: Ejecución (x)
-		if (digit & 1) != 0 {
+		// Bit 1: Ejecución (x), o el bit especial de esta posición
+		hasSpecial := special&(4>>i) != 0
+		if hasSpecial && (digit&1) != 0 {
+			permStr += specialChars[i]
+		} else if hasSpecial {
+			permStr += strings.ToUpper(specialChars[i])
+		} else if (digit & 1) != 0 {
 			permStr += "x"
 		} else {
 			permStr += "-"
@@ -267,7 +276,7 @@ func getUserGroupNameMaps(sb *structures.SuperBlock, diskPath string) (map[int32
 	// Asumimos que users.txt está en el inodo 1 (según tu CreateUsersFile)
 	usersInode := &structures.Inode{}
 	usersInodeOffset := int64(sb.S_inode_start) + 1*int64(sb.S_inode_size) // Offset del inodo 1
-	err := usersInode.Deserialize(diskPath, usersInodeOffset)
+	err := usersInode.Deserialize(sb, diskPath, usersInodeOffset)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error al leer inodo de users.txt: %v", err)
 	}
@@ -31,7 +31,15 @@ func ReportMBR(mbr *structures.MBR, diskPath string, outputPath string) error {
             <tr><td bgcolor="lightgray"><b>mbr_tamano</b></td><td>%d</td></tr>
             <tr><td bgcolor="lightgray"><b>mbr_fecha_creacion</b></td><td>%s</td></tr>
             <tr><td bgcolor="lightgray"><b>mbr_disk_signature</b></td><td>%d</td></tr>
-        `, mbr.Mbr_size, time.Unix(int64(mbr.Mbr_creation_date), 0), mbr.Mbr_disk_signature)
+        `, mbr.Mbr_size, time.Unix(mbr.Mbr_creation_date, 0), mbr.Mbr_disk_signature)
+
+	// Revisión y características del formato del disco
+	dotContent += fmt.Sprintf(`<tr><td bgcolor="lightgray"><b>mbr_revision</b></td><td>%d</td></tr>
+        `, mbr.Revision())
+	if mbr.HasMetadata() {
+		dotContent += fmt.Sprintf(`<tr><td bgcolor="lightgray"><b>mbr_features</b></td><td>compat 0x%X, ro_compat 0x%X, incompat 0x%X</td></tr>
+        `, mbr.Mbr_feature_compat, mbr.Mbr_feature_ro_compat, mbr.Mbr_feature_incompat)
+	}
 
 	// Iterar sobre las particiones
 	for i, part := range mbr.Mbr_partitions {
@@ -73,7 +81,7 @@ func ReportMBR(mbr *structures.MBR, diskPath string, outputPath string) error {
 			dotContent += `<tr><td colspan="2" bgcolor="lightgreen"><b> Particiones Lógicas </b></td></tr>`
 
 			// Abrir el archivo para leer los EBRs
-			file, err := os.Open(diskPath)
+			file, err := structures.OpenDisk(diskPath)
 			if err != nil {
 				return fmt.Errorf("error abriendo el archivo del disco: %v", err)
 			}
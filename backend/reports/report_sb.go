@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 	"time"
 )
 
@@ -43,10 +44,47 @@ func ReportSuperBlock(superblock *structures.SuperBlock, diskPath string, output
 			<tr><td bgcolor="lightblue"><b>s_inode_start</b></td><td>%d</td></tr>
 			<tr><td bgcolor="lightgreen"><b>s_block_start</b></td><td>%d</td></tr>
 		`, superblock.S_inodes_count, superblock.S_blocks_count, superblock.S_free_inodes_count,
-		superblock.S_free_blocks_count, time.Unix(int64(superblock.S_mtime), 0), time.Unix(int64(superblock.S_umtime), 0),
+		superblock.S_free_blocks_count, time.Unix(superblock.S_mtime, 0), time.Unix(superblock.S_umtime, 0),
 		superblock.S_mnt_count, superblock.S_magic, superblock.S_inode_size, superblock.S_block_size, superblock.S_first_ino,
 		superblock.S_first_blo, superblock.S_bm_inode_start, superblock.S_bm_block_start, superblock.S_inode_start, superblock.S_block_start)
 
+	// Campos de la extensión del formato (no existen en imágenes antiguas)
+	if superblock.HasExtension() {
+		bitmapFormat := "ascii"
+		if superblock.PackedBitmaps() {
+			bitmapFormat = "packed"
+		}
+		inodeRatio := "3 bloques por inodo"
+		if superblock.S_inode_ratio > 0 {
+			inodeRatio = fmt.Sprintf("%d bytes por inodo", superblock.S_inode_ratio)
+		}
+		direntFormat := "classic"
+		if superblock.LongNames() {
+			direntFormat = "long"
+		}
+		sizeBits := 32
+		if superblock.LargeFiles() {
+			sizeBits = 64
+		}
+		timeFormat := "float32 (redondeadas a ~2 minutos)"
+		if superblock.WideTimes() {
+			timeFormat = "int64 (segundos exactos)"
+		}
+		dotContent += fmt.Sprintf(`<tr><td bgcolor="lightgray"><b>s_rev_level</b></td><td>%d</td></tr>
+			<tr><td bgcolor="lightgray"><b>s_feature_compat</b></td><td>0x%X %s</td></tr>
+			<tr><td bgcolor="lightgray"><b>s_feature_ro_compat</b></td><td>0x%X %s</td></tr>
+			<tr><td bgcolor="lightgray"><b>s_feature_incompat</b></td><td>0x%X %s</td></tr>
+			<tr><td bgcolor="lightgray"><b>bitmaps</b></td><td>%s</td></tr>
+			<tr><td bgcolor="lightgray"><b>s_inode_ratio</b></td><td>%s</td></tr>
+			<tr><td bgcolor="lightgray"><b>dirent</b></td><td>%s (nombres de hasta %d bytes)</td></tr>
+			<tr><td bgcolor="lightgray"><b>i_size</b></td><td>%d bits (archivos de hasta %d bytes)</td></tr>
+			<tr><td bgcolor="lightgray"><b>marcas de tiempo</b></td><td>%s</td></tr>
+		`, superblock.Revision(),
+			superblock.S_feature_compat, strings.Join(superblock.FeatureNames(structures.FeatureKindCompat), ", "),
+			superblock.S_feature_ro_compat, strings.Join(superblock.FeatureNames(structures.FeatureKindRoCompat), ", "),
+			superblock.S_feature_incompat, strings.Join(superblock.FeatureNames(structures.FeatureKindIncompat), ", "), bitmapFormat, inodeRatio, direntFormat, superblock.MaxNameLen(), sizeBits, superblock.MaxFileSize(), timeFormat)
+	}
+
 	// Cerrar la tabla y el contenido DOT
 	dotContent += "</table>>] }"
 
@@ -2,6 +2,7 @@ package reports
 
 import (
 	"fmt"
+	"html"
 	"os"
 	"os/exec"
 	"strings"
@@ -83,7 +84,8 @@ func generateTreeRecursive(
 	generatedNodes[inodeNodeID] = true
 	inode := &structures.Inode{}
 	inodeOffset := int64(sb.S_inode_start) + int64(inodeIndex)*int64(sb.S_inode_size)
-	if err := inode.Deserialize(diskPath, inodeOffset); err != nil {
+	err := inode.Deserialize(sb, diskPath, inodeOffset)
+	if err != nil {
 		fmt.Printf("Error deserializando inodo %d: %v. Saltando.\n", inodeIndex, err)
 
 		// Solo coloco un mensaje de error y un nodo de error en el DOT y sigo
@@ -92,8 +94,17 @@ func generateTreeRecursive(
 	}
 
 	// 3. Generate DOT node for the Inode
-	inodeLabel := createInodeLabel(inodeIndex, inode)
+	target := ""
+	if inode.IsSymlink() {
+		if target, err = structures.ReadSymlink(sb, diskPath, inode); err != nil {
+			target = "?"
+		}
+	}
+	inodeLabel := createInodeLabel(inodeIndex, inode, target)
 	dotContent.WriteString(fmt.Sprintf("\t%s [label=<\n%s\n>];\n", inodeNodeID, inodeLabel))
+	if inode.InlineSymlink() {
+		return nil // El destino está en I_block, no hay bloques
+	}
 
 	// 4. Process Inode pointers (I_block)
 	for k := 0; k < 15; k++ {
@@ -121,18 +132,18 @@ func generateTreeRecursive(
 			switch {
 			case k < 12: // Bloques directos porque no tengo indirectos
 				if inode.I_type[0] == '0' { // Folder Block
-					folderBlock := &structures.FolderBlock{}
-					if err := folderBlock.Deserialize(diskPath, blockOffset); err != nil {
+					folderBlock, err := sb.ReadDirBlock(diskPath, blockPtr)
+					if err != nil {
 						fmt.Printf("Error deserializando FolderBlock %d: %v\n", blockPtr, err)
 						dotContent.WriteString(fmt.Sprintf("\t%s [label=\"Error FolderBlock %d\", shape=box, style=filled, fillcolor=red];\n", blockNodeID, blockPtr))
 					} else {
 						label := createFolderBlockLabel(blockPtr, folderBlock)
 						dotContent.WriteString(fmt.Sprintf("\t%s [label=<\n%s\n>];\n", blockNodeID, label))
 						//Recursividad para procesar los hijos del bloque de carpeta
-						for entryIdx, content := range folderBlock.B_content {
-							name := strings.TrimRight(string(content.B_name[:]), "\x00")
-							if content.B_inodo != -1 && name != "." && name != ".." {
-								childInodeIndex := content.B_inodo
+						for entryIdx, entry := range folderBlock.Entries {
+							name := entry.Name
+							if name != "." && name != ".." {
+								childInodeIndex := entry.Inode
 								childInodeNodeID := fmt.Sprintf("inode_%d", childInodeIndex)
 								folderPort := fmt.Sprintf("i%d", entryIdx)
 								entryEdgeID := fmt.Sprintf("%s:%s -> %s", blockNodeID, folderPort, childInodeNodeID)
@@ -150,7 +161,7 @@ func generateTreeRecursive(
 
 					}
 				} else { // File Block
-					fileBlock := &structures.FileBlock{}
+					fileBlock := structures.NewFileBlock(sb.S_block_size)
 					if err := fileBlock.Deserialize(diskPath, blockOffset); err != nil {
 						fmt.Printf("Error deserializando FileBlock %d: %v\n", blockPtr, err)
 						dotContent.WriteString(fmt.Sprintf("\t%s [label=\"Error FileBlock %d\", shape=box, style=filled, fillcolor=red];\n", blockNodeID, blockPtr))
@@ -161,7 +172,7 @@ func generateTreeRecursive(
 				}
 				// Bloques directos terminan aquí------------------------------------------------------------------------------------------------------------
 			case k == 12:
-				pointerBlock := &structures.PointerBlock{}
+				pointerBlock := structures.NewPointerBlock(sb.S_block_size)
 				if err := pointerBlock.Deserialize(diskPath, blockOffset); err != nil {
 					fmt.Printf("Error deserializando PointerBlock %d (indirecto simple): %v\n", blockPtr, err)
 					dotContent.WriteString(fmt.Sprintf("\t%s [label=\"Error PointerBlock %d\", shape=box, style=filled, fillcolor=red];\n", blockNodeID, blockPtr))
@@ -223,8 +234,8 @@ func ensureBlockNodeExists(
 
 	// Genera el bloque del Inodo
 	if originalInodeType == '0' { // Folder Block
-		folderBlock := &structures.FolderBlock{}
-		if err := folderBlock.Deserialize(diskPath, blockOffset); err != nil {
+		folderBlock, err := sb.ReadDirBlock(diskPath, blockIndex)
+		if err != nil {
 			fmt.Printf("Error deserializando FolderBlock %d (indirecto): %v\n", blockIndex, err)
 			dotContent.WriteString(fmt.Sprintf("\t%s [label=\"Error FolderBlock %d\", shape=box, style=filled, fillcolor=red];\n", blockNodeID, blockIndex))
 			return err
@@ -233,15 +244,15 @@ func ensureBlockNodeExists(
 		dotContent.WriteString(fmt.Sprintf("\t%s [label=<\n%s\n>];\n", blockNodeID, label))
 
 		// Si es un bloque de carpeta, procesar sus entradas
-		for entryIdx, content := range folderBlock.B_content {
-			if content.B_inodo != -1 && string(content.B_name[:]) != "." && string(content.B_name[:]) != ".." {
-				childInodeIndex := content.B_inodo
+		for entryIdx, entry := range folderBlock.Entries {
+			if entry.Name != "." && entry.Name != ".." {
+				childInodeIndex := entry.Inode
 				childInodeNodeID := fmt.Sprintf("inode_%d", childInodeIndex)
 				folderPort := fmt.Sprintf("i%d", entryIdx)
 				entryEdgeID := fmt.Sprintf("%s:%s -> %s", blockNodeID, folderPort, childInodeNodeID)
 
 				if !generatedEdges[entryEdgeID] {
-					entryName := strings.TrimRight(string(content.B_name[:]), "\x00")
+					entryName := entry.Name
 					dotContent.WriteString(fmt.Sprintf("\t%s:%s -> %s [label=\"%s\"];\n", blockNodeID, folderPort, childInodeNodeID, entryName))
 					generatedEdges[entryEdgeID] = true
 				}
@@ -254,7 +265,7 @@ func ensureBlockNodeExists(
 		}
 
 	} else { // File Block
-		fileBlock := &structures.FileBlock{}
+		fileBlock := structures.NewFileBlock(sb.S_block_size)
 		if err := fileBlock.Deserialize(diskPath, blockOffset); err != nil {
 			fmt.Printf("Error deserializando FileBlock %d (indirecto): %v\n", blockIndex, err)
 			dotContent.WriteString(fmt.Sprintf("\t%s [label=\"Error FileBlock %d\", shape=box, style=filled, fillcolor=red];\n", blockNodeID, blockIndex))
@@ -266,26 +277,31 @@ func ensureBlockNodeExists(
 	return nil
 }
 
-// Genera la etiqueta HTML para el inodo
-func createInodeLabel(index int32, inode *structures.Inode) string {
+// Genera la etiqueta HTML para el inodo; target es el destino si el inodo es un enlace
+func createInodeLabel(index int32, inode *structures.Inode, target string) string {
 	var label strings.Builder
 	label.WriteString("<TABLE BORDER=\"0\" CELLBORDER=\"1\" CELLSPACING=\"0\" CELLPADDING=\"4\">\n")
 	label.WriteString(fmt.Sprintf("<TR><TD COLSPAN=\"2\" BGCOLOR=\"lightblue\"><B>Inodo %d</B></TD></TR>\n", index))
 	label.WriteString(fmt.Sprintf("<TR><TD ALIGN=\"LEFT\">I_UID</TD><TD ALIGN=\"LEFT\">%d</TD></TR>\n", inode.I_uid))
 	label.WriteString(fmt.Sprintf("<TR><TD ALIGN=\"LEFT\">I_GID</TD><TD ALIGN=\"LEFT\">%d</TD></TR>\n", inode.I_gid))
 	label.WriteString(fmt.Sprintf("<TR><TD ALIGN=\"LEFT\">I_SIZE</TD><TD ALIGN=\"LEFT\">%d</TD></TR>\n", inode.I_size))
-	atime := time.Unix(int64(inode.I_atime), 0).Format("2006-01-02 15:04:05")
-	ctime := time.Unix(int64(inode.I_ctime), 0).Format("2006-01-02 15:04:05")
-	mtime := time.Unix(int64(inode.I_mtime), 0).Format("2006-01-02 15:04:05")
+	atime := time.Unix(inode.I_atime, 0).Format("2006-01-02 15:04:05")
+	ctime := time.Unix(inode.I_ctime, 0).Format("2006-01-02 15:04:05")
+	mtime := time.Unix(inode.I_mtime, 0).Format("2006-01-02 15:04:05")
 	label.WriteString(fmt.Sprintf("<TR><TD ALIGN=\"LEFT\">I_ATIME</TD><TD ALIGN=\"LEFT\">%s</TD></TR>\n", atime))
 	label.WriteString(fmt.Sprintf("<TR><TD ALIGN=\"LEFT\">I_CTIME</TD><TD ALIGN=\"LEFT\">%s</TD></TR>\n", ctime))
 	label.WriteString(fmt.Sprintf("<TR><TD ALIGN=\"LEFT\">I_MTIME</TD><TD ALIGN=\"LEFT\">%s</TD></TR>\n", mtime))
 	typeStr := "Archivo ('1')"
 	if inode.I_type[0] == '0' {
 		typeStr = "Directorio ('0')"
+	} else if inode.IsSymlink() {
+		typeStr = "Enlace ('2')"
 	}
 	label.WriteString(fmt.Sprintf("<TR><TD ALIGN=\"LEFT\">I_TYPE</TD><TD ALIGN=\"LEFT\">%s</TD></TR>\n", typeStr))
-	label.WriteString(fmt.Sprintf("<TR><TD ALIGN=\"LEFT\">I_PERM</TD><TD ALIGN=\"LEFT\">%s</TD></TR>\n", string(inode.I_perm[:])))
+	if inode.IsSymlink() {
+		label.WriteString(fmt.Sprintf("<TR><TD ALIGN=\"LEFT\">DESTINO</TD><TD ALIGN=\"LEFT\">%s</TD></TR>\n", html.EscapeString(target)))
+	}
+	label.WriteString(fmt.Sprintf("<TR><TD ALIGN=\"LEFT\">I_PERM</TD><TD ALIGN=\"LEFT\">%s</TD></TR>\n", inode.Mode()))
 	for i := 0; i < 15; i++ {
 		label.WriteString(fmt.Sprintf("<TR><TD ALIGN=\"LEFT\" PORT=\"p%d\">I_BLOCK[%d]</TD><TD ALIGN=\"LEFT\">%d</TD></TR>\n", i, i, inode.I_block[i]))
 	}
@@ -294,14 +310,13 @@ func createInodeLabel(index int32, inode *structures.Inode) string {
 }
 
 // Genera la etiqueta HTML para el bloque de carpeta
-func createFolderBlockLabel(index int32, block *structures.FolderBlock) string {
+func createFolderBlockLabel(index int32, block *structures.DirBlock) string {
 	var label strings.Builder
 	label.WriteString("<TABLE BORDER=\"0\" CELLBORDER=\"1\" CELLSPACING=\"0\" CELLPADDING=\"4\">\n")
 	label.WriteString(fmt.Sprintf("<TR><TD COLSPAN=\"3\" BGCOLOR=\"lightcoral\"><B>FolderBlock %d</B></TD></TR>\n", index))
 	label.WriteString("<TR><TD><B>Index</B></TD><TD><B>Name</B></TD><TD><B>Inode Ptr</B></TD></TR>\n")
-	for i, content := range block.B_content {
-		name := strings.TrimRight(string(content.B_name[:]), "\x00")
-		label.WriteString(fmt.Sprintf("<TR><TD>%d</TD><TD PORT=\"i%d\">%s</TD><TD>%d</TD></TR>\n", i, i, name, content.B_inodo))
+	for i, entry := range block.Entries {
+		label.WriteString(fmt.Sprintf("<TR><TD>%d</TD><TD PORT=\"i%d\">%s</TD><TD>%d</TD></TR>\n", i, i, entry.Name, entry.Inode))
 	}
 	label.WriteString("</TABLE>")
 	return label.String()
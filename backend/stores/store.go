@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // Carnet de estudiante
@@ -19,13 +20,51 @@ var (
 	// Mapa para discos creados
 	DiskRegistry map[string]string = make(map[string]string) 
 
+	// Directorios donde se buscan discos al iniciar y con scandisks
+	DiskSearchDirs []string = []string{"disks"}
+
+	// Papelera: si RecycleDir no está vacío, rmdisk mueve ahí los discos en lugar de borrarlos
+	RecycleDir       string        = ""
+	RecycleRetention time.Duration = 7 * 24 * time.Hour // Tiempo que se conserva un disco en la papelera
+
 	ListPatitions []string = make([]string, 0) // Guarda NOMBRES de particiones montadas
 	ListMounted   []string = make([]string, 0) // Guarda IDs de particiones montadas
+
+	// Formato de los IDs de montaje (ver utils.GenerateMountID)
+	MountIDPrefix string = Carnet   // Prefijo de todos los IDs
+	MountIDScheme string = "letter" // "letter", "number" o "stable"
+
+	// Etiquetas asignadas con mount -label (etiqueta -> ID)
+	MountLabels map[string]string = make(map[string]string)
 )
 
+// ResolveMountID traduce una referencia a partición montada a su ID. Acepta el ID, una
+// etiqueta de mount -label o el nombre de la partición. Si no encuentra nada devuelve ref.
+func ResolveMountID(ref string) string {
+	if _, ok := MountedPartitions[ref]; ok {
+		return ref
+	}
+	if id, ok := MountLabels[ref]; ok {
+		return id
+	}
+	// mount no permite dos particiones montadas con el mismo nombre
+	for id, path := range MountedPartitions {
+		var mbr structures.MBR
+		if err := mbr.Deserialize(path); err != nil {
+			continue
+		}
+		part, err := mbr.GetPartitionByID(id)
+		if err == nil && strings.TrimRight(string(part.Part_name[:]), "\x00 ") == ref {
+			return id
+		}
+	}
+	return ref
+}
+
 // GetMountedPartition obtiene la partición montada con el id especificado
 func GetMountedPartition(id string) (*structures.Partition, string, error) {
 	// Obtener el path de la partición montada
+	id = ResolveMountID(id)
 	path := MountedPartitions[id]
 	if path == "" {
 		return nil, "", errors.New("la partición no está montada")
@@ -52,6 +91,7 @@ func GetMountedPartition(id string) (*structures.Partition, string, error) {
 // GetMountedMBR obtiene el MBR de la partición montada con el id especificado
 func GetMountedPartitionRep(id string) (*structures.MBR, *structures.SuperBlock, string, error) {
 	// Obtener el path de la partición montada
+	id = ResolveMountID(id)
 	path := MountedPartitions[id]
 	if path == "" {
 		return nil, nil, "", errors.New("la partición no está montada")
@@ -87,6 +127,7 @@ func GetMountedPartitionRep(id string) (*structures.MBR, *structures.SuperBlock,
 // GetMountedPartitionSuperblock obtiene el SuperBlock de la partición montada con el id especificado
 func GetMountedPartitionSuperblock(id string) (*structures.SuperBlock, *structures.Partition, string, error) {
 	// Obtener el path de la partición montada
+	id = ResolveMountID(id)
 	path := MountedPartitions[id]
 	if path == "" {
 		return nil, nil, "", errors.New("la partición no está montada")
@@ -143,6 +184,12 @@ func (a *AuthStore) Login(username, password, partitionID string) {
 }
 
 func (a *AuthStore) Logout() {
+	// Bajar al disco los cambios de la sesión que sigan en el caché
+	if path, ok := MountedPartitions[a.PartitionID]; ok {
+		if err := structures.SyncDisk(path); err != nil {
+			fmt.Println("Advertencia:", err)
+		}
+	}
 	a.IsLoggedIn = false
 	a.Username = ""
 	a.Password = ""
@@ -162,6 +209,7 @@ func (a *AuthStore) GetPartitionID() string {
 }
 
 func GetMountedPartitionInfo(id string) (*structures.MBR, *structures.Partition, string, error) {
+	id = ResolveMountID(id)
 	path := MountedPartitions[id]
 	if path == "" {
 		return nil, nil, "", fmt.Errorf("partición con id '%s' no está montada", id)
@@ -0,0 +1,238 @@
+package structures
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Listas de control de acceso estilo POSIX, guardadas como atributos extendidos del espacio
+// system. (requieren FeatureIncompatXattrs). Cada entrada ocupa 6 bytes: etiqueta, permisos
+// (rwx = 4|2|1) e ID del usuario o grupo.
+//
+// La ACL de acceso solo guarda las entradas con nombre y la máscara: dueño, grupo y otros siguen
+// en I_perm. La ACL por defecto de una carpeta es completa y la heredan los archivos y carpetas
+// que se crean dentro (mkfile, mkdir).
+const (
+	ACLUserObj  byte = 0x01 // user::   dueño (solo en la ACL por defecto)
+	ACLUser     byte = 0x02 // user:nombre:
+	ACLGroupObj byte = 0x04 // group::  grupo dueño (solo en la ACL por defecto)
+	ACLGroup    byte = 0x08 // group:nombre:
+	ACLMask     byte = 0x10 // mask::   límite para las entradas con nombre y el grupo dueño
+	ACLOther    byte = 0x20 // other::  (solo en la ACL por defecto)
+
+	XattrACLAccess  = "system.acl"
+	XattrACLDefault = "system.acl_default"
+
+	aclEntrySize = 6
+)
+
+type ACLEntry struct {
+	Tag  byte
+	ID   int32 // UID o GID para ACLUser y ACLGroup; -1 en el resto
+	Perm byte  // Bits rwx (0-7)
+}
+
+type ACL []ACLEntry
+
+// Find devuelve la posición de la entrada con tag e id, o -1.
+func (acl ACL) Find(tag byte, id int32) int {
+	for i, entry := range acl {
+		if entry.Tag == tag && (entry.ID == id || (tag != ACLUser && tag != ACLGroup)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Mask devuelve los permisos de la máscara, o 7 si la ACL no tiene máscara.
+func (acl ACL) Mask() byte {
+	if i := acl.Find(ACLMask, -1); i != -1 {
+		return acl[i].Perm
+	}
+	return 7
+}
+
+// HasNamed indica si la ACL tiene entradas de usuarios o grupos con nombre.
+func (acl ACL) HasNamed() bool {
+	for _, entry := range acl {
+		if entry.Tag == ACLUser || entry.Tag == ACLGroup {
+			return true
+		}
+	}
+	return false
+}
+
+// Set agrega la entrada o reemplaza los permisos de la que tiene la misma etiqueta e ID.
+func (acl ACL) Set(entry ACLEntry) ACL {
+	if i := acl.Find(entry.Tag, entry.ID); i != -1 {
+		acl[i].Perm = entry.Perm
+		return acl
+	}
+	return append(acl, entry)
+}
+
+// Remove quita la entrada con tag e id (si existe).
+func (acl ACL) Remove(tag byte, id int32) ACL {
+	if i := acl.Find(tag, id); i != -1 {
+		return append(acl[:i], acl[i+1:]...)
+	}
+	return acl
+}
+
+// PermBits convierte un permiso de I_perm ('0'-'7') a bits rwx.
+func PermBits(perm byte) byte {
+	if perm >= '0' && perm <= '7' {
+		return perm - '0'
+	}
+	return 0
+}
+
+// PermString muestra bits rwx como "r-x".
+func PermString(bits byte) string {
+	out := []byte("---")
+	for i, c := range "rwx" {
+		if bits&(4>>i) != 0 {
+			out[i] = byte(c)
+		}
+	}
+	return string(out)
+}
+
+// ParsePermString convierte "rw-" (o un dígito 0-7) a bits rwx.
+func ParsePermString(s string) (byte, error) {
+	if len(s) == 1 && s[0] >= '0' && s[0] <= '7' {
+		return s[0] - '0', nil
+	}
+	if len(s) != 3 {
+		return 0, fmt.Errorf("permisos inválidos '%s': se esperaba rwx, r-x, etc. o un dígito 0-7", s)
+	}
+	bits := byte(0)
+	for i, c := range "rwx" {
+		switch s[i] {
+		case byte(c):
+			bits |= 4 >> i
+		case '-':
+		default:
+			return 0, fmt.Errorf("permisos inválidos '%s': se esperaba rwx, r-x, etc. o un dígito 0-7", s)
+		}
+	}
+	return bits, nil
+}
+
+func encodeACL(acl ACL) string {
+	raw := make([]byte, 0, len(acl)*aclEntrySize)
+	for _, entry := range acl {
+		var id [4]byte
+		binary.LittleEndian.PutUint32(id[:], uint32(entry.ID))
+		raw = append(append(raw, entry.Tag, entry.Perm), id[:]...)
+	}
+	return string(raw)
+}
+
+func decodeACL(value string) (ACL, error) {
+	raw := []byte(value)
+	if len(raw)%aclEntrySize != 0 {
+		return nil, fmt.Errorf("ACL inválida: %d bytes no es múltiplo de %d", len(raw), aclEntrySize)
+	}
+	acl := ACL{}
+	for pos := 0; pos < len(raw); pos += aclEntrySize {
+		acl = append(acl, ACLEntry{
+			Tag:  raw[pos],
+			Perm: raw[pos+1] & 7,
+			ID:   int32(binary.LittleEndian.Uint32(raw[pos+2:])),
+		})
+	}
+	return acl, nil
+}
+
+// ReadACL devuelve la ACL guardada en el atributo name (XattrACLAccess o XattrACLDefault); vacía
+// si el inodo no tiene.
+func (sb *SuperBlock) ReadACL(path string, inode *Inode, name string) (ACL, error) {
+	attrs, err := sb.ReadXattrs(path, inode)
+	if err != nil {
+		return nil, err
+	}
+	for _, attr := range attrs {
+		if attr.Name == name {
+			return decodeACL(attr.Value)
+		}
+	}
+	return ACL{}, nil
+}
+
+// WriteACL guarda acl en el atributo name; una ACL vacía borra el atributo. Como WriteXattrs,
+// actualiza I_xattr pero no guarda el inodo.
+func (sb *SuperBlock) WriteACL(path string, inode *Inode, name string, acl ACL) error {
+	attrs, err := sb.ReadXattrs(path, inode)
+	if err != nil {
+		return err
+	}
+	return sb.WriteXattrs(path, inode, setACLAttr(attrs, name, acl))
+}
+
+// Reemplaza, agrega o quita (si acl está vacía) el atributo name de la lista.
+func setACLAttr(attrs []Xattr, name string, acl ACL) []Xattr {
+	out := []Xattr{}
+	for _, attr := range attrs {
+		if attr.Name != name {
+			out = append(out, attr)
+		}
+	}
+	if len(acl) > 0 {
+		out = append(out, Xattr{Name: name, Value: encodeACL(acl)})
+	}
+	return out
+}
+
+// InheritACL aplica la ACL por defecto de parent a child, recién creado y todavía sin guardar: los
+// permisos de child quedan limitados por las entradas user::, group:: (o la máscara) y other::,
+// las entradas con nombre pasan a su ACL de acceso y, si child es carpeta, hereda también la ACL
+// por defecto. No hace nada si parent no tiene ACL por defecto.
+func (sb *SuperBlock) InheritACL(path string, parent *Inode, child *Inode) error {
+	if parent.I_xattr == -1 {
+		return nil
+	}
+	def, err := sb.ReadACL(path, parent, XattrACLDefault)
+	if err != nil {
+		return fmt.Errorf("error leyendo la ACL por defecto de la carpeta: %w", err)
+	}
+	if len(def) == 0 {
+		return nil
+	}
+
+	mode := [3]byte{PermBits(child.I_perm[0]), PermBits(child.I_perm[1]), PermBits(child.I_perm[2])}
+	base := [3]byte{7, 7, 7}
+	for k, tag := range []byte{ACLUserObj, ACLGroupObj, ACLOther} {
+		if i := def.Find(tag, -1); i != -1 {
+			base[k] = def[i].Perm
+		}
+	}
+	access := ACL{}
+	for _, entry := range def {
+		switch entry.Tag {
+		case ACLUser, ACLGroup:
+			access = append(access, entry)
+		case ACLMask:
+			access = append(access, ACLEntry{Tag: ACLMask, ID: -1, Perm: entry.Perm & mode[1]})
+		}
+	}
+	for k := range child.I_perm {
+		child.I_perm[k] = '0' + (base[k] & mode[k])
+	}
+
+	attrs, err := sb.ReadXattrs(path, child)
+	if err != nil {
+		return err
+	}
+	attrs = setACLAttr(attrs, XattrACLAccess, access)
+	if child.I_type[0] == '0' {
+		attrs = setACLAttr(attrs, XattrACLDefault, def)
+	}
+	fmt.Printf("Heredando ACL por defecto: permisos %s, %d entradas de acceso\n", string(child.I_perm[:]), len(access))
+	return sb.WriteXattrs(path, child, attrs)
+}
+
+// IsACLXattr indica si name es uno de los atributos donde se guardan las ACL.
+func IsACLXattr(name string) bool {
+	return name == XattrACLAccess || name == XattrACLDefault
+}
@@ -0,0 +1,158 @@
+package structures
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testDefaultACL() ACL {
+	return ACL{
+		{Tag: ACLUserObj, ID: -1, Perm: 7},
+		{Tag: ACLUser, ID: 5, Perm: 6},
+		{Tag: ACLGroupObj, ID: -1, Perm: 5},
+		{Tag: ACLGroup, ID: 3, Perm: 4},
+		{Tag: ACLMask, ID: -1, Perm: 7},
+		{Tag: ACLOther, ID: -1, Perm: 0},
+	}
+}
+
+func TestACLRoundTrip(t *testing.T) {
+	for _, acl := range []ACL{{}, testDefaultACL(), {{Tag: ACLUser, ID: 1 << 30, Perm: 1}}} {
+		value := encodeACL(acl)
+		if len(value) != len(acl)*aclEntrySize {
+			t.Fatalf("encodeACL = %d bytes, se esperaban %d", len(value), len(acl)*aclEntrySize)
+		}
+		decoded, err := decodeACL(value)
+		if err != nil {
+			t.Fatalf("decodeACL: %v", err)
+		}
+		if !reflect.DeepEqual(decoded, acl) {
+			t.Errorf("decodeACL = %+v, se esperaba %+v", decoded, acl)
+		}
+	}
+	// Etiqueta, permisos e ID little endian
+	if got := encodeACL(ACL{{Tag: ACLGroup, ID: 0x0102, Perm: 5}}); got != "\x08\x05\x02\x01\x00\x00" {
+		t.Errorf("encodeACL = %q", got)
+	}
+}
+
+func TestDecodeACLErrors(t *testing.T) {
+	value := encodeACL(testDefaultACL())
+	for _, size := range []int{1, aclEntrySize - 1, aclEntrySize + 1, len(value) - 1} {
+		if acl, err := decodeACL(value[:size]); err == nil {
+			t.Errorf("decodeACL de %d bytes = %+v, se esperaba error", size, acl)
+		}
+	}
+	// Los bits de permiso fuera de rwx se descartan al leer
+	acl, err := decodeACL("\x02\xff\x05\x00\x00\x00")
+	if err != nil || len(acl) != 1 || acl[0].Perm != 7 {
+		t.Errorf("decodeACL con permisos 0xff = %+v, %v", acl, err)
+	}
+}
+
+func TestACLEntries(t *testing.T) {
+	acl := ACL{{Tag: ACLUser, ID: 5, Perm: 6}}
+	if acl.Mask() != 7 || !acl.HasNamed() {
+		t.Errorf("sin máscara: Mask = %d, HasNamed = %v", acl.Mask(), acl.HasNamed())
+	}
+	acl = acl.Set(ACLEntry{Tag: ACLMask, ID: -1, Perm: 4})
+	acl = acl.Set(ACLEntry{Tag: ACLUser, ID: 5, Perm: 7})
+	acl = acl.Set(ACLEntry{Tag: ACLUser, ID: 6, Perm: 1})
+	want := ACL{{Tag: ACLUser, ID: 5, Perm: 7}, {Tag: ACLMask, ID: -1, Perm: 4}, {Tag: ACLUser, ID: 6, Perm: 1}}
+	if !reflect.DeepEqual(acl, want) || acl.Mask() != 4 {
+		t.Errorf("Set = %+v, Mask = %d", acl, acl.Mask())
+	}
+	acl = acl.Remove(ACLUser, 5).Remove(ACLUser, 6).Remove(ACLGroup, 5)
+	if acl.HasNamed() || len(acl) != 1 {
+		t.Errorf("Remove = %+v", acl)
+	}
+}
+
+func TestPermStrings(t *testing.T) {
+	for bits, text := range []string{"---", "--x", "-w-", "-wx", "r--", "r-x", "rw-", "rwx"} {
+		if got := PermString(byte(bits)); got != text {
+			t.Errorf("PermString(%d) = %q, se esperaba %q", bits, got, text)
+		}
+		if got, err := ParsePermString(text); err != nil || got != byte(bits) {
+			t.Errorf("ParsePermString(%q) = %d, %v", text, got, err)
+		}
+	}
+	if got, err := ParsePermString("5"); err != nil || got != 5 {
+		t.Errorf("ParsePermString(5) = %d, %v", got, err)
+	}
+	for _, text := range []string{"", "8", "rw", "wrx", "rwxr"} {
+		if _, err := ParsePermString(text); err == nil {
+			t.Errorf("ParsePermString(%q): se esperaba error", text)
+		}
+	}
+}
+
+func TestInheritACL(t *testing.T) {
+	sb, path := newTestFS(t, 128, FeatureIncompatXattrs, 8)
+	parent := newTestFile()
+	parent.I_type = [1]byte{'0'}
+	if err := sb.WriteACL(path, parent, XattrACLDefault, testDefaultACL()); err != nil {
+		t.Fatalf("WriteACL: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name        string
+		kind        byte
+		perm        string
+		wantPerm    string
+		wantMask    byte
+		wantDefault bool
+	}{
+		{"archivo", '1', "664", "640", 6, false},
+		{"carpeta", '0', "775", "750", 7, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			child := newTestFile()
+			child.I_type = [1]byte{tt.kind}
+			copy(child.I_perm[:], tt.perm)
+			if err := sb.InheritACL(path, parent, child); err != nil {
+				t.Fatalf("InheritACL: %v", err)
+			}
+			if got := string(child.I_perm[:]); got != tt.wantPerm {
+				t.Errorf("I_perm = %s, se esperaba %s", got, tt.wantPerm)
+			}
+			access, err := sb.ReadACL(path, child, XattrACLAccess)
+			if err != nil {
+				t.Fatalf("ReadACL: %v", err)
+			}
+			want := ACL{{Tag: ACLUser, ID: 5, Perm: 6}, {Tag: ACLGroup, ID: 3, Perm: 4}, {Tag: ACLMask, ID: -1, Perm: tt.wantMask}}
+			if !reflect.DeepEqual(access, want) {
+				t.Errorf("ACL de acceso = %+v, se esperaba %+v", access, want)
+			}
+			def, err := sb.ReadACL(path, child, XattrACLDefault)
+			if err != nil {
+				t.Fatalf("ReadACL: %v", err)
+			}
+			if got := len(def) > 0; got != tt.wantDefault || (got && !reflect.DeepEqual(def, testDefaultACL())) {
+				t.Errorf("ACL por defecto heredada = %+v", def)
+			}
+		})
+	}
+
+	// Sin ACL por defecto el hijo conserva sus permisos y no recibe bloque de atributos
+	plain := newTestFile()
+	if err := sb.WriteXattrs(path, plain, []Xattr{{Name: "user.mime", Value: "text/plain"}}); err != nil {
+		t.Fatalf("WriteXattrs: %v", err)
+	}
+	for _, p := range []*Inode{newTestFile(), plain} {
+		child := newTestFile()
+		if err := sb.InheritACL(path, p, child); err != nil || child.I_xattr != -1 || string(child.I_perm[:]) != "664" {
+			t.Errorf("InheritACL sin ACL por defecto: I_perm %s, I_xattr %d, %v", string(child.I_perm[:]), child.I_xattr, err)
+		}
+	}
+
+	// Una ACL por defecto corrupta se informa en lugar de heredarse a medias
+	corrupt := newTestFile()
+	if err := sb.WriteXattrs(path, corrupt, []Xattr{{Name: XattrACLDefault, Value: "\x01\x07\xff\xff\xff"}}); err != nil {
+		t.Fatalf("WriteXattrs: %v", err)
+	}
+	child := newTestFile()
+	if err := sb.InheritACL(path, corrupt, child); err == nil || string(child.I_perm[:]) != "664" {
+		t.Errorf("InheritACL con la ACL por defecto de 5 bytes: I_perm %s, %v", string(child.I_perm[:]), err)
+	}
+}
@@ -0,0 +1,135 @@
+package structures
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PartitionFit devuelve el ajuste (Part_fit) de la partición que contiene el sistema de archivos,
+// o 'F' si no se encuentra.
+func (sb *SuperBlock) PartitionFit(path string) byte {
+	var mbr MBR
+	if err := mbr.Deserialize(path); err != nil {
+		return 'F'
+	}
+	start := sb.S_bm_inode_start - sb.DiskSize()
+	for _, part := range mbr.Mbr_partitions {
+		if part.Part_size > 0 && part.Part_start == start {
+			switch part.Part_fit[0] {
+			case 'B', 'W':
+				return part.Part_fit[0]
+			}
+			return 'F'
+		}
+	}
+	return 'F'
+}
+
+// PlanBlocks elige count bloques libres para un archivo sin marcarlos: un tramo contiguo según
+// el ajuste de la partición o, si ninguno alcanza, bloques sueltos a partir de S_first_blo.
+// Devuelve también si el tramo es contiguo.
+func (sb *SuperBlock) PlanBlocks(path string, count int32) ([]int32, bool, error) {
+	if count <= 0 {
+		return []int32{}, true, nil
+	}
+	index, err := sb.FreeIndex(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if index.Blocks.Free() < count {
+		return nil, false, fmt.Errorf("espacio insuficiente: se necesitan %d bloques, disponibles %d", count, index.Blocks.Free())
+	}
+
+	fit := sb.PartitionFit(path)
+	if start, ok := index.Blocks.FindRun(count, fit); ok {
+		blocks := make([]int32, count)
+		for i := range blocks {
+			blocks[i] = start + int32(i)
+		}
+		fmt.Printf("Allocate: tramo contiguo de %d bloques en %d (ajuste %c)\n", count, start, fit)
+		return blocks, true, nil
+	}
+
+	blocks := index.Blocks.Collect(sb.S_first_blo, count)
+	if int32(len(blocks)) < count {
+		return nil, false, errors.New("no hay bloques libres disponibles")
+	}
+	fmt.Printf("Allocate: sin tramo contiguo de %d bloques, se usan bloques sueltos\n", count)
+	return blocks, false, nil
+}
+
+// BlocksForFile devuelve cuántos bloques (datos más punteros) necesita un archivo de dataBlocks bloques de datos
+// con el tamaño de bloque del superbloque.
+func (sb *SuperBlock) BlocksForFile(dataBlocks int32) int32 {
+	pointersPerBlock := PointersPerBlock(sb.S_block_size)
+	total := dataBlocks
+	remaining := dataBlocks - 12
+	if remaining > 0 { // Indirecto simple
+		total++
+		remaining -= pointersPerBlock
+	}
+	if remaining > 0 { // Indirecto doble: L1 más un L2 por cada pointersPerBlock bloques
+		double := remaining
+		if double > pointersPerBlock*pointersPerBlock {
+			double = pointersPerBlock * pointersPerBlock
+		}
+		total += 1 + (double+pointersPerBlock-1)/pointersPerBlock
+		remaining -= double
+	}
+	if remaining > 0 { // Indirecto triple
+		l2 := (remaining + pointersPerBlock*pointersPerBlock - 1) / (pointersPerBlock * pointersPerBlock)
+		total += 1 + l2 + (remaining+pointersPerBlock-1)/pointersPerBlock
+	}
+	return total
+}
+
+// InodeBlocks devuelve los bloques de datos de un inodo y todos sus bloques (datos y punteros)
+// en el orden en que aparecen en el archivo.
+func (sb *SuperBlock) InodeBlocks(path string, inode *Inode) ([]int32, []int32, error) {
+	data, all := []int32{}, []int32{}
+	if inode.InlineSymlink() {
+		return data, all, nil // I_block guarda el destino del enlace, no punteros
+	}
+	var walk func(ptr int32, level int) error
+	walk = func(ptr int32, level int) error {
+		if ptr < 0 || ptr >= sb.S_blocks_count {
+			return nil
+		}
+		all = append(all, ptr)
+		if level == 0 {
+			data = append(data, ptr)
+			return nil
+		}
+		pb := NewPointerBlock(sb.S_block_size)
+		if err := pb.Deserialize(path, int64(sb.S_block_start)+int64(ptr)*int64(sb.S_block_size)); err != nil {
+			return fmt.Errorf("error leyendo bloque de punteros %d: %w", ptr, err)
+		}
+		for _, child := range pb.P_pointers {
+			if err := walk(child, level-1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for k, ptr := range inode.I_block {
+		level := 0
+		if k >= 12 {
+			level = k - 11
+		}
+		if err := walk(ptr, level); err != nil {
+			return data, all, err
+		}
+	}
+	return data, all, nil
+}
+
+// CountRuns cuenta los tramos de bloques consecutivos de una lista (1 = sin fragmentar).
+func CountRuns(blocks []int32) int {
+	runs := 0
+	for i, b := range blocks {
+		if i == 0 || b != blocks[i-1]+1 {
+			runs++
+		}
+	}
+	return runs
+}
@@ -1,19 +1,33 @@
 package structures
 
 import (
+	"bytes"
 	"fmt"
-	"os"
 )
 
+// PackedBitmaps indica si los bitmaps usan 1 bit por inodo/bloque (FeatureIncompatPackedBitmaps).
+func (sb *SuperBlock) PackedBitmaps() bool {
+	return sb.HasIncompat(FeatureIncompatPackedBitmaps)
+}
+
+// BitmapBytes devuelve los bytes que ocupa en disco un bitmap de count entradas.
+func (sb *SuperBlock) BitmapBytes(count int32) int64 {
+	if sb.PackedBitmaps() {
+		return (int64(count) + 7) / 8
+	}
+	return int64(count)
+}
+
 // inicializando como libres 
 func (sb *SuperBlock) CreateBitMaps(path string) error {
 	// Abrir archivo para escritura, creándolo si no existe
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	file, err := OpenDisk(path)
 	if err != nil {
 		// Mejoramos el mensaje de error
 		return fmt.Errorf("error al abrir/crear archivo para bitmaps (%s): %w", path, err)
 	}
 	defer file.Close()
+	defer sb.dropFreeIndex(path)
 
 	// --- Bitmap de inodos ---
 	// Validar que el conteo de inodos sea positivo
@@ -27,10 +41,7 @@ func (sb *SuperBlock) CreateBitMaps(path string) error {
 	}
 
 	//libre
-	inodeBitmapBuffer := make([]byte, sb.S_inodes_count)
-	for i := range inodeBitmapBuffer {
-		inodeBitmapBuffer[i] = '0' // '0' representa un inodo libre
-	}
+	inodeBitmapBuffer := sb.encodeBitmap(bytes.Repeat([]byte{'0'}, int(sb.S_inodes_count)))
 
 	// Escribir el buffer del bitmap de inodos en el archivo
 	bytesWritten, err := file.Write(inodeBitmapBuffer)
@@ -52,10 +63,7 @@ func (sb *SuperBlock) CreateBitMaps(path string) error {
 	}
 
 	//libre
-	blockBitmapBuffer := make([]byte, sb.S_blocks_count)
-	for i := range blockBitmapBuffer {
-		blockBitmapBuffer[i] = '0'
-	}
+	blockBitmapBuffer := sb.encodeBitmap(bytes.Repeat([]byte{'0'}, int(sb.S_blocks_count)))
 
 	// Escribir el buffer del bitmap de bloques en el archivo
 	bytesWritten, err = file.Write(blockBitmapBuffer)
@@ -70,6 +78,127 @@ func (sb *SuperBlock) CreateBitMaps(path string) error {
 	return nil
 }
 
+// Convierte un bitmap '0'/'1' al formato en disco.
+func (sb *SuperBlock) encodeBitmap(bitmap []byte) []byte {
+	if !sb.PackedBitmaps() {
+		return bitmap
+	}
+	packed := make([]byte, (len(bitmap)+7)/8)
+	for i, state := range bitmap {
+		if state == '1' {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	return packed
+}
+
+// Convierte count entradas del formato en disco a '0'/'1'.
+func (sb *SuperBlock) decodeBitmap(raw []byte, count int32) []byte {
+	if !sb.PackedBitmaps() {
+		return raw
+	}
+	bitmap := make([]byte, count)
+	for i := range bitmap {
+		bitmap[i] = '0'
+		if raw[i/8]&(1<<(i%8)) != 0 {
+			bitmap[i] = '1'
+		}
+	}
+	return bitmap
+}
+
+func (sb *SuperBlock) readBitmap(path string, start int32, count int32) ([]byte, error) {
+	file, err := OpenDisk(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	raw := make([]byte, sb.BitmapBytes(count))
+	if _, err := file.ReadAt(raw, int64(start)); err != nil {
+		return nil, err
+	}
+	return sb.decodeBitmap(raw, count), nil
+}
+
+func (sb *SuperBlock) writeBitmap(path string, start int32, bitmap []byte) error {
+	if err := sb.CheckWritable(); err != nil {
+		return err
+	}
+	file, err := OpenDisk(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	defer sb.dropFreeIndex(path)
+
+	_, err = file.WriteAt(sb.encodeBitmap(bitmap), int64(start))
+	return err
+}
+
+// ReadInodeBitmap devuelve el bitmap de inodos como '0'/'1' por inodo, sin importar el formato en disco.
+func (sb *SuperBlock) ReadInodeBitmap(path string) ([]byte, error) {
+	return sb.readBitmap(path, sb.S_bm_inode_start, sb.S_inodes_count)
+}
+
+// ReadBlockBitmap devuelve el bitmap de bloques como '0'/'1' por bloque.
+func (sb *SuperBlock) ReadBlockBitmap(path string) ([]byte, error) {
+	return sb.readBitmap(path, sb.S_bm_block_start, sb.S_blocks_count)
+}
+
+// WriteInodeBitmap reemplaza el bitmap de inodos completo ('0'/'1' por inodo).
+func (sb *SuperBlock) WriteInodeBitmap(path string, bitmap []byte) error {
+	return sb.writeBitmap(path, sb.S_bm_inode_start, bitmap)
+}
+
+// WriteBlockBitmap reemplaza el bitmap de bloques completo ('0'/'1' por bloque).
+func (sb *SuperBlock) WriteBlockBitmap(path string, bitmap []byte) error {
+	return sb.writeBitmap(path, sb.S_bm_block_start, bitmap)
+}
+
+// Lee la entrada index de un bitmap.
+func (sb *SuperBlock) readBitmapEntry(file *DiskFile, start int32, index int32) (bool, error) {
+	offset, mask := sb.bitmapPosition(start, index)
+	state := make([]byte, 1)
+	if _, err := file.ReadAt(state, offset); err != nil {
+		return false, err
+	}
+	if sb.PackedBitmaps() {
+		return state[0]&mask != 0, nil
+	}
+	return state[0] == '1', nil
+}
+
+// Escribe la entrada index de un bitmap; en formato empaquetado modifica solo su bit.
+func (sb *SuperBlock) writeBitmapEntry(file *DiskFile, start int32, index int32, used bool) error {
+	if err := sb.CheckWritable(); err != nil {
+		return err
+	}
+	offset, mask := sb.bitmapPosition(start, index)
+	state := []byte{'0'}
+	if sb.PackedBitmaps() {
+		if _, err := file.ReadAt(state, offset); err != nil {
+			return err
+		}
+		if used {
+			state[0] |= mask
+		} else {
+			state[0] &^= mask
+		}
+	} else if used {
+		state[0] = '1'
+	}
+	_, err := file.WriteAt(state, offset)
+	return err
+}
+
+func (sb *SuperBlock) bitmapPosition(start int32, index int32) (int64, byte) {
+	if sb.PackedBitmaps() {
+		return int64(start) + int64(index/8), 1 << (index % 8)
+	}
+	return int64(start) + int64(index), 0
+}
+
 // UpdateBitmapInode: MODIFICADO para aceptar el estado ('0' o '1')
 func (sb *SuperBlock) UpdateBitmapInode(path string, inodeIndex int32, state byte) error {
 	// Validación del estado deseado
@@ -81,26 +210,24 @@ func (sb *SuperBlock) UpdateBitmapInode(path string, inodeIndex int32, state byt
 		return fmt.Errorf("índice de inodo fuera de rango: %d (total: %d)", inodeIndex, sb.S_inodes_count)
 	}
 
-	file, err := os.OpenFile(path, os.O_RDWR, 0644) // Necesita RDWR para escribir
+	file, err := OpenDisk(path) // Necesita RDWR para escribir
 	if err != nil {
 		return fmt.Errorf("error al abrir archivo ('%s') para actualizar bitmap inodos: %w", path, err)
 	}
 	defer file.Close()
 
-	// Calcular el offset EXACTO dentro del bitmap
-	offset := int64(sb.S_bm_inode_start) + int64(inodeIndex)
-	_, err = file.Seek(offset, 0)
-	if err != nil {
-		return fmt.Errorf("error al buscar posición %d en bitmap inodos: %w", offset, err)
+	// Escribir la entrada (un byte o un bit según el formato)
+	if err := sb.writeBitmapEntry(file, sb.S_bm_inode_start, inodeIndex, state == '1'); err != nil {
+		return fmt.Errorf("error al escribir '%c' en bitmap inodos (índice %d): %w", state, inodeIndex, err)
 	}
 
-	// Escribir el byte de estado proporcionado
-	bytesWritten, err := file.Write([]byte{state}) // <-- Usa el parámetro 'state'
-	if err != nil {
-		return fmt.Errorf("error al escribir '%c' en bitmap inodos (índice %d, offset %d): %w", state, inodeIndex, offset, err)
-	}
-	if bytesWritten != 1 {
-		return fmt.Errorf("escritura incompleta en bitmap inodos (índice %d)", inodeIndex)
+	// Mantener el índice de libres al día
+	if index := sb.loadedFreeIndex(path); index != nil {
+		if state == '1' {
+			index.Inodes.Take(inodeIndex)
+		} else {
+			index.Inodes.Release(inodeIndex)
+		}
 	}
 
 	statusMsg := "ocupado"; if state == '0' { statusMsg = "libre" }
@@ -119,26 +246,24 @@ func (sb *SuperBlock) UpdateBitmapBlock(path string, blockIndex int32, state byt
 		return fmt.Errorf("índice de bloque fuera de rango: %d (total: %d)", blockIndex, sb.S_blocks_count)
 	}
 
-	file, err := os.OpenFile(path, os.O_RDWR, 0644) // Necesita RDWR
+	file, err := OpenDisk(path) // Necesita RDWR
 	if err != nil {
 		return fmt.Errorf("error al abrir archivo ('%s') para actualizar bitmap bloques: %w", path, err)
 	}
 	defer file.Close()
 
-	// Calcular el offset EXACTO dentro del bitmap
-	offset := int64(sb.S_bm_block_start) + int64(blockIndex)
-	_, err = file.Seek(offset, 0)
-	if err != nil {
-		return fmt.Errorf("error al buscar posición %d en bitmap bloques: %w", offset, err)
+	// Escribir la entrada (un byte o un bit según el formato)
+	if err := sb.writeBitmapEntry(file, sb.S_bm_block_start, blockIndex, state == '1'); err != nil {
+		return fmt.Errorf("error al escribir '%c' en bitmap bloques (índice %d): %w", state, blockIndex, err)
 	}
 
-	// Escribir el byte de estado proporcionado
-	bytesWritten, err := file.Write([]byte{state}) // <-- Usa el parámetro 'state'
-	if err != nil {
-		return fmt.Errorf("error al escribir '%c' en bitmap bloques (índice %d, offset %d): %w", state, blockIndex, offset, err)
-	}
-	if bytesWritten != 1 {
-		return fmt.Errorf("escritura incompleta en bitmap bloques (índice %d)", blockIndex)
+	// Mantener el índice de libres al día
+	if index := sb.loadedFreeIndex(path); index != nil {
+		if state == '1' {
+			index.Blocks.Take(blockIndex)
+		} else {
+			index.Blocks.Release(blockIndex)
+		}
 	}
 
 	statusMsg := "ocupado"; if state == '0' { statusMsg = "libre" }
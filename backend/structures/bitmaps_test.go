@@ -0,0 +1,210 @@
+package structures
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeDecodeBitmap(t *testing.T) {
+	bitmap := []byte("1011000011") // 10 entradas: el segundo byte empaquetado queda a medias
+	for _, tt := range []struct {
+		name     string
+		features int32
+		raw      []byte
+	}{
+		{"texto", 0, []byte("1011000011")},
+		{"empaquetado", FeatureIncompatPackedBitmaps, []byte{0b00001101, 0b00000011}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := &SuperBlock{S_ext_magic: SuperBlockExtMagic, S_feature_incompat: tt.features}
+			if got := sb.BitmapBytes(int32(len(bitmap))); got != int64(len(tt.raw)) {
+				t.Errorf("BitmapBytes(%d) = %d, se esperaba %d", len(bitmap), got, len(tt.raw))
+			}
+			raw := sb.encodeBitmap(bitmap)
+			if !bytes.Equal(raw, tt.raw) {
+				t.Errorf("encodeBitmap = %08b, se esperaba %08b", raw, tt.raw)
+			}
+			if got := sb.decodeBitmap(raw, int32(len(bitmap))); !bytes.Equal(got, bitmap) {
+				t.Errorf("decodeBitmap = %q, se esperaba %q", got, bitmap)
+			}
+		})
+	}
+}
+
+// Las entradas sueltas cambian solo su bit y el bitmap completo se lee igual en los dos formatos.
+func TestBitmapEntries(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		features int32
+	}{
+		{"texto", 0},
+		{"empaquetado", FeatureIncompatPackedBitmaps},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sb, path := newTestFS(t, 64, tt.features, 20)
+			for _, index := range []int32{0, 7, 8, 19} {
+				if err := sb.UpdateBitmapBlock(path, index, '1'); err != nil {
+					t.Fatalf("UpdateBitmapBlock(%d): %v", index, err)
+				}
+			}
+			if err := sb.UpdateBitmapBlock(path, 7, '0'); err != nil {
+				t.Fatalf("UpdateBitmapBlock(7, '0'): %v", err)
+			}
+			want := []byte("10000000100000000001")
+			got, err := sb.ReadBlockBitmap(path)
+			if err != nil {
+				t.Fatalf("ReadBlockBitmap: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("bitmap = %s, se esperaba %s", got, want)
+			}
+			// El bitmap de inodos, justo antes, no se toca
+			inodes, err := sb.ReadInodeBitmap(path)
+			if err != nil {
+				t.Fatalf("ReadInodeBitmap: %v", err)
+			}
+			if bytes.Contains(inodes, []byte{'1'}) {
+				t.Errorf("bitmap de inodos modificado: %s", inodes)
+			}
+
+			for _, index := range []int32{-1, 20} {
+				if err := sb.UpdateBitmapBlock(path, index, '1'); err == nil {
+					t.Errorf("UpdateBitmapBlock(%d): se esperaba error por índice fuera de rango", index)
+				}
+			}
+			if err := sb.UpdateBitmapInode(path, 0, 'x'); err == nil {
+				t.Errorf("UpdateBitmapInode con estado 'x': se esperaba error")
+			}
+		})
+	}
+}
+
+func TestFreeExtents(t *testing.T) {
+	fe := newFreeExtents([]byte("1100010000"))
+	wantExtents := func(want ...Extent) {
+		t.Helper()
+		got := fe.Extents()
+		if len(got) != len(want) {
+			t.Fatalf("tramos = %v, se esperaban %v", got, want)
+		}
+		free := int32(0)
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("tramos = %v, se esperaban %v", got, want)
+			}
+			free += want[i].Length
+		}
+		if fe.Free() != free {
+			t.Errorf("Free() = %d, se esperaba %d", fe.Free(), free)
+		}
+	}
+	wantExtents(Extent{2, 3}, Extent{6, 4})
+
+	if !fe.IsFree(2) || fe.IsFree(5) || fe.IsFree(0) {
+		t.Errorf("IsFree no coincide con el bitmap")
+	}
+	if got, ok := fe.First(5); !ok || got != 6 {
+		t.Errorf("First(5) = (%d, %v), se esperaba (6, true)", got, ok)
+	}
+	if got, ok := fe.First(3); !ok || got != 3 {
+		t.Errorf("First(3) = (%d, %v), se esperaba (3, true)", got, ok)
+	}
+
+	// FindRun: 'F' el primero que alcance, 'B' el más justo, 'W' el más grande
+	for _, tt := range []struct {
+		count int32
+		fit   byte
+		want  int32
+		ok    bool
+	}{
+		{3, 'F', 2, true},
+		{3, 'B', 2, true},
+		{3, 'W', 6, true},
+		{4, 'F', 6, true},
+		{5, 'F', -1, false},
+	} {
+		if got, ok := fe.FindRun(tt.count, tt.fit); got != tt.want || ok != tt.ok {
+			t.Errorf("FindRun(%d, %c) = (%d, %v), se esperaba (%d, %v)", tt.count, tt.fit, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	// Collect sigue desde from y da la vuelta al inicio
+	if got := fe.Collect(8, 5); !equalIndices(got, []int32{8, 9, 2, 3, 4}) {
+		t.Errorf("Collect(8, 5) = %v", got)
+	}
+	if got := fe.Collect(3, 10); !equalIndices(got, []int32{3, 4, 6, 7, 8, 9, 2}) {
+		t.Errorf("Collect(3, 10) = %v", got)
+	}
+
+	// Take parte tramos y Release los vuelve a unir
+	fe.Take(7)
+	wantExtents(Extent{2, 3}, Extent{6, 1}, Extent{8, 2})
+	fe.Take(2)
+	fe.Take(9)
+	wantExtents(Extent{3, 2}, Extent{6, 1}, Extent{8, 1})
+	fe.Take(0) // Ya ocupado: no cambia nada
+	wantExtents(Extent{3, 2}, Extent{6, 1}, Extent{8, 1})
+	fe.Release(5)
+	wantExtents(Extent{3, 4}, Extent{8, 1})
+	fe.Release(7)
+	wantExtents(Extent{3, 6})
+	fe.Release(4) // Ya libre
+	wantExtents(Extent{3, 6})
+	fe.Release(0)
+	wantExtents(Extent{0, 1}, Extent{3, 6})
+
+	for _, index := range []int32{0, 3, 4, 5, 6, 7, 8} {
+		fe.Take(index)
+	}
+	wantExtents()
+	if _, ok := fe.First(0); ok {
+		t.Errorf("First en un índice sin libres: se esperaba false")
+	}
+}
+
+func equalIndices(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// El índice se mantiene al día con las entradas sueltas y se descarta al reescribir el bitmap.
+func TestFreeIndexFollowsBitmap(t *testing.T) {
+	sb, path := newTestFS(t, 64, FeatureIncompatPackedBitmaps, 12)
+	index, err := sb.FreeIndex(path)
+	if err != nil {
+		t.Fatalf("FreeIndex: %v", err)
+	}
+	if err := sb.UpdateBitmapBlock(path, 3, '1'); err != nil {
+		t.Fatalf("UpdateBitmapBlock: %v", err)
+	}
+	if index.Blocks.IsFree(3) || index.Blocks.Free() != 11 {
+		t.Errorf("el índice no registró el bloque 3 ocupado: %v", index.Blocks.Extents())
+	}
+	if err := sb.UpdateBitmapBlock(path, 3, '0'); err != nil {
+		t.Fatalf("UpdateBitmapBlock: %v", err)
+	}
+	if !index.Blocks.IsFree(3) || index.Blocks.Free() != 12 {
+		t.Errorf("el índice no registró el bloque 3 libre: %v", index.Blocks.Extents())
+	}
+
+	if err := sb.WriteBlockBitmap(path, []byte("111111000000")); err != nil {
+		t.Fatalf("WriteBlockBitmap: %v", err)
+	}
+	rebuilt, err := sb.FreeIndex(path)
+	if err != nil {
+		t.Fatalf("FreeIndex: %v", err)
+	}
+	if rebuilt == index {
+		t.Fatalf("WriteBlockBitmap no descartó el índice anterior")
+	}
+	if got := rebuilt.Blocks.Extents(); len(got) != 1 || got[0] != (Extent{6, 6}) {
+		t.Errorf("índice reconstruido = %v, se esperaba [{6 6}]", got)
+	}
+}
@@ -0,0 +1,271 @@
+package structures
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Correspondencia entre los bloques lógicos de un archivo (0, 1, 2, ...) y los bloques del disco.
+// Un puntero en -1, sea directo, dentro de un bloque de punteros o el de un bloque de punteros
+// completo, es un hueco: se lee como ceros y no ocupa espacio (archivos dispersos).
+
+// blockSlot devuelve la posición de I_block que cubre el bloque lógico n, el nivel de
+// indirección de ese puntero (0 = directo) y el primer bloque lógico que cubre.
+func (sb *SuperBlock) blockSlot(n int64) (int, int, int64, error) {
+	if n < 0 {
+		return 0, 0, 0, fmt.Errorf("bloque lógico inválido: %d", n)
+	}
+	if n < 12 {
+		return int(n), 0, n, nil
+	}
+	p := int64(PointersPerBlock(sb.S_block_size))
+	base, span := int64(12), p
+	for level := 1; level <= 3; level++ {
+		if n < base+span {
+			return 11 + level, level, base, nil
+		}
+		base += span
+		span *= p
+	}
+	return 0, 0, 0, fmt.Errorf("el bloque lógico %d excede el máximo direccionable con bloques de %d bytes", n, sb.S_block_size)
+}
+
+// blockSpan devuelve cuántos bloques de datos cubre un puntero de nivel level.
+func (sb *SuperBlock) blockSpan(level int) int64 {
+	span := int64(1)
+	for i := 0; i < level; i++ {
+		span *= int64(PointersPerBlock(sb.S_block_size))
+	}
+	return span
+}
+
+func (sb *SuperBlock) readPointerBlock(path string, blockIndex int32) (*PointerBlock, error) {
+	if blockIndex < 0 || blockIndex >= sb.S_blocks_count {
+		return nil, fmt.Errorf("puntero a bloque de punteros inválido: %d", blockIndex)
+	}
+	pb := NewPointerBlock(sb.S_block_size)
+	if err := pb.Deserialize(path, int64(sb.S_block_start)+int64(blockIndex)*int64(sb.S_block_size)); err != nil {
+		return nil, fmt.Errorf("error leyendo bloque de punteros %d: %w", blockIndex, err)
+	}
+	return pb, nil
+}
+
+// BlockAt devuelve el bloque del disco que guarda el bloque lógico n del archivo, o -1 si es un
+// hueco.
+func (sb *SuperBlock) BlockAt(path string, inode *Inode, n int64) (int32, error) {
+	slot, level, base, err := sb.blockSlot(n)
+	if err != nil {
+		return -1, err
+	}
+	ptr := inode.I_block[slot]
+	rel := n - base
+	for ; level > 0 && ptr != -1; level-- {
+		pb, err := sb.readPointerBlock(path, ptr)
+		if err != nil {
+			return -1, err
+		}
+		span := sb.blockSpan(level - 1)
+		ptr = pb.P_pointers[rel/span]
+		rel %= span
+	}
+	return ptr, nil
+}
+
+// AllocBlockAt asegura que el bloque lógico n tenga un bloque en el disco: si es un hueco asigna
+// los bloques de punteros que falten y un bloque de datos lleno de ceros. Devuelve el bloque y si
+// se acaba de asignar. Actualiza I_block pero no guarda el inodo ni el superbloque.
+func (sb *SuperBlock) AllocBlockAt(path string, inode *Inode, n int64) (int32, bool, error) {
+	slot, level, base, err := sb.blockSlot(n)
+	if err != nil {
+		return -1, false, err
+	}
+	if n*int64(sb.S_block_size) >= sb.MaxFileSize() {
+		return -1, false, fmt.Errorf("el bloque lógico %d excede el tamaño máximo de archivo (%d bytes)", n, sb.MaxFileSize())
+	}
+
+	// Asigna el bloque que falta en un puntero: de punteros si quedan niveles, de datos si no
+	alloc := func(level int) (int32, error) {
+		if level > 0 {
+			return sb.allocPointerBlock(path)
+		}
+		blockIndex, err := sb.allocBlock(path)
+		if err != nil {
+			return -1, err
+		}
+		if err := NewFileBlock(sb.S_block_size).Serialize(path, int64(sb.S_block_start)+int64(blockIndex)*int64(sb.S_block_size)); err != nil {
+			return -1, fmt.Errorf("error inicializando bloque de datos %d: %w", blockIndex, err)
+		}
+		return blockIndex, nil
+	}
+
+	// Si falla una asignación más abajo (disco lleno) se liberan los bloques ya asignados y se
+	// quita el primer puntero nuevo, para no dejar bloques marcados que nadie referencia
+	var allocated []int32
+	undo := func() {}
+	fail := func(err error) (int32, bool, error) {
+		undo()
+		for _, blockIndex := range allocated {
+			if errFree := freeDataBlockIfValid(blockIndex, sb, path); errFree != nil {
+				fmt.Printf("ADVERTENCIA: no se pudo liberar el bloque %d: %v\n", blockIndex, errFree)
+			}
+		}
+		return -1, false, err
+	}
+
+	if inode.I_block[slot] == -1 {
+		if inode.I_block[slot], err = alloc(level); err != nil {
+			inode.I_block[slot] = -1
+			return -1, false, err
+		}
+		allocated = append(allocated, inode.I_block[slot])
+		undo = func() { inode.I_block[slot] = -1 }
+	}
+	ptr := inode.I_block[slot]
+	rel := n - base
+	for ; level > 0; level-- {
+		pb, err := sb.readPointerBlock(path, ptr)
+		if err != nil {
+			return fail(err)
+		}
+		span := sb.blockSpan(level - 1)
+		i := rel / span
+		rel %= span
+		if pb.P_pointers[i] == -1 {
+			blockIndex, err := alloc(level - 1)
+			if err != nil {
+				return fail(err)
+			}
+			allocated = append(allocated, blockIndex)
+			pb.P_pointers[i] = blockIndex
+			pbOffset := int64(sb.S_block_start) + int64(ptr)*int64(sb.S_block_size)
+			if err := pb.Serialize(path, pbOffset); err != nil {
+				return fail(fmt.Errorf("error guardando bloque de punteros %d: %w", ptr, err))
+			}
+			if len(allocated) == 1 { // Primer puntero nuevo: está en un bloque que ya existía
+				undo = func() {
+					pb.P_pointers[i] = -1
+					if err := pb.Serialize(path, pbOffset); err != nil {
+						fmt.Printf("ADVERTENCIA: no se pudo restaurar el bloque de punteros %d: %v\n", ptr, err)
+					}
+				}
+			}
+		}
+		ptr = pb.P_pointers[i]
+	}
+	return ptr, len(allocated) > 0, nil
+}
+
+// TruncateBlocks libera los bloques de datos desde el bloque lógico keep en adelante y los
+// bloques de punteros que queden sin punteros. Actualiza I_block pero no guarda el inodo ni el
+// superbloque.
+func (sb *SuperBlock) TruncateBlocks(path string, inode *Inode, keep int64) error {
+	if inode.InlineSymlink() {
+		return errors.New("un enlace simbólico rápido no tiene bloques que truncar")
+	}
+	if keep < 0 {
+		keep = 0
+	}
+
+	// Recorta el subárbol de ptr (nivel level, primer bloque lógico base); devuelve si quedó vacío
+	var trim func(ptr int32, level int, base int64) (bool, error)
+	trim = func(ptr int32, level int, base int64) (bool, error) {
+		if base >= keep {
+			if level == 0 {
+				return true, freeDataBlockIfValid(ptr, sb, path)
+			}
+			return true, freeIndirectBlocksRecursive(level, ptr, sb, path)
+		}
+		if level == 0 || base+sb.blockSpan(level) <= keep {
+			return false, nil // Todo el subárbol está antes de keep
+		}
+		pb, err := sb.readPointerBlock(path, ptr)
+		if err != nil {
+			return false, err
+		}
+		span := sb.blockSpan(level - 1)
+		empty, changed := true, false
+		for i, child := range pb.P_pointers {
+			if child == -1 {
+				continue
+			}
+			gone, err := trim(child, level-1, base+int64(i)*span)
+			if err != nil {
+				return false, err
+			}
+			if gone {
+				pb.P_pointers[i] = -1
+				changed = true
+			} else {
+				empty = false
+			}
+		}
+		if empty {
+			fmt.Printf("Truncate: bloque de punteros nivel %d (%d) quedó vacío, se libera\n", level, ptr)
+			return true, freeDataBlockIfValid(ptr, sb, path)
+		}
+		if changed {
+			if err := pb.Serialize(path, int64(sb.S_block_start)+int64(ptr)*int64(sb.S_block_size)); err != nil {
+				return false, fmt.Errorf("error guardando bloque de punteros %d: %w", ptr, err)
+			}
+		}
+		return false, nil
+	}
+
+	base := int64(0)
+	for k := range inode.I_block {
+		level := 0
+		if k >= 12 {
+			level = k - 11
+		}
+		if inode.I_block[k] != -1 {
+			gone, err := trim(inode.I_block[k], level, base)
+			if err != nil {
+				return err
+			}
+			if gone {
+				inode.I_block[k] = -1
+			}
+		}
+		base += sb.blockSpan(level)
+	}
+	return nil
+}
+
+// TruncateFile cambia el tamaño de un archivo a size bytes. Al achicar libera los bloques que
+// quedan fuera; al agrandar no asigna nada, la parte nueva es un hueco. En ambos casos pone en
+// cero el resto del último bloque para que lo que se agregue después se lea como ceros. No guarda
+// el inodo ni el superbloque.
+func (sb *SuperBlock) TruncateFile(path string, inode *Inode, size int64) error {
+	if size < 0 {
+		return fmt.Errorf("tamaño inválido: %d", size)
+	}
+	if size > sb.MaxFileSize() {
+		return fmt.Errorf("el tamaño %d excede el máximo de %d bytes con bloques de %d bytes", size, sb.MaxFileSize(), sb.S_block_size)
+	}
+	bs := int64(sb.S_block_size)
+
+	// Poner en cero la cola del bloque que queda parcialmente dentro del archivo
+	if tail := min(size, inode.I_size); tail%bs != 0 {
+		blockIndex, err := sb.BlockAt(path, inode, tail/bs)
+		if err != nil {
+			return err
+		}
+		if blockIndex != -1 {
+			offset := int64(sb.S_block_start) + int64(blockIndex)*bs
+			fileBlock := NewFileBlock(sb.S_block_size)
+			if err := fileBlock.Deserialize(path, offset); err != nil {
+				return fmt.Errorf("error leyendo bloque de datos %d: %w", blockIndex, err)
+			}
+			clear(fileBlock.B_content[tail%bs:])
+			if err := fileBlock.Serialize(path, offset); err != nil {
+				return fmt.Errorf("error guardando bloque de datos %d: %w", blockIndex, err)
+			}
+		}
+	}
+
+	if err := sb.TruncateBlocks(path, inode, (size+bs-1)/bs); err != nil {
+		return err
+	}
+	inode.I_size = size
+	return nil
+}
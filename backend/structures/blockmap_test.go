@@ -0,0 +1,349 @@
+package structures
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// newTestFS crea un sistema de archivos vacío sobre un MemDevice: superbloque al inicio del
+// disco, bitmaps, tabla de inodos y blocks bloques. Devuelve el superbloque y el path con que se
+// registró el dispositivo.
+func newTestFS(t *testing.T, blockSize int32, features int32, blocks int32) (*SuperBlock, string) {
+	t.Helper()
+	const inodes = 16
+	sb := &SuperBlock{
+		S_filesystem_type:   2,
+		S_inodes_count:      inodes,
+		S_blocks_count:      blocks,
+		S_free_inodes_count: inodes,
+		S_free_blocks_count: blocks,
+		S_magic:             0xEF53,
+		S_block_size:        blockSize,
+		S_inode_size:        InodeDiskSize(features),
+		S_ext_magic:         SuperBlockExtMagic,
+		S_feature_incompat:  features,
+	}
+	sb.S_bm_inode_start = sb.DiskSize()
+	sb.S_bm_block_start = sb.S_bm_inode_start + int32(sb.BitmapBytes(inodes))
+	sb.S_inode_start = sb.S_bm_block_start + int32(sb.BitmapBytes(blocks))
+	sb.S_block_start = sb.S_inode_start + inodes*sb.S_inode_size
+
+	path := "/mem/" + strings.ReplaceAll(t.Name(), "/", "_") + ".mia"
+	RegisterDevice(path, NewMemDevice(int64(sb.S_block_start)+int64(blocks)*int64(blockSize)))
+	t.Cleanup(func() {
+		DropFreeIndex(path)
+		UnregisterDevice(path)
+	})
+	if err := sb.CreateBitMaps(path); err != nil {
+		t.Fatalf("CreateBitMaps: %v", err)
+	}
+	return sb, path
+}
+
+// newTestFile devuelve un inodo de archivo vacío, sin bloques.
+func newTestFile() *Inode {
+	inode := &Inode{I_type: [1]byte{'1'}, I_perm: [3]byte{'6', '6', '4'}, I_links: 1, I_xattr: -1}
+	for i := range inode.I_block {
+		inode.I_block[i] = -1
+	}
+	return inode
+}
+
+// blockPattern es el contenido que se escribe en el bloque lógico n: distinto en cada bloque.
+func blockPattern(n int64, blockSize int32) []byte {
+	return bytes.Repeat([]byte(fmt.Sprintf("%08d", n%100000000)), int(blockSize)/8)
+}
+
+func usedBlocks(t *testing.T, sb *SuperBlock, path string) int {
+	t.Helper()
+	bitmap, err := sb.ReadBlockBitmap(path)
+	if err != nil {
+		t.Fatalf("ReadBlockBitmap: %v", err)
+	}
+	return bytes.Count(bitmap, []byte{'1'})
+}
+
+// Bloques lógicos en los bordes de cada nivel de indirección con P punteros por bloque.
+func boundaryBlocks(p int64) (lastDirect, firstSingle, lastSingle, firstDouble, lastDouble, firstTriple, lastTriple int64) {
+	return 11, 12, 12 + p - 1, 12 + p, 12 + p + p*p - 1, 12 + p + p*p, 12 + p + p*p + p*p*p - 1
+}
+
+func TestBlockSlot(t *testing.T) {
+	for _, blockSize := range []int32{64, 1024} {
+		sb := &SuperBlock{S_block_size: blockSize}
+		p := int64(PointersPerBlock(blockSize))
+		lastDirect, firstSingle, lastSingle, firstDouble, lastDouble, firstTriple, lastTriple := boundaryBlocks(p)
+		tests := []struct {
+			n     int64
+			slot  int
+			level int
+			base  int64
+		}{
+			{0, 0, 0, 0},
+			{lastDirect, 11, 0, lastDirect},
+			{firstSingle, 12, 1, firstSingle},
+			{lastSingle, 12, 1, firstSingle},
+			{firstDouble, 13, 2, firstDouble},
+			{lastDouble, 13, 2, firstDouble},
+			{firstTriple, 14, 3, firstTriple},
+			{lastTriple, 14, 3, firstTriple},
+		}
+		for _, tt := range tests {
+			slot, level, base, err := sb.blockSlot(tt.n)
+			if err != nil {
+				t.Errorf("bs=%d blockSlot(%d): error inesperado: %v", blockSize, tt.n, err)
+				continue
+			}
+			if slot != tt.slot || level != tt.level || base != tt.base {
+				t.Errorf("bs=%d blockSlot(%d) = (%d, %d, %d), se esperaba (%d, %d, %d)", blockSize, tt.n, slot, level, base, tt.slot, tt.level, tt.base)
+			}
+		}
+		for _, n := range []int64{-1, lastTriple + 1} {
+			if _, _, _, err := sb.blockSlot(n); err == nil {
+				t.Errorf("bs=%d blockSlot(%d): se esperaba error", blockSize, n)
+			}
+		}
+	}
+}
+
+// Escribe en los dos bloques de cada borde de indirección (y en el último bloque que alcanza la
+// indirección triple) y los vuelve a leer con BlockAt y ReadFileContent; después FreeInodeBlocks
+// debe devolver todos los bloques, también los de punteros.
+func TestAllocBlockAtBoundaries(t *testing.T) {
+	for _, fs := range []struct {
+		blockSize int32
+		features  int32
+	}{
+		{64, 0},
+		{1024, FeatureIncompatLargeFiles}, // Sin I_size de 64 bits no se llega al último bloque
+	} {
+		p := int64(PointersPerBlock(fs.blockSize))
+		lastDirect, firstSingle, lastSingle, firstDouble, lastDouble, firstTriple, lastTriple := boundaryBlocks(p)
+		tests := []struct {
+			name          string
+			blocks        []int64 // Bloques lógicos que se escriben
+			pointerBlocks int     // Bloques de punteros que deben quedar asignados
+		}{
+			{"directo/simple", []int64{lastDirect, firstSingle}, 1},
+			{"simple/doble", []int64{lastSingle, firstDouble}, 1 + 2},
+			{"doble/triple", []int64{lastDouble, firstTriple}, 2 + 3},
+			{"último", []int64{lastTriple}, 3},
+		}
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("bs%d/%s", fs.blockSize, tt.name), func(t *testing.T) {
+				sb, path := newTestFS(t, fs.blockSize, fs.features, 64)
+				inode := newTestFile()
+				bs := int64(fs.blockSize)
+
+				for _, n := range tt.blocks {
+					blockIndex, created, err := sb.AllocBlockAt(path, inode, n)
+					if err != nil {
+						t.Fatalf("AllocBlockAt(%d): %v", n, err)
+					}
+					if !created {
+						t.Fatalf("AllocBlockAt(%d): el bloque ya existía", n)
+					}
+					again, created, err := sb.AllocBlockAt(path, inode, n)
+					if err != nil || created || again != blockIndex {
+						t.Fatalf("AllocBlockAt(%d) otra vez = (%d, %v, %v), se esperaba (%d, false, nil)", n, again, created, err, blockIndex)
+					}
+					block := &FileBlock{B_content: blockPattern(n, fs.blockSize)}
+					if err := block.Serialize(path, int64(sb.S_block_start)+int64(blockIndex)*bs); err != nil {
+						t.Fatalf("escribiendo bloque %d: %v", blockIndex, err)
+					}
+				}
+				last := tt.blocks[len(tt.blocks)-1]
+				inode.I_size = (last + 1) * bs
+
+				if got, want := usedBlocks(t, sb, path), len(tt.blocks)+tt.pointerBlocks; got != want {
+					t.Errorf("bloques usados = %d, se esperaban %d", got, want)
+				}
+				if got := int(sb.S_blocks_count - sb.S_free_blocks_count); got != len(tt.blocks)+tt.pointerBlocks {
+					t.Errorf("S_free_blocks_count no coincide con el bitmap: %d usados", got)
+				}
+
+				// Los bloques escritos se encuentran y los vecinos sin escribir son huecos
+				for _, n := range tt.blocks {
+					blockIndex, err := sb.BlockAt(path, inode, n)
+					if err != nil || blockIndex == -1 {
+						t.Fatalf("BlockAt(%d) = (%d, %v), se esperaba un bloque", n, blockIndex, err)
+					}
+					block := NewFileBlock(fs.blockSize)
+					if err := block.Deserialize(path, int64(sb.S_block_start)+int64(blockIndex)*bs); err != nil {
+						t.Fatalf("leyendo bloque %d: %v", blockIndex, err)
+					}
+					if !bytes.Equal(block.B_content, blockPattern(n, fs.blockSize)) {
+						t.Errorf("bloque lógico %d: contenido distinto al escrito", n)
+					}
+				}
+				for _, n := range []int64{0, tt.blocks[0] - 1} {
+					if blockIndex, err := sb.BlockAt(path, inode, n); err != nil || blockIndex != -1 {
+						t.Errorf("BlockAt(%d) = (%d, %v), se esperaba un hueco", n, blockIndex, err)
+					}
+				}
+
+				// ReadFileContent arma el archivo completo: huecos en cero y los bloques escritos
+				if inode.I_size <= ReadFileContentMax {
+					content, err := ReadFileContent(sb, path, inode)
+					if err != nil {
+						t.Fatalf("ReadFileContent: %v", err)
+					}
+					if int64(len(content)) != inode.I_size {
+						t.Fatalf("ReadFileContent leyó %d bytes, se esperaban %d", len(content), inode.I_size)
+					}
+					want := make([]byte, inode.I_size)
+					for _, n := range tt.blocks {
+						copy(want[n*bs:], blockPattern(n, fs.blockSize))
+					}
+					if content != string(want) {
+						t.Errorf("ReadFileContent: contenido distinto al escrito")
+					}
+				} else if _, err := ReadFileContent(sb, path, inode); err == nil {
+					t.Errorf("ReadFileContent de %d bytes: se esperaba error por exceder %d", inode.I_size, ReadFileContentMax)
+				}
+
+				if err := FreeInodeBlocks(inode, sb, path); err != nil {
+					t.Fatalf("FreeInodeBlocks: %v", err)
+				}
+				if got := usedBlocks(t, sb, path); got != 0 {
+					t.Errorf("después de FreeInodeBlocks quedan %d bloques usados", got)
+				}
+				if sb.S_free_blocks_count != sb.S_blocks_count {
+					t.Errorf("S_free_blocks_count = %d, se esperaba %d", sb.S_free_blocks_count, sb.S_blocks_count)
+				}
+				for k, ptr := range inode.I_block {
+					if ptr != -1 {
+						t.Errorf("I_block[%d] = %d después de FreeInodeBlocks", k, ptr)
+					}
+				}
+			})
+		}
+
+		t.Run(fmt.Sprintf("bs%d/fuera de rango", fs.blockSize), func(t *testing.T) {
+			sb, path := newTestFS(t, fs.blockSize, fs.features, 64)
+			if _, _, err := sb.AllocBlockAt(path, newTestFile(), lastTriple+1); err == nil {
+				t.Errorf("AllocBlockAt(%d): se esperaba error", lastTriple+1)
+			}
+			if got := usedBlocks(t, sb, path); got != 0 {
+				t.Errorf("el intento fallido dejó %d bloques usados", got)
+			}
+		})
+	}
+}
+
+// Archivos completos (sin huecos) de tamaños en los bordes de indirección: deben ocupar lo que
+// calcula BlocksForFile y FreeInodeBlocks debe liberar todos sus bloques de datos y de punteros.
+func TestFreeInodeBlocksSizes(t *testing.T) {
+	const blockSize = 64
+	p := int64(PointersPerBlock(blockSize))
+	_, firstSingle, lastSingle, firstDouble, lastDouble, firstTriple, lastTriple := boundaryBlocks(p)
+	for _, count := range []int64{
+		firstSingle, firstSingle + 1, // Solo directos / primer bloque de la indirección simple
+		lastSingle + 1, firstDouble + 1, // Simple llena / primer bloque de la doble
+		lastDouble + 1, firstTriple + 1, // Doble llena / primer bloque de la triple
+		lastTriple + 1, // Tamaño máximo
+	} {
+		t.Run(fmt.Sprintf("%d bloques", count), func(t *testing.T) {
+			sb, path := newTestFS(t, blockSize, 0, int32(lastTriple)+512)
+			inode := newTestFile()
+			for n := int64(0); n < count; n++ {
+				if _, _, err := sb.AllocBlockAt(path, inode, n); err != nil {
+					t.Fatalf("AllocBlockAt(%d): %v", n, err)
+				}
+			}
+			inode.I_size = count * blockSize
+
+			want := int(sb.BlocksForFile(int32(count)))
+			if got := usedBlocks(t, sb, path); got != want {
+				t.Errorf("bloques usados = %d, BlocksForFile = %d", got, want)
+			}
+			if _, all, err := sb.InodeBlocks(path, inode); err != nil || len(all) != want {
+				t.Errorf("InodeBlocks = %d bloques (%v), se esperaban %d", len(all), err, want)
+			}
+
+			if err := FreeInodeBlocks(inode, sb, path); err != nil {
+				t.Fatalf("FreeInodeBlocks: %v", err)
+			}
+			if got := usedBlocks(t, sb, path); got != 0 {
+				t.Errorf("después de FreeInodeBlocks quedan %d bloques usados", got)
+			}
+			if sb.S_free_blocks_count != sb.S_blocks_count {
+				t.Errorf("S_free_blocks_count = %d, se esperaba %d", sb.S_free_blocks_count, sb.S_blocks_count)
+			}
+		})
+	}
+}
+
+// Si el disco se llena a mitad de AllocBlockAt, los bloques de punteros que alcanzó a asignar se
+// liberan y el puntero que los enlazaba vuelve a -1.
+func TestAllocBlockAtDiskFull(t *testing.T) {
+	const blockSize = 64
+	p := int64(PointersPerBlock(blockSize))
+	_, _, _, firstDouble, lastDouble, _, _ := boundaryBlocks(p)
+
+	t.Run("desde I_block", func(t *testing.T) {
+		sb, path := newTestFS(t, blockSize, 0, 2) // La doble indirección necesita 3 bloques
+		inode := newTestFile()
+		if _, _, err := sb.AllocBlockAt(path, inode, firstDouble); err == nil {
+			t.Fatalf("AllocBlockAt(%d): se esperaba error por disco lleno", firstDouble)
+		}
+		if inode.I_block[13] != -1 {
+			t.Errorf("I_block[13] = %d, se esperaba -1", inode.I_block[13])
+		}
+		if got := usedBlocks(t, sb, path); got != 0 {
+			t.Errorf("el intento fallido dejó %d bloques usados", got)
+		}
+		if sb.S_free_blocks_count != sb.S_blocks_count {
+			t.Errorf("S_free_blocks_count = %d, se esperaba %d", sb.S_free_blocks_count, sb.S_blocks_count)
+		}
+	})
+
+	t.Run("desde un bloque de punteros", func(t *testing.T) {
+		sb, path := newTestFS(t, blockSize, 0, 4)
+		inode := newTestFile()
+		if _, _, err := sb.AllocBlockAt(path, inode, firstDouble); err != nil {
+			t.Fatalf("AllocBlockAt(%d): %v", firstDouble, err)
+		}
+		// lastDouble necesita otro bloque de punteros simple y uno de datos, pero queda uno libre
+		if _, _, err := sb.AllocBlockAt(path, inode, lastDouble); err == nil {
+			t.Fatalf("AllocBlockAt(%d): se esperaba error por disco lleno", lastDouble)
+		}
+		if got := usedBlocks(t, sb, path); got != 3 {
+			t.Errorf("bloques usados = %d, se esperaban 3", got)
+		}
+		if got := int(sb.S_blocks_count - sb.S_free_blocks_count); got != 3 {
+			t.Errorf("S_free_blocks_count no coincide con el bitmap: %d usados", got)
+		}
+		// El bloque de punteros doble no debe apuntar al bloque liberado
+		if blockIndex, err := sb.BlockAt(path, inode, lastDouble); err != nil || blockIndex != -1 {
+			t.Errorf("BlockAt(%d) = (%d, %v), se esperaba un hueco", lastDouble, blockIndex, err)
+		}
+		inode.I_size = (firstDouble + 1) * blockSize
+		if err := FreeInodeBlocks(inode, sb, path); err != nil {
+			t.Fatalf("FreeInodeBlocks: %v", err)
+		}
+		if got := usedBlocks(t, sb, path); got != 0 {
+			t.Errorf("después de FreeInodeBlocks quedan %d bloques usados", got)
+		}
+	})
+
+	t.Run("File.WriteAt", func(t *testing.T) {
+		sb, path := newTestFS(t, blockSize, 0, 2)
+		inode := newTestFile()
+		if err := inode.Serialize(sb, path, int64(sb.S_inode_start)); err != nil {
+			t.Fatalf("Serialize: %v", err)
+		}
+		file, err := sb.OpenFile(path, 0)
+		if err != nil {
+			t.Fatalf("OpenFile: %v", err)
+		}
+		if n, err := file.WriteAt([]byte("x"), firstDouble*blockSize); err == nil || n != 0 {
+			t.Fatalf("WriteAt = (%d, %v), se esperaba error por disco lleno", n, err)
+		}
+		if got := usedBlocks(t, sb, path); got != 0 {
+			t.Errorf("el intento fallido dejó %d bloques usados", got)
+		}
+	})
+}
@@ -0,0 +1,315 @@
+package structures
+
+import (
+	"container/list"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Modos del caché de los discos montados (CacheMode)
+const (
+	CacheOff          = "off"          // Sin caché: cada acceso va al archivo
+	CacheWriteThrough = "writethrough" // Las escrituras van al archivo y actualizan el caché
+	CacheWriteBack    = "writeback"    // Las escrituras quedan en el caché hasta sync/unmount/logout
+)
+
+// Configuración del caché que usa AttachDisk; se fija al iniciar el servidor. Por defecto las
+// escrituras llegan al archivo en el momento (write-through); write-back se activa con
+// MIA_CACHE_MODE=writeback y deja los cambios en memoria hasta sync, unmount o logout.
+var (
+	CacheMode     = CacheWriteThrough
+	CachePages    = 1024 // Páginas por disco montado
+	CachePageSize = 512  // Bytes por página: cubre varios inodos, bloques o un tramo de bitmap
+)
+
+// CacheStats son los contadores de un CachedDevice.
+type CacheStats struct {
+	Hits       uint64 // Accesos resueltos en memoria
+	Misses     uint64 // Accesos que tuvieron que leer del disco
+	Evictions  uint64 // Páginas descartadas por falta de espacio
+	Writebacks uint64 // Páginas sucias escritas al disco
+	Pages      int    // Páginas en memoria
+	Dirty      int    // Páginas con cambios pendientes
+}
+
+type cachePage struct {
+	index int64
+	data  []byte
+	dirty bool
+}
+
+// CachedDevice es un caché LRU de páginas sobre otro BlockDevice. Como inodos, bloques y
+// bitmaps viven en el mismo archivo, una sola caché por disco sirve a todas sus estructuras.
+type CachedDevice struct {
+	mu        sync.Mutex
+	dev       BlockDevice
+	writeBack bool
+	pageSize  int64
+	capacity  int
+	pages     map[int64]*list.Element
+	lru       *list.List // Frente: página usada más recientemente
+	extent    int64      // Tamaño lógico del disco, incluyendo escrituras aún no bajadas
+	stats     CacheStats
+}
+
+// NewCachedDevice envuelve dev con un caché de capacity páginas de pageSize bytes.
+func NewCachedDevice(dev BlockDevice, mode string, capacity int, pageSize int) (*CachedDevice, error) {
+	if mode != CacheWriteThrough && mode != CacheWriteBack {
+		return nil, fmt.Errorf("modo de caché inválido: '%s'", mode)
+	}
+	if capacity <= 0 || pageSize <= 0 {
+		return nil, fmt.Errorf("tamaño de caché inválido: %d páginas de %d bytes", capacity, pageSize)
+	}
+	size, err := dev.Size()
+	if err != nil {
+		return nil, err
+	}
+	return &CachedDevice{
+		dev:       dev,
+		writeBack: mode == CacheWriteBack,
+		pageSize:  int64(pageSize),
+		capacity:  capacity,
+		pages:     make(map[int64]*list.Element),
+		lru:       list.New(),
+		extent:    size,
+	}, nil
+}
+
+func (c *CachedDevice) Mode() string {
+	if c.writeBack {
+		return CacheWriteBack
+	}
+	return CacheWriteThrough
+}
+
+// Devuelve la página index, leyéndola del disco si no está. Con load=false (la página se va a
+// sobrescribir completa) no se lee nada.
+func (c *CachedDevice) page(index int64, load bool) (*cachePage, error) {
+	if elem, ok := c.pages[index]; ok {
+		c.stats.Hits++
+		c.lru.MoveToFront(elem)
+		return elem.Value.(*cachePage), nil
+	}
+	c.stats.Misses++
+
+	p := &cachePage{index: index, data: make([]byte, c.pageSize)}
+	if load {
+		start := index * c.pageSize
+		if start+c.pageSize > c.extent {
+			// El archivo pudo crecer por fuera (growdisk)
+			if size, err := c.dev.Size(); err == nil && size > c.extent {
+				c.extent = size
+			}
+		}
+		if start < c.extent {
+			if _, err := c.dev.ReadAt(p.data, start); err != nil && err != io.EOF {
+				return nil, err
+			}
+		}
+	}
+
+	for c.lru.Len() >= c.capacity {
+		if err := c.evict(); err != nil {
+			return nil, err
+		}
+	}
+	c.pages[index] = c.lru.PushFront(p)
+	return p, nil
+}
+
+func (c *CachedDevice) evict() error {
+	elem := c.lru.Back()
+	p := elem.Value.(*cachePage)
+	if err := c.writePage(p); err != nil {
+		return err
+	}
+	c.lru.Remove(elem)
+	delete(c.pages, p.index)
+	c.stats.Evictions++
+	return nil
+}
+
+// Baja una página sucia al disco sin pasar del tamaño lógico.
+func (c *CachedDevice) writePage(p *cachePage) error {
+	if !p.dirty {
+		return nil
+	}
+	start := p.index * c.pageSize
+	length := c.pageSize
+	if start+length > c.extent {
+		length = c.extent - start
+	}
+	if length > 0 {
+		if _, err := c.dev.WriteAt(p.data[:length], start); err != nil {
+			return fmt.Errorf("error escribiendo página %d del caché: %w", p.index, err)
+		}
+	}
+	p.dirty = false
+	c.stats.Writebacks++
+	return nil
+}
+
+func (c *CachedDevice) ReadAt(b []byte, off int64) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if off < 0 {
+		return 0, fmt.Errorf("offset negativo: %d", off)
+	}
+
+	n := 0
+	for n < len(b) {
+		pos := off + int64(n)
+		if pos >= c.extent {
+			if size, err := c.dev.Size(); err == nil && size > c.extent {
+				c.extent = size
+			}
+			if pos >= c.extent {
+				return n, io.EOF
+			}
+		}
+		p, err := c.page(pos/c.pageSize, true)
+		if err != nil {
+			return n, err
+		}
+		inPage := pos % c.pageSize
+		end := int64(len(p.data))
+		if limit := c.extent - pos + inPage; limit < end {
+			end = limit
+		}
+		n += copy(b[n:], p.data[inPage:end])
+	}
+	return n, nil
+}
+
+func (c *CachedDevice) WriteAt(b []byte, off int64) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if off < 0 {
+		return 0, fmt.Errorf("offset negativo: %d", off)
+	}
+
+	if !c.writeBack {
+		// Write-through: primero el disco, luego las páginas que ya estén en memoria
+		n, err := c.dev.WriteAt(b, off)
+		if end := off + int64(n); end > c.extent {
+			c.extent = end
+		}
+		for i := int64(0); i < int64(n); {
+			pos := off + i
+			inPage := pos % c.pageSize
+			chunk := c.pageSize - inPage
+			if chunk > int64(n)-i {
+				chunk = int64(n) - i
+			}
+			if elem, ok := c.pages[pos/c.pageSize]; ok {
+				copy(elem.Value.(*cachePage).data[inPage:], b[i:i+chunk])
+			}
+			i += chunk
+		}
+		return n, err
+	}
+
+	n := 0
+	for n < len(b) {
+		pos := off + int64(n)
+		inPage := pos % c.pageSize
+		chunk := c.pageSize - inPage
+		if chunk > int64(len(b)-n) {
+			chunk = int64(len(b) - n)
+		}
+		p, err := c.page(pos/c.pageSize, chunk != c.pageSize)
+		if err != nil {
+			return n, err
+		}
+		copy(p.data[inPage:], b[n:n+int(chunk)])
+		p.dirty = true
+		n += int(chunk)
+		if pos+chunk > c.extent {
+			c.extent = pos + chunk
+		}
+	}
+	return n, nil
+}
+
+// Flush baja todas las páginas sucias en orden de posición.
+func (c *CachedDevice) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.flushLocked()
+}
+
+func (c *CachedDevice) flushLocked() error {
+	dirty := []*cachePage{}
+	for _, elem := range c.pages {
+		if p := elem.Value.(*cachePage); p.dirty {
+			dirty = append(dirty, p)
+		}
+	}
+	sort.Slice(dirty, func(i, j int) bool { return dirty[i].index < dirty[j].index })
+	for _, p := range dirty {
+		if err := c.writePage(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Invalidate baja lo pendiente y descarta todas las páginas; se usa después de escribir en el
+// archivo sin pasar por el dispositivo (importpart, clonedisk).
+func (c *CachedDevice) Invalidate() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.flushLocked(); err != nil {
+		return err
+	}
+	c.pages = make(map[int64]*list.Element)
+	c.lru.Init()
+	if size, err := c.dev.Size(); err == nil {
+		c.extent = size
+	}
+	return nil
+}
+
+func (c *CachedDevice) Sync() error {
+	if err := c.Flush(); err != nil {
+		return err
+	}
+	return c.dev.Sync()
+}
+
+func (c *CachedDevice) Size() (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	size, err := c.dev.Size()
+	if err != nil {
+		return 0, err
+	}
+	if size > c.extent {
+		c.extent = size
+	}
+	return c.extent, nil
+}
+
+func (c *CachedDevice) Close() error {
+	if err := c.Sync(); err != nil {
+		c.dev.Close()
+		return err
+	}
+	return c.dev.Close()
+}
+
+// Stats devuelve una copia de los contadores.
+func (c *CachedDevice) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Pages = c.lru.Len()
+	for _, elem := range c.pages {
+		if elem.Value.(*cachePage).dirty {
+			stats.Dirty++
+		}
+	}
+	return stats
+}
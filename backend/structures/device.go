@@ -0,0 +1,335 @@
+package structures
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// BlockDevice es el medio donde vive un disco. Todas las estructuras leen y escriben a través
+// de él, así un disco montado se abre una sola vez y se pueden usar dispositivos en memoria o
+// en capas (caché, cifrado) sin cambiar las estructuras.
+type BlockDevice interface {
+	io.ReaderAt
+	io.WriterAt
+	Sync() error
+	Size() (int64, error)
+	Close() error
+}
+
+// --- Dispositivo sobre un archivo .mia ---
+
+type fileDevice struct {
+	file *os.File
+}
+
+// OpenFileDevice abre el archivo del disco en lectura/escritura (o solo lectura si no hay permiso).
+func OpenFileDevice(path string) (BlockDevice, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if errors.Is(err, os.ErrPermission) {
+		file, err = os.Open(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &fileDevice{file: file}, nil
+}
+
+func (d *fileDevice) ReadAt(p []byte, off int64) (int, error)  { return d.file.ReadAt(p, off) }
+func (d *fileDevice) WriteAt(p []byte, off int64) (int, error) { return d.file.WriteAt(p, off) }
+func (d *fileDevice) Sync() error                              { return d.file.Sync() }
+func (d *fileDevice) Close() error                             { return d.file.Close() }
+
+func (d *fileDevice) Size() (int64, error) {
+	info, err := d.file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// --- Dispositivo en memoria ---
+
+// MemDevice guarda el disco completo en memoria; crece al escribir más allá del final.
+type MemDevice struct {
+	mu   sync.RWMutex
+	data []byte
+}
+
+func NewMemDevice(size int64) *MemDevice {
+	return &MemDevice{data: make([]byte, size)}
+}
+
+func (d *MemDevice) ReadAt(p []byte, off int64) (int, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if off < 0 {
+		return 0, fmt.Errorf("offset negativo: %d", off)
+	}
+	if off >= int64(len(d.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, d.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (d *MemDevice) WriteAt(p []byte, off int64) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if off < 0 {
+		return 0, fmt.Errorf("offset negativo: %d", off)
+	}
+	if end := off + int64(len(p)); end > int64(len(d.data)) {
+		d.data = append(d.data, make([]byte, end-int64(len(d.data)))...)
+	}
+	return copy(d.data[off:], p), nil
+}
+
+func (d *MemDevice) Sync() error  { return nil }
+func (d *MemDevice) Close() error { return nil }
+
+func (d *MemDevice) Size() (int64, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return int64(len(d.data)), nil
+}
+
+// --- Dispositivos abiertos ---
+
+type attachedDevice struct {
+	dev  BlockDevice
+	refs int // Particiones montadas que lo usan (-1: registrado con RegisterDevice)
+}
+
+var (
+	devicesMu sync.Mutex
+	devices   = make(map[string]*attachedDevice) // path absoluto -> dispositivo abierto
+)
+
+func deviceKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// AttachDisk deja abierto el disco mientras tenga particiones montadas. Cada llamada debe
+// tener su DetachDisk.
+func AttachDisk(path string) error {
+	devicesMu.Lock()
+	defer devicesMu.Unlock()
+	key := deviceKey(path)
+	if attached, ok := devices[key]; ok {
+		if attached.refs >= 0 {
+			attached.refs++
+		}
+		return nil
+	}
+	dev, err := OpenFileDevice(path)
+	if err != nil {
+		return fmt.Errorf("error abriendo disco '%s': %w", path, err)
+	}
+	if CacheMode != CacheOff {
+		cached, err := NewCachedDevice(dev, CacheMode, CachePages, CachePageSize)
+		if err != nil {
+			dev.Close()
+			return fmt.Errorf("error creando caché para '%s': %w", path, err)
+		}
+		dev = cached
+	}
+	devices[key] = &attachedDevice{dev: dev, refs: 1}
+	return nil
+}
+
+// DetachDisk libera una referencia del disco y lo sincroniza y cierra con la última.
+func DetachDisk(path string) error {
+	devicesMu.Lock()
+	defer devicesMu.Unlock()
+	key := deviceKey(path)
+	attached, ok := devices[key]
+	if !ok || attached.refs < 0 {
+		return nil
+	}
+	attached.refs--
+	if attached.refs > 0 {
+		return nil
+	}
+	delete(devices, key)
+	if err := attached.dev.Sync(); err != nil {
+		attached.dev.Close()
+		return fmt.Errorf("error sincronizando disco '%s': %w", path, err)
+	}
+	return attached.dev.Close()
+}
+
+// RegisterDevice asocia un dispositivo propio (en memoria, en capas...) a un path. Queda
+// registrado hasta UnregisterDevice, sin importar montajes.
+func RegisterDevice(path string, dev BlockDevice) {
+	devicesMu.Lock()
+	defer devicesMu.Unlock()
+	devices[deviceKey(path)] = &attachedDevice{dev: dev, refs: -1}
+}
+
+// UnregisterDevice quita el dispositivo asociado a path (sin cerrarlo) y lo devuelve.
+func UnregisterDevice(path string) BlockDevice {
+	devicesMu.Lock()
+	defer devicesMu.Unlock()
+	key := deviceKey(path)
+	attached, ok := devices[key]
+	if !ok {
+		return nil
+	}
+	delete(devices, key)
+	return attached.dev
+}
+
+// AttachedDevice devuelve el dispositivo abierto para path, o nil si no hay ninguno.
+func AttachedDevice(path string) BlockDevice {
+	devicesMu.Lock()
+	defer devicesMu.Unlock()
+	if attached, ok := devices[deviceKey(path)]; ok {
+		return attached.dev
+	}
+	return nil
+}
+
+// SyncDisk fuerza a disco las escrituras pendientes del dispositivo de path, si está abierto.
+func SyncDisk(path string) error {
+	if dev := AttachedDevice(path); dev != nil {
+		return dev.Sync()
+	}
+	return nil
+}
+
+// SyncAllDisks sincroniza todos los dispositivos abiertos y devuelve los paths que fallaron.
+func SyncAllDisks() map[string]error {
+	failed := make(map[string]error)
+	for _, path := range AttachedDisks() {
+		if err := SyncDisk(path); err != nil {
+			failed[path] = err
+		}
+	}
+	return failed
+}
+
+// InvalidateDisk descarta lo que el dispositivo de path tenga en memoria, después de que el
+// archivo se modificó por fuera de él.
+func InvalidateDisk(path string) error {
+	DropFreeIndex(path)
+	if cached, ok := AttachedDevice(path).(*CachedDevice); ok {
+		return cached.Invalidate()
+	}
+	return nil
+}
+
+// AttachedDisks devuelve los paths de los discos abiertos, ordenados.
+func AttachedDisks() []string {
+	devicesMu.Lock()
+	defer devicesMu.Unlock()
+	paths := make([]string, 0, len(devices))
+	for path := range devices {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// --- Acceso tipo archivo ---
+
+// DiskFile es un cursor sobre el dispositivo de un disco con la interfaz de *os.File que usan
+// Serialize/Deserialize (Seek, Read, Write). Si el disco está abierto reutiliza ese
+// dispositivo; si no, abre el archivo y lo cierra con Close.
+type DiskFile struct {
+	dev   BlockDevice
+	pos   int64
+	owned bool // El dispositivo se abrió solo para este DiskFile
+}
+
+// OpenDisk devuelve un DiskFile para el disco en path.
+func OpenDisk(path string) (*DiskFile, error) {
+	if dev := AttachedDevice(path); dev != nil {
+		return &DiskFile{dev: dev}, nil
+	}
+	dev, err := OpenFileDevice(path)
+	if err != nil {
+		return nil, err
+	}
+	return &DiskFile{dev: dev, owned: true}, nil
+}
+
+// OpenDiskReadOnly es como OpenDisk pero no permite escribir: el archivo se abre en solo lectura
+// y, si el disco ya está abierto, las escrituras a través de este DiskFile fallan.
+func OpenDiskReadOnly(path string) (*DiskFile, error) {
+	if dev := AttachedDevice(path); dev != nil {
+		return &DiskFile{dev: readOnlyDevice{dev}}, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &DiskFile{dev: readOnlyDevice{&fileDevice{file: file}}, owned: true}, nil
+}
+
+// readOnlyDevice rechaza las escrituras sobre otro dispositivo.
+type readOnlyDevice struct {
+	BlockDevice
+}
+
+func (d readOnlyDevice) WriteAt(p []byte, off int64) (int, error) {
+	return 0, fmt.Errorf("el disco está abierto en solo lectura (escritura de %d bytes en %d)", len(p), off)
+}
+
+func (f *DiskFile) Read(p []byte) (int, error) {
+	n, err := f.dev.ReadAt(p, f.pos)
+	f.pos += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil // Igual que os.File: el EOF se informa en la siguiente lectura
+	}
+	return n, err
+}
+
+func (f *DiskFile) Write(p []byte) (int, error) {
+	n, err := f.dev.WriteAt(p, f.pos)
+	f.pos += int64(n)
+	return n, err
+}
+
+func (f *DiskFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.pos
+	case io.SeekEnd:
+		size, err := f.dev.Size()
+		if err != nil {
+			return f.pos, err
+		}
+		offset += size
+	default:
+		return f.pos, fmt.Errorf("whence inválido: %d", whence)
+	}
+	if offset < 0 {
+		return f.pos, fmt.Errorf("offset negativo: %d", offset)
+	}
+	f.pos = offset
+	return offset, nil
+}
+
+func (f *DiskFile) ReadAt(p []byte, off int64) (int, error)  { return f.dev.ReadAt(p, off) }
+func (f *DiskFile) WriteAt(p []byte, off int64) (int, error) { return f.dev.WriteAt(p, off) }
+func (f *DiskFile) Sync() error                              { return f.dev.Sync() }
+
+// Close cierra el dispositivo solo si se abrió para este DiskFile.
+func (f *DiskFile) Close() error {
+	if f.owned {
+		return f.dev.Close()
+	}
+	return nil
+}
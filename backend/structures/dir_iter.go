@@ -0,0 +1,227 @@
+package structures
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DirIterator recorre los bloques de una carpeta en orden lógico: I_block[0..11] y luego los
+// bloques alcanzados por los punteros indirectos simple, doble y triple (I_block[12..14]).
+type DirIterator struct {
+	sb     *SuperBlock
+	path   string
+	blocks []int32
+	pos    int
+	err    error
+	Index  int32     // Índice del bloque de carpeta actual
+	Block  *DirBlock // Entradas del bloque actual
+}
+
+// IterDir crea un iterador sobre los bloques de la carpeta dir.
+func (sb *SuperBlock) IterDir(path string, dir *Inode) *DirIterator {
+	it := &DirIterator{sb: sb, path: path, Index: -1}
+	if dir.I_type[0] != '0' {
+		it.err = errors.New("el inodo no es una carpeta")
+		return it
+	}
+	it.blocks, _, it.err = sb.InodeBlocks(path, dir)
+	return it
+}
+
+// Next avanza al siguiente bloque; devuelve false al terminar o ante un error (ver Err).
+func (it *DirIterator) Next() bool {
+	if it.err != nil || it.pos >= len(it.blocks) {
+		return false
+	}
+	it.Index = it.blocks[it.pos]
+	it.pos++
+	it.Block, it.err = it.sb.ReadDirBlock(it.path, it.Index)
+	return it.err == nil
+}
+
+// Err devuelve el error que detuvo el recorrido, si lo hubo.
+func (it *DirIterator) Err() error {
+	return it.err
+}
+
+// Save escribe en disco el bloque actual (después de modificar it.Block).
+func (it *DirIterator) Save() error {
+	return it.sb.WriteDirBlock(it.path, it.Index, it.Block)
+}
+
+// ReadDir devuelve todas las entradas de la carpeta, incluidas "." y "..".
+func (sb *SuperBlock) ReadDir(path string, dir *Inode) ([]DirEntry, error) {
+	entries := []DirEntry{}
+	it := sb.IterDir(path, dir)
+	for it.Next() {
+		entries = append(entries, it.Block.Entries...)
+	}
+	return entries, it.Err()
+}
+
+// LookupDir busca name en la carpeta dir.
+func (sb *SuperBlock) LookupDir(path string, dir *Inode, name string) (DirEntry, bool, error) {
+	it := sb.IterDir(path, dir)
+	for it.Next() {
+		if i := it.Block.Find(name); i != -1 {
+			return it.Block.Entries[i], true, nil
+		}
+	}
+	return DirEntry{}, false, it.Err()
+}
+
+// RemoveDirEntry quita de la carpeta la entrada name que apunta a inodeIndex.
+func (sb *SuperBlock) RemoveDirEntry(path string, dir *Inode, name string, inodeIndex int32) (bool, error) {
+	it := sb.IterDir(path, dir)
+	for it.Next() {
+		i := it.Block.Find(name)
+		if i == -1 || it.Block.Entries[i].Inode != inodeIndex {
+			continue
+		}
+		it.Block.Remove(i)
+		if err := it.Save(); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, it.Err()
+}
+
+// AddDirEntry agrega entry a la carpeta dirIndex. Si ningún bloque tiene espacio asigna uno nuevo
+// en el primer puntero libre (directo o indirecto) y guarda el inodo de la carpeta.
+func (sb *SuperBlock) AddDirEntry(path string, dirIndex int32, dir *Inode, entry DirEntry) error {
+	dirOffset := int64(sb.S_inode_start) + int64(dirIndex)*int64(sb.S_inode_size)
+	now := time.Now().Unix()
+
+	it := sb.IterDir(path, dir)
+	for it.Next() {
+		if !it.Block.Add(entry) {
+			continue
+		}
+		if err := it.Save(); err != nil {
+			return err
+		}
+		dir.I_mtime, dir.I_atime = now, now
+		return dir.Serialize(sb, path, dirOffset)
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	// Ningún bloque tiene espacio: asignar uno nuevo
+	blockIndex, err := sb.growDir(path, dir)
+	if err != nil {
+		return err
+	}
+	block := sb.NewDirBlock()
+	if !block.Add(entry) {
+		return fmt.Errorf("la entrada '%s' no cabe en un bloque vacío", entry.Name)
+	}
+	if err := sb.WriteDirBlock(path, blockIndex, block); err != nil {
+		return err
+	}
+	dir.I_mtime, dir.I_atime = now, now
+	return dir.Serialize(sb, path, dirOffset)
+}
+
+// Asigna un bloque libre del bitmap y lo descuenta del superbloque.
+func (sb *SuperBlock) allocBlock(path string) (int32, error) {
+	blockIndex, err := sb.FindFreeBlock(path)
+	if err != nil {
+		return -1, err
+	}
+	if err := sb.UpdateBitmapBlock(path, blockIndex, '1'); err != nil {
+		return -1, err
+	}
+	sb.S_free_blocks_count--
+	return blockIndex, nil
+}
+
+// Asigna un bloque de punteros con todos sus punteros en -1.
+func (sb *SuperBlock) allocPointerBlock(path string) (int32, error) {
+	blockIndex, err := sb.allocBlock(path)
+	if err != nil {
+		return -1, err
+	}
+	pb := NewPointerBlock(sb.S_block_size)
+	for i := range pb.P_pointers {
+		pb.P_pointers[i] = -1
+	}
+	if err := pb.Serialize(path, int64(sb.S_block_start)+int64(blockIndex)*int64(sb.S_block_size)); err != nil {
+		return -1, err
+	}
+	return blockIndex, nil
+}
+
+// Agrega un bloque de carpeta vacío en el primer puntero libre de dir (no guarda el inodo).
+func (sb *SuperBlock) growDir(path string, dir *Inode) (int32, error) {
+	for k := 0; k < 12; k++ {
+		if dir.I_block[k] != -1 {
+			continue
+		}
+		blockIndex, err := sb.allocBlock(path)
+		if err != nil {
+			return -1, fmt.Errorf("no hay bloques libres para ampliar la carpeta: %w", err)
+		}
+		dir.I_block[k] = blockIndex
+		return blockIndex, nil
+	}
+
+	for level := 1; level <= 3; level++ {
+		k := 11 + level
+		if dir.I_block[k] == -1 {
+			ptr, err := sb.allocPointerBlock(path)
+			if err != nil {
+				return -1, fmt.Errorf("no hay bloques libres para el puntero indirecto I_block[%d]: %w", k, err)
+			}
+			dir.I_block[k] = ptr
+		}
+		blockIndex, err := sb.growDirIndirect(path, dir.I_block[k], level)
+		if err != nil {
+			return -1, err
+		}
+		if blockIndex != -1 {
+			return blockIndex, nil
+		}
+	}
+	return -1, errors.New("la carpeta alcanzó el máximo de bloques direccionables")
+}
+
+// Busca un puntero libre bajo el bloque de punteros ptr (de nivel level) y cuelga de él un
+// bloque de carpeta nuevo. Devuelve -1 si el subárbol está lleno.
+func (sb *SuperBlock) growDirIndirect(path string, ptr int32, level int) (int32, error) {
+	offset := int64(sb.S_block_start) + int64(ptr)*int64(sb.S_block_size)
+	pb := NewPointerBlock(sb.S_block_size)
+	if err := pb.Deserialize(path, offset); err != nil {
+		return -1, fmt.Errorf("error leyendo bloque de punteros %d: %w", ptr, err)
+	}
+	for i, child := range pb.P_pointers {
+		if child == -1 {
+			var err error
+			if level == 1 {
+				child, err = sb.allocBlock(path)
+			} else {
+				child, err = sb.allocPointerBlock(path)
+			}
+			if err != nil {
+				return -1, fmt.Errorf("no hay bloques libres para ampliar la carpeta: %w", err)
+			}
+			pb.P_pointers[i] = child
+			if err := pb.Serialize(path, offset); err != nil {
+				return -1, fmt.Errorf("error escribiendo bloque de punteros %d: %w", ptr, err)
+			}
+			if level == 1 {
+				return child, nil
+			}
+		}
+		if level == 1 {
+			continue // Bloque de carpeta existente (sin espacio)
+		}
+		blockIndex, err := sb.growDirIndirect(path, child, level-1)
+		if err != nil || blockIndex != -1 {
+			return blockIndex, err
+		}
+	}
+	return -1, nil
+}
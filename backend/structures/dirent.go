@@ -0,0 +1,281 @@
+package structures
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Formato de entrada de longitud variable (FeatureIncompatLongNames):
+//
+//	inodo (int32) | rec_len (uint16) | name_len (uint8) | tipo (uint8) | nombre (name_len bytes)
+//
+// rec_len es la distancia a la siguiente entrada (múltiplo de 4); la última entrada se extiende
+// hasta el final del bloque. Un bloque vacío tiene una sola entrada con inodo -1.
+const (
+	direntHeaderSize = 8
+	direntAlign      = 4
+	LongNameMax      = 255 // Límite de name_len
+	ClassicNameMax   = 11  // B_name [12]byte con el nulo final
+)
+
+// DirEntry es una entrada de directorio en uso, independiente del formato en disco.
+type DirEntry struct {
+	Inode int32
+	Type  byte // I_type del inodo ('0' carpeta, '1' archivo, '2' enlace); 0 si no se conoce (formato clásico)
+	Name  string
+}
+
+// DirBlock es un bloque de carpeta decodificado: solo contiene las entradas en uso.
+type DirBlock struct {
+	Entries []DirEntry
+	size    int32 // Tamaño del bloque en bytes
+	long    bool  // Entradas de longitud variable
+}
+
+// LongNames indica si los directorios usan entradas de longitud variable.
+func (sb *SuperBlock) LongNames() bool {
+	return sb.HasIncompat(FeatureIncompatLongNames)
+}
+
+// MaxNameLen devuelve la longitud máxima (en bytes) de un nombre en este sistema de archivos.
+func (sb *SuperBlock) MaxNameLen() int {
+	return NameMaxFor(sb.S_block_size, sb.LongNames())
+}
+
+// NameMaxFor devuelve la longitud máxima de un nombre con bloques de blockSize bytes.
+func NameMaxFor(blockSize int32, long bool) int {
+	if !long {
+		return ClassicNameMax
+	}
+	if max := int(blockSize) - direntHeaderSize; max < LongNameMax {
+		return max
+	}
+	return LongNameMax
+}
+
+// ValidateName comprueba que name se pueda guardar en una entrada de directorio.
+func (sb *SuperBlock) ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("el nombre no puede estar vacío")
+	}
+	if strings.ContainsAny(name, "/\x00") {
+		return fmt.Errorf("el nombre '%s' contiene caracteres no permitidos", name)
+	}
+	if !utf8.ValidString(name) {
+		return fmt.Errorf("el nombre '%s' no es UTF-8 válido", name)
+	}
+	if len(name) > sb.MaxNameLen() {
+		if !sb.LongNames() {
+			return fmt.Errorf("el nombre '%s' excede los %d bytes permitidos (formatee con mkfs -dirent=long para nombres largos)", name, ClassicNameMax)
+		}
+		return fmt.Errorf("el nombre '%s' excede los %d bytes permitidos", name, sb.MaxNameLen())
+	}
+	return nil
+}
+
+// NewDirBlock crea un bloque de carpeta vacío con el formato del superbloque.
+func (sb *SuperBlock) NewDirBlock() *DirBlock {
+	return &DirBlock{size: sb.S_block_size, long: sb.LongNames()}
+}
+
+// Bytes que ocupa una entrada con un nombre de nameLen bytes.
+func direntLen(nameLen int) int {
+	return (direntHeaderSize + nameLen + direntAlign - 1) / direntAlign * direntAlign
+}
+
+// Fits indica si las entradas caben en el bloque.
+func (d *DirBlock) Fits() bool {
+	if !d.long {
+		if int32(len(d.Entries)) > d.size/folderContentSize {
+			return false
+		}
+		for _, entry := range d.Entries {
+			if len(entry.Name) > ClassicNameMax+1 {
+				return false
+			}
+		}
+		return true
+	}
+	used := 0
+	for _, entry := range d.Entries {
+		if len(entry.Name) > LongNameMax {
+			return false
+		}
+		used += direntLen(len(entry.Name))
+	}
+	return used <= int(d.size)
+}
+
+// Add agrega una entrada si cabe en el bloque y devuelve si se agregó.
+func (d *DirBlock) Add(entry DirEntry) bool {
+	d.Entries = append(d.Entries, entry)
+	if !d.Fits() {
+		d.Entries = d.Entries[:len(d.Entries)-1]
+		return false
+	}
+	return true
+}
+
+// HasRoom indica si una entrada con ese nombre cabría en el bloque.
+func (d *DirBlock) HasRoom(name string) bool {
+	if d.Add(DirEntry{Inode: -1, Name: name}) {
+		d.Entries = d.Entries[:len(d.Entries)-1]
+		return true
+	}
+	return false
+}
+
+// Find devuelve la posición de la entrada con ese nombre o -1.
+func (d *DirBlock) Find(name string) int {
+	for i, entry := range d.Entries {
+		if entry.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Remove quita la entrada en la posición i.
+func (d *DirBlock) Remove(i int) {
+	d.Entries = append(d.Entries[:i], d.Entries[i+1:]...)
+}
+
+// Capacity devuelve cuántas entradas de un nombre de nameLen bytes caben en un bloque vacío.
+func (d *DirBlock) Capacity(nameLen int) int {
+	if !d.long {
+		return int(d.size / folderContentSize)
+	}
+	return int(d.size) / direntLen(nameLen)
+}
+
+// Decode interpreta raw (los bytes de un bloque) según el formato del bloque.
+func (d *DirBlock) Decode(raw []byte) error {
+	d.Entries = d.Entries[:0]
+	if !d.long {
+		fb := NewFolderBlock(d.size)
+		if err := binary.Read(bytes.NewReader(raw), binary.LittleEndian, fb.B_content); err != nil {
+			return err
+		}
+		for _, content := range fb.B_content {
+			if content.B_inodo == -1 {
+				continue
+			}
+			d.Entries = append(d.Entries, DirEntry{Inode: content.B_inodo, Name: strings.TrimRight(string(content.B_name[:]), "\x00")})
+		}
+		return nil
+	}
+
+	for pos := 0; pos+direntHeaderSize <= len(raw); {
+		inode := int32(binary.LittleEndian.Uint32(raw[pos:]))
+		recLen := int(binary.LittleEndian.Uint16(raw[pos+4:]))
+		nameLen := int(raw[pos+6])
+		if recLen < direntHeaderSize || pos+recLen > len(raw) || direntHeaderSize+nameLen > recLen {
+			return fmt.Errorf("entrada de directorio corrupta en el byte %d (rec_len %d, name_len %d)", pos, recLen, nameLen)
+		}
+		if inode != -1 {
+			name := string(raw[pos+direntHeaderSize : pos+direntHeaderSize+nameLen])
+			d.Entries = append(d.Entries, DirEntry{Inode: inode, Type: raw[pos+7], Name: name})
+		}
+		pos += recLen
+	}
+	return nil
+}
+
+// Encode devuelve los bytes del bloque; falla si las entradas no caben.
+func (d *DirBlock) Encode() ([]byte, error) {
+	if !d.Fits() {
+		return nil, fmt.Errorf("las %d entradas no caben en un bloque de %d bytes", len(d.Entries), d.size)
+	}
+	if !d.long {
+		fb := NewFolderBlock(d.size)
+		fb.Initialize()
+		for i, entry := range d.Entries {
+			copy(fb.B_content[i].B_name[:], entry.Name)
+			fb.B_content[i].B_inodo = entry.Inode
+		}
+		buf := new(bytes.Buffer)
+		if err := binary.Write(buf, binary.LittleEndian, fb.B_content); err != nil {
+			return nil, err
+		}
+		raw := make([]byte, d.size)
+		copy(raw, buf.Bytes())
+		return raw, nil
+	}
+
+	raw := make([]byte, d.size)
+	entries := d.Entries
+	if len(entries) == 0 {
+		entries = []DirEntry{{Inode: -1}}
+	}
+	pos := 0
+	for i, entry := range entries {
+		recLen := direntLen(len(entry.Name))
+		if i == len(entries)-1 {
+			recLen = int(d.size) - pos
+		}
+		binary.LittleEndian.PutUint32(raw[pos:], uint32(entry.Inode))
+		binary.LittleEndian.PutUint16(raw[pos+4:], uint16(recLen))
+		raw[pos+6] = byte(len(entry.Name))
+		raw[pos+7] = entry.Type
+		copy(raw[pos+direntHeaderSize:], entry.Name)
+		pos += recLen
+	}
+	return raw, nil
+}
+
+// ReadDirBlock lee y decodifica el bloque de carpeta blockIndex.
+func (sb *SuperBlock) ReadDirBlock(path string, blockIndex int32) (*DirBlock, error) {
+	if blockIndex < 0 || blockIndex >= sb.S_blocks_count {
+		return nil, fmt.Errorf("índice de bloque de carpeta inválido: %d", blockIndex)
+	}
+	file, err := OpenDisk(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	raw := make([]byte, sb.S_block_size)
+	if _, err := file.ReadAt(raw, int64(sb.S_block_start)+int64(blockIndex)*int64(sb.S_block_size)); err != nil {
+		return nil, fmt.Errorf("error leyendo bloque de carpeta %d: %w", blockIndex, err)
+	}
+	block := sb.NewDirBlock()
+	if err := block.Decode(raw); err != nil {
+		return nil, fmt.Errorf("error decodificando bloque de carpeta %d: %w", blockIndex, err)
+	}
+	return block, nil
+}
+
+// WriteDirBlock codifica y escribe block en el bloque blockIndex.
+func (sb *SuperBlock) WriteDirBlock(path string, blockIndex int32, block *DirBlock) error {
+	if blockIndex < 0 || blockIndex >= sb.S_blocks_count {
+		return fmt.Errorf("índice de bloque de carpeta inválido: %d", blockIndex)
+	}
+	if err := sb.CheckWritable(); err != nil {
+		return err
+	}
+	raw, err := block.Encode()
+	if err != nil {
+		return fmt.Errorf("error codificando bloque de carpeta %d: %w", blockIndex, err)
+	}
+	file, err := OpenDisk(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.WriteAt(raw, int64(sb.S_block_start)+int64(blockIndex)*int64(sb.S_block_size)); err != nil {
+		return fmt.Errorf("error escribiendo bloque de carpeta %d: %w", blockIndex, err)
+	}
+	return nil
+}
+
+// Print imprime las entradas del bloque de carpeta.
+func (d *DirBlock) Print() {
+	fmt.Println("  Contenido del bloque de carpeta:")
+	for i, entry := range d.Entries {
+		fmt.Printf("    [%d] Nombre: %q Inodo: %d\n", i, entry.Name, entry.Inode)
+	}
+}
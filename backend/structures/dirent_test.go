@@ -0,0 +1,204 @@
+package structures
+
+import (
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDirBlockRoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		size    int32
+		long    bool
+		entries []DirEntry
+	}{
+		{"clásico", 64, false, []DirEntry{{Inode: 0, Name: "."}, {Inode: 0, Name: ".."}, {Inode: 5, Name: "users.txt"}, {Inode: 7, Name: "abcdefghijk"}}},
+		{"largo bs64", 64, true, []DirEntry{{Inode: 3, Type: '0', Name: "."}, {Inode: 0, Type: '0', Name: ".."}, {Inode: 9, Type: '1', Name: "informe-año.txt"}}},
+		{"largo bs1024", 1024, true, []DirEntry{{Inode: 3, Type: '0', Name: "."}, {Inode: 12, Type: '2', Name: strings.Repeat("n", LongNameMax)}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			block := &DirBlock{size: tt.size, long: tt.long}
+			for _, entry := range tt.entries {
+				if !block.Add(entry) {
+					t.Fatalf("Add(%q): no cupo", entry.Name)
+				}
+			}
+			raw, err := block.Encode()
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if len(raw) != int(tt.size) {
+				t.Fatalf("Encode devolvió %d bytes, se esperaban %d", len(raw), tt.size)
+			}
+			decoded := &DirBlock{size: tt.size, long: tt.long}
+			if err := decoded.Decode(raw); err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if !reflect.DeepEqual(decoded.Entries, tt.entries) {
+				t.Errorf("Decode = %+v, se esperaba %+v", decoded.Entries, tt.entries)
+			}
+		})
+	}
+}
+
+// rec_len de cada entrada es su tamaño alineado a 4 salvo la última, que llega al final del bloque.
+func TestDirBlockLongLayout(t *testing.T) {
+	block := &DirBlock{size: 64, long: true}
+	block.Add(DirEntry{Inode: 1, Type: '1', Name: "abcde"})
+	block.Add(DirEntry{Inode: 2, Type: '1', Name: "x"})
+	raw, err := block.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if recLen := binary.LittleEndian.Uint16(raw[4:]); recLen != 16 {
+		t.Errorf("rec_len de 'abcde' = %d, se esperaba 16", recLen)
+	}
+	if nameLen := raw[6]; nameLen != 5 {
+		t.Errorf("name_len de 'abcde' = %d, se esperaba 5", nameLen)
+	}
+	if recLen := binary.LittleEndian.Uint16(raw[16+4:]); recLen != 64-16 {
+		t.Errorf("rec_len de la última entrada = %d, se esperaba %d", recLen, 64-16)
+	}
+
+	// Un bloque vacío es una sola entrada libre que ocupa todo el bloque
+	empty, err := (&DirBlock{size: 64, long: true}).Encode()
+	if err != nil {
+		t.Fatalf("Encode vacío: %v", err)
+	}
+	if inode := int32(binary.LittleEndian.Uint32(empty)); inode != -1 {
+		t.Errorf("inodo de la entrada libre = %d, se esperaba -1", inode)
+	}
+	if recLen := binary.LittleEndian.Uint16(empty[4:]); recLen != 64 {
+		t.Errorf("rec_len de la entrada libre = %d, se esperaba 64", recLen)
+	}
+	decoded := &DirBlock{size: 64, long: true}
+	if err := decoded.Decode(empty); err != nil || len(decoded.Entries) != 0 {
+		t.Errorf("Decode vacío = (%v, %v), se esperaba un bloque sin entradas", decoded.Entries, err)
+	}
+}
+
+func TestDirBlockDecodeCorrupt(t *testing.T) {
+	entry := func(recLen uint16, nameLen byte) []byte {
+		raw := make([]byte, 64)
+		binary.LittleEndian.PutUint32(raw, 4)
+		binary.LittleEndian.PutUint16(raw[4:], recLen)
+		raw[6] = nameLen
+		return raw
+	}
+	for _, tt := range []struct {
+		name string
+		raw  []byte
+	}{
+		{"rec_len menor que el encabezado", entry(4, 0)},
+		{"rec_len cero", entry(0, 0)},
+		{"rec_len pasa el final del bloque", entry(72, 1)},
+		{"name_len no cabe en rec_len", entry(12, 5)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			block := &DirBlock{size: 64, long: true}
+			if err := block.Decode(tt.raw); err == nil {
+				t.Errorf("Decode: se esperaba error, entradas %+v", block.Entries)
+			}
+		})
+	}
+}
+
+// Capacity coincide con las entradas que acepta Add y Encode rechaza un bloque que no cabe.
+func TestDirBlockCapacity(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		long    bool
+		nameLen int
+	}{
+		{"clásico", false, 4},
+		{"largo corto", true, 4},
+		{"largo máximo", true, NameMaxFor(64, true)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			block := &DirBlock{size: 64, long: tt.long}
+			name := strings.Repeat("a", tt.nameLen)
+			added := 0
+			for block.Add(DirEntry{Inode: int32(added), Name: name}) {
+				added++
+			}
+			if want := block.Capacity(tt.nameLen); added != want {
+				t.Errorf("Add aceptó %d entradas, Capacity = %d", added, want)
+			}
+			if block.HasRoom(name) {
+				t.Errorf("HasRoom con el bloque lleno: se esperaba false")
+			}
+			block.Entries = append(block.Entries, DirEntry{Inode: 99, Name: name})
+			if _, err := block.Encode(); err == nil {
+				t.Errorf("Encode con %d entradas: se esperaba error", len(block.Entries))
+			}
+		})
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	classic := &SuperBlock{S_block_size: 64}
+	long64 := &SuperBlock{S_block_size: 64, S_ext_magic: SuperBlockExtMagic, S_feature_incompat: FeatureIncompatLongNames}
+	long1024 := &SuperBlock{S_block_size: 1024, S_ext_magic: SuperBlockExtMagic, S_feature_incompat: FeatureIncompatLongNames}
+	for _, tt := range []struct {
+		sb    *SuperBlock
+		name  string
+		valid bool
+	}{
+		{classic, "users.txt", true},
+		{classic, strings.Repeat("a", ClassicNameMax), true},
+		{classic, strings.Repeat("a", ClassicNameMax+1), false},
+		{classic, "", false},
+		{classic, "a/b", false},
+		{classic, "a\x00b", false},
+		{long64, "\xff\xfe", false},
+		{long64, strings.Repeat("a", 64-direntHeaderSize), true},
+		{long64, strings.Repeat("a", 64-direntHeaderSize+1), false},
+		{long1024, strings.Repeat("ñ", LongNameMax/2), true},
+		{long1024, strings.Repeat("a", LongNameMax+1), false},
+	} {
+		err := tt.sb.ValidateName(tt.name)
+		if (err == nil) != tt.valid {
+			t.Errorf("bs=%d largo=%v ValidateName(%q) = %v, se esperaba válido=%v", tt.sb.S_block_size, tt.sb.LongNames(), tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestReadWriteDirBlock(t *testing.T) {
+	sb, path := newTestFS(t, 64, FeatureIncompatLongNames, 4)
+	block := sb.NewDirBlock()
+	block.Add(DirEntry{Inode: 0, Type: '0', Name: "."})
+	block.Add(DirEntry{Inode: 0, Type: '0', Name: ".."})
+	block.Add(DirEntry{Inode: 1, Type: '1', Name: "un nombre largo.txt"})
+	if err := sb.WriteDirBlock(path, 2, block); err != nil {
+		t.Fatalf("WriteDirBlock: %v", err)
+	}
+	read, err := sb.ReadDirBlock(path, 2)
+	if err != nil {
+		t.Fatalf("ReadDirBlock: %v", err)
+	}
+	if !reflect.DeepEqual(read.Entries, block.Entries) {
+		t.Errorf("ReadDirBlock = %+v, se esperaba %+v", read.Entries, block.Entries)
+	}
+	for _, index := range []int32{-1, 4} {
+		if _, err := sb.ReadDirBlock(path, index); err == nil {
+			t.Errorf("ReadDirBlock(%d): se esperaba error", index)
+		}
+		if err := sb.WriteDirBlock(path, index, block); err == nil {
+			t.Errorf("WriteDirBlock(%d): se esperaba error", index)
+		}
+	}
+	// Un bloque corrupto en disco se informa al leerlo
+	file, err := OpenDisk(path)
+	if err != nil {
+		t.Fatalf("OpenDisk: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteAt([]byte{0, 0}, int64(sb.S_block_start)+2*64+4); err != nil {
+		t.Fatalf("WriteAt: %v", err)
+	}
+	if _, err := sb.ReadDirBlock(path, 2); err == nil {
+		t.Errorf("ReadDirBlock de un bloque con rec_len 0: se esperaba error")
+	}
+}
@@ -2,7 +2,6 @@ package structures
 
 import (
 	"fmt"
-	"os"
 	"time"
 )
 
@@ -25,16 +24,18 @@ func (sb *SuperBlock) CreateUsersFile(path string) error {
 		I_uid:   1,
 		I_gid:   1,
 		I_size:  0,
-		I_atime: float32(time.Now().Unix()),
-		I_ctime: float32(time.Now().Unix()),
-		I_mtime: float32(time.Now().Unix()),
+		I_atime: time.Now().Unix(),
+		I_ctime: time.Now().Unix(),
+		I_mtime: time.Now().Unix(),
 		I_block: [15]int32{rootBlockIndex, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
 		I_type:  [1]byte{'0'},
 		I_perm:  [3]byte{'7', '7', '7'},
+		I_links: 1,
+		I_xattr: -1,
 	}
 
 	// Serializar el inodo raíz en la posición S_first_ino
-	err := rootInode.Serialize(path, int64(sb.S_first_ino))
+	err := rootInode.Serialize(sb, path, int64(sb.S_first_ino))
 	if err != nil {
 		return fmt.Errorf("error serializando inodo raíz: %w", err)
 	}
@@ -50,17 +51,12 @@ func (sb *SuperBlock) CreateUsersFile(path string) error {
 	sb.S_first_ino += sb.S_inode_size
 
 	// Creamos el bloque del Inodo Raíz
-	rootBlock := &FolderBlock{
-		B_content: [4]FolderContent{
-			{B_name: [12]byte{'.'}, B_inodo: rootInodeIndex},      // Apunta a sí mismo (índice 0)
-			{B_name: [12]byte{'.', '.'}, B_inodo: rootInodeIndex}, // El padre de la raíz es la raíz (índice 0)
-			{B_name: [12]byte{'-'}, B_inodo: -1},
-			{B_name: [12]byte{'-'}, B_inodo: -1},
-		},
-	}
+	rootBlock := sb.NewDirBlock()
+	rootBlock.Add(DirEntry{Inode: rootInodeIndex, Type: '0', Name: "."})  // Apunta a sí mismo (índice 0)
+	rootBlock.Add(DirEntry{Inode: rootInodeIndex, Type: '0', Name: ".."}) // El padre de la raíz es la raíz (índice 0)
 
 	// Serializar el bloque raíz en la posición S_first_blo
-	err = rootBlock.Serialize(path, int64(sb.S_first_blo))
+	err = sb.WriteDirBlock(path, rootBlockIndex, rootBlock)
 	if err != nil {
 		return fmt.Errorf("error serializando bloque raíz: %w", err)
 	}
@@ -83,25 +79,24 @@ func (sb *SuperBlock) CreateUsersFile(path string) error {
 	usersBlockIndex := (sb.S_first_blo - sb.S_block_start) / sb.S_block_size
 
 	// Actualizar la entrada en el bloque raíz para que apunte a users.txt
-	if err := rootBlock.Deserialize(path, int64(sb.S_block_start)); err != nil { // Usa offset calculado o conocido
-		return fmt.Errorf("error re-deserializando bloque raíz para actualizar: %w", err)
-	}
-	rootBlock.B_content[2] = FolderContent{B_name: [12]byte{'u', 's', 'e', 'r', 's', '.', 't', 'x', 't'}, B_inodo: usersInodeIndex} // Apunta al índice calculado
-	if err := rootBlock.Serialize(path, int64(sb.S_block_start)); err != nil {
+	rootBlock.Add(DirEntry{Inode: usersInodeIndex, Type: '1', Name: "users.txt"}) // Apunta al índice calculado
+	if err := sb.WriteDirBlock(path, rootBlockIndex, rootBlock); err != nil {
 		return fmt.Errorf("error re-serializando bloque raíz actualizado: %w", err)
 	}
 
 	// Crear el inodo users.txt
 	usersInode := &Inode{
 		I_uid: 1, I_gid: 1,
-		I_size:  int32(len(usersText)),
-		I_atime: float32(time.Now().Unix()), I_ctime: float32(time.Now().Unix()), I_mtime: float32(time.Now().Unix()),
+		I_size:  int64(len(usersText)),
+		I_atime: time.Now().Unix(), I_ctime: time.Now().Unix(), I_mtime: time.Now().Unix(),
 		I_block: [15]int32{usersBlockIndex, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1}, // Usa índice calculado
 		I_type:  [1]byte{'1'}, I_perm: [3]byte{'7', '7', '7'},
+		I_links: 1,
+		I_xattr: -1,
 	}
 
 	// Serializar inodo users.txt en S_first_ino
-	err = usersInode.Serialize(path, int64(sb.S_first_ino))
+	err = usersInode.Serialize(sb, path, int64(sb.S_first_ino))
 	if err != nil {
 		return fmt.Errorf("error serializando inodo users.txt: %w", err)
 	}
@@ -117,7 +112,7 @@ func (sb *SuperBlock) CreateUsersFile(path string) error {
 	sb.S_first_ino += sb.S_inode_size
 
 	// Crear el bloque de users.txt
-	usersBlock := &FileBlock{B_content: [64]byte{}}
+	usersBlock := NewFileBlock(sb.S_block_size)
 	copy(usersBlock.B_content[:], usersText)
 
 	// Serializar el bloque de users.txt en S_first_blo
@@ -146,7 +141,13 @@ func (sb *SuperBlock) CreateUsersFile(path string) error {
 // Actualiza el bitmap de bloques y el contador de bloques libres
 func FreeInodeBlocks(inode *Inode, sb *SuperBlock, partitionPath string) error {
 	fmt.Printf("Liberando bloques para inodo con tamaño %d...\n", inode.I_size)
-	if inode.I_size == 0 { // Si el tamaño es 0
+	if inode.InlineSymlink() { // Enlace rápido: I_block guarda el destino, no hay bloques
+		for i := range inode.I_block {
+			inode.I_block[i] = -1
+		}
+		return nil
+	}
+	if inode.I_size == 0 && inode.I_type[0] != '0' { // Si el tamaño es 0 (las carpetas siempre tienen bloques)
 		// Podemos verificar I_block por si acaso, pero es probable que estén en -1
 		fmt.Println("Tamaño de inodo es 0, no se liberan bloques.")
 
@@ -191,7 +192,7 @@ func freeIndirectBlocksRecursive(level int, blockPtr int32, sb *SuperBlock, part
 	}
 
 	// Deserializar el bloque de punteros de este nivel
-	ptrBlock := &PointerBlock{}
+	ptrBlock := NewPointerBlock(sb.S_block_size)
 	ptrOffset := int64(sb.S_block_start) + int64(blockPtr)*int64(sb.S_block_size)
 	if err := ptrBlock.Deserialize(partitionPath, ptrOffset); err != nil {
 		fmt.Printf("Advertencia: no se pudo leer bloque de punteros Nivel %d (%d): %v. Intentando liberar bloque %d de todas formas.\n", level, blockPtr, err, blockPtr)
@@ -221,23 +222,19 @@ func freeDataBlockIfValid(blockIndex int32, sb *SuperBlock, partitionPath string
 		return nil // Índice inválido o no usado, nada que hacer
 	}
 
-	// Actualizar bitmap
-	bitmapOffset := int64(sb.S_bm_block_start) + int64(blockIndex)
-	file, err := os.OpenFile(partitionPath, os.O_WRONLY, 0644) // Solo escritura
+	// Actualizar bitmap (y el índice de libres)
+	file, err := OpenDisk(partitionPath)
 	if err != nil {
 		return fmt.Errorf("error abriendo disco para liberar bloque %d: %w", blockIndex, err)
 	}
 	defer file.Close()
 
-	_, err = file.Seek(bitmapOffset, 0)
-	if err != nil {
-		return fmt.Errorf("error buscando bitmap para liberar bloque %d: %w", blockIndex, err)
-	}
-
-	_, err = file.Write([]byte{'0'}) // Marcar como libre
-	if err != nil {
+	if err := sb.writeBitmapEntry(file, sb.S_bm_block_start, blockIndex, false); err != nil {
 		return fmt.Errorf("error escribiendo en bitmap para liberar bloque %d: %w", blockIndex, err)
 	}
+	if index := sb.loadedFreeIndex(partitionPath); index != nil {
+		index.Blocks.Release(blockIndex)
+	}
 
 	// Actualizar contador de libres en Superbloque (EN MEMORIA)
 	sb.S_free_blocks_count++
@@ -0,0 +1,160 @@
+package structures
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Revisiones del formato del superbloque:
+//   - 0: formato original de 68 bytes, sin extensión.
+//   - 1: extensión con S_feature_incompat (S_rev_level todavía era reservado y vale 0).
+//   - 2: S_rev_level y las máscaras compat y ro_compat.
+const SuperBlockRevision = int32(2)
+
+const (
+	FeatureCompatJournal = int32(0x0001) // Journal de operaciones en /.journal (EXT3)
+
+	FeatureRoCompatSymlinks = int32(0x0001) // Inodos tipo '2': un lector antiguo tomaría el destino guardado en I_block por punteros
+)
+
+// Clase de una característica, con la misma semántica que en ext2: un lector que no conoce una
+// característica compat puede ignorarla, una ro_compat le permite leer pero no escribir y una
+// incompat le impide usar el sistema de archivos.
+type FeatureKind int
+
+const (
+	FeatureKindCompat FeatureKind = iota
+	FeatureKindRoCompat
+	FeatureKindIncompat
+)
+
+func (kind FeatureKind) String() string {
+	switch kind {
+	case FeatureKindCompat:
+		return "compat"
+	case FeatureKindRoCompat:
+		return "ro_compat"
+	}
+	return "incompat"
+}
+
+// Feature describe una característica del formato que este código sabe interpretar.
+type Feature struct {
+	Name        string
+	Kind        FeatureKind
+	Mask        int32
+	Tunable     bool // tunefs puede activarla o desactivarla reescribiendo el sistema de archivos
+	Description string
+}
+
+// Características conocidas del superbloque.
+var Features = []Feature{
+	{Name: "has_journal", Kind: FeatureKindCompat, Mask: FeatureCompatJournal, Description: "journal de operaciones en /.journal (EXT3)"},
+	{Name: "symlinks", Kind: FeatureKindRoCompat, Mask: FeatureRoCompatSymlinks, Description: "enlaces simbólicos (ln -s)"},
+	{Name: "packed_bitmaps", Kind: FeatureKindIncompat, Mask: FeatureIncompatPackedBitmaps, Tunable: true, Description: "bitmaps de un bit por inodo/bloque"},
+	{Name: "long_names", Kind: FeatureKindIncompat, Mask: FeatureIncompatLongNames, Description: "entradas de directorio de longitud variable"},
+	{Name: "large_files", Kind: FeatureKindIncompat, Mask: FeatureIncompatLargeFiles, Tunable: true, Description: "tamaño de archivo de 64 bits"},
+	{Name: "wide_times", Kind: FeatureKindIncompat, Mask: FeatureIncompatWideTimes, Tunable: true, Description: "marcas de tiempo int64"},
+	{Name: "link_counts", Kind: FeatureKindIncompat, Mask: FeatureIncompatLinkCounts, Tunable: true, Description: "contador de enlaces duros (ln)"},
+	{Name: "xattrs", Kind: FeatureKindIncompat, Mask: FeatureIncompatXattrs, Tunable: true, Description: "atributos extendidos (setxattr)"},
+	{Name: "special_perms", Kind: FeatureKindIncompat, Mask: FeatureIncompatSpecialPerms, Tunable: true, Description: "bits setuid, setgid y sticky (chmod de 4 dígitos)"},
+}
+
+// ErrUnsupportedFeatures indica un formato más nuevo que este código: revisión o características
+// desconocidas.
+var ErrUnsupportedFeatures = errors.New("formato no soportado")
+
+// FindFeature busca una característica conocida por nombre.
+func FindFeature(name string) (Feature, bool) {
+	for _, feature := range Features {
+		if strings.EqualFold(feature.Name, name) {
+			return feature, true
+		}
+	}
+	return Feature{}, false
+}
+
+// Máscara de las características conocidas de una clase.
+func knownFeatures(kind FeatureKind) int32 {
+	mask := int32(0)
+	for _, feature := range Features {
+		if feature.Kind == kind {
+			mask |= feature.Mask
+		}
+	}
+	return mask
+}
+
+// Revision devuelve la revisión del formato del superbloque.
+func (sb *SuperBlock) Revision() int32 {
+	if !sb.HasExtension() {
+		return 0
+	}
+	if sb.S_rev_level == 0 {
+		return 1
+	}
+	return sb.S_rev_level
+}
+
+// FeatureMask devuelve la máscara de características de la clase kind.
+func (sb *SuperBlock) FeatureMask(kind FeatureKind) int32 {
+	if !sb.HasExtension() {
+		return 0
+	}
+	switch kind {
+	case FeatureKindCompat:
+		return sb.S_feature_compat
+	case FeatureKindRoCompat:
+		return sb.S_feature_ro_compat
+	}
+	return sb.S_feature_incompat
+}
+
+// UnknownFeatures devuelve los bits de la clase kind que este código no conoce.
+func (sb *SuperBlock) UnknownFeatures(kind FeatureKind) int32 {
+	return sb.FeatureMask(kind) &^ knownFeatures(kind)
+}
+
+// FeatureNames devuelve los nombres de las características activas de la clase kind; los bits
+// desconocidos se muestran en hexadecimal.
+func (sb *SuperBlock) FeatureNames(kind FeatureKind) []string {
+	mask := sb.FeatureMask(kind)
+	names := []string{}
+	for _, feature := range Features {
+		if feature.Kind == kind && mask&feature.Mask != 0 {
+			names = append(names, feature.Name)
+		}
+	}
+	if unknown := sb.UnknownFeatures(kind); unknown != 0 {
+		names = append(names, fmt.Sprintf("0x%X", unknown))
+	}
+	return names
+}
+
+// CheckFeatures devuelve ErrUnsupportedFeatures si el sistema de archivos usa una revisión o
+// características incompat que este código no conoce.
+func (sb *SuperBlock) CheckFeatures() error {
+	if sb.S_magic != 0xEF53 || !sb.HasExtension() {
+		return nil
+	}
+	if sb.Revision() > SuperBlockRevision {
+		return fmt.Errorf("%w: revisión %d del superbloque (se soporta hasta la %d)", ErrUnsupportedFeatures, sb.Revision(), SuperBlockRevision)
+	}
+	if unknown := sb.UnknownFeatures(FeatureKindIncompat); unknown != 0 {
+		return fmt.Errorf("%w: características incompat desconocidas 0x%X", ErrUnsupportedFeatures, unknown)
+	}
+	return nil
+}
+
+// CheckWritable devuelve ErrUnsupportedFeatures si el sistema de archivos tiene características
+// ro_compat desconocidas: se puede leer, pero escribir podría dañarlo.
+func (sb *SuperBlock) CheckWritable() error {
+	if !sb.HasExtension() {
+		return nil
+	}
+	if unknown := sb.UnknownFeatures(FeatureKindRoCompat); unknown != 0 {
+		return fmt.Errorf("%w: características ro_compat desconocidas 0x%X, el sistema de archivos es de solo lectura", ErrUnsupportedFeatures, unknown)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package structures
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSuperBlockRevision(t *testing.T) {
+	legacy := testSuperBlock(0)
+	legacy.S_ext_magic = 0
+	firstExtension := testSuperBlock(FeatureIncompatPackedBitmaps)
+	firstExtension.S_rev_level = 0
+	for _, tt := range []struct {
+		name string
+		sb   *SuperBlock
+		want int32
+	}{
+		{"sin extensión", legacy, 0},
+		{"extensión sin S_rev_level", firstExtension, 1},
+		{"actual", testSuperBlock(0), SuperBlockRevision},
+	} {
+		if got := tt.sb.Revision(); got != tt.want {
+			t.Errorf("%s: Revision = %d, se esperaba %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFeatureMasks(t *testing.T) {
+	sb := testSuperBlock(FeatureIncompatWideTimes | FeatureIncompatXattrs | 0x4000)
+	sb.S_feature_ro_compat = FeatureRoCompatSymlinks | 0x10
+
+	if got := sb.FeatureMask(FeatureKindIncompat); got != FeatureIncompatWideTimes|FeatureIncompatXattrs|0x4000 {
+		t.Errorf("FeatureMask(incompat) = 0x%X", got)
+	}
+	if got := sb.UnknownFeatures(FeatureKindIncompat); got != 0x4000 {
+		t.Errorf("UnknownFeatures(incompat) = 0x%X, se esperaba 0x4000", got)
+	}
+	if got := sb.UnknownFeatures(FeatureKindCompat); got != 0 {
+		t.Errorf("UnknownFeatures(compat) = 0x%X, se esperaba 0", got)
+	}
+	for _, tt := range []struct {
+		kind FeatureKind
+		want []string
+	}{
+		{FeatureKindCompat, []string{"has_journal"}},
+		{FeatureKindRoCompat, []string{"symlinks", "0x10"}},
+		{FeatureKindIncompat, []string{"wide_times", "xattrs", "0x4000"}},
+	} {
+		if got := sb.FeatureNames(tt.kind); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FeatureNames(%s) = %v, se esperaba %v", tt.kind, got, tt.want)
+		}
+	}
+
+	// Sin extensión los campos no existen en disco: las máscaras valen 0 aunque tengan basura
+	sb.S_ext_magic = 0
+	for _, kind := range []FeatureKind{FeatureKindCompat, FeatureKindRoCompat, FeatureKindIncompat} {
+		if got := sb.FeatureMask(kind); got != 0 {
+			t.Errorf("FeatureMask(%s) sin extensión = 0x%X, se esperaba 0", kind, got)
+		}
+	}
+	if err := sb.CheckFeatures(); err != nil {
+		t.Errorf("CheckFeatures sin extensión = %v", err)
+	}
+}
+
+func TestCheckFeatures(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		edit     func(*SuperBlock)
+		readable bool
+		writable bool
+	}{
+		{"conocidas", func(sb *SuperBlock) { sb.S_feature_ro_compat = FeatureRoCompatSymlinks }, true, true},
+		{"compat desconocida", func(sb *SuperBlock) { sb.S_feature_compat |= 0x100 }, true, true},
+		{"ro_compat desconocida", func(sb *SuperBlock) { sb.S_feature_ro_compat = 0x100 }, true, false},
+		{"incompat desconocida", func(sb *SuperBlock) { sb.S_feature_incompat |= 0x100 }, false, true},
+		{"revisión nueva", func(sb *SuperBlock) { sb.S_rev_level = SuperBlockRevision + 1 }, false, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := testSuperBlock(FeatureIncompatPackedBitmaps)
+			tt.edit(sb)
+			if err := sb.CheckFeatures(); (err == nil) != tt.readable || (err != nil && !errors.Is(err, ErrUnsupportedFeatures)) {
+				t.Errorf("CheckFeatures = %v, se esperaba legible=%v", err, tt.readable)
+			}
+			if err := sb.CheckWritable(); (err == nil) != tt.writable || (err != nil && !errors.Is(err, ErrUnsupportedFeatures)) {
+				t.Errorf("CheckWritable = %v, se esperaba escribible=%v", err, tt.writable)
+			}
+		})
+	}
+}
+
+// Deserialize rechaza un formato más nuevo y Serialize no escribe sobre uno de solo lectura.
+func TestSuperBlockUnsupportedOnDisk(t *testing.T) {
+	path := newTestDisk(t, 512)
+	sb := testSuperBlock(FeatureIncompatPackedBitmaps | 0x4000)
+	if err := sb.Serialize(path, 0); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	if err := (&SuperBlock{}).Deserialize(path, 0); !errors.Is(err, ErrUnsupportedFeatures) {
+		t.Errorf("Deserialize con incompat desconocida = %v, se esperaba ErrUnsupportedFeatures", err)
+	}
+
+	sb = testSuperBlock(0)
+	sb.S_feature_ro_compat = 0x100
+	if err := sb.Serialize(path, 0); !errors.Is(err, ErrUnsupportedFeatures) {
+		t.Errorf("Serialize con ro_compat desconocida = %v, se esperaba ErrUnsupportedFeatures", err)
+	}
+}
+
+func TestFindFeature(t *testing.T) {
+	feature, ok := FindFeature("Wide_Times")
+	if !ok || feature.Mask != FeatureIncompatWideTimes || feature.Kind != FeatureKindIncompat || !feature.Tunable {
+		t.Errorf("FindFeature(Wide_Times) = %+v, %v", feature, ok)
+	}
+	if feature, ok := FindFeature("long_names"); !ok || feature.Tunable {
+		t.Errorf("FindFeature(long_names) = %+v, %v, se esperaba no modificable", feature, ok)
+	}
+	if _, ok := FindFeature("dir_index"); ok {
+		t.Errorf("FindFeature(dir_index): se esperaba no encontrada")
+	}
+	// Cada bit pertenece a una sola característica de su clase
+	seen := map[FeatureKind]int32{}
+	for _, feature := range Features {
+		if seen[feature.Kind]&feature.Mask != 0 {
+			t.Errorf("la máscara 0x%X de '%s' se repite en %s", feature.Mask, feature.Name, feature.Kind)
+		}
+		seen[feature.Kind] |= feature.Mask
+	}
+}
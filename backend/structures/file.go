@@ -0,0 +1,211 @@
+package structures
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// File es un archivo abierto por su inodo, con acceso aleatorio por posición. Los bloques se
+// buscan con BlockAt y se asignan al escribir (AllocBlockAt), así que escribir lejos del final
+// deja un hueco en medio. Los cambios del inodo y del superbloque quedan en memoria hasta Sync.
+type File struct {
+	sb    *SuperBlock
+	path  string // Disco donde vive el archivo
+	index int32  // Índice del inodo
+	inode *Inode
+	pos   int64 // Posición de Read, Write y Seek
+	dirty bool  // El inodo cambió y falta guardarlo
+}
+
+var (
+	_ io.ReaderAt        = (*File)(nil)
+	_ io.WriterAt        = (*File)(nil)
+	_ io.ReadWriteSeeker = (*File)(nil)
+)
+
+// OpenFile abre el archivo del inodo inodeIndex. No verifica permisos: eso lo hacen los comandos.
+func (sb *SuperBlock) OpenFile(path string, inodeIndex int32) (*File, error) {
+	if inodeIndex < 0 || inodeIndex >= sb.S_inodes_count {
+		return nil, fmt.Errorf("índice de inodo inválido: %d", inodeIndex)
+	}
+	if sb.S_block_size <= 0 {
+		return nil, errors.New("tamaño de bloque inválido en superbloque")
+	}
+	inode := &Inode{}
+	if err := inode.Deserialize(sb, path, int64(sb.S_inode_start)+int64(inodeIndex)*int64(sb.S_inode_size)); err != nil {
+		return nil, fmt.Errorf("error leyendo inodo %d: %w", inodeIndex, err)
+	}
+	if inode.I_type[0] != '1' {
+		return nil, fmt.Errorf("el inodo %d no es de tipo archivo (tipo: %c)", inodeIndex, inode.I_type[0])
+	}
+	return &File{sb: sb, path: path, index: inodeIndex, inode: inode}, nil
+}
+
+// Inode devuelve el inodo en memoria; si el llamador lo cambia debe llamar a MarkDirty.
+func (f *File) Inode() *Inode { return f.inode }
+
+// Index devuelve el índice del inodo.
+func (f *File) Index() int32 { return f.index }
+
+// Size devuelve el tamaño actual del archivo.
+func (f *File) Size() int64 { return f.inode.I_size }
+
+// MarkDirty indica que el inodo cambió y Sync debe guardarlo.
+func (f *File) MarkDirty() { f.dirty = true }
+
+// ReadAt lee desde off; los huecos se leen como ceros. Como io.ReaderAt, devuelve io.EOF si
+// lee menos de len(p) por llegar al final del archivo.
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("posición negativa: %d", off)
+	}
+	if off >= f.inode.I_size {
+		return 0, io.EOF
+	}
+	n := int(min(int64(len(p)), f.inode.I_size-off))
+	bs := int64(f.sb.S_block_size)
+	for done := 0; done < n; {
+		pos := off + int64(done)
+		within := pos % bs
+		chunk := int(min(bs-within, int64(n-done)))
+		blockIndex, err := f.sb.BlockAt(f.path, f.inode, pos/bs)
+		if err != nil {
+			return done, err
+		}
+		if blockIndex == -1 {
+			clear(p[done : done+chunk])
+		} else {
+			fileBlock := NewFileBlock(f.sb.S_block_size)
+			if err := fileBlock.Deserialize(f.path, int64(f.sb.S_block_start)+int64(blockIndex)*bs); err != nil {
+				return done, fmt.Errorf("error leyendo bloque de datos %d: %w", blockIndex, err)
+			}
+			copy(p[done:done+chunk], fileBlock.B_content[within:])
+		}
+		done += chunk
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// WriteAt escribe p desde off asignando los bloques que falten y agranda el archivo si escribe
+// más allá del final. Si se queda sin espacio devuelve lo escrito hasta ese punto.
+func (f *File) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("posición negativa: %d", off)
+	}
+	if end := off + int64(len(p)); end > f.sb.MaxFileSize() {
+		return 0, fmt.Errorf("escribir hasta %d bytes excede el máximo de %d bytes con bloques de %d bytes", end, f.sb.MaxFileSize(), f.sb.S_block_size)
+	}
+	bs := int64(f.sb.S_block_size)
+	done := 0
+	defer func() {
+		if done > 0 {
+			f.grow(off + int64(done))
+		}
+	}()
+	for done < len(p) {
+		pos := off + int64(done)
+		within := pos % bs
+		chunk := int(min(bs-within, int64(len(p)-done)))
+		blockIndex, created, err := f.sb.AllocBlockAt(f.path, f.inode, pos/bs)
+		if err != nil {
+			return done, err
+		}
+		if created {
+			f.dirty = true // Cambió I_block
+		}
+		blockOffset := int64(f.sb.S_block_start) + int64(blockIndex)*bs
+		fileBlock := NewFileBlock(f.sb.S_block_size)
+		if !created && int64(chunk) < bs { // Bloque existente escrito en parte: conservar el resto
+			if err := fileBlock.Deserialize(f.path, blockOffset); err != nil {
+				return done, fmt.Errorf("error leyendo bloque de datos %d: %w", blockIndex, err)
+			}
+		}
+		copy(fileBlock.B_content[within:], p[done:done+chunk])
+		if err := fileBlock.Serialize(f.path, blockOffset); err != nil {
+			return done, fmt.Errorf("error escribiendo bloque de datos %d: %w", blockIndex, err)
+		}
+		done += chunk
+	}
+	return done, nil
+}
+
+// Actualiza tamaño y tiempos después de escribir hasta end.
+func (f *File) grow(end int64) {
+	if end > f.inode.I_size {
+		f.inode.I_size = end
+	}
+	now := time.Now().Unix()
+	f.inode.I_mtime, f.inode.I_ctime = now, now
+	f.dirty = true
+}
+
+// Read lee desde la posición actual y la avanza.
+func (f *File) Read(p []byte) (int, error) {
+	n, err := f.ReadAt(p, f.pos)
+	f.pos += int64(n)
+	return n, err
+}
+
+// Write escribe en la posición actual y la avanza.
+func (f *File) Write(p []byte) (int, error) {
+	n, err := f.WriteAt(p, f.pos)
+	f.pos += int64(n)
+	return n, err
+}
+
+// Seek mueve la posición como io.Seeker; se puede pasar del final (escribir ahí deja un hueco).
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.pos
+	case io.SeekEnd:
+		offset += f.inode.I_size
+	default:
+		return f.pos, fmt.Errorf("whence inválido: %d", whence)
+	}
+	if offset < 0 {
+		return f.pos, fmt.Errorf("posición negativa: %d", offset)
+	}
+	f.pos = offset
+	return f.pos, nil
+}
+
+// Truncate cambia el tamaño del archivo (ver SuperBlock.TruncateFile). No mueve la posición.
+func (f *File) Truncate(size int64) error {
+	if err := f.sb.TruncateFile(f.path, f.inode, size); err != nil {
+		return err
+	}
+	now := time.Now().Unix()
+	f.inode.I_mtime, f.inode.I_ctime = now, now
+	f.dirty = true
+	return nil
+}
+
+// Append escribe p al final del archivo y deja la posición después de lo escrito.
+func (f *File) Append(p []byte) (int, error) {
+	n, err := f.WriteAt(p, f.inode.I_size)
+	f.pos = f.inode.I_size
+	return n, err
+}
+
+// Sync guarda el inodo y el superbloque (contadores de libres) si hubo cambios.
+func (f *File) Sync() error {
+	if !f.dirty {
+		return nil
+	}
+	if err := f.inode.Serialize(f.sb, f.path, int64(f.sb.S_inode_start)+int64(f.index)*int64(f.sb.S_inode_size)); err != nil {
+		return fmt.Errorf("error guardando inodo %d: %w", f.index, err)
+	}
+	// El superbloque está al inicio de la partición, justo antes del bitmap de inodos
+	if err := f.sb.Serialize(f.path, int64(f.sb.S_bm_inode_start-f.sb.DiskSize())); err != nil {
+		return fmt.Errorf("error guardando superbloque: %w", err)
+	}
+	f.dirty = false
+	return nil
+}
@@ -1,20 +1,22 @@
 package structures
 
 import (
-	"bytes"
-	"encoding/binary"
+	"errors"
 	"fmt"
-	"os"
 )
 
 type FileBlock struct {
-	B_content [64]byte
-	// Total: 64 bytes
+	B_content []byte // S_block_size bytes
+}
+
+// NewFileBlock crea un bloque de archivo vacío de blockSize bytes.
+func NewFileBlock(blockSize int32) *FileBlock {
+	return &FileBlock{B_content: make([]byte, blockSize)}
 }
 
 // Serialize escribe la estructura FileBlock en un archivo binario en la posición especificada
 func (fb *FileBlock) Serialize(path string, offset int64) error {
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	file, err := OpenDisk(path)
 	if err != nil {
 		return err
 	}
@@ -26,8 +28,8 @@ func (fb *FileBlock) Serialize(path string, offset int64) error {
 		return err
 	}
 
-	// Serializar la estructura FileBlock directamente en el archivo
-	err = binary.Write(file, binary.LittleEndian, fb)
+	// Serializar el contenido del bloque directamente en el archivo
+	_, err = file.Write(fb.B_content)
 	if err != nil {
 		return err
 	}
@@ -37,7 +39,7 @@ func (fb *FileBlock) Serialize(path string, offset int64) error {
 
 // Deserialize lee la estructura FileBlock desde un archivo binario en la posición especificada
 func (fb *FileBlock) Deserialize(path string, offset int64) error {
-	file, err := os.Open(path)
+	file, err := OpenDisk(path)
 	if err != nil {
 		return err
 	}
@@ -49,24 +51,13 @@ func (fb *FileBlock) Deserialize(path string, offset int64) error {
 		return err
 	}
 
-	// Obtener el tamaño de la estructura FileBlock
-	fbSize := binary.Size(fb)
-	if fbSize <= 0 {
-		return fmt.Errorf("invalid FileBlock size: %d", fbSize)
+	// El tamaño del bloque lo fija quien lo crea (NewFileBlock con S_block_size)
+	if len(fb.B_content) == 0 {
+		return errors.New("FileBlock sin tamaño: use NewFileBlock con el S_block_size del superbloque")
 	}
 
-	// Leer solo la cantidad de bytes que corresponden al tamaño de la estructura FileBlock
-	buffer := make([]byte, fbSize)
-	_, err = file.Read(buffer)
-	if err != nil {
-		return err
-	}
-
-	
-
-	// Deserializar los bytes leídos en la estructura FileBlock
-	reader := bytes.NewReader(buffer)
-	err = binary.Read(reader, binary.LittleEndian, fb)
+	// Leer solo la cantidad de bytes que corresponden al bloque
+	_, err = file.Read(fb.B_content)
 	if err != nil {
 		return err
 	}
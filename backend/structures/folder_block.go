@@ -4,13 +4,11 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
-	"os"
 	"strings"
 )
 
 type FolderBlock struct {
-	B_content [4]FolderContent // 4 * 16 = 64 bytes
-	// Total: 64 bytes
+	B_content []FolderContent // S_block_size / 16 entradas (4 con bloques de 64 bytes)
 }
 
 type FolderContent struct {
@@ -19,9 +17,16 @@ type FolderContent struct {
 	// Total: 16 bytes
 }
 
+const folderContentSize = 16
+
+// NewFolderBlock crea un bloque de carpeta con las entradas que caben en blockSize bytes.
+func NewFolderBlock(blockSize int32) *FolderBlock {
+	return &FolderBlock{B_content: make([]FolderContent, blockSize/folderContentSize)}
+}
+
 // Serialize escribe la estructura FolderBlock en un archivo binario en la posición especificada
 func (fb *FolderBlock) Serialize(path string, offset int64) error {
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	file, err := OpenDisk(path)
 	if err != nil {
 		return err
 	}
@@ -33,8 +38,8 @@ func (fb *FolderBlock) Serialize(path string, offset int64) error {
 		return err
 	}
 
-	// Serializar la estructura FolderBlock directamente en el archivo
-	err = binary.Write(file, binary.LittleEndian, fb)
+	// Serializar las entradas directamente en el archivo
+	err = binary.Write(file, binary.LittleEndian, fb.B_content)
 	if err != nil {
 		return err
 	}
@@ -44,7 +49,7 @@ func (fb *FolderBlock) Serialize(path string, offset int64) error {
 
 // Deserialize lee la estructura FolderBlock desde un archivo binario en la posición especificada
 func (fb *FolderBlock) Deserialize(path string, offset int64) error {
-	file, err := os.Open(path)
+	file, err := OpenDisk(path)
 	if err != nil {
 		return err
 	}
@@ -56,10 +61,10 @@ func (fb *FolderBlock) Deserialize(path string, offset int64) error {
 		return err
 	}
 
-	// Obtener el tamaño de la estructura FolderBlock
-	fbSize := binary.Size(fb)
+	// Obtener el tamaño del bloque a partir de sus entradas (NewFolderBlock con S_block_size)
+	fbSize := binary.Size(fb.B_content)
 	if fbSize <= 0 {
-		return fmt.Errorf("invalid FolderBlock size: %d", fbSize)
+		return fmt.Errorf("FolderBlock sin tamaño: use NewFolderBlock con el S_block_size del superbloque")
 	}
 
 	// Leer solo la cantidad de bytes que corresponden al tamaño de la estructura FolderBlock
@@ -73,9 +78,9 @@ func (fb *FolderBlock) Deserialize(path string, offset int64) error {
         return fmt.Errorf("no se pudieron leer todos los bytes: leídos %d, esperados %d", bytesRead, fbSize)
     }
 
-	// Deserializar los bytes leídos en la estructura FolderBlock
+	// Deserializar los bytes leídos en las entradas del FolderBlock
 	reader := bytes.NewReader(buffer)
-	err = binary.Read(reader, binary.LittleEndian, fb)
+	err = binary.Read(reader, binary.LittleEndian, fb.B_content)
 	if err != nil {
 		return err
 	}
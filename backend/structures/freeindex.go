@@ -0,0 +1,274 @@
+package structures
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Extent es un tramo de índices consecutivos libres.
+type Extent struct {
+	Start  int32
+	Length int32
+}
+
+// FreeExtents es el índice en memoria de los tramos libres de un bitmap, ordenados por inicio.
+type FreeExtents struct {
+	mu      sync.Mutex
+	extents []Extent
+	free    int32
+}
+
+// Construye el índice a partir de un bitmap en formato '0'/'1'.
+func newFreeExtents(bitmap []byte) *FreeExtents {
+	fe := &FreeExtents{}
+	for i := 0; i < len(bitmap); i++ {
+		if bitmap[i] == '1' {
+			continue
+		}
+		start := i
+		for i < len(bitmap) && bitmap[i] != '1' {
+			i++
+		}
+		fe.extents = append(fe.extents, Extent{Start: int32(start), Length: int32(i - start)})
+		fe.free += int32(i - start)
+	}
+	return fe
+}
+
+// Posición del primer tramo que termina después de index.
+func (fe *FreeExtents) search(index int32) int {
+	return sort.Search(len(fe.extents), func(i int) bool {
+		return fe.extents[i].Start+fe.extents[i].Length > index
+	})
+}
+
+// First devuelve el primer índice libre en from o después; si no hay, vuelve a empezar desde 0.
+func (fe *FreeExtents) First(from int32) (int32, bool) {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+	if len(fe.extents) == 0 {
+		return -1, false
+	}
+	if from < 0 {
+		from = 0
+	}
+	i := fe.search(from)
+	if i == len(fe.extents) {
+		return fe.extents[0].Start, true
+	}
+	if fe.extents[i].Start > from {
+		return fe.extents[i].Start, true
+	}
+	return from, true
+}
+
+// IsFree indica si index está libre según el índice.
+func (fe *FreeExtents) IsFree(index int32) bool {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+	i := fe.search(index)
+	return i < len(fe.extents) && fe.extents[i].Start <= index
+}
+
+// Take marca index como ocupado partiendo el tramo que lo contiene.
+func (fe *FreeExtents) Take(index int32) {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+	i := fe.search(index)
+	if i == len(fe.extents) || fe.extents[i].Start > index {
+		return // Ya estaba ocupado
+	}
+	ext := fe.extents[i]
+	before := Extent{Start: ext.Start, Length: index - ext.Start}
+	after := Extent{Start: index + 1, Length: ext.Start + ext.Length - index - 1}
+	switch {
+	case before.Length == 0 && after.Length == 0:
+		fe.extents = append(fe.extents[:i], fe.extents[i+1:]...)
+	case before.Length == 0:
+		fe.extents[i] = after
+	case after.Length == 0:
+		fe.extents[i] = before
+	default:
+		fe.extents = append(fe.extents[:i+1], fe.extents[i:]...)
+		fe.extents[i], fe.extents[i+1] = before, after
+	}
+	fe.free--
+}
+
+// Release marca index como libre uniéndolo con los tramos vecinos.
+func (fe *FreeExtents) Release(index int32) {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+	i := fe.search(index)
+	if i < len(fe.extents) && fe.extents[i].Start <= index {
+		return // Ya estaba libre
+	}
+	joinPrev := i > 0 && fe.extents[i-1].Start+fe.extents[i-1].Length == index
+	joinNext := i < len(fe.extents) && fe.extents[i].Start == index+1
+	switch {
+	case joinPrev && joinNext:
+		fe.extents[i-1].Length += 1 + fe.extents[i].Length
+		fe.extents = append(fe.extents[:i], fe.extents[i+1:]...)
+	case joinPrev:
+		fe.extents[i-1].Length++
+	case joinNext:
+		fe.extents[i].Start--
+		fe.extents[i].Length++
+	default:
+		fe.extents = append(fe.extents[:i], append([]Extent{{Start: index, Length: 1}}, fe.extents[i:]...)...)
+	}
+	fe.free++
+}
+
+// Free devuelve la cantidad de índices libres.
+func (fe *FreeExtents) Free() int32 {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+	return fe.free
+}
+
+// Extents devuelve una copia de los tramos libres.
+func (fe *FreeExtents) Extents() []Extent {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+	return append([]Extent(nil), fe.extents...)
+}
+
+// FindRun busca un tramo libre de count índices consecutivos con el mismo criterio que
+// GetFirstAvailablePartition: 'F' el primero que alcance, 'B' el de menor sobrante y 'W' el más grande.
+func (fe *FreeExtents) FindRun(count int32, fit byte) (int32, bool) {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+	best := -1
+	for i, ext := range fe.extents {
+		if ext.Length < count {
+			continue
+		}
+		switch fit {
+		case 'B':
+			if best == -1 || ext.Length < fe.extents[best].Length {
+				best = i
+			}
+		case 'W':
+			if best == -1 || ext.Length > fe.extents[best].Length {
+				best = i
+			}
+		default: // 'F'
+			return ext.Start, true
+		}
+	}
+	if best == -1 {
+		return -1, false
+	}
+	return fe.extents[best].Start, true
+}
+
+// Collect devuelve hasta count índices libres recorriendo los tramos desde from (con vuelta al inicio).
+func (fe *FreeExtents) Collect(from int32, count int32) []int32 {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+	indices := []int32{}
+	if len(fe.extents) == 0 {
+		return indices
+	}
+	if from < 0 {
+		from = 0
+	}
+	first := fe.search(from)
+	for n := 0; n < len(fe.extents) && int32(len(indices)) < count; n++ {
+		ext := fe.extents[(first+n)%len(fe.extents)]
+		start := ext.Start
+		if n == 0 && first < len(fe.extents) && from > start {
+			start = from
+		}
+		for i := start; i < ext.Start+ext.Length && int32(len(indices)) < count; i++ {
+			indices = append(indices, i)
+		}
+	}
+	// Lo que quedó antes de from en el tramo inicial
+	if int32(len(indices)) < count && first < len(fe.extents) {
+		ext := fe.extents[first]
+		for i := ext.Start; i < from && i < ext.Start+ext.Length && int32(len(indices)) < count; i++ {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// FreeIndex agrupa los índices de inodos y bloques libres de un sistema de archivos.
+type FreeIndex struct {
+	Inodes *FreeExtents
+	Blocks *FreeExtents
+}
+
+type freeIndexKey struct {
+	disk  string // Path absoluto del disco
+	start int32  // Inicio del sistema de archivos (posición del superbloque)
+}
+
+var (
+	freeIndexesMu sync.Mutex
+	freeIndexes   = make(map[freeIndexKey]*FreeIndex)
+)
+
+func (sb *SuperBlock) freeIndexKey(path string) freeIndexKey {
+	return freeIndexKey{disk: deviceKey(path), start: sb.S_bm_inode_start - sb.DiskSize()}
+}
+
+// FreeIndex devuelve el índice de espacio libre del sistema de archivos, construyéndolo desde
+// los bitmaps la primera vez.
+func (sb *SuperBlock) FreeIndex(path string) (*FreeIndex, error) {
+	key := sb.freeIndexKey(path)
+	freeIndexesMu.Lock()
+	index, ok := freeIndexes[key]
+	freeIndexesMu.Unlock()
+	if ok {
+		return index, nil
+	}
+	return sb.LoadFreeIndex(path)
+}
+
+// LoadFreeIndex (re)construye el índice de espacio libre leyendo los bitmaps del disco.
+func (sb *SuperBlock) LoadFreeIndex(path string) (*FreeIndex, error) {
+	inodeBitmap, err := sb.ReadInodeBitmap(path)
+	if err != nil {
+		return nil, fmt.Errorf("error construyendo índice de inodos libres: %w", err)
+	}
+	blockBitmap, err := sb.ReadBlockBitmap(path)
+	if err != nil {
+		return nil, fmt.Errorf("error construyendo índice de bloques libres: %w", err)
+	}
+	index := &FreeIndex{Inodes: newFreeExtents(inodeBitmap), Blocks: newFreeExtents(blockBitmap)}
+
+	freeIndexesMu.Lock()
+	freeIndexes[sb.freeIndexKey(path)] = index
+	freeIndexesMu.Unlock()
+	return index, nil
+}
+
+// Devuelve el índice solo si ya está construido.
+func (sb *SuperBlock) loadedFreeIndex(path string) *FreeIndex {
+	freeIndexesMu.Lock()
+	defer freeIndexesMu.Unlock()
+	return freeIndexes[sb.freeIndexKey(path)]
+}
+
+func (sb *SuperBlock) dropFreeIndex(path string) {
+	freeIndexesMu.Lock()
+	defer freeIndexesMu.Unlock()
+	delete(freeIndexes, sb.freeIndexKey(path))
+}
+
+// DropFreeIndex descarta los índices de espacio libre de los sistemas de archivos del disco
+// en path; se reconstruyen al volver a usarse.
+func DropFreeIndex(path string) {
+	disk := deviceKey(path)
+	freeIndexesMu.Lock()
+	defer freeIndexesMu.Unlock()
+	for key := range freeIndexes {
+		if key.disk == disk {
+			delete(freeIndexes, key)
+		}
+	}
+}
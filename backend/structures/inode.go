@@ -2,34 +2,41 @@ package structures
 
 import (
 	"bytes"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
-	"os"
-	"strings"
 	"time"
 )
 
 type Inode struct {
-	I_uid   int32
-	I_gid   int32
-	I_size  int32
-	I_atime float32
-	I_ctime float32
-	I_mtime float32
-	I_block [15]int32
-	I_type  [1]byte
-	I_perm  [3]byte
-	// Total: 88 bytes
+	I_uid     int32
+	I_gid     int32
+	I_size    int64 // En disco: 32 bits, o 64 con FeatureIncompatLargeFiles (ver inode_layout.go)
+	I_atime   int64 // Segundos Unix; en disco float32, o int64 con FeatureIncompatWideTimes
+	I_ctime   int64
+	I_mtime   int64
+	I_block   [15]int32
+	I_type    [1]byte
+	I_perm    [3]byte
+	I_links   int32 // Entradas de directorio que apuntan al inodo (sin contar . y ..); en disco solo con FeatureIncompatLinkCounts
+	I_xattr   int32 // Bloque de atributos extendidos, -1 si no tiene; en disco solo con FeatureIncompatXattrs
+	I_special byte  // Bits setuid (4), setgid (2) y sticky (1): el cuarto dígito de chmod; en disco solo con FeatureIncompatSpecialPerms
+	// Total en disco: 88 bytes (+4 con FeatureIncompatLargeFiles, +24 con FeatureIncompatWideTimes, +4 con FeatureIncompatLinkCounts, +4 con FeatureIncompatXattrs, +1 con FeatureIncompatSpecialPerms)
 }
 
-func (inode *Inode) Serialize(path string, offset int64) error {
+// Serialize escribe el inodo en offset con el formato de las características de sb.
+func (inode *Inode) Serialize(sb *SuperBlock, path string, offset int64) error {
 	if offset < 0 {
 		return fmt.Errorf("offset negativo inválido para serializar inodo: %d", offset)
 	}
+	if sb == nil {
+		return errors.New("superbloque nil al serializar inodo")
+	}
+	if err := sb.CheckWritable(); err != nil {
+		return err
+	}
 
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	file, err := OpenDisk(path)
 	if err != nil {
 		return fmt.Errorf("error abriendo archivo '%s' para escribir inodo en offset %d: %w", path, offset, err)
 	}
@@ -40,21 +47,29 @@ func (inode *Inode) Serialize(path string, offset int64) error {
 		return fmt.Errorf("error buscando offset %d para escribir inodo: %w", offset, err)
 	}
 
-	err = binary.Write(file, binary.LittleEndian, inode)
+	data, err := encodeInode(inode, sb.S_feature_incompat)
+	if err != nil {
+		return fmt.Errorf("error codificando inodo para offset %d: %w", offset, err)
+	}
+	_, err = file.Write(data)
 	if err != nil {
-		return fmt.Errorf("error escribiendo inodo en offset %d con binary.Write: %w", offset, err)
+		return fmt.Errorf("error escribiendo inodo en offset %d: %w", offset, err)
 	}
 
 	return nil
 }
 
-// Deserialize lee la estructura Inode desde un archivo binario en la posición especificada
-func (inode *Inode) Deserialize(path string, offset int64) error {
+// Deserialize lee la estructura Inode desde un archivo binario en la posición especificada, con
+// el formato de las características de sb
+func (inode *Inode) Deserialize(sb *SuperBlock, path string, offset int64) error {
 	if offset < 0 {
 		return fmt.Errorf("offset negativo inválido para deserializar inodo: %d", offset)
 	}
+	if sb == nil {
+		return errors.New("superbloque nil al deserializar inodo")
+	}
 
-	file, err := os.Open(path)
+	file, err := OpenDisk(path)
 	if err != nil {
 		return fmt.Errorf("error abriendo archivo '%s' para leer inodo en offset %d: %w", path, offset, err)
 	}
@@ -67,11 +82,9 @@ func (inode *Inode) Deserialize(path string, offset int64) error {
 		return fmt.Errorf("error buscando offset %d para leer inodo: %w", offset, err)
 	}
 
-	// Usar un puntero a un Inode vacío para calcular el tamaño esperado
-	expectedSize := binary.Size(&Inode{})
-	if expectedSize <= 0 {
-		return fmt.Errorf("tamaño calculado de estructura Inode inválido: %d", expectedSize)
-	}
+	// El tamaño en disco depende de las características del sistema de archivos
+	features := sb.S_feature_incompat
+	expectedSize := int(InodeDiskSize(features))
 
 	// Leer solo la cantidad de bytes que corresponden al tamaño de la estructura Inode
 	buffer := make([]byte, expectedSize)
@@ -94,8 +107,7 @@ func (inode *Inode) Deserialize(path string, offset int64) error {
 	}
 
 	// Deserializar los bytes leídos en la estructura Inode
-	reader := bytes.NewReader(buffer)
-	err = binary.Read(reader, binary.LittleEndian, inode)
+	err = decodeInode(buffer, features, inode)
 	if err != nil {
 		return fmt.Errorf("error deserializando bytes de inodo desde offset %d: %w", offset, err)
 	}
@@ -105,19 +117,21 @@ func (inode *Inode) Deserialize(path string, offset int64) error {
 // Print imprime los atributos del inodo de forma legible
 func (inode *Inode) Print() {
 
-	atime := time.Unix(int64(inode.I_atime), 0)
-	ctime := time.Unix(int64(inode.I_ctime), 0)
-	mtime := time.Unix(int64(inode.I_mtime), 0)
+	atime := time.Unix(inode.I_atime, 0)
+	ctime := time.Unix(inode.I_ctime, 0)
+	mtime := time.Unix(inode.I_mtime, 0)
 	timeFormat := "2006-01-02 15:04:05"
 
 	fmt.Printf("  I_uid: %d\n", inode.I_uid)
 	fmt.Printf("  I_gid: %d\n", inode.I_gid)
 	fmt.Printf("  I_size: %d bytes\n", inode.I_size)
-	fmt.Printf("  I_atime: %s (%.0f)\n", atime.Format(timeFormat), inode.I_atime)
-	fmt.Printf("  I_ctime: %s (%.0f)\n", ctime.Format(timeFormat), inode.I_ctime)
-	fmt.Printf("  I_mtime: %s (%.0f)\n", mtime.Format(timeFormat), inode.I_mtime)
-	fmt.Printf("  I_type: %c (%s)\n", inode.I_type[0], map[byte]string{'0': "Directorio", '1': "Archivo"}[inode.I_type[0]])
-	fmt.Printf("  I_perm: %s\n", string(inode.I_perm[:]))
+	fmt.Printf("  I_atime: %s (%d)\n", atime.Format(timeFormat), inode.I_atime)
+	fmt.Printf("  I_ctime: %s (%d)\n", ctime.Format(timeFormat), inode.I_ctime)
+	fmt.Printf("  I_mtime: %s (%d)\n", mtime.Format(timeFormat), inode.I_mtime)
+	fmt.Printf("  I_type: %c (%s)\n", inode.I_type[0], map[byte]string{'0': "Directorio", '1': "Archivo", '2': "Enlace"}[inode.I_type[0]])
+	fmt.Printf("  I_perm: %s\n", inode.Mode())
+	fmt.Printf("  I_links: %d\n", inode.I_links)
+	fmt.Printf("  I_xattr: %d\n", inode.I_xattr)
 	fmt.Printf("  I_block Pointers:\n")
 	fmt.Printf("    Directos [0-11] : %v\n", inode.I_block[0:12])
 	fmt.Printf("    Indirecto L1 [12]: %d\n", inode.I_block[12])
@@ -128,127 +142,28 @@ func (inode *Inode) Print() {
 
 // FUNCIÓN PARA BUSCAR UN ARCHIVO---------------------------------------------------------------------------------------
 // FUNCIÓN PARA BUSCAR UN ARCHIVO---------------------------------------------------------------------------------------
+// Los enlaces simbólicos se siguen, también el del último componente (ver resolvePath).
 func FindInodeByPath(sb *SuperBlock, diskPath string, path string) (int32, *Inode, error) {
-	fmt.Printf("Buscando inodo para path: %s\n", path)
-
-	components := strings.Split(path, "/")
-	var cleanComponents []string
-	for _, c := range components {
-		if c != "" {
-			cleanComponents = append(cleanComponents, c)
-		}
-	}
-
-	fmt.Printf("Componentes del path: %v\n", cleanComponents)
-
-	// Si es un path vacío o solo la raíz (/), devolver el inodo raíz
-	if len(cleanComponents) == 0 {
-		rootInode := &Inode{}
-		if err := rootInode.Deserialize(diskPath, int64(sb.S_inode_start)); err != nil {
-			return -1, nil, fmt.Errorf("error al leer inodo raíz: %v", err)
-		}
-		return 0, rootInode, nil
-	}
-
-	currentInodeNum := int32(0) // Inodo raíz es 0
-
-	// Para cada componente del path, buscar en el directorio correspondiente
-	for i, component := range cleanComponents {
-		fmt.Printf("Buscando componente %d: %s (en inodo %d)\n", i, component, currentInodeNum)
-
-		currentInode := &Inode{}
-		offset := int64(sb.S_inode_start + currentInodeNum*sb.S_inode_size)
-		if err := currentInode.Deserialize(diskPath, offset); err != nil {
-			return -1, nil, err
-		}
-
-		fmt.Printf("Tipo de inodo actual: %s\n", string(currentInode.I_type[:]))
-
-		// Verificar que el inodo actual es un directorio (excepto para el último componente)
-		if i < len(cleanComponents)-1 && currentInode.I_type[0] != '0' {
-			return -1, nil, fmt.Errorf("'%s' no es un directorio", component)
-		}
-
-		found := false
-		// Iterar sobre los bloques de punteros del inodo actual
-		for blockIndex, blockPtr := range currentInode.I_block {
-			if blockPtr == -1 {
-				continue
-			}
-			// Debugeando
-			fmt.Printf("Examinando bloque %d (puntero %d) del inodo %d\n",
-				blockIndex, blockPtr, currentInodeNum)
-
-			// Leer el bloque de carpeta
-			folderBlock := &FolderBlock{}
-			blockOffset := int64(sb.S_block_start + blockPtr*sb.S_block_size)
-			if err := folderBlock.Deserialize(diskPath, blockOffset); err != nil {
-				return -1, nil, fmt.Errorf("error al leer bloque %d: %v", blockPtr, err)
-			}
-
-			// Imprimir el contenido del bloque para depuración
-			fmt.Println("Contenido del bloque:")
-			for entryIndex, entry := range folderBlock.B_content {
-				if entry.B_inodo != -1 {
-					name := strings.TrimRight(string(entry.B_name[:]), "\x00")
-					fmt.Printf("  [%d] %q -> inodo %d\n", entryIndex, name, entry.B_inodo)
-				}
-			}
-
-			// Buscar el componente actual en el bloque de carpeta
-			for _, entry := range folderBlock.B_content {
-				if entry.B_inodo == -1 {
-					continue
-				}
-
-				// Convertir el nombre del archivo a una cadena y eliminar los caracteres nulos
-				name := strings.TrimRight(string(entry.B_name[:]), "\x00")
-				fmt.Printf("Comparando '%s' con '%s'\n", name, component)
-
-				// Si el nombre del archivo coincide con el componente actual, actualizar el inodo actual
-				if name == component {
-					currentInodeNum = entry.B_inodo
-					found = true
-					fmt.Printf("¡Encontrado! El inodo para '%s' es %d\n", component, currentInodeNum)
-					break
-				}
-			}
-			// Si se encontró el componente actual, salir del bucle de bloques
-			if found {
-				break
-			}
-		}
-
-		// Si no se encontró el componente actual, devolver un error
-		if !found {
-			return -1, nil, fmt.Errorf("no se encontró '%s' en el directorio actual", component)
-		}
-	}
-
-	// Leer el inodo final
-	targetInode := &Inode{}
-	offset := int64(sb.S_inode_start + currentInodeNum*sb.S_inode_size)
-	fmt.Printf("Obteniendo inodo final %d en offset %d\n", currentInodeNum, offset)
-
-	// Debugeando
-	if err := targetInode.Deserialize(diskPath, offset); err != nil {
-		return -1, nil, fmt.Errorf("error al leer inodo final %d: %v", currentInodeNum, err)
-	}
-
-	// Debugeando
-	fmt.Printf("Inodo encontrado - tipo: %s, tamaño: %d\n",
-		string(targetInode.I_type[:]), targetInode.I_size)
-
-	return currentInodeNum, targetInode, nil
+	return resolvePath(sb, diskPath, path, true)
 }
 
-// ReadFileContent lee el contenido completo de un archivo, manejando indirección.
+// ReadFileContentMax es el tamaño máximo que ReadFileContent carga en memoria. Sirve para archivos
+// de metadatos pequeños (users.txt, journal, destinos de enlaces); para el contenido de archivos
+// de usuario se usa File (OpenFile), que lee por bloques.
+const ReadFileContentMax = 16 << 20
+
+// ReadFileContent lee el contenido completo de un archivo, manejando indirección. Rechaza archivos
+// de más de ReadFileContentMax bytes.
 func ReadFileContent(sb *SuperBlock, diskPath string, inode *Inode) (string, error) {
 	// Validaciones iniciales
 	if inode == nil {
 		return "", errors.New("inodo proporcionado es nil")
 	}
-	if inode.I_type[0] != '1' {
+	// El contenido de un enlace simbólico es su destino
+	if inode.InlineSymlink() {
+		return inode.inlineTarget(), nil
+	}
+	if inode.I_type[0] != '1' && !inode.IsSymlink() {
 		// No podemos obtener el índice aquí fácilmente, pero sí el tipo
 		return "", fmt.Errorf("el inodo no es de tipo archivo (tipo: %c)", inode.I_type[0])
 	}
@@ -258,6 +173,9 @@ func ReadFileContent(sb *SuperBlock, diskPath string, inode *Inode) (string, err
 	if inode.I_size == 0 {
 		return "", nil // Archivo vacío, retornar string vacío sin error
 	}
+	if inode.I_size > ReadFileContentMax {
+		return "", fmt.Errorf("el archivo (%d bytes) es demasiado grande para leerlo completo en memoria (máximo %d)", inode.I_size, ReadFileContentMax)
+	}
 	if sb.S_block_size <= 0 {
 		return "", errors.New("tamaño de bloque inválido en superbloque")
 	}
@@ -271,16 +189,16 @@ func ReadFileContent(sb *SuperBlock, diskPath string, inode *Inode) (string, err
 	// Función auxiliar para leer un bloque de datos y añadirlo al buffer
 	readBlock := func(blockPtr int32) error {
 		// Verificar si ya leímos todo lo necesario
-		if int32(content.Len()) >= inode.I_size {
+		if int64(content.Len()) >= inode.I_size {
 			fmt.Printf("  readBlock: Límite de tamaño %d alcanzado (%d leídos). Deteniendo.\n", inode.I_size, content.Len())
 			return nil // Ya se leyó suficiente, no es un error
 		}
 
-		// Validar puntero de bloque
+		// Un puntero -1 es un hueco del archivo disperso: se lee como ceros
 		if blockPtr == -1 {
-			// Esto podría indicar un "sparse file" o un error si se esperaba un bloque.
-			fmt.Printf("  readBlock: Puntero -1 encontrado. Tratando como bloque vacío/sparse.\n")
-			return nil // No leer nada, continuar
+			fmt.Printf("  readBlock: Puntero -1 encontrado. Tratando como hueco (ceros).\n")
+			writeHole(&content, 1, sb.S_block_size, inode.I_size)
+			return nil
 		}
 		if blockPtr < 0 || blockPtr >= sb.S_blocks_count {
 			// Error grave, puntero corrupto
@@ -288,7 +206,7 @@ func ReadFileContent(sb *SuperBlock, diskPath string, inode *Inode) (string, err
 		}
 
 		fmt.Printf("  readBlock: Leyendo bloque de datos %d...\n", blockPtr)
-		fileBlock := &FileBlock{} // Usar FileBlock
+		fileBlock := NewFileBlock(sb.S_block_size) // Usar FileBlock
 		blockOffset := int64(sb.S_block_start) + int64(blockPtr)*int64(sb.S_block_size)
 		if err := fileBlock.Deserialize(diskPath, blockOffset); err != nil {
 			// Error al leer el bloque físico
@@ -296,10 +214,10 @@ func ReadFileContent(sb *SuperBlock, diskPath string, inode *Inode) (string, err
 		}
 
 		// Calcular cuántos bytes copiar de este bloque
-		remainingInFile := inode.I_size - int32(content.Len())
-		bytesAvailableInBlock := int32(len(fileBlock.B_content)) // Suele ser 64
-		if bytesAvailableInBlock > sb.S_block_size {
-			bytesAvailableInBlock = sb.S_block_size
+		remainingInFile := inode.I_size - int64(content.Len())
+		bytesAvailableInBlock := int64(len(fileBlock.B_content)) // Suele ser 64
+		if bytesAvailableInBlock > int64(sb.S_block_size) {
+			bytesAvailableInBlock = int64(sb.S_block_size)
 		} // Asegurar no exceder tamaño bloque
 
 		bytesToCopy := bytesAvailableInBlock
@@ -316,7 +234,7 @@ func ReadFileContent(sb *SuperBlock, diskPath string, inode *Inode) (string, err
 				// Error escribiendo en el buffer de memoria (muy improbable)
 				return fmt.Errorf("error escribiendo en buffer interno: %w", errWrite)
 			}
-			if int32(written) != bytesToCopy {
+			if int64(written) != bytesToCopy {
 				return fmt.Errorf("escritura incompleta en buffer interno (%d vs %d)", written, bytesToCopy)
 			}
 		} else {
@@ -339,56 +257,50 @@ func ReadFileContent(sb *SuperBlock, diskPath string, inode *Inode) (string, err
 		}
 		blocksProcessed++
 		// Verificar si ya terminamos después de procesar este bloque
-		if int32(content.Len()) >= inode.I_size {
+		if int64(content.Len()) >= inode.I_size {
 			break
 		}
 	}
-	if int32(content.Len()) >= inode.I_size {
+	if int64(content.Len()) >= inode.I_size {
 		fmt.Println("Contenido completo leído desde bloques directos.")
 		return content.String(), nil // Terminado
 	}
 
-	// Indirecto Simple (12)
-	if inode.I_block[12] != -1 {
-		fmt.Printf("Leyendo bloques desde Indirecto Simple (L1 en %d)...\n", inode.I_block[12])
-		errRead = readIndirectBlocksRecursive(1, inode.I_block[12], sb, diskPath, &content, inode.I_size, readBlock)
-		if errRead != nil {
-			return "", fmt.Errorf("error en indirección simple (puntero %d): %w", inode.I_block[12], errRead)
-		}
-		if int32(content.Len()) >= inode.I_size {
-			fmt.Println("Contenido completo leído (alcanzado en indirección simple).")
-			return content.String(), nil // Terminado
-		}
+	// Indirecto Simple (12); un puntero -1 es un hueco que cubre todos sus bloques
+	fmt.Printf("Leyendo bloques desde Indirecto Simple (L1 en %d)...\n", inode.I_block[12])
+	errRead = readIndirectBlocksRecursive(1, inode.I_block[12], sb, diskPath, &content, inode.I_size, readBlock)
+	if errRead != nil {
+		return "", fmt.Errorf("error en indirección simple (puntero %d): %w", inode.I_block[12], errRead)
+	}
+	if int64(content.Len()) >= inode.I_size {
+		fmt.Println("Contenido completo leído (alcanzado en indirección simple).")
+		return content.String(), nil // Terminado
 	}
 
 	// Indirecto Doble (13)
-	if inode.I_block[13] != -1 {
-		fmt.Printf("Leyendo bloques desde Indirecto Doble (L1 en %d)...\n", inode.I_block[13])
-		errRead = readIndirectBlocksRecursive(2, inode.I_block[13], sb, diskPath, &content, inode.I_size, readBlock)
-		if errRead != nil {
-			return "", fmt.Errorf("error en indirección doble (puntero %d): %w", inode.I_block[13], errRead)
-		}
-		if int32(content.Len()) >= inode.I_size {
-			fmt.Println("Contenido completo leído (alcanzado en indirección doble).")
-			return content.String(), nil // Terminado
-		}
+	fmt.Printf("Leyendo bloques desde Indirecto Doble (L1 en %d)...\n", inode.I_block[13])
+	errRead = readIndirectBlocksRecursive(2, inode.I_block[13], sb, diskPath, &content, inode.I_size, readBlock)
+	if errRead != nil {
+		return "", fmt.Errorf("error en indirección doble (puntero %d): %w", inode.I_block[13], errRead)
+	}
+	if int64(content.Len()) >= inode.I_size {
+		fmt.Println("Contenido completo leído (alcanzado en indirección doble).")
+		return content.String(), nil // Terminado
 	}
 
 	// Indirecto Triple (14)
-	if inode.I_block[14] != -1 {
-		fmt.Printf("Leyendo bloques desde Indirecto Triple (L1 en %d)...\n", inode.I_block[14])
-		errRead = readIndirectBlocksRecursive(3, inode.I_block[14], sb, diskPath, &content, inode.I_size, readBlock)
-		if errRead != nil {
-			return "", fmt.Errorf("error en indirección triple (puntero %d): %w", inode.I_block[14], errRead)
-		}
-		// No necesitamos verificar tamaño aquí, es la última etapa
+	fmt.Printf("Leyendo bloques desde Indirecto Triple (L1 en %d)...\n", inode.I_block[14])
+	errRead = readIndirectBlocksRecursive(3, inode.I_block[14], sb, diskPath, &content, inode.I_size, readBlock)
+	if errRead != nil {
+		return "", fmt.Errorf("error en indirección triple (puntero %d): %w", inode.I_block[14], errRead)
 	}
+	// No necesitamos verificar tamaño aquí, es la última etapa
 
 	// Verificación final: si después de todo, no se leyó el tamaño esperado, hay un problema
-	if int32(content.Len()) < inode.I_size {
+	if int64(content.Len()) < inode.I_size {
 		fmt.Printf("Advertencia: Se leyeron %d bytes pero el tamaño del inodo es %d. ¿Punteros faltantes o corruptos?\n", content.Len(), inode.I_size)
 		// Podríamos retornar error o el contenido parcial. Retornamos parcial.
-	} else if int32(content.Len()) > inode.I_size {
+	} else if int64(content.Len()) > inode.I_size {
 		// Esto no debería pasar con la lógica de readBlock, pero por si acaso. Truncar.
 		fmt.Printf("Advertencia: Se leyeron %d bytes pero el tamaño del inodo es %d. Truncando.\n", content.Len(), inode.I_size)
 		return content.String()[:inode.I_size], nil
@@ -405,7 +317,7 @@ func readIndirectBlocksRecursive(
 	sb *SuperBlock,
 	diskPath string,
 	content *bytes.Buffer, // Usar buffer para eficiencia
-	sizeLimit int32,
+	sizeLimit int64,
 	readBlockFunc func(int32) error, // Función para leer un bloque de DATOS
 ) error {
 
@@ -415,8 +327,9 @@ func readIndirectBlocksRecursive(
 		return fmt.Errorf("nivel de indirección inválido: %d", level)
 	}
 	if blockPtr == -1 {
-		// fmt.Printf("  readIndirect L%d: Puntero -1, nada que hacer.\n", level)
-		return nil // No es un error, simplemente no hay bloque aquí
+		// Hueco: el bloque de punteros no existe, todos los bloques que cubriría son ceros
+		writeHole(content, sb.blockSpan(level), sb.S_block_size, sizeLimit)
+		return nil
 	}
 	if blockPtr < 0 || blockPtr >= sb.S_blocks_count {
 		return fmt.Errorf("puntero inválido %d encontrado en indirección nivel %d", blockPtr, level)
@@ -425,7 +338,7 @@ func readIndirectBlocksRecursive(
 	fmt.Printf("  readIndirect L%d: Procesando bloque de punteros %d...\n", level, blockPtr)
 
 	// Deserializar el bloque de punteros de este nivel
-	ptrBlock := &PointerBlock{} // Usar PointerBlock
+	ptrBlock := NewPointerBlock(sb.S_block_size) // Usar PointerBlock
 	ptrOffset := int64(sb.S_block_start) + int64(blockPtr)*int64(sb.S_block_size)
 	if err := ptrBlock.Deserialize(diskPath, ptrOffset); err != nil {
 		// Loguear error pero intentar continuar si es posible? O retornar error?
@@ -436,13 +349,15 @@ func readIndirectBlocksRecursive(
 	// Iterar sobre los punteros de este bloque
 	for i, nextPtr := range ptrBlock.P_pointers {
 		// Detener si ya hemos leído suficiente ANTES de procesar el siguiente puntero
-		if int32(content.Len()) >= sizeLimit {
+		if int64(content.Len()) >= sizeLimit {
 			// fmt.Printf("  readIndirect L%d: Límite de tamaño alcanzado en índice %d. Deteniendo.\n", level, i)
 			break // Salir del bucle for P_pointers
 		}
 
 		if nextPtr == -1 {
-			continue // Puntero no usado
+			// Hueco de un bloque de datos o de todo un subárbol
+			writeHole(content, sb.blockSpan(level-1), sb.S_block_size, sizeLimit)
+			continue
 		}
 		// Validar el nextPtr antes de usarlo
 		if nextPtr < 0 || nextPtr >= sb.S_blocks_count {
@@ -471,3 +386,14 @@ func readIndirectBlocksRecursive(
 
 	return nil // Éxito para este nivel
 }
+
+// writeHole agrega al contenido los ceros de blocks bloques de un hueco, sin pasar de sizeLimit.
+func writeHole(content *bytes.Buffer, blocks int64, blockSize int32, sizeLimit int64) {
+	n := sizeLimit - int64(content.Len())
+	if hole := blocks * int64(blockSize); hole < n {
+		n = hole
+	}
+	if n > 0 {
+		content.Write(make([]byte, n))
+	}
+}
@@ -0,0 +1,233 @@
+package structures
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Formato en disco del inodo original (88 bytes): I_size de 32 bits con signo y marcas de tiempo
+// float32.
+type inodeDisk struct {
+	I_uid   int32
+	I_gid   int32
+	I_size  int32
+	I_atime float32
+	I_ctime float32
+	I_mtime float32
+	I_block [15]int32
+	I_type  [1]byte
+	I_perm  [3]byte
+}
+
+// Con FeatureIncompatLargeFiles se agregan los 32 bits altos del tamaño (+4 bytes). I_size guarda
+// los 32 bits bajos.
+type inodeDiskLarge struct {
+	I_size_high int32
+}
+
+// Con FeatureIncompatWideTimes se agregan las marcas de tiempo exactas en segundos (+24 bytes). Los
+// campos float32 del formato original se siguen escribiendo, redondeados.
+type inodeDiskTimes struct {
+	I_atime int64
+	I_ctime int64
+	I_mtime int64
+}
+
+// Con FeatureIncompatLinkCounts se agrega el contador de enlaces (+4 bytes). Sin ella cada inodo
+// tiene exactamente una entrada de directorio y I_links vale 1.
+type inodeDiskLinks struct {
+	I_links int32
+}
+
+// Con FeatureIncompatXattrs se agrega el bloque de atributos extendidos (+4 bytes, -1 = ninguno).
+type inodeDiskXattr struct {
+	I_xattr int32
+}
+
+// Con FeatureIncompatSpecialPerms se agregan los bits setuid, setgid y sticky (+1 byte).
+type inodeDiskSpecial struct {
+	I_special byte
+}
+
+// InodeDiskSize devuelve los bytes que ocupa un inodo en la tabla con las características features.
+func InodeDiskSize(features int32) int32 {
+	size := binary.Size(inodeDisk{})
+	if features&FeatureIncompatLargeFiles != 0 {
+		size += binary.Size(inodeDiskLarge{})
+	}
+	if features&FeatureIncompatWideTimes != 0 {
+		size += binary.Size(inodeDiskTimes{})
+	}
+	if features&FeatureIncompatLinkCounts != 0 {
+		size += binary.Size(inodeDiskLinks{})
+	}
+	if features&FeatureIncompatXattrs != 0 {
+		size += binary.Size(inodeDiskXattr{})
+	}
+	if features&FeatureIncompatSpecialPerms != 0 {
+		size += binary.Size(inodeDiskSpecial{})
+	}
+	return int32(size)
+}
+
+// LargeFiles indica si los inodos guardan el tamaño en 64 bits (FeatureIncompatLargeFiles).
+func (sb *SuperBlock) LargeFiles() bool {
+	return sb.HasIncompat(FeatureIncompatLargeFiles)
+}
+
+// LinkCounts indica si los inodos guardan un contador de enlaces duros (FeatureIncompatLinkCounts).
+func (sb *SuperBlock) LinkCounts() bool {
+	return sb.HasIncompat(FeatureIncompatLinkCounts)
+}
+
+// Xattrs indica si los inodos pueden tener un bloque de atributos extendidos (FeatureIncompatXattrs).
+func (sb *SuperBlock) Xattrs() bool {
+	return sb.HasIncompat(FeatureIncompatXattrs)
+}
+
+// SpecialPerms indica si los inodos guardan los bits setuid, setgid y sticky
+// (FeatureIncompatSpecialPerms).
+func (sb *SuperBlock) SpecialPerms() bool {
+	return sb.HasIncompat(FeatureIncompatSpecialPerms)
+}
+
+// MaxFileSizeFor devuelve el tamaño máximo de un archivo con bloques de bs bytes: lo que
+// direccionan los 12 punteros directos y los indirectos simple, doble y triple, limitado a
+// 2 GiB - 1 si I_size es de 32 bits.
+func MaxFileSizeFor(bs int32, large bool) int64 {
+	p := int64(PointersPerBlock(bs))
+	max := (12 + p + p*p + p*p*p) * int64(bs)
+	if !large && max > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return max
+}
+
+// MaxFileSize devuelve el tamaño máximo de un archivo en este sistema de archivos.
+func (sb *SuperBlock) MaxFileSize() int64 {
+	return MaxFileSizeFor(sb.S_block_size, sb.LargeFiles())
+}
+
+func encodeInode(inode *Inode, features int32) ([]byte, error) {
+	large := features&FeatureIncompatLargeFiles != 0
+	if inode.I_size < 0 || (!large && inode.I_size > math.MaxInt32) {
+		return nil, fmt.Errorf("el tamaño %d no se puede guardar en un inodo de 32 bits (formatee con mkfs -filesize=64)", inode.I_size)
+	}
+	links := features&FeatureIncompatLinkCounts != 0
+	if !links && inode.I_links > 1 {
+		return nil, fmt.Errorf("el inodo tiene %d enlaces duros y el formato no guarda el contador (característica link_counts)", inode.I_links)
+	}
+	xattrs := features&FeatureIncompatXattrs != 0
+	if !xattrs && inode.I_xattr != -1 {
+		return nil, fmt.Errorf("el inodo tiene atributos extendidos (bloque %d) y el formato no los guarda (característica xattrs)", inode.I_xattr)
+	}
+	special := features&FeatureIncompatSpecialPerms != 0
+	if !special && inode.I_special != 0 {
+		return nil, fmt.Errorf("el inodo tiene bits especiales (%d) y el formato no los guarda (característica special_perms)", inode.I_special)
+	}
+	base := inodeDisk{
+		I_uid: inode.I_uid, I_gid: inode.I_gid, I_size: int32(uint32(inode.I_size)),
+		I_atime: float32(inode.I_atime), I_ctime: float32(inode.I_ctime), I_mtime: float32(inode.I_mtime),
+		I_block: inode.I_block, I_type: inode.I_type, I_perm: inode.I_perm,
+	}
+	parts := []interface{}{base}
+	if large {
+		parts = append(parts, inodeDiskLarge{I_size_high: int32(inode.I_size >> 32)})
+	}
+	if features&FeatureIncompatWideTimes != 0 {
+		parts = append(parts, inodeDiskTimes{I_atime: inode.I_atime, I_ctime: inode.I_ctime, I_mtime: inode.I_mtime})
+	}
+	if links {
+		parts = append(parts, inodeDiskLinks{I_links: inode.I_links})
+	}
+	if xattrs {
+		parts = append(parts, inodeDiskXattr{I_xattr: inode.I_xattr})
+	}
+	if special {
+		parts = append(parts, inodeDiskSpecial{I_special: inode.I_special})
+	}
+	buffer := new(bytes.Buffer)
+	for _, part := range parts {
+		if err := binary.Write(buffer, binary.LittleEndian, part); err != nil {
+			return nil, err
+		}
+	}
+	return buffer.Bytes(), nil
+}
+
+func decodeInode(data []byte, features int32, inode *Inode) error {
+	reader := bytes.NewReader(data)
+	var base inodeDisk
+	if err := binary.Read(reader, binary.LittleEndian, &base); err != nil {
+		return err
+	}
+	*inode = Inode{
+		I_uid: base.I_uid, I_gid: base.I_gid, I_size: int64(base.I_size),
+		I_atime: int64(base.I_atime), I_ctime: int64(base.I_ctime), I_mtime: int64(base.I_mtime),
+		I_block: base.I_block, I_type: base.I_type, I_perm: base.I_perm,
+		I_links: 1, I_xattr: -1,
+	}
+	if features&FeatureIncompatLargeFiles != 0 {
+		var large inodeDiskLarge
+		if err := binary.Read(reader, binary.LittleEndian, &large); err != nil {
+			return err
+		}
+		inode.I_size = int64(large.I_size_high)<<32 | int64(uint32(base.I_size))
+	}
+	if features&FeatureIncompatWideTimes != 0 {
+		var times inodeDiskTimes
+		if err := binary.Read(reader, binary.LittleEndian, &times); err != nil {
+			return err
+		}
+		inode.I_atime, inode.I_ctime, inode.I_mtime = times.I_atime, times.I_ctime, times.I_mtime
+	}
+	if features&FeatureIncompatLinkCounts != 0 {
+		var links inodeDiskLinks
+		if err := binary.Read(reader, binary.LittleEndian, &links); err != nil {
+			return err
+		}
+		inode.I_links = links.I_links
+	}
+	if features&FeatureIncompatXattrs != 0 {
+		var xattr inodeDiskXattr
+		if err := binary.Read(reader, binary.LittleEndian, &xattr); err != nil {
+			return err
+		}
+		inode.I_xattr = xattr.I_xattr
+	}
+	if features&FeatureIncompatSpecialPerms != 0 {
+		var special inodeDiskSpecial
+		if err := binary.Read(reader, binary.LittleEndian, &special); err != nil {
+			return err
+		}
+		inode.I_special = special.I_special & 7
+	}
+	return nil
+}
+
+// DecodeInodes decodifica una tabla de inodos completa leída del disco.
+func (sb *SuperBlock) DecodeInodes(table []byte) ([]Inode, error) {
+	size := int(InodeDiskSize(sb.S_feature_incompat))
+	inodes := make([]Inode, len(table)/size)
+	for i := range inodes {
+		if err := decodeInode(table[i*size:(i+1)*size], sb.S_feature_incompat, &inodes[i]); err != nil {
+			return nil, fmt.Errorf("error decodificando inodo %d: %w", i, err)
+		}
+	}
+	return inodes, nil
+}
+
+// EncodeInodes codifica una tabla de inodos con el formato del superbloque.
+func (sb *SuperBlock) EncodeInodes(inodes []Inode) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	for i := range inodes {
+		data, err := encodeInode(&inodes[i], sb.S_feature_incompat)
+		if err != nil {
+			return nil, fmt.Errorf("error codificando inodo %d: %w", i, err)
+		}
+		buffer.Write(data)
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,157 @@
+package structures
+
+import (
+	"math"
+	"testing"
+)
+
+func testInode() *Inode {
+	inode := &Inode{
+		I_uid: 2, I_gid: 3, I_size: 1000,
+		I_atime: testTime, I_ctime: testTime + 1, I_mtime: testTime + 2,
+		I_type: [1]byte{'1'}, I_perm: [3]byte{'6', '4', '0'}, I_links: 1, I_xattr: -1,
+	}
+	for i := range inode.I_block {
+		inode.I_block[i] = int32(i*10 - 1)
+	}
+	return inode
+}
+
+func TestInodeDiskSize(t *testing.T) {
+	for _, tt := range []struct {
+		features int32
+		size     int32
+	}{
+		{0, 88},
+		{FeatureIncompatLargeFiles, 92},
+		{FeatureIncompatWideTimes, 112},
+		{FeatureIncompatLargeFiles | FeatureIncompatWideTimes, 116},
+		{FeatureIncompatXattrs, 92},
+		{FeatureIncompatSpecialPerms, 89},
+		{FeatureIncompatPackedBitmaps | FeatureIncompatLongNames, 88}, // No cambian el inodo
+	} {
+		if got := InodeDiskSize(tt.features); got != tt.size {
+			t.Errorf("InodeDiskSize(%#x) = %d, se esperaba %d", tt.features, got, tt.size)
+		}
+	}
+}
+
+func TestInodeRoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		features int32
+		edit     func(*Inode)
+		want     func(*Inode) // Cambios esperados al leer lo escrito
+	}{
+		{"original", 0, nil, func(inode *Inode) {
+			// float32 redondea las marcas de tiempo
+			inode.I_atime, inode.I_ctime, inode.I_mtime = testTimeRounded, testTimeRounded, testTimeRounded
+		}},
+		{"tiempos int64", FeatureIncompatWideTimes, nil, nil},
+		{"tamaño de 64 bits", FeatureIncompatLargeFiles | FeatureIncompatWideTimes, func(inode *Inode) {
+			inode.I_size = 5<<32 | 0xFFFFFFF0
+		}, nil},
+		{"bloque de atributos", FeatureIncompatWideTimes | FeatureIncompatXattrs, func(inode *Inode) {
+			inode.I_xattr = 17
+		}, nil},
+		{"bits especiales", FeatureIncompatWideTimes | FeatureIncompatSpecialPerms, func(inode *Inode) {
+			inode.I_special = PermSetgid | PermSticky
+		}, nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			inode := testInode()
+			if tt.edit != nil {
+				tt.edit(inode)
+			}
+			data, err := encodeInode(inode, tt.features)
+			if err != nil {
+				t.Fatalf("encodeInode: %v", err)
+			}
+			if int32(len(data)) != InodeDiskSize(tt.features) {
+				t.Fatalf("encodeInode = %d bytes, InodeDiskSize = %d", len(data), InodeDiskSize(tt.features))
+			}
+			decoded := &Inode{}
+			if err := decodeInode(data, tt.features, decoded); err != nil {
+				t.Fatalf("decodeInode: %v", err)
+			}
+			want := *inode
+			if tt.want != nil {
+				tt.want(&want)
+			}
+			if *decoded != want {
+				t.Errorf("decodeInode = %+v\nse esperaba %+v", *decoded, want)
+			}
+		})
+	}
+}
+
+func TestEncodeInodeErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		features int32
+		edit     func(*Inode)
+	}{
+		{"tamaño negativo", FeatureIncompatLargeFiles, func(inode *Inode) { inode.I_size = -1 }},
+		{"tamaño de 64 bits sin large_files", 0, func(inode *Inode) { inode.I_size = math.MaxInt32 + 1 }},
+		{"atributos sin xattrs", FeatureIncompatWideTimes, func(inode *Inode) { inode.I_xattr = 17 }},
+		{"bits especiales sin special_perms", FeatureIncompatWideTimes, func(inode *Inode) { inode.I_special = PermSticky }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			inode := testInode()
+			tt.edit(inode)
+			if _, err := encodeInode(inode, tt.features); err == nil {
+				t.Errorf("encodeInode: se esperaba error")
+			}
+		})
+	}
+}
+
+func TestDecodeInodeTruncated(t *testing.T) {
+	features := FeatureIncompatLargeFiles | FeatureIncompatWideTimes
+	data, err := encodeInode(testInode(), features)
+	if err != nil {
+		t.Fatalf("encodeInode: %v", err)
+	}
+	for _, size := range []int{0, 87, 91, len(data) - 1} {
+		if err := decodeInode(data[:size], features, &Inode{}); err == nil {
+			t.Errorf("decodeInode de %d bytes: se esperaba error", size)
+		}
+	}
+}
+
+// La tabla de inodos se codifica y decodifica completa con el formato del superbloque.
+func TestEncodeDecodeInodes(t *testing.T) {
+	sb := testSuperBlock(FeatureIncompatWideTimes)
+	inodes := []Inode{*testInode(), *testInode()}
+	inodes[1].I_type = [1]byte{'0'}
+	table, err := sb.EncodeInodes(inodes)
+	if err != nil {
+		t.Fatalf("EncodeInodes: %v", err)
+	}
+	decoded, err := sb.DecodeInodes(table)
+	if err != nil {
+		t.Fatalf("DecodeInodes: %v", err)
+	}
+	if len(decoded) != 2 || decoded[0] != inodes[0] || decoded[1] != inodes[1] {
+		t.Errorf("DecodeInodes = %+v", decoded)
+	}
+}
+
+// Al leer I_special solo se conservan los bits setuid, setgid y sticky.
+func TestDecodeInodeSpecialMask(t *testing.T) {
+	features := FeatureIncompatSpecialPerms
+	inode := testInode()
+	inode.I_special = PermSetuid
+	data, err := encodeInode(inode, features)
+	if err != nil {
+		t.Fatalf("encodeInode: %v", err)
+	}
+	data[len(data)-1] = 0xF0 | PermSetuid | PermSticky
+	decoded := &Inode{}
+	if err := decodeInode(data, features, decoded); err != nil {
+		t.Fatalf("decodeInode: %v", err)
+	}
+	if decoded.I_special != PermSetuid|PermSticky {
+		t.Errorf("I_special = %d, se esperaba %d", decoded.I_special, PermSetuid|PermSticky)
+	}
+}
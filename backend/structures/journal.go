@@ -1,32 +1,115 @@
 package structures
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
-	"os"
+	"io"
 	"time"
 )
 
 type Journal struct {
 	J_count   int32       // 4 bytes
 	J_content Information // 110 bytes
-	// Total: 114 bytes
+	// Total en disco: 114 bytes (122 con FeatureIncompatWideTimes, ver JournalEntrySize)
 }
 
 type Information struct {
 	I_operation [10]byte // 10 bytes
 	I_path      [32]byte // 32 bytes
 	I_content   [64]byte // 64 bytes
-	I_date      float32  // 4 bytes
+	I_date      int64    // 4 bytes (float32) en disco; +8 con la fecha exacta si FeatureIncompatWideTimes
 	// Total: 110 bytes
 }
 
+// Formato en disco de una entrada del journal original (114 bytes).
+type journalDisk struct {
+	J_count     int32
+	I_operation [10]byte
+	I_path      [32]byte
+	I_content   [64]byte
+	I_date      float32
+}
+
+// Con FeatureIncompatWideTimes se agrega la fecha exacta al final de la entrada.
+type journalDiskTimes struct {
+	I_date int64
+}
+
+// JournalEntrySize devuelve los bytes de una entrada del journal con las características features.
+func JournalEntrySize(features int32) int32 {
+	size := binary.Size(journalDisk{})
+	if features&FeatureIncompatWideTimes != 0 {
+		size += binary.Size(journalDiskTimes{})
+	}
+	return int32(size)
+}
+
+// Encode codifica la entrada con el formato de las características features.
+func (journal *Journal) Encode(features int32) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	err := binary.Write(buffer, binary.LittleEndian, journalDisk{
+		J_count:     journal.J_count,
+		I_operation: journal.J_content.I_operation,
+		I_path:      journal.J_content.I_path,
+		I_content:   journal.J_content.I_content,
+		I_date:      float32(journal.J_content.I_date),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if features&FeatureIncompatWideTimes != 0 {
+		if err := binary.Write(buffer, binary.LittleEndian, journalDiskTimes{I_date: journal.J_content.I_date}); err != nil {
+			return nil, err
+		}
+	}
+	return buffer.Bytes(), nil
+}
+
+// Decode lee una entrada codificada con el formato de las características features.
+func (journal *Journal) Decode(data []byte, features int32) error {
+	reader := bytes.NewReader(data)
+	var disk journalDisk
+	if err := binary.Read(reader, binary.LittleEndian, &disk); err != nil {
+		return err
+	}
+	*journal = Journal{J_count: disk.J_count, J_content: Information{
+		I_operation: disk.I_operation,
+		I_path:      disk.I_path,
+		I_content:   disk.I_content,
+		I_date:      int64(disk.I_date),
+	}}
+	if features&FeatureIncompatWideTimes != 0 {
+		var times journalDiskTimes
+		if err := binary.Read(reader, binary.LittleEndian, &times); err != nil {
+			return err
+		}
+		journal.J_content.I_date = times.I_date
+	}
+	return nil
+}
+
+// DecodeJournal separa el contenido del archivo de journal en entradas. Los bytes finales que no
+// completan una entrada se ignoran.
+func DecodeJournal(content []byte, features int32) ([]Journal, error) {
+	entrySize := int(JournalEntrySize(features))
+	entries := make([]Journal, 0, len(content)/entrySize)
+	for offset := 0; offset+entrySize <= len(content); offset += entrySize {
+		var entry Journal
+		if err := entry.Decode(content[offset:offset+entrySize], features); err != nil {
+			return entries, fmt.Errorf("error decodificando entrada %d del journal: %w", len(entries)+1, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
 // SerializeJournal escribe la estructura Journal en un archivo binario
-func (journal *Journal) Serialize(path string, journauling_start int64) error {
+func (journal *Journal) Serialize(path string, journauling_start int64, features int32) error {
 	// Calcular la posición en el archivo
-	offset := journauling_start + (int64(binary.Size(Journal{})) * int64(journal.J_count))
+	offset := journauling_start + (int64(JournalEntrySize(features)) * int64(journal.J_count))
 
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	file, err := OpenDisk(path)
 	if err != nil {
 		return err
 	}
@@ -38,8 +121,12 @@ func (journal *Journal) Serialize(path string, journauling_start int64) error {
 		return err
 	}
 
-	// Serializar la estructura Journal directamente en el archivo
-	err = binary.Write(file, binary.LittleEndian, journal)
+	// Serializar la entrada con el formato del sistema de archivos
+	data, err := journal.Encode(features)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
 	if err != nil {
 		return err
 	}
@@ -48,11 +135,12 @@ func (journal *Journal) Serialize(path string, journauling_start int64) error {
 }
 
 // DeserializeJournal lee la estructura Journal desde un archivo binario
-func (journal *Journal) Deserialize(path string, offset int64) error {
-	file, err := os.Open(path)
+func (journal *Journal) Deserialize(path string, offset int64, features int32) error {
+	file, err := OpenDisk(path)
 	if err != nil {
 		return err
 	}
+	defer file.Close()
 
 	// Mover el puntero del archivo a la posición especificada
 	_, err = file.Seek(offset, 0)
@@ -60,18 +148,19 @@ func (journal *Journal) Deserialize(path string, offset int64) error {
 		return err
 	}
 
-	// Deserializar la estructura Journal directamente desde el archivo
-	err = binary.Read(file, binary.LittleEndian, journal)
+	// Leer y decodificar la entrada con el formato del sistema de archivos
+	buffer := make([]byte, JournalEntrySize(features))
+	_, err = io.ReadFull(file, buffer)
 	if err != nil {
 		return err
 	}
-	return nil
+	return journal.Decode(buffer, features)
 }
 
 // PrintJournal imprime en consola la estructura Journal
 func (journal *Journal) Print() {
 	// Convertir el tiempo de montaje a una fecha
-	date := time.Unix(int64(journal.J_content.I_date), 0)
+	date := time.Unix(journal.J_content.I_date, 0)
 
 	fmt.Println("Journal:")
 	fmt.Printf("J_count: %d", journal.J_count)
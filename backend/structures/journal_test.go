@@ -0,0 +1,83 @@
+package structures
+
+import (
+	"testing"
+)
+
+func testJournal(count int32) Journal {
+	entry := Journal{J_count: count, J_content: Information{I_date: testTime}}
+	copy(entry.J_content.I_operation[:], "mkfile")
+	copy(entry.J_content.I_path[:], "/home/a.txt")
+	copy(entry.J_content.I_content[:], "hola")
+	return entry
+}
+
+func TestJournalRoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		features int32
+		size     int32
+		wantDate int64
+	}{
+		{"original", 0, 114, testTimeRounded},
+		{"tiempos int64", FeatureIncompatWideTimes, 122, testTime},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := JournalEntrySize(tt.features); got != tt.size {
+				t.Fatalf("JournalEntrySize = %d, se esperaba %d", got, tt.size)
+			}
+			entry := testJournal(4)
+			data, err := entry.Encode(tt.features)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if int32(len(data)) != tt.size {
+				t.Fatalf("Encode = %d bytes, se esperaban %d", len(data), tt.size)
+			}
+			var decoded Journal
+			if err := decoded.Decode(data, tt.features); err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			want := entry
+			want.J_content.I_date = tt.wantDate
+			if decoded != want {
+				t.Errorf("Decode = %+v\nse esperaba %+v", decoded, want)
+			}
+			if err := decoded.Decode(data[:len(data)-1], tt.features); err == nil {
+				t.Errorf("Decode de una entrada incompleta: se esperaba error")
+			}
+		})
+	}
+}
+
+// DecodeJournal separa las entradas e ignora los bytes finales que no completan una.
+func TestDecodeJournal(t *testing.T) {
+	features := FeatureIncompatWideTimes
+	var content []byte
+	for i := int32(0); i < 3; i++ {
+		entry := testJournal(i)
+		data, err := entry.Encode(features)
+		if err != nil {
+			t.Fatalf("Encode: %v", err)
+		}
+		content = append(content, data...)
+	}
+	content = append(content, make([]byte, JournalEntrySize(features)-1)...)
+
+	entries, err := DecodeJournal(content, features)
+	if err != nil {
+		t.Fatalf("DecodeJournal: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("DecodeJournal = %d entradas, se esperaban 3", len(entries))
+	}
+	for i, entry := range entries {
+		if entry != testJournal(int32(i)) {
+			t.Errorf("entrada %d = %+v", i, entry)
+		}
+	}
+	// Leído con el formato anterior las fechas int64 se interpretan como otra entrada
+	if old, err := DecodeJournal(content, 0); err != nil || len(old) == 3 {
+		t.Errorf("DecodeJournal con el formato original = %d entradas (%v)", len(old), err)
+	}
+}
@@ -0,0 +1,80 @@
+package structures
+
+import "fmt"
+
+// Enlaces duros (FeatureIncompatLinkCounts). I_links cuenta las entradas de directorio que apuntan
+// al inodo, sin "." ni ".."; por eso una carpeta siempre tiene 1 y la raíz también (su entrada es
+// el punto de montaje). Solo los archivos pueden tener más de un enlace.
+
+// CountLinks recorre el árbol desde la raíz y devuelve cuántas entradas apuntan a cada inodo
+// alcanzable, es decir, el valor que debería tener su I_links.
+func (sb *SuperBlock) CountLinks(path string) (map[int32]int32, error) {
+	counts := map[int32]int32{0: 1}
+	visited := map[int32]bool{}
+	var walk func(dirIndex int32) error
+	walk = func(dirIndex int32) error {
+		if visited[dirIndex] {
+			return nil
+		}
+		visited[dirIndex] = true
+
+		dir := &Inode{}
+		if err := dir.Deserialize(sb, path, int64(sb.S_inode_start)+int64(dirIndex)*int64(sb.S_inode_size)); err != nil {
+			return fmt.Errorf("error al leer el inodo %d: %w", dirIndex, err)
+		}
+		entries, err := sb.ReadDir(path, dir)
+		if err != nil {
+			return fmt.Errorf("error al leer la carpeta %d: %w", dirIndex, err)
+		}
+		for _, entry := range entries {
+			if entry.Name == "." || entry.Name == ".." {
+				continue
+			}
+			if entry.Inode < 0 || entry.Inode >= sb.S_inodes_count {
+				return fmt.Errorf("la carpeta %d apunta al inodo inválido %d ('%s')", dirIndex, entry.Inode, entry.Name)
+			}
+			counts[entry.Inode]++
+			child := &Inode{}
+			if err := child.Deserialize(sb, path, int64(sb.S_inode_start)+int64(entry.Inode)*int64(sb.S_inode_size)); err != nil {
+				return fmt.Errorf("error al leer el inodo %d: %w", entry.Inode, err)
+			}
+			if child.I_type[0] == '0' {
+				if err := walk(entry.Inode); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(0); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// ReconcileLinks corrige el I_links de cada inodo alcanzable desde la raíz para que coincida con
+// las entradas que lo apuntan (recovery). Devuelve cuántos inodos se corrigieron.
+func (sb *SuperBlock) ReconcileLinks(path string) (int, error) {
+	counts, err := sb.CountLinks(path)
+	if err != nil {
+		return 0, err
+	}
+	fixed := 0
+	for index, count := range counts {
+		inode := &Inode{}
+		offset := int64(sb.S_inode_start) + int64(index)*int64(sb.S_inode_size)
+		if err := inode.Deserialize(sb, path, offset); err != nil {
+			return fixed, fmt.Errorf("error al leer el inodo %d: %w", index, err)
+		}
+		if inode.I_links == count {
+			continue
+		}
+		fmt.Printf("  Inodo %d: I_links %d -> %d\n", index, inode.I_links, count)
+		inode.I_links = count
+		if err := inode.Serialize(sb, path, offset); err != nil {
+			return fixed, fmt.Errorf("error al escribir el inodo %d: %w", index, err)
+		}
+		fixed++
+	}
+	return fixed, nil
+}
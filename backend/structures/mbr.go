@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"  // Paquete para formateo de E/S
 	"math" // Para math.MaxInt32 en Best Fit
-	"os"   // Paquete para funciones del sistema operativo
 	"sort" // Para ordenar particiones
 	"strings"
 	"time"
@@ -14,22 +13,154 @@ import (
 
 type MBR struct {
 	Mbr_size           int32        // Tamaño del MBR en bytes
-	Mbr_creation_date  float32      // Fecha y hora de creación del MBR
+	Mbr_creation_date  int64        // Fecha y hora de creación del MBR (segundos Unix)
 	Mbr_disk_signature int32        // Firma del disco
 	Mbr_disk_fit       [1]byte      // Tipo de ajuste
 	Mbr_partitions     [4]Partition // Particiones del MBR
+	Mbr_ext_magic      int32        // MBRExtMagic o MBRMetaMagic si el disco tiene la extensión del MBR
+
+	// Metadatos del formato del disco, solo con MBRMetaMagic (misma semántica que en el superbloque)
+	Mbr_revision          int32
+	Mbr_feature_compat    int32
+	Mbr_feature_ro_compat int32
+	Mbr_feature_incompat  int32
+}
+
+// Formato en disco del MBR original (153 bytes): la fecha es float32 y se redondea a pasos de
+// unos 2 minutos.
+type mbrDisk struct {
+	Mbr_size           int32
+	Mbr_creation_date  float32
+	Mbr_disk_signature int32
+	Mbr_disk_fit       [1]byte
+	Mbr_partitions     [4]Partition
+}
+
+// Extensión del MBR (12 bytes) a continuación de la tabla de particiones. Solo es válida si
+// Mbr_ext_magic es MBRExtMagic o MBRMetaMagic; en los discos antiguos estos bytes pertenecen a la
+// primera partición o al espacio libre.
+type mbrDiskExt struct {
+	Mbr_ext_magic     int32
+	Mbr_creation_date int64
+}
+
+// Con MBRMetaMagic se agregan después de la extensión la revisión y las máscaras de características (16 bytes).
+type mbrDiskMeta struct {
+	Mbr_revision          int32
+	Mbr_feature_compat    int32
+	Mbr_feature_ro_compat int32
+	Mbr_feature_incompat  int32
+}
+
+const (
+	MBRExtMagic  = int32(0x3152424D) // "MBR1": fecha de creación int64
+	MBRMetaMagic = int32(0x3252424D) // "MBR2": fecha de creación int64, revisión y características
+
+	// Revisiones del MBR: 0 original, 1 con la fecha int64, 2 con revisión y características.
+	// Todavía no hay características del MBR; las máscaras quedan para versiones futuras.
+	MBRRevision = int32(2)
+)
+
+// HasExtension indica si el MBR incluye la extensión con la fecha de creación exacta.
+func (mbr *MBR) HasExtension() bool {
+	return mbr.Mbr_ext_magic == MBRExtMagic || mbr.HasMetadata()
+}
+
+// HasMetadata indica si el MBR guarda la revisión y las características del disco.
+func (mbr *MBR) HasMetadata() bool {
+	return mbr.Mbr_ext_magic == MBRMetaMagic
+}
+
+// Revision devuelve la revisión del formato del MBR.
+func (mbr *MBR) Revision() int32 {
+	if mbr.HasMetadata() {
+		return mbr.Mbr_revision
+	}
+	if mbr.HasExtension() {
+		return 1
+	}
+	return 0
+}
+
+// DiskSize devuelve los bytes que ocupa el MBR en disco (153 en los discos antiguos, 165 con la
+// extensión y 181 con los metadatos).
+func (mbr *MBR) DiskSize() int32 {
+	size := binary.Size(mbrDisk{})
+	if mbr.HasExtension() {
+		size += binary.Size(mbrDiskExt{})
+	}
+	if mbr.HasMetadata() {
+		size += binary.Size(mbrDiskMeta{})
+	}
+	return int32(size)
+}
+
+// MBRPartitionEntryOffset devuelve el byte del disco donde empieza la entrada i de la tabla de
+// particiones: antes van los campos del MBR original (tamaño, fecha, firma y ajuste).
+func MBRPartitionEntryOffset(i int) int64 {
+	header := binary.Size(mbrDisk{}) - binary.Size(mbrDisk{}.Mbr_partitions)
+	return int64(header + i*binary.Size(Partition{}))
+}
+
+// CheckFeatures devuelve ErrUnsupportedFeatures si el disco usa una revisión del MBR o
+// características incompat que este código no conoce.
+func (mbr *MBR) CheckFeatures() error {
+	if !mbr.HasMetadata() {
+		return nil
+	}
+	if mbr.Mbr_revision > MBRRevision {
+		return fmt.Errorf("%w: revisión %d del MBR (se soporta hasta la %d)", ErrUnsupportedFeatures, mbr.Mbr_revision, MBRRevision)
+	}
+	if mbr.Mbr_feature_incompat != 0 {
+		return fmt.Errorf("%w: características incompat desconocidas en el MBR 0x%X", ErrUnsupportedFeatures, mbr.Mbr_feature_incompat)
+	}
+	return nil
+}
+
+// CheckWritable devuelve ErrUnsupportedFeatures si el MBR tiene características ro_compat desconocidas.
+func (mbr *MBR) CheckWritable() error {
+	if mbr.HasMetadata() && mbr.Mbr_feature_ro_compat != 0 {
+		return fmt.Errorf("%w: características ro_compat desconocidas en el MBR 0x%X, la tabla de particiones es de solo lectura", ErrUnsupportedFeatures, mbr.Mbr_feature_ro_compat)
+	}
+	return nil
 }
 
 // SerializeMBR escribe la estructura MBR al inicio de un archivo binario
 func (mbr *MBR) Serialize(path string) error {
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	if err := mbr.CheckWritable(); err != nil {
+		return err
+	}
+	file, err := OpenDisk(path)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	// Serializar la estructura MBR directamente en el archivo
-	err = binary.Write(file, binary.LittleEndian, mbr)
+	// Serializar la estructura MBR; la extensión solo se escribe si el disco la tiene
+	buffer := new(bytes.Buffer)
+	err = binary.Write(buffer, binary.LittleEndian, mbrDisk{
+		Mbr_size: mbr.Mbr_size, Mbr_creation_date: float32(mbr.Mbr_creation_date),
+		Mbr_disk_signature: mbr.Mbr_disk_signature, Mbr_disk_fit: mbr.Mbr_disk_fit, Mbr_partitions: mbr.Mbr_partitions,
+	})
+	if err != nil {
+		return err
+	}
+	if mbr.HasExtension() {
+		err = binary.Write(buffer, binary.LittleEndian, mbrDiskExt{Mbr_ext_magic: mbr.Mbr_ext_magic, Mbr_creation_date: mbr.Mbr_creation_date})
+		if err != nil {
+			return err
+		}
+	}
+	if mbr.HasMetadata() {
+		err = binary.Write(buffer, binary.LittleEndian, mbrDiskMeta{
+			Mbr_revision: mbr.Mbr_revision, Mbr_feature_compat: mbr.Mbr_feature_compat,
+			Mbr_feature_ro_compat: mbr.Mbr_feature_ro_compat, Mbr_feature_incompat: mbr.Mbr_feature_incompat,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	_, err = file.Write(buffer.Bytes())
 	if err != nil {
 		return err
 	}
@@ -39,20 +170,14 @@ func (mbr *MBR) Serialize(path string) error {
 
 // DeserializeMBR lee la estructura MBR desde el inicio de un archivo binario
 func (mbr *MBR) Deserialize(path string) error {
-	file, err := os.Open(path)
+	file, err := OpenDisk(path)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	// Obtener el tamaño de la estructura MBR
-	mbrSize := binary.Size(mbr)
-	if mbrSize <= 0 {
-		return fmt.Errorf("invalid MBR size: %d", mbrSize)
-	}
-
-	// Leer solo la cantidad de bytes que corresponden al tamaño de la estructura MBR
-	buffer := make([]byte, mbrSize)
+	// Leer el MBR con la extensión; si no tiene la firma, los bytes extra no son parte del MBR
+	buffer := make([]byte, binary.Size(mbrDisk{})+binary.Size(mbrDiskExt{})+binary.Size(mbrDiskMeta{}))
 	_, err = file.Read(buffer)
 	if err != nil {
 		return err
@@ -60,12 +185,31 @@ func (mbr *MBR) Deserialize(path string) error {
 
 	// Deserializar los bytes leídos en la estructura MBR
 	reader := bytes.NewReader(buffer)
-	err = binary.Read(reader, binary.LittleEndian, mbr)
+	var disk mbrDisk
+	err = binary.Read(reader, binary.LittleEndian, &disk)
 	if err != nil {
 		return err
 	}
+	*mbr = MBR{
+		Mbr_size: disk.Mbr_size, Mbr_creation_date: int64(disk.Mbr_creation_date),
+		Mbr_disk_signature: disk.Mbr_disk_signature, Mbr_disk_fit: disk.Mbr_disk_fit, Mbr_partitions: disk.Mbr_partitions,
+	}
+	var ext mbrDiskExt
+	if binary.Read(reader, binary.LittleEndian, &ext) == nil && (ext.Mbr_ext_magic == MBRExtMagic || ext.Mbr_ext_magic == MBRMetaMagic) {
+		mbr.Mbr_ext_magic = ext.Mbr_ext_magic
+		mbr.Mbr_creation_date = ext.Mbr_creation_date
+	}
+	if mbr.HasMetadata() {
+		var meta mbrDiskMeta
+		if err := binary.Read(reader, binary.LittleEndian, &meta); err != nil {
+			return err
+		}
+		mbr.Mbr_revision, mbr.Mbr_feature_compat = meta.Mbr_revision, meta.Mbr_feature_compat
+		mbr.Mbr_feature_ro_compat, mbr.Mbr_feature_incompat = meta.Mbr_feature_ro_compat, meta.Mbr_feature_incompat
+	}
 
-	return nil
+	// Un disco con un formato más nuevo se rechaza aquí, así ningún comando lo interpreta
+	return mbr.CheckFeatures()
 }
 
 // Método para obtener una lista de los nombres de las particiones
@@ -116,7 +260,7 @@ func (mbr *MBR) GetPartitionByID(id string) (*Partition, error) {
 // Método para imprimir los valores del MBR
 func (mbr *MBR) PrintMBR() {
 	// Convertir Mbr_creation_date a time.Time
-	creationTime := time.Unix(int64(mbr.Mbr_creation_date), 0)
+	creationTime := time.Unix(mbr.Mbr_creation_date, 0)
 
 	// Convertir Mbr_disk_fit a char
 	diskFit := rune(mbr.Mbr_disk_fit[0])
@@ -125,6 +269,10 @@ func (mbr *MBR) PrintMBR() {
 	fmt.Printf("Creation Date: %s\n", creationTime.Format(time.RFC3339))
 	fmt.Printf("Disk Signature: %d\n", mbr.Mbr_disk_signature)
 	fmt.Printf("Disk Fit: %c\n", diskFit)
+	fmt.Printf("Revision: %d\n", mbr.Revision())
+	if mbr.HasMetadata() {
+		fmt.Printf("Features: compat 0x%X, ro_compat 0x%X, incompat 0x%X\n", mbr.Mbr_feature_compat, mbr.Mbr_feature_ro_compat, mbr.Mbr_feature_incompat)
+	}
 }
 
 // Método para imprimir las particiones del MBR
@@ -193,8 +341,8 @@ func (mbr *MBR) GetFirstAvailablePartition(requestedSize int32, fit byte) (*Part
 
 	// Calcular Huecos
 	gaps := []Gap{}
-	mbrStructSize := int32(binary.Size(MBR{})) // Tamaño de la estructura MBR en sí
-	lastEndOffset := mbrStructSize             // Empezar a buscar espacio DESPUÉS del MBR
+	mbrStructSize := mbr.DiskSize() // Tamaño del MBR en disco
+	lastEndOffset := mbrStructSize  // Empezar a buscar espacio DESPUÉS del MBR
 
 	for _, part := range existingPartitions {
 		// Validar consistencia básica
@@ -292,7 +440,7 @@ func (mbr *MBR) IsPartitionNameTaken(name string, diskPath string) bool {
 	// Verificar Lógicas (si hay extendida)
 	extendedPartition, _ := mbr.GetExtendedPartition()
 	if extendedPartition != nil {
-		file, err := os.Open(diskPath)
+		file, err := OpenDisk(diskPath)
 		if err != nil {
 			fmt.Printf("Advertencia: No se pudo abrir disco para verificar nombres lógicos: %v\n", err)
 			return false // No podemos confirmar, asumir que no está tomado
@@ -0,0 +1,108 @@
+package structures
+
+import (
+	"errors"
+	"testing"
+)
+
+func newTestDisk(t *testing.T, size int64) string {
+	t.Helper()
+	path := "/mem/" + t.Name() + ".mia"
+	RegisterDevice(path, NewMemDevice(size))
+	t.Cleanup(func() { UnregisterDevice(path) })
+	return path
+}
+
+func testMBR(magic int32) *MBR {
+	mbr := &MBR{Mbr_size: 4096, Mbr_creation_date: testTime, Mbr_disk_signature: 77, Mbr_disk_fit: [1]byte{'F'}, Mbr_ext_magic: magic}
+	for i := range mbr.Mbr_partitions {
+		mbr.Mbr_partitions[i] = Partition{Part_status: [1]byte{'N'}, Part_start: -1, Part_correlative: -1}
+	}
+	mbr.Mbr_partitions[0] = Partition{Part_status: [1]byte{'0'}, Part_type: [1]byte{'P'}, Part_fit: [1]byte{'W'}, Part_start: 200, Part_size: 1000, Part_correlative: 1}
+	copy(mbr.Mbr_partitions[0].Part_name[:], "P1")
+	if magic == MBRMetaMagic {
+		mbr.Mbr_revision = MBRRevision
+	}
+	return mbr
+}
+
+func TestMBRRoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		magic    int32
+		size     int32
+		revision int32
+		wantDate int64
+	}{
+		{"original", 0, 153, 0, testTimeRounded},
+		{"fecha int64", MBRExtMagic, 165, 1, testTime},
+		{"metadatos", MBRMetaMagic, 181, 2, testTime},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := newTestDisk(t, 4096)
+			mbr := testMBR(tt.magic)
+			if mbr.DiskSize() != tt.size || mbr.Revision() != tt.revision {
+				t.Fatalf("DiskSize = %d, Revision = %d, se esperaba %d y %d", mbr.DiskSize(), mbr.Revision(), tt.size, tt.revision)
+			}
+			// Bytes que no son parte del MBR original: no deben tomarse como extensión
+			file, err := OpenDisk(path)
+			if err != nil {
+				t.Fatalf("OpenDisk: %v", err)
+			}
+			defer file.Close()
+			if _, err := file.WriteAt([]byte{0x4D, 0x42, 0x52, 0x31}, 153+1); err != nil {
+				t.Fatalf("WriteAt: %v", err)
+			}
+
+			if err := mbr.Serialize(path); err != nil {
+				t.Fatalf("Serialize: %v", err)
+			}
+			read := &MBR{}
+			if err := read.Deserialize(path); err != nil {
+				t.Fatalf("Deserialize: %v", err)
+			}
+			want := *mbr
+			want.Mbr_creation_date = tt.wantDate
+			if *read != want {
+				t.Errorf("Deserialize = %+v\nse esperaba %+v", *read, want)
+			}
+		})
+	}
+}
+
+func TestMBRUnsupported(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		edit func(*MBR)
+	}{
+		{"revisión nueva", func(mbr *MBR) { mbr.Mbr_revision = MBRRevision + 1 }},
+		{"incompat desconocida", func(mbr *MBR) { mbr.Mbr_feature_incompat = 1 }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := newTestDisk(t, 4096)
+			mbr := testMBR(MBRMetaMagic)
+			tt.edit(mbr)
+			if err := mbr.Serialize(path); err != nil {
+				t.Fatalf("Serialize: %v", err)
+			}
+			if err := (&MBR{}).Deserialize(path); !errors.Is(err, ErrUnsupportedFeatures) {
+				t.Errorf("Deserialize = %v, se esperaba ErrUnsupportedFeatures", err)
+			}
+		})
+	}
+
+	mbr := testMBR(MBRMetaMagic)
+	mbr.Mbr_feature_ro_compat = 1
+	if err := mbr.Serialize(newTestDisk(t, 4096)); !errors.Is(err, ErrUnsupportedFeatures) {
+		t.Errorf("Serialize con ro_compat desconocida = %v, se esperaba ErrUnsupportedFeatures", err)
+	}
+}
+
+func TestMBRPartitionEntryOffset(t *testing.T) {
+	// Tamaño (4), fecha float32 (4), firma (4) y ajuste (1); cada entrada ocupa 35 bytes
+	for i, want := range []int64{13, 48, 83, 118} {
+		if got := MBRPartitionEntryOffset(i); got != want {
+			t.Errorf("MBRPartitionEntryOffset(%d) = %d, se esperaba %d", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package structures
+
+import "fmt"
+
+// Bits de I_special, en el mismo orden que el primer dígito de un chmod de 4 dígitos.
+const (
+	PermSetuid byte = 4 // Sin ejecución de programas, solo se guarda y se muestra
+	PermSetgid byte = 2 // En una carpeta: lo que se crea dentro toma su grupo
+	PermSticky byte = 1 // En una carpeta: solo el dueño de la entrada, el de la carpeta o root borran o renombran
+)
+
+// Mode devuelve los permisos como en chmod: "755", o "1777" si el inodo tiene bits especiales.
+func (inode *Inode) Mode() string {
+	if inode.I_special == 0 {
+		return string(inode.I_perm[:])
+	}
+	return fmt.Sprintf("%d%s", inode.I_special, string(inode.I_perm[:]))
+}
+
+// InheritSetgid aplica el setgid de la carpeta parent a child, recién creado: child toma el grupo
+// de parent y, si es carpeta, también el bit para que lo sigan heredando sus hijos.
+func InheritSetgid(parent *Inode, child *Inode) {
+	if parent.I_special&PermSetgid == 0 {
+		return
+	}
+	child.I_gid = parent.I_gid
+	if child.I_type[0] == '0' {
+		child.I_special |= PermSetgid
+	}
+	fmt.Printf("Carpeta padre con setgid: grupo %d heredado\n", parent.I_gid)
+}
@@ -0,0 +1,43 @@
+package structures
+
+import "testing"
+
+func TestInodeMode(t *testing.T) {
+	for _, tt := range []struct {
+		special byte
+		want    string
+	}{
+		{0, "755"},
+		{PermSticky, "1755"},
+		{PermSetgid, "2755"},
+		{PermSetuid | PermSetgid | PermSticky, "7755"},
+	} {
+		inode := &Inode{I_perm: [3]byte{'7', '5', '5'}, I_special: tt.special}
+		if got := inode.Mode(); got != tt.want {
+			t.Errorf("Mode con I_special %d = %s, se esperaba %s", tt.special, got, tt.want)
+		}
+	}
+}
+
+func TestInheritSetgid(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		parentBits  byte
+		kind        byte
+		wantGid     int32
+		wantSpecial byte
+	}{
+		{"carpeta sin setgid", PermSticky, '0', 9, 0},
+		{"archivo en carpeta con setgid", PermSetgid, '1', 4, 0},
+		{"carpeta en carpeta con setgid", PermSetgid | PermSticky, '0', 4, PermSetgid},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := &Inode{I_gid: 4, I_type: [1]byte{'0'}, I_special: tt.parentBits}
+			child := &Inode{I_gid: 9, I_type: [1]byte{tt.kind}}
+			InheritSetgid(parent, child)
+			if child.I_gid != tt.wantGid || child.I_special != tt.wantSpecial {
+				t.Errorf("hijo: I_gid %d, I_special %d; se esperaba %d y %d", child.I_gid, child.I_special, tt.wantGid, tt.wantSpecial)
+			}
+		})
+	}
+}
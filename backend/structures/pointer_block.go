@@ -4,18 +4,26 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
-	"os"
 )
 
 type PointerBlock struct {
-	P_pointers [16]int32 // 16 * 4 = 64 bytes
-	// Total: 64 bytes
+	P_pointers []int32 // S_block_size / 4 punteros (16 con bloques de 64 bytes)
+}
+
+// NewPointerBlock crea un bloque de punteros con los punteros que caben en blockSize bytes.
+func NewPointerBlock(blockSize int32) *PointerBlock {
+	return &PointerBlock{P_pointers: make([]int32, blockSize/4)}
+}
+
+// PointersPerBlock devuelve cuántos punteros caben en un bloque de blockSize bytes.
+func PointersPerBlock(blockSize int32) int32 {
+	return blockSize / 4
 }
 
 
 
 func (pb *PointerBlock) Deserialize(path string, offset int64) error {
-	file, err := os.Open(path)
+	file, err := OpenDisk(path)
 	if err != nil {
 		return err
 	}
@@ -27,10 +35,10 @@ func (pb *PointerBlock) Deserialize(path string, offset int64) error {
 		return err
 	}
 
-	// Obtener el tamaño de la estructura PointerBlock
-	pbSize := binary.Size(pb)
+	// Obtener el tamaño del bloque a partir de sus punteros (NewPointerBlock con S_block_size)
+	pbSize := binary.Size(pb.P_pointers)
 	if pbSize <= 0 {
-		return fmt.Errorf("invalid PointerBlock size: %d", pbSize)
+		return fmt.Errorf("PointerBlock sin tamaño: use NewPointerBlock con el S_block_size del superbloque")
 	}
 	
 	// Leer solo la cantidad de bytes que corresponden al tamaño de la estructura PointerBlock
@@ -40,9 +48,9 @@ func (pb *PointerBlock) Deserialize(path string, offset int64) error {
 		return err
 	}
 
-	// Deserializar los bytes leídos en la estructura PointerBlock
+	// Deserializar los bytes leídos en los punteros del PointerBlock
 	reader := bytes.NewReader(buffer)
-	err = binary.Read(reader, binary.LittleEndian, pb)
+	err = binary.Read(reader, binary.LittleEndian, pb.P_pointers)
 	if err != nil {
 		return err
 	}
@@ -52,7 +60,7 @@ func (pb *PointerBlock) Deserialize(path string, offset int64) error {
 
 // Serialize escribe la estructura FileBlock en un archivo binario en la posición especificada
 func (pb *PointerBlock) Serialize(path string, offset int64) error {
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	file, err := OpenDisk(path)
 	if err != nil {
 		return err
 	}
@@ -64,8 +72,8 @@ func (pb *PointerBlock) Serialize(path string, offset int64) error {
 		return err
 	}
 
-	// Serializar la estructura FileBlock directamente en el archivo
-	err = binary.Write(file, binary.LittleEndian, pb)
+	// Serializar los punteros directamente en el archivo
+	err = binary.Write(file, binary.LittleEndian, pb.P_pointers)
 	if err != nil {
 		return err
 	}
@@ -5,12 +5,45 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 )
 
 type SuperBlock struct {
+	S_filesystem_type   int32
+	S_inodes_count      int32
+	S_blocks_count      int32
+	S_free_inodes_count int32
+	S_free_blocks_count int32
+	S_mtime             int64 // Segundos Unix; en disco float32, o int64 con FeatureIncompatWideTimes
+	S_umtime            int64
+	S_mnt_count         int32
+	S_magic             int32
+	S_inode_size        int32
+	S_block_size        int32
+	S_first_ino         int32
+	S_first_blo         int32
+	S_bm_inode_start    int32
+	S_bm_block_start    int32
+	S_inode_start       int32
+	S_block_start       int32
+	// Total: 68 bytes (formato original)
+
+	// Extensión del formato: solo es válida si S_ext_magic == SuperBlockExtMagic. En las
+	// imágenes antiguas estos bytes pertenecen al bitmap de inodos.
+	S_ext_magic         int32
+	S_feature_incompat  int32    // Características que un lector antiguo no sabe interpretar
+	S_inode_ratio       int32    // Bytes de partición por inodo (mkfs -inoderatio); 0 = 3 bloques por inodo
+	S_rev_level         int32    // Revisión del formato (ver SuperBlockRevision); 0 en las imágenes de revisión 1
+	S_feature_compat    int32    // Características que un lector antiguo puede ignorar
+	S_feature_ro_compat int32    // Características que un lector antiguo solo puede leer
+	S_reserved          [2]int32 // Reservado para futuras extensiones del formato
+	// Total en disco: 100 bytes (116 con FeatureIncompatWideTimes, ver superBlockDisk)
+}
+
+// Formato en disco del superbloque: los 68 bytes originales (marcas de tiempo float32) y la
+// extensión de características.
+type superBlockDisk struct {
 	S_filesystem_type   int32
 	S_inodes_count      int32
 	S_blocks_count      int32
@@ -28,11 +61,148 @@ type SuperBlock struct {
 	S_bm_block_start    int32
 	S_inode_start       int32
 	S_block_start       int32
-	// Total: 68 bytes
+	S_ext_magic         int32
+	S_feature_incompat  int32
+	S_inode_ratio       int32
+	S_rev_level         int32
+	S_feature_compat    int32
+	S_feature_ro_compat int32
+	S_reserved          [2]int32
+}
+
+// Con FeatureIncompatWideTimes se agregan las marcas de tiempo exactas después de la extensión.
+type superBlockDiskTimes struct {
+	S_mtime  int64
+	S_umtime int64
+}
+
+const (
+	superBlockLegacySize = 68
+	SuperBlockExtMagic   = int32(0x3141494D) // "MIA1"
+
+	FeatureIncompatPackedBitmaps = int32(0x0001) // Bitmaps de 1 bit por inodo/bloque en lugar de '0'/'1'
+	FeatureIncompatLongNames     = int32(0x0002) // Entradas de directorio de longitud variable (nombres de hasta 255 bytes)
+	FeatureIncompatLargeFiles    = int32(0x0004) // I_size de 64 bits en el inodo (inodos de 92 bytes)
+	FeatureIncompatWideTimes     = int32(0x0008) // Marcas de tiempo int64 en inodos, superbloque y journal
+	FeatureIncompatLinkCounts    = int32(0x0010) // I_links en el inodo: contador de enlaces duros
+	FeatureIncompatXattrs        = int32(0x0020) // I_xattr en el inodo: bloque de atributos extendidos
+	FeatureIncompatSpecialPerms  = int32(0x0040) // I_special en el inodo: bits setuid, setgid y sticky
+
+	DefaultBlockSize = int32(64) // Tamaño de bloque del formato original
+)
+
+// Tamaños de bloque que admite mkfs -bs
+var BlockSizes = []int32{64, 512, 1024, 4096}
+
+// ValidBlockSize indica si size es uno de los tamaños de bloque admitidos.
+func ValidBlockSize(size int32) bool {
+	for _, valid := range BlockSizes {
+		if size == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// HasExtension indica si el superbloque incluye la extensión de características.
+func (sb *SuperBlock) HasExtension() bool {
+	return sb.S_ext_magic == SuperBlockExtMagic
+}
+
+// HasIncompat indica si el sistema de archivos usa la característica incompatible feature.
+func (sb *SuperBlock) HasIncompat(feature int32) bool {
+	return sb.HasExtension() && sb.S_feature_incompat&feature != 0
+}
+
+// DiskSize devuelve los bytes que ocupa el superbloque en disco (68 en las imágenes antiguas).
+func (sb *SuperBlock) DiskSize() int32 {
+	if !sb.HasExtension() {
+		return superBlockLegacySize
+	}
+	return SuperBlockDiskSize(sb.S_feature_incompat)
+}
+
+// SuperBlockDiskSize devuelve los bytes del superbloque con extensión y las características features.
+func SuperBlockDiskSize(features int32) int32 {
+	size := binary.Size(superBlockDisk{})
+	if features&FeatureIncompatWideTimes != 0 {
+		size += binary.Size(superBlockDiskTimes{})
+	}
+	return int32(size)
+}
+
+// WideTimes indica si las marcas de tiempo se guardan como int64 (FeatureIncompatWideTimes).
+func (sb *SuperBlock) WideTimes() bool {
+	return sb.HasIncompat(FeatureIncompatWideTimes)
+}
+
+func (sb *SuperBlock) encode() ([]byte, error) {
+	disk := superBlockDisk{
+		S_filesystem_type: sb.S_filesystem_type, S_inodes_count: sb.S_inodes_count, S_blocks_count: sb.S_blocks_count,
+		S_free_inodes_count: sb.S_free_inodes_count, S_free_blocks_count: sb.S_free_blocks_count,
+		S_mtime: float32(sb.S_mtime), S_umtime: float32(sb.S_umtime), S_mnt_count: sb.S_mnt_count,
+		S_magic: sb.S_magic, S_inode_size: sb.S_inode_size, S_block_size: sb.S_block_size,
+		S_first_ino: sb.S_first_ino, S_first_blo: sb.S_first_blo,
+		S_bm_inode_start: sb.S_bm_inode_start, S_bm_block_start: sb.S_bm_block_start,
+		S_inode_start: sb.S_inode_start, S_block_start: sb.S_block_start,
+		S_ext_magic: sb.S_ext_magic, S_feature_incompat: sb.S_feature_incompat,
+		S_inode_ratio: sb.S_inode_ratio, S_rev_level: sb.S_rev_level,
+		S_feature_compat: sb.S_feature_compat, S_feature_ro_compat: sb.S_feature_ro_compat, S_reserved: sb.S_reserved,
+	}
+	buffer := new(bytes.Buffer)
+	if err := binary.Write(buffer, binary.LittleEndian, disk); err != nil {
+		return nil, err
+	}
+	if sb.WideTimes() {
+		if err := binary.Write(buffer, binary.LittleEndian, superBlockDiskTimes{S_mtime: sb.S_mtime, S_umtime: sb.S_umtime}); err != nil {
+			return nil, err
+		}
+	}
+	return buffer.Bytes()[:sb.DiskSize()], nil
+}
+
+func (sb *SuperBlock) decode(data []byte) error {
+	reader := bytes.NewReader(data)
+	var disk superBlockDisk
+	if err := binary.Read(reader, binary.LittleEndian, &disk); err != nil {
+		return err
+	}
+	*sb = SuperBlock{
+		S_filesystem_type: disk.S_filesystem_type, S_inodes_count: disk.S_inodes_count, S_blocks_count: disk.S_blocks_count,
+		S_free_inodes_count: disk.S_free_inodes_count, S_free_blocks_count: disk.S_free_blocks_count,
+		S_mtime: int64(disk.S_mtime), S_umtime: int64(disk.S_umtime), S_mnt_count: disk.S_mnt_count,
+		S_magic: disk.S_magic, S_inode_size: disk.S_inode_size, S_block_size: disk.S_block_size,
+		S_first_ino: disk.S_first_ino, S_first_blo: disk.S_first_blo,
+		S_bm_inode_start: disk.S_bm_inode_start, S_bm_block_start: disk.S_bm_block_start,
+		S_inode_start: disk.S_inode_start, S_block_start: disk.S_block_start,
+		S_ext_magic: disk.S_ext_magic, S_feature_incompat: disk.S_feature_incompat,
+		S_inode_ratio: disk.S_inode_ratio, S_rev_level: disk.S_rev_level,
+		S_feature_compat: disk.S_feature_compat, S_feature_ro_compat: disk.S_feature_ro_compat, S_reserved: disk.S_reserved,
+	}
+
+	// Imagen antigua: lo leído después de los 68 bytes no es parte del superbloque
+	if !sb.HasExtension() {
+		sb.S_ext_magic, sb.S_feature_incompat, sb.S_inode_ratio = 0, 0, 0
+		sb.S_rev_level, sb.S_feature_compat, sb.S_feature_ro_compat, sb.S_reserved = 0, 0, 0, [2]int32{}
+		return nil
+	}
+	if sb.WideTimes() {
+		var times superBlockDiskTimes
+		if err := binary.Read(reader, binary.LittleEndian, &times); err != nil {
+			return err
+		}
+		sb.S_mtime, sb.S_umtime = times.S_mtime, times.S_umtime
+	}
+	return nil
 }
 
 func (sb *SuperBlock) Serialize(path string, offset int64) error {
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	// Un sistema de archivos con características ro_compat desconocidas no se modifica
+	if err := sb.CheckWritable(); err != nil {
+		return err
+	}
+
+	file, err := OpenDisk(path)
 	if err != nil {
 		return err
 	}
@@ -44,17 +214,21 @@ func (sb *SuperBlock) Serialize(path string, offset int64) error {
 		return err
 	}
 
-	// Serializar la estructura SuperBlock directamente en el archivo
-	err = binary.Write(file, binary.LittleEndian, sb)
+	// Serializar la estructura SuperBlock; sin extensión solo se escriben los 68 bytes
+	// originales para no pisar el bitmap que viene después
+	data, err := sb.encode()
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
 	if err != nil {
 		return err
 	}
-
 	return nil
 }
 
 func (sb *SuperBlock) Deserialize(path string, offset int64) error {
-	file, err := os.Open(path)
+	file, err := OpenDisk(path)
 	if err != nil {
 		return err
 	}
@@ -66,32 +240,28 @@ func (sb *SuperBlock) Deserialize(path string, offset int64) error {
 		return err
 	}
 
-	// Obtener el tamaño de la estructura SuperBlock
-	sbSize := binary.Size(sb)
-	if sbSize <= 0 {
-		return fmt.Errorf("invalid SuperBlock size: %d", sbSize)
-	}
-
-	// Leer solo la cantidad de bytes que corresponden al tamaño de la estructura SuperBlock
-	buffer := make([]byte, sbSize)
+	// Leer el tamaño máximo del superbloque; el formato real se decide por la extensión
+	buffer := make([]byte, SuperBlockDiskSize(FeatureIncompatWideTimes))
 	_, err = file.Read(buffer)
 	if err != nil {
 		return err
 	}
 
 	// Deserializar los bytes leídos en la estructura SuperBlock
-	reader := bytes.NewReader(buffer)
-	err = binary.Read(reader, binary.LittleEndian, sb)
+	err = sb.decode(buffer)
 	if err != nil {
 		return err
 	}
-
+	// Un formato más nuevo se rechaza aquí, así ningún comando llega a interpretarlo
+	if err := sb.CheckFeatures(); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (sb *SuperBlock) Print() {
-	mountTime := time.Unix(int64(sb.S_mtime), 0)
-	unmountTime := time.Unix(int64(sb.S_umtime), 0)
+	mountTime := time.Unix(sb.S_mtime, 0)
+	unmountTime := time.Unix(sb.S_umtime, 0)
 
 	fmt.Printf("Filesystem Type: %d\n", sb.S_filesystem_type)
 	fmt.Printf("Inodes Count: %d\n", sb.S_inodes_count)
@@ -110,13 +280,23 @@ func (sb *SuperBlock) Print() {
 	fmt.Printf("Bitmap Block Start: %d\n", sb.S_bm_block_start)
 	fmt.Printf("Inode Start: %d\n", sb.S_inode_start)
 	fmt.Printf("Block Start: %d\n", sb.S_block_start)
+	if sb.HasExtension() {
+		fmt.Printf("Revision: %d\n", sb.Revision())
+		fmt.Printf("Compat Features: 0x%X\n", sb.S_feature_compat)
+		fmt.Printf("Ro Compat Features: 0x%X\n", sb.S_feature_ro_compat)
+		fmt.Printf("Incompat Features: 0x%X\n", sb.S_feature_incompat)
+		fmt.Printf("Inode Ratio: %d\n", sb.S_inode_ratio)
+		fmt.Printf("Max Name Length: %d\n", sb.MaxNameLen())
+		fmt.Printf("Max File Size: %d\n", sb.MaxFileSize())
+		fmt.Printf("Wide Timestamps: %t\n", sb.WideTimes())
+	}
 }
 
 func (sb *SuperBlock) PrintInodes(path string) error {
 	fmt.Println("\nInodos\n----------------")
 	for i := int32(0); i < sb.S_inodes_count; i++ {
 		inode := &Inode{}
-		err := inode.Deserialize(path, int64(sb.S_inode_start+(i*sb.S_inode_size)))
+		err := inode.Deserialize(sb, path, int64(sb.S_inode_start+(i*sb.S_inode_size)))
 		if err != nil {
 			return err
 		}
@@ -134,14 +314,14 @@ func (sb *SuperBlock) PrintBlocks(path string) error {
 	for i := int32(0); i < sb.S_inodes_count; i++ {
 		inode := &Inode{}
 		offset := int64(sb.S_inode_start + (i * sb.S_inode_size))
-		err := inode.Deserialize(path, offset)
+		err := inode.Deserialize(sb, path, offset)
 		if err != nil {
 			continue 
 		}
 
 		isUsed, _ := sb.IsInodeUsed(path, i)
-		if !isUsed {
-			continue 
+		if !isUsed || inode.InlineSymlink() {
+			continue // Libre, o enlace rápido sin bloques
 		}
 
 		var blocksToProcess []int32
@@ -169,16 +349,15 @@ func (sb *SuperBlock) PrintBlocks(path string) error {
 			fmt.Printf("\nBloque %d (Offset: %d, Status: %s):\n", blockIndex, blockOffset, status)
 
 			if inode.I_type[0] == '0' { // Bloque de Carpeta
-				block := &FolderBlock{}
-				err := block.Deserialize(path, blockOffset)
+				block, err := sb.ReadDirBlock(path, blockIndex)
 				if err != nil {
 					fmt.Printf("  Error al leer como FolderBlock: %v\n", err)
 				} else {
 					fmt.Println("  Tipo: FolderBlock")
 					block.Print() 
 				}
-			} else if inode.I_type[0] == '1' { // Bloque de Archivo
-				block := &FileBlock{}
+			} else if inode.I_type[0] == '1' || inode.I_type[0] == '2' { // Bloque de Archivo (o destino de un enlace)
+				block := NewFileBlock(sb.S_block_size)
 				err := block.Deserialize(path, blockOffset)
 				if err != nil {
 					fmt.Printf("  Error al leer como FileBlock: %v\n", err)
@@ -194,7 +373,9 @@ func (sb *SuperBlock) PrintBlocks(path string) error {
 	return nil
 }
 
-func (sb *SuperBlock) CreateFolder(diskPath string, parentsDir []string, destDir string) error {
+// CreateFolder crea la carpeta destDir dentro de parentsDir con dueño uid y grupo gid (el grupo
+// puede cambiar por el setgid del padre).
+func (sb *SuperBlock) CreateFolder(diskPath string, parentsDir []string, destDir string, uid int32, gid int32) error {
 	fmt.Printf(">> CreateFolder: diskPath='%s', parentsDir=%v, destDir='%s'\n", diskPath, parentsDir, destDir)
 
 	// Encontrar el inodo del directorio padre
@@ -215,134 +396,20 @@ func (sb *SuperBlock) CreateFolder(diskPath string, parentsDir []string, destDir
 	}
 
 	fmt.Printf("   Inodo padre encontrado: %d\n", parentInodeNum)
-	parentInodeOffset := int64(sb.S_inode_start + parentInodeNum*sb.S_inode_size)
-
-	//Verificar si destDir ya existe en el padre
-	entryExists := false
-	for blockPtrIndex := 0; blockPtrIndex < 12; blockPtrIndex++ { 
-		blockPtr := parentInode.I_block[blockPtrIndex]
-		if blockPtr == -1 {
-			continue
-		}
-		folderBlock := &FolderBlock{}
-		blockOffset := int64(sb.S_block_start + blockPtr*sb.S_block_size)
-		if err := folderBlock.Deserialize(diskPath, blockOffset); err != nil {
-			fmt.Printf("   Advertencia: Error leyendo bloque %d del padre: %v\n", blockPtr, err)
-			continue
-		}
-		for _, entry := range folderBlock.B_content {
-			if entry.B_inodo != -1 {
-				name := strings.TrimRight(string(entry.B_name[:]), "\x00")
-				if name == destDir {
-					entryExists = true
-					break
-				}
-			}
-		}
-		if entryExists {
-			break
-		}
+
+	if err := sb.ValidateName(destDir); err != nil {
+		return err
 	}
-	if entryExists {
+
+	// Verificar si destDir ya existe en el padre (bloques directos e indirectos)
+	if _, exists, err := sb.LookupDir(diskPath, parentInode, destDir); err != nil {
+		return fmt.Errorf("error al leer el directorio padre '%s': %w", parentPath, err)
+	} else if exists {
 		// Si se usa -p, esto no es un error, simplemente ya existe.
 		fmt.Printf("   Directorio '%s' ya existe en '%s'.\n", destDir, parentPath)
 		return nil
 	}
 
-	// Buscar un slot libre en los bloques existentes del padre
-	foundSlot := false
-	var targetBlockPtr int32 = -1
-	var targetEntryIndex int = -1
-
-	fmt.Printf("   Buscando slot libre en bloques existentes del inodo padre %d...\n", parentInodeNum)
-	for blockPtrIndex := 0; blockPtrIndex < 12; blockPtrIndex++ {
-		blockPtr := parentInode.I_block[blockPtrIndex]
-		if blockPtr == -1 {
-			continue // Este puntero directo no está usado
-		}
-		fmt.Printf("      Examinando bloque directo %d (puntero %d)\n", blockPtrIndex, blockPtr)
-		folderBlock := &FolderBlock{}
-		blockOffset := int64(sb.S_block_start + blockPtr*sb.S_block_size)
-		if err := folderBlock.Deserialize(diskPath, blockOffset); err != nil {
-			fmt.Printf("      Advertencia: Error leyendo bloque %d: %v. Saltando.\n", blockPtr, err)
-			continue
-		}
-		for entryIndex, entry := range folderBlock.B_content {
-			if entry.B_inodo == -1 { 
-				fmt.Printf("      Slot libre encontrado, Bloque %d, Índice %d\n", blockPtr, entryIndex)
-				foundSlot = true
-				targetBlockPtr = blockPtr
-				targetEntryIndex = entryIndex
-				break
-			}
-		}
-		if foundSlot {
-			break
-		}
-	}
-
-	// Si NO se encontró slot libre, asignar nuevo bloque al padre
-	var parentModifiedBlock *FolderBlock // Guardará el bloque modificado
-	var parentModifiedBlockOffset int64  // Offset del bloque modificado
-
-	if !foundSlot {
-		fmt.Printf("   No se encontró slot libre. Intentando asignar nuevo bloque al inodo padre %d...\n", parentInodeNum)
-		freePtrIndex := -1
-		for i := 0; i < 12; i++ {
-			if parentInode.I_block[i] == -1 {
-				freePtrIndex = i
-				break
-			}
-		}
-		if freePtrIndex == -1 {
-			return errors.New("no hay punteros directos libres en el inodo padre")
-		}
-		fmt.Printf("      Puntero directo libre encontrado en I_block[%d]\n", freePtrIndex)
-
-		// Asignar nuevo bloque del bitmap
-		newParentBlockIndex, err := sb.FindFreeBlock(diskPath)
-		if err != nil {
-			return fmt.Errorf("no se pudo asignar un nuevo bloque para el directorio padre: %w", err)
-		}
-		fmt.Printf("      Asignando bloque libre %d del bitmap\n", newParentBlockIndex)
-
-		// Actualizar bitmap de bloques
-		if err := sb.UpdateBitmapBlock(diskPath, newParentBlockIndex,'1'); err != nil {
-			return fmt.Errorf("error al actualizar bitmap de bloques para el nuevo bloque %d: %w", newParentBlockIndex, err)
-		}
-		sb.S_free_blocks_count-- // Decrementar contador global
-
-		// Actualizar puntero en inodo padre
-		parentInode.I_block[freePtrIndex] = newParentBlockIndex
-		parentInode.I_mtime = float32(time.Now().Unix()) // Actualizar tiempo de modificación
-
-		// Serializar inodo padre actualizado
-		if err := parentInode.Serialize(diskPath, parentInodeOffset); err != nil {
-			return fmt.Errorf("error al serializar inodo padre actualizado (offset %d): %w", parentInodeOffset, err)
-		}
-		fmt.Printf("      Inodo padre %d actualizado con puntero a nuevo bloque %d en índice %d\n", parentInodeNum, newParentBlockIndex, freePtrIndex)
-
-		// Inicializar el nuevo bloque como FolderBlock vacío
-		newParentBlock := &FolderBlock{}
-		newParentBlock.Initialize() 
-		parentModifiedBlock = newParentBlock
-		parentModifiedBlockOffset = int64(sb.S_block_start + newParentBlockIndex*sb.S_block_size)
-
-		// Usaremos el primer slot de este nuevo bloque
-		targetBlockPtr = newParentBlockIndex
-		targetEntryIndex = 0
-		fmt.Printf("      Nuevo bloque %d inicializado. Se usará el slot 0.\n", newParentBlockIndex)
-
-	} else {
-		// Si sí encontramos slot, necesitamos leer ese bloque para modificarlo
-		parentModifiedBlock = &FolderBlock{}
-		parentModifiedBlockOffset = int64(sb.S_block_start + targetBlockPtr*sb.S_block_size)
-		if err := parentModifiedBlock.Deserialize(diskPath, parentModifiedBlockOffset); err != nil {
-			return fmt.Errorf("error al leer bloque padre %d para modificación: %w", targetBlockPtr, err)
-		}
-		fmt.Printf("   Se usará el slot %d del bloque existente %d.\n", targetEntryIndex, targetBlockPtr)
-	}
-
 	//Asignar nuevo INODO para el directorio a crear
 	newDirInodeNum, err := sb.FindFreeInode(diskPath)
 	if err != nil {
@@ -369,58 +436,51 @@ func (sb *SuperBlock) CreateFolder(diskPath string, parentsDir []string, destDir
 
 	// Inicializar el NUEVO INODO 
 	newDirInode := &Inode{}
-	now := float32(time.Now().Unix())
-	newDirInode.I_uid = 0
-	newDirInode.I_gid = 0
+	now := time.Now().Unix()
+	newDirInode.I_uid = uid
+	newDirInode.I_gid = gid
 	newDirInode.I_size = 0 
 	newDirInode.I_atime = now
 	newDirInode.I_ctime = now
 	newDirInode.I_mtime = now
 	newDirInode.I_type[0] = '0' 
 	copy(newDirInode.I_perm[:], "777") // Permiso por defecto
+	newDirInode.I_links = 1
+	newDirInode.I_xattr = -1
 	for i := range newDirInode.I_block {
 		newDirInode.I_block[i] = -1
 	}
 	// Asignar el primer bloque directo
 	newDirInode.I_block[0] = newDirBlockIndex
+	// Grupo, permisos y ACL según el setgid y la ACL por defecto del padre
+	InheritSetgid(parentInode, newDirInode)
+	if err := sb.InheritACL(diskPath, parentInode, newDirInode); err != nil {
+		fmt.Printf("      Advertencia: no se heredó la ACL por defecto de '%s': %v\n", parentPath, err)
+	}
 
 	// Serializar el nuevo inodo
-	if err := newDirInode.Serialize(diskPath, newDirInodeOffset); err != nil {
+	if err := newDirInode.Serialize(sb, diskPath, newDirInodeOffset); err != nil {
 		return fmt.Errorf("error al serializar el nuevo inodo %d (offset %d): %w", newDirInodeNum, newDirInodeOffset, err)
 	}
 	fmt.Printf("      Nuevo inodo %d inicializado y serializado.\n", newDirInodeNum)
 
-	// Inicializar el NUEVO BLOQUE 
-	newDirFolderBlock := &FolderBlock{}
-	newDirFolderBlock.Initialize()
-	copy(newDirFolderBlock.B_content[0].B_name[:], ".")
-	newDirFolderBlock.B_content[0].B_inodo = newDirInodeNum
-	copy(newDirFolderBlock.B_content[1].B_name[:], "..")
-	newDirFolderBlock.B_content[1].B_inodo = parentInodeNum
+	// Inicializar el NUEVO BLOQUE con '.' y '..'
+	newDirBlock := sb.NewDirBlock()
+	newDirBlock.Add(DirEntry{Inode: newDirInodeNum, Type: '0', Name: "."})
+	newDirBlock.Add(DirEntry{Inode: parentInodeNum, Type: '0', Name: ".."})
 
 	// Serializar el nuevo bloque de carpeta
-	if err := newDirFolderBlock.Serialize(diskPath, newDirBlockOffset); err != nil {
+	if err := sb.WriteDirBlock(diskPath, newDirBlockIndex, newDirBlock); err != nil {
 		return fmt.Errorf("error al serializar el nuevo bloque de carpeta %d (offset %d): %w", newDirBlockIndex, newDirBlockOffset, err)
 	}
 	fmt.Printf("      Nuevo bloque %d inicializado con '.' y '..' y serializado.\n", newDirBlockIndex)
 
-	// Actualizar la entrada en el BLOQUE PADRE MODIFICADO
-	copy(parentModifiedBlock.B_content[targetEntryIndex].B_name[:], destDir)
-	parentModifiedBlock.B_content[targetEntryIndex].B_inodo = newDirInodeNum
-
-	// Serializar el bloque padre modificado
-	if err := parentModifiedBlock.Serialize(diskPath, parentModifiedBlockOffset); err != nil {
-		return fmt.Errorf("error al serializar el bloque padre modificado %d (offset %d): %w", targetBlockPtr, parentModifiedBlockOffset, err)
+	// Agregar la entrada al padre (asigna un bloque nuevo, directo o indirecto, si hace falta)
+	if err := sb.AddDirEntry(diskPath, parentInodeNum, parentInode, DirEntry{Inode: newDirInodeNum, Type: '0', Name: destDir}); err != nil {
+		return fmt.Errorf("error al agregar '%s' al directorio padre %d: %w", destDir, parentInodeNum, err)
 	}
-	fmt.Printf("      Bloque padre %d actualizado con entrada para '%s' -> %d y serializado.\n", targetBlockPtr, destDir, newDirInodeNum)
+	fmt.Printf("      Directorio padre %d actualizado con entrada para '%s' -> %d.\n", parentInodeNum, destDir, newDirInodeNum)
 
-	// Actualizar tiempo del inodo padre (ya se hizo si se añadió bloque, hacerlo si no)
-	if foundSlot { // Actualizar mtime ahora
-		parentInode.I_mtime = float32(time.Now().Unix())
-		if err := parentInode.Serialize(diskPath, parentInodeOffset); err != nil {
-			fmt.Printf("   Advertencia: error al actualizar mtime del inodo padre %d: %v\n", parentInodeNum, err)
-		}
-	}
 	fmt.Printf(">> Directorio '%s' creado exitosamente.\n", destDir)
 	return nil
 }
@@ -433,7 +493,7 @@ func (sb *SuperBlock) GetUsersBlock(path string) (*FileBlock, error) {
 	inode := &Inode{}
 
 	// Deserializar el inodo
-	err := inode.Deserialize(path, int64(sb.S_inode_start+(1*sb.S_inode_size)))
+	err := inode.Deserialize(sb, path, int64(sb.S_inode_start+(1*sb.S_inode_size)))
 	if err != nil {
 		return nil, err
 	}
@@ -446,9 +506,9 @@ func (sb *SuperBlock) GetUsersBlock(path string) (*FileBlock, error) {
 		}
 		// Si el inodo es de tipo archivo
 		if inode.I_type[0] == '1' {
-			block := &FileBlock{}
+			block := NewFileBlock(sb.S_block_size)
 			// Deserializar el bloque
-			err := block.Deserialize(path, int64(sb.S_block_start+(blockIndex*sb.S_block_size))) // 64 porque es el tamaño de un bloque
+			err := block.Deserialize(path, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
 			if err != nil {
 				return nil, err
 			}
@@ -459,86 +519,46 @@ func (sb *SuperBlock) GetUsersBlock(path string) (*FileBlock, error) {
 	return nil, fmt.Errorf("users.txt block not found")
 }
 
-// FindFreeInode busca el primer inodo libre ('0') en el bitmap de inodos.
+// FindFreeInode busca el primer inodo libre a partir de S_first_ino usando el índice de libres.
 func (sb *SuperBlock) FindFreeInode(diskPath string) (int32, error) {
-	file, err := os.Open(diskPath)
-	if err != nil {
-		return -1, fmt.Errorf("error al abrir disco para buscar inodo libre: %w", err)
-	}
-	defer file.Close()
-
 	if sb.S_inodes_count <= 0 || sb.S_inodes_count > 1000000 { // Límite arbitrario
 		return -1, fmt.Errorf("número de inodos inválido o excesivo: %d", sb.S_inodes_count)
 	}
-	bitmap := make([]byte, sb.S_inodes_count)
-	_, err = file.ReadAt(bitmap, int64(sb.S_bm_inode_start))
+	index, err := sb.FreeIndex(diskPath)
 	if err != nil {
 		return -1, fmt.Errorf("error al leer bitmap de inodos: %w", err)
 	}
 
-	// Buscar el primer byte '0'
+	// Empezar en S_first_ino y dar la vuelta si no hay libres después
 	startIndex := sb.S_first_ino
 	if startIndex < 0 || startIndex >= sb.S_inodes_count {
 			startIndex = 0 // Empezar desde el principio si S_first_ino no es útil
 	}
-
-	for i := int(startIndex); i < len(bitmap); i++ {
-		if bitmap[i] == '0' {
-			sb.S_first_ino = int32(i) + 1
-			return int32(i), nil
-		}
-	}
-	if startIndex > 0 {
-		for i := 0; i < int(startIndex); i++ {
-			if bitmap[i] == '0' {
-				sb.S_first_ino = int32(i) + 1
-				return int32(i), nil
-			}
-		}
+	if i, ok := index.Inodes.First(startIndex); ok {
+		sb.S_first_ino = i + 1
+		return i, nil
 	}
 
-
 	return -1, errors.New("no hay inodos libres disponibles")
 }
 
-// FindFreeBlock busca el primer bloque libre ('0') en el bitmap de bloques.
+// FindFreeBlock busca el primer bloque libre a partir de S_first_blo usando el índice de libres.
 func (sb *SuperBlock) FindFreeBlock(diskPath string) (int32, error) {
-	file, err := os.Open(diskPath)
-	if err != nil {
-		return -1, fmt.Errorf("error al abrir disco para buscar bloque libre: %w", err)
-	}
-	defer file.Close()
-
-	// Buffer para leer el bitmap de bloques completo
 	if sb.S_blocks_count <= 0 || sb.S_blocks_count > 10000000 { //Cualquier limite xd
 		return -1, fmt.Errorf("número de bloques inválido o excesivo: %d", sb.S_blocks_count)
 	}
-	bitmap := make([]byte, sb.S_blocks_count)
-	_, err = file.ReadAt(bitmap, int64(sb.S_bm_block_start))
+	index, err := sb.FreeIndex(diskPath)
 	if err != nil {
 		return -1, fmt.Errorf("error al leer bitmap de bloques: %w", err)
 	}
 
-	// Buscar el primer byte '0'
 	startIndex := sb.S_first_blo
 	if startIndex < 0 || startIndex >= sb.S_blocks_count {
 			startIndex = 0
 	}
-
-	for i := int(startIndex); i < len(bitmap); i++ {
-		if bitmap[i] == '0' {
-			sb.S_first_blo = int32(i) + 1 
-			return int32(i), nil
-		}
-	}
-	// Buscar desde el principio si no se encontró
-	if startIndex > 0 {
-		for i := 0; i < int(startIndex); i++ {
-			if bitmap[i] == '0' {
-				sb.S_first_blo = int32(i) + 1
-				return int32(i), nil
-			}
-		}
+	if i, ok := index.Blocks.First(startIndex); ok {
+		sb.S_first_blo = i + 1
+		return i, nil
 	}
 
 	return -1, errors.New("no hay bloques libres disponibles")
@@ -549,20 +569,20 @@ func (sb *SuperBlock) IsInodeUsed(diskPath string, inodeIndex int32) (bool, erro
     if inodeIndex < 0 || inodeIndex >= sb.S_inodes_count {
         return false, fmt.Errorf("índice de inodo fuera de rango: %d", inodeIndex)
     }
-    file, err := os.Open(diskPath)
+    if index := sb.loadedFreeIndex(diskPath); index != nil {
+        return !index.Inodes.IsFree(inodeIndex), nil
+    }
+    file, err := OpenDisk(diskPath)
     if err != nil {
         return false, fmt.Errorf("error al abrir disco para verificar inodo: %w", err)
     }
     defer file.Close()
 
-    inodeStatus := make([]byte, 1)
-    offset := int64(sb.S_bm_inode_start) + int64(inodeIndex)
-    _, err = file.ReadAt(inodeStatus, offset)
+    used, err := sb.readBitmapEntry(file, sb.S_bm_inode_start, inodeIndex)
     if err != nil {
         return false, fmt.Errorf("error al leer estado del inodo %d en bitmap: %w", inodeIndex, err)
     }
-
-    return inodeStatus[0] == '1', nil // '1' significa usado
+    return used, nil
 }
 
 // IsBlockUsed verifica el estado de un bloque en el bitmap.
@@ -570,20 +590,20 @@ func (sb *SuperBlock) IsBlockUsed(diskPath string, blockIndex int32) (bool, erro
     if blockIndex < 0 || blockIndex >= sb.S_blocks_count {
         return false, fmt.Errorf("índice de bloque fuera de rango: %d", blockIndex)
     }
-    file, err := os.Open(diskPath)
+    if index := sb.loadedFreeIndex(diskPath); index != nil {
+        return !index.Blocks.IsFree(blockIndex), nil
+    }
+    file, err := OpenDisk(diskPath)
     if err != nil {
         return false, fmt.Errorf("error al abrir disco para verificar bloque: %w", err)
     }
     defer file.Close()
 
-    blockStatus := make([]byte, 1)
-    offset := int64(sb.S_bm_block_start) + int64(blockIndex)
-    _, err = file.ReadAt(blockStatus, offset)
+    used, err := sb.readBitmapEntry(file, sb.S_bm_block_start, blockIndex)
     if err != nil {
         return false, fmt.Errorf("error al leer estado del bloque %d en bitmap: %w", blockIndex, err)
     }
-
-    return blockStatus[0] == '1', nil // usado
+    return used, nil
 }
 
 
@@ -0,0 +1,114 @@
+package structures
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Segundos que float32 no representa exactamente (el paso en esta zona es de 128 s).
+const (
+	testTime        = int64(1760000077)
+	testTimeRounded = int64(1760000128)
+)
+
+func testSuperBlock(features int32) *SuperBlock {
+	return &SuperBlock{
+		S_filesystem_type: 3, S_inodes_count: 16, S_blocks_count: 48,
+		S_free_inodes_count: 14, S_free_blocks_count: 45,
+		S_mtime: testTime, S_umtime: testTime + 60, S_mnt_count: 2,
+		S_magic: 0xEF53, S_inode_size: InodeDiskSize(features), S_block_size: 64,
+		S_first_ino: 2, S_first_blo: 3,
+		S_bm_inode_start: 100, S_bm_block_start: 116, S_inode_start: 164, S_block_start: 2000,
+		S_ext_magic: SuperBlockExtMagic, S_feature_incompat: features, S_rev_level: SuperBlockRevision,
+		S_feature_compat: FeatureCompatJournal,
+	}
+}
+
+func TestSuperBlockRoundTrip(t *testing.T) {
+	legacy := testSuperBlock(0)
+	legacy.S_ext_magic, legacy.S_rev_level, legacy.S_feature_compat = 0, 0, 0
+
+	for _, tt := range []struct {
+		name      string
+		sb        *SuperBlock
+		size      int
+		wantMtime int64
+	}{
+		{"original", legacy, 68, testTimeRounded},
+		{"extensión", testSuperBlock(FeatureIncompatPackedBitmaps), 100, testTimeRounded},
+		{"tiempos int64", testSuperBlock(FeatureIncompatWideTimes), 116, testTime},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.sb.encode()
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			if len(data) != tt.size || int(tt.sb.DiskSize()) != tt.size {
+				t.Fatalf("encode = %d bytes, DiskSize = %d, se esperaban %d", len(data), tt.sb.DiskSize(), tt.size)
+			}
+			// Lo que sigue al superbloque en disco (el bitmap de inodos) no debe leerse como extensión
+			padded := append(append([]byte(nil), data...), bytes.Repeat([]byte{0xAB}, 48)...)
+			decoded := &SuperBlock{}
+			if err := decoded.decode(padded); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if decoded.S_mtime != tt.wantMtime {
+				t.Errorf("S_mtime = %d, se esperaba %d", decoded.S_mtime, tt.wantMtime)
+			}
+			want := *tt.sb
+			want.S_mtime, want.S_umtime = decoded.S_mtime, decoded.S_umtime
+			if *decoded != want {
+				t.Errorf("decode = %+v\nse esperaba %+v", *decoded, want)
+			}
+		})
+	}
+}
+
+func TestSuperBlockDecodeTruncated(t *testing.T) {
+	data, err := testSuperBlock(FeatureIncompatWideTimes).encode()
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	for _, size := range []int{0, 67, len(data) - 1} {
+		if err := (&SuperBlock{}).decode(data[:size]); err == nil {
+			t.Errorf("decode de %d bytes: se esperaba error", size)
+		}
+	}
+}
+
+// Serialize sin extensión escribe solo 68 bytes: no pisa el bitmap que empieza justo después.
+func TestSuperBlockSerializeLegacy(t *testing.T) {
+	const path = "/mem/TestSuperBlockSerializeLegacy.mia"
+	RegisterDevice(path, NewMemDevice(512))
+	t.Cleanup(func() { UnregisterDevice(path) })
+
+	file, err := OpenDisk(path)
+	if err != nil {
+		t.Fatalf("OpenDisk: %v", err)
+	}
+	defer file.Close()
+	bitmap := bytes.Repeat([]byte{'1'}, 32)
+	if _, err := file.WriteAt(bitmap, 10+superBlockLegacySize); err != nil {
+		t.Fatalf("WriteAt: %v", err)
+	}
+
+	sb := testSuperBlock(0)
+	sb.S_ext_magic, sb.S_rev_level, sb.S_feature_compat = 0, 0, 0
+	if err := sb.Serialize(path, 10); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	got := make([]byte, len(bitmap))
+	if _, err := file.ReadAt(got, 10+superBlockLegacySize); err != nil {
+		t.Fatalf("ReadAt: %v", err)
+	}
+	if !bytes.Equal(got, bitmap) {
+		t.Errorf("Serialize pisó los bytes después del superbloque: %q", got)
+	}
+	read := &SuperBlock{}
+	if err := read.Deserialize(path, 10); err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	if read.HasExtension() || read.S_inodes_count != sb.S_inodes_count || read.S_mtime != testTimeRounded {
+		t.Errorf("Deserialize = %+v", *read)
+	}
+}
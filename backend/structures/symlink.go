@@ -0,0 +1,189 @@
+package structures
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Enlaces simbólicos (I_type '2'). El destino es una ruta, absoluta o relativa a la carpeta que
+// contiene el enlace. Si cabe en los 60 bytes de I_block se guarda ahí mismo (enlace rápido, sin
+// bloques); si no, se guarda en bloques de datos igual que el contenido de un archivo.
+const (
+	SymlinkInlineMax = 15 * 4 // Bytes de I_block
+	SymlinkTargetMax = 4095   // Longitud máxima del destino
+	MaxSymlinkHops   = 16     // Enlaces que se siguen al resolver una ruta antes de suponer un ciclo
+)
+
+// IsSymlink indica si el inodo es un enlace simbólico.
+func (inode *Inode) IsSymlink() bool {
+	return inode.I_type[0] == '2'
+}
+
+// InlineSymlink indica si el destino del enlace está guardado en I_block. En ese caso I_block no
+// contiene punteros y no se debe recorrer.
+func (inode *Inode) InlineSymlink() bool {
+	return inode.IsSymlink() && inode.I_size <= SymlinkInlineMax
+}
+
+// SetInlineTarget guarda target en I_block y en I_size su longitud.
+func (inode *Inode) SetInlineTarget(target string) error {
+	if len(target) > SymlinkInlineMax {
+		return fmt.Errorf("el destino (%d bytes) no cabe en el inodo (máximo %d)", len(target), SymlinkInlineMax)
+	}
+	raw := make([]byte, SymlinkInlineMax)
+	copy(raw, target)
+	for i := range inode.I_block {
+		inode.I_block[i] = int32(binary.LittleEndian.Uint32(raw[i*4:]))
+	}
+	inode.I_size = int64(len(target))
+	return nil
+}
+
+func (inode *Inode) inlineTarget() string {
+	raw := make([]byte, SymlinkInlineMax)
+	for i, word := range inode.I_block {
+		binary.LittleEndian.PutUint32(raw[i*4:], uint32(word))
+	}
+	return string(raw[:inode.I_size])
+}
+
+// ReadSymlink devuelve el destino de un enlace simbólico.
+func ReadSymlink(sb *SuperBlock, diskPath string, inode *Inode) (string, error) {
+	if !inode.IsSymlink() {
+		return "", fmt.Errorf("el inodo no es un enlace simbólico (tipo: %c)", inode.I_type[0])
+	}
+	if inode.I_size > SymlinkTargetMax {
+		return "", fmt.Errorf("el destino del enlace es demasiado largo (%d bytes, máximo %d)", inode.I_size, SymlinkTargetMax)
+	}
+	return ReadFileContent(sb, diskPath, inode)
+}
+
+// ValidateSymlinkTarget verifica que target se pueda guardar como destino de un enlace.
+func ValidateSymlinkTarget(target string) error {
+	if target == "" {
+		return errors.New("el destino del enlace no puede estar vacío")
+	}
+	if len(target) > SymlinkTargetMax {
+		return fmt.Errorf("el destino del enlace es demasiado largo (%d bytes, máximo %d)", len(target), SymlinkTargetMax)
+	}
+	if strings.ContainsRune(target, 0) {
+		return errors.New("el destino del enlace no puede contener bytes nulos")
+	}
+	return nil
+}
+
+// FindInodeByPathNoFollow es FindInodeByPath sin seguir el último componente: si es un enlace
+// devuelve el inodo del enlace (remove, rename y move actúan sobre el enlace y no su destino).
+func FindInodeByPathNoFollow(sb *SuperBlock, diskPath string, path string) (int32, *Inode, error) {
+	return resolvePath(sb, diskPath, path, false)
+}
+
+func splitPath(path string) []string {
+	var components []string
+	for _, c := range strings.Split(path, "/") {
+		if c != "" {
+			components = append(components, c)
+		}
+	}
+	return components
+}
+
+// Recorre path desde la raíz. Los enlaces de los componentes intermedios siempre se siguen; el
+// último solo si followLast. Se lleva la pila de carpetas recorridas para resolver ".." y los
+// destinos relativos desde la carpeta que contiene el enlace.
+func resolvePath(sb *SuperBlock, diskPath string, path string, followLast bool) (int32, *Inode, error) {
+	fmt.Printf("Buscando inodo para path: %s\n", path)
+
+	readInode := func(index int32) (*Inode, error) {
+		if index < 0 || index >= sb.S_inodes_count {
+			return nil, fmt.Errorf("índice de inodo inválido: %d", index)
+		}
+		inode := &Inode{}
+		if err := inode.Deserialize(sb, diskPath, int64(sb.S_inode_start)+int64(index)*int64(sb.S_inode_size)); err != nil {
+			return nil, fmt.Errorf("error al leer inodo %d: %v", index, err)
+		}
+		return inode, nil
+	}
+
+	components := splitPath(path)
+	fmt.Printf("Componentes del path: %v\n", components)
+
+	stack := []int32{0} // Inodo raíz es 0
+	currentName := "/"
+	current, err := readInode(0)
+	if err != nil {
+		return -1, nil, fmt.Errorf("error al leer inodo raíz: %v", err)
+	}
+	hops := 0
+
+	for len(components) > 0 {
+		component := components[0]
+		components = components[1:]
+
+		switch component {
+		case ".":
+			continue
+		case "..":
+			if current.I_type[0] != '0' {
+				return -1, nil, fmt.Errorf("'%s' no es un directorio", currentName)
+			}
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+			if current, err = readInode(stack[len(stack)-1]); err != nil {
+				return -1, nil, err
+			}
+			continue
+		}
+
+		dirIndex := stack[len(stack)-1]
+		fmt.Printf("Buscando componente '%s' (en inodo %d)\n", component, dirIndex)
+		if current.I_type[0] != '0' {
+			return -1, nil, fmt.Errorf("'%s' no es un directorio", component)
+		}
+
+		// Buscar el componente en los bloques de la carpeta (directos e indirectos)
+		entry, found, err := sb.LookupDir(diskPath, current, component)
+		if err != nil {
+			return -1, nil, fmt.Errorf("error al leer la carpeta %d: %v", dirIndex, err)
+		}
+		if !found {
+			return -1, nil, fmt.Errorf("no se encontró '%s' en el directorio actual", component)
+		}
+		fmt.Printf("¡Encontrado! El inodo para '%s' es %d\n", component, entry.Inode)
+
+		child, err := readInode(entry.Inode)
+		if err != nil {
+			return -1, nil, err
+		}
+
+		if child.IsSymlink() && (len(components) > 0 || followLast) {
+			hops++
+			if hops > MaxSymlinkHops {
+				return -1, nil, fmt.Errorf("demasiados niveles de enlaces simbólicos al resolver '%s' (máximo %d)", path, MaxSymlinkHops)
+			}
+			target, err := ReadSymlink(sb, diskPath, child)
+			if err != nil {
+				return -1, nil, fmt.Errorf("error leyendo el enlace '%s': %v", component, err)
+			}
+			fmt.Printf("'%s' es un enlace a '%s'\n", component, target)
+			if strings.HasPrefix(target, "/") {
+				stack = stack[:1]
+				if current, err = readInode(0); err != nil {
+					return -1, nil, err
+				}
+			}
+			components = append(splitPath(target), components...)
+			continue
+		}
+
+		stack = append(stack, entry.Inode)
+		current = child
+		currentName = component
+	}
+
+	fmt.Printf("Inodo encontrado - tipo: %s, tamaño: %d\n", string(current.I_type[:]), current.I_size)
+	return stack[len(stack)-1], current, nil
+}
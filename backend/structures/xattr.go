@@ -0,0 +1,155 @@
+package structures
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Atributos extendidos (FeatureIncompatXattrs). Cada inodo puede apuntar con I_xattr a un bloque
+// con sus atributos, uno detrás de otro:
+//
+//	espacio (1 byte) | largo del nombre (1 byte) | largo del valor (2 bytes) | nombre | valor
+//
+// El espacio es el índice en XattrNamespaces y el nombre se guarda sin su prefijo. Un espacio 0
+// marca el final. Todos los atributos de un inodo tienen que caber en ese único bloque.
+const (
+	XattrNameMax     = 255 // Bytes del nombre sin el prefijo del espacio
+	xattrEntryHeader = 4
+)
+
+// XattrNamespaces son los prefijos admitidos; el índice es el que se guarda en disco.
+var XattrNamespaces = []string{"", "user.", "system."}
+
+type Xattr struct {
+	Name  string // Nombre completo, con el prefijo del espacio (user.mime)
+	Value string
+}
+
+// Devuelve el índice del espacio de name y el nombre sin el prefijo.
+func splitXattrName(name string) (byte, string, error) {
+	for i, prefix := range XattrNamespaces {
+		if prefix != "" && strings.HasPrefix(name, prefix) {
+			return byte(i), strings.TrimPrefix(name, prefix), nil
+		}
+	}
+	return 0, "", fmt.Errorf("el atributo '%s' debe empezar con user. o system.", name)
+}
+
+// ValidateXattrName verifica que name tenga un espacio conocido y un nombre que se pueda guardar.
+func ValidateXattrName(name string) error {
+	_, short, err := splitXattrName(name)
+	if err != nil {
+		return err
+	}
+	if short == "" {
+		return fmt.Errorf("el atributo '%s' no tiene nombre después del espacio", name)
+	}
+	if len(short) > XattrNameMax {
+		return fmt.Errorf("el nombre del atributo es demasiado largo (%d bytes, máximo %d)", len(short), XattrNameMax)
+	}
+	if strings.ContainsRune(name, 0) {
+		return errors.New("el nombre del atributo no puede contener bytes nulos")
+	}
+	return nil
+}
+
+// IsSystemXattr indica si name pertenece al espacio system. (solo root lo modifica).
+func IsSystemXattr(name string) bool {
+	return strings.HasPrefix(name, "system.")
+}
+
+func encodeXattrs(attrs []Xattr, blockSize int32) ([]byte, error) {
+	raw := make([]byte, 0, blockSize)
+	for _, attr := range attrs {
+		ns, short, err := splitXattrName(attr.Name)
+		if err != nil {
+			return nil, err
+		}
+		entry := make([]byte, xattrEntryHeader, xattrEntryHeader+len(short)+len(attr.Value))
+		entry[0] = ns
+		entry[1] = byte(len(short))
+		binary.LittleEndian.PutUint16(entry[2:], uint16(len(attr.Value)))
+		entry = append(append(entry, short...), attr.Value...)
+		raw = append(raw, entry...)
+	}
+	if int32(len(raw)) > blockSize {
+		return nil, fmt.Errorf("los atributos ocupan %d bytes y no caben en un bloque de %d", len(raw), blockSize)
+	}
+	return append(raw, make([]byte, int(blockSize)-len(raw))...), nil
+}
+
+func decodeXattrs(raw []byte) ([]Xattr, error) {
+	attrs := []Xattr{}
+	for pos := 0; pos+xattrEntryHeader <= len(raw) && raw[pos] != 0; {
+		ns := int(raw[pos])
+		nameLen := int(raw[pos+1])
+		valueLen := int(binary.LittleEndian.Uint16(raw[pos+2:]))
+		end := pos + xattrEntryHeader + nameLen + valueLen
+		if ns >= len(XattrNamespaces) || end > len(raw) {
+			return nil, fmt.Errorf("entrada de atributo inválida en el byte %d", pos)
+		}
+		name := string(raw[pos+xattrEntryHeader : pos+xattrEntryHeader+nameLen])
+		attrs = append(attrs, Xattr{
+			Name:  XattrNamespaces[ns] + name,
+			Value: string(raw[pos+xattrEntryHeader+nameLen : end]),
+		})
+		pos = end
+	}
+	return attrs, nil
+}
+
+// ReadXattrs devuelve los atributos del inodo (vacío si no tiene bloque de atributos).
+func (sb *SuperBlock) ReadXattrs(path string, inode *Inode) ([]Xattr, error) {
+	if inode.I_xattr == -1 {
+		return []Xattr{}, nil
+	}
+	if inode.I_xattr < 0 || inode.I_xattr >= sb.S_blocks_count {
+		return nil, fmt.Errorf("bloque de atributos inválido: %d", inode.I_xattr)
+	}
+	block := NewFileBlock(sb.S_block_size)
+	if err := block.Deserialize(path, int64(sb.S_block_start)+int64(inode.I_xattr)*int64(sb.S_block_size)); err != nil {
+		return nil, fmt.Errorf("error leyendo bloque de atributos %d: %w", inode.I_xattr, err)
+	}
+	return decodeXattrs(block.B_content)
+}
+
+// WriteXattrs reemplaza los atributos del inodo. Asigna el bloque si hace falta y lo libera si
+// attrs queda vacío; actualiza I_xattr pero no guarda el inodo ni el superbloque.
+func (sb *SuperBlock) WriteXattrs(path string, inode *Inode, attrs []Xattr) error {
+	if len(attrs) == 0 {
+		return sb.FreeXattrs(path, inode)
+	}
+	if !sb.Xattrs() {
+		return errors.New("el sistema de archivos no guarda atributos extendidos (característica xattrs)")
+	}
+	raw, err := encodeXattrs(attrs, sb.S_block_size)
+	if err != nil {
+		return err
+	}
+	if inode.I_xattr == -1 {
+		blockIndex, err := sb.allocBlock(path)
+		if err != nil {
+			return fmt.Errorf("no se pudo asignar el bloque de atributos: %w", err)
+		}
+		inode.I_xattr = blockIndex
+	}
+	block := &FileBlock{B_content: raw}
+	if err := block.Serialize(path, int64(sb.S_block_start)+int64(inode.I_xattr)*int64(sb.S_block_size)); err != nil {
+		return fmt.Errorf("error escribiendo bloque de atributos %d: %w", inode.I_xattr, err)
+	}
+	return nil
+}
+
+// FreeXattrs libera el bloque de atributos del inodo y deja I_xattr en -1 (no guarda el inodo).
+func (sb *SuperBlock) FreeXattrs(path string, inode *Inode) error {
+	if inode.I_xattr == -1 {
+		return nil
+	}
+	if err := freeDataBlockIfValid(inode.I_xattr, sb, path); err != nil {
+		return fmt.Errorf("error liberando bloque de atributos %d: %w", inode.I_xattr, err)
+	}
+	inode.I_xattr = -1
+	return nil
+}
@@ -0,0 +1,178 @@
+package structures
+
+import (
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestXattrsRoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		attrs []Xattr
+	}{
+		{"vacío", []Xattr{}},
+		{"varios espacios", []Xattr{{Name: "user.mime", Value: "text/plain"}, {Name: "system.posix_acl_access", Value: "\x01\x00\x00\x00\x06\x00"}, {Name: "user.vacío", Value: ""}}},
+		{"nombre máximo", []Xattr{{Name: "user." + strings.Repeat("n", XattrNameMax), Value: "v"}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := encodeXattrs(tt.attrs, 512)
+			if err != nil {
+				t.Fatalf("encodeXattrs: %v", err)
+			}
+			if len(raw) != 512 {
+				t.Fatalf("encodeXattrs = %d bytes, se esperaba el bloque completo de 512", len(raw))
+			}
+			decoded, err := decodeXattrs(raw)
+			if err != nil {
+				t.Fatalf("decodeXattrs: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, tt.attrs) {
+				t.Errorf("decodeXattrs = %+v, se esperaba %+v", decoded, tt.attrs)
+			}
+		})
+	}
+}
+
+// Las entradas ocupan encabezado + nombre sin prefijo + valor; el bloque se rellena con ceros.
+func TestEncodeXattrsLayout(t *testing.T) {
+	raw, err := encodeXattrs([]Xattr{{Name: "system.ab", Value: "xyz"}}, 64)
+	if err != nil {
+		t.Fatalf("encodeXattrs: %v", err)
+	}
+	want := []byte{2, 2, 3, 0, 'a', 'b', 'x', 'y', 'z'}
+	if !reflect.DeepEqual(raw[:len(want)], want) {
+		t.Errorf("entrada = %v, se esperaba %v", raw[:len(want)], want)
+	}
+	if raw[len(want)] != 0 {
+		t.Errorf("el byte después de la última entrada = %d, se esperaba el fin 0", raw[len(want)])
+	}
+
+	// Los atributos que justo llenan el bloque no dejan lugar al 0 final y se siguen leyendo
+	exact := []Xattr{{Name: "user.a", Value: strings.Repeat("v", 64-xattrEntryHeader-1)}}
+	raw, err = encodeXattrs(exact, 64)
+	if err != nil {
+		t.Fatalf("encodeXattrs con el bloque justo: %v", err)
+	}
+	if decoded, err := decodeXattrs(raw); err != nil || !reflect.DeepEqual(decoded, exact) {
+		t.Errorf("decodeXattrs con el bloque justo = %+v, %v", decoded, err)
+	}
+}
+
+func TestEncodeXattrsErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		attrs []Xattr
+	}{
+		{"espacio desconocido", []Xattr{{Name: "trusted.x", Value: "1"}}},
+		{"sin espacio", []Xattr{{Name: "mime", Value: "1"}}},
+		{"no cabe en el bloque", []Xattr{{Name: "user.a", Value: strings.Repeat("v", 64-xattrEntryHeader)}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := encodeXattrs(tt.attrs, 64); err == nil {
+				t.Errorf("encodeXattrs: se esperaba error")
+			}
+		})
+	}
+}
+
+func TestDecodeXattrsCorrupt(t *testing.T) {
+	valid, err := encodeXattrs([]Xattr{{Name: "user.mime", Value: "text/plain"}}, 64)
+	if err != nil {
+		t.Fatalf("encodeXattrs: %v", err)
+	}
+	entryLen := xattrEntryHeader + len("mime") + len("text/plain")
+	for _, tt := range []struct {
+		name string
+		raw  func() []byte
+	}{
+		{"entrada truncada", func() []byte { return valid[:entryLen-1] }},
+		{"valor que pasa el final del bloque", func() []byte {
+			raw := append([]byte(nil), valid...)
+			binary.LittleEndian.PutUint16(raw[2:], 64)
+			return raw
+		}},
+		{"espacio desconocido", func() []byte {
+			raw := append([]byte(nil), valid...)
+			raw[0] = byte(len(XattrNamespaces))
+			return raw
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if attrs, err := decodeXattrs(tt.raw()); err == nil {
+				t.Errorf("decodeXattrs = %+v, se esperaba error", attrs)
+			}
+		})
+	}
+}
+
+func TestValidateXattrName(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		valid bool
+	}{
+		{"user.mime", true},
+		{"system.posix_acl_access", true},
+		{"user." + strings.Repeat("n", XattrNameMax), true},
+		{"user." + strings.Repeat("n", XattrNameMax+1), false},
+		{"user.", false},
+		{"trusted.x", false},
+		{"user.a\x00b", false},
+	} {
+		if err := ValidateXattrName(tt.name); (err == nil) != tt.valid {
+			t.Errorf("ValidateXattrName(%q) = %v, se esperaba válido=%v", tt.name, err, tt.valid)
+		}
+	}
+}
+
+// WriteXattrs asigna el bloque la primera vez, lo reutiliza después y lo libera con una lista vacía.
+func TestReadWriteXattrs(t *testing.T) {
+	sb, path := newTestFS(t, 64, FeatureIncompatXattrs, 8)
+	inode := newTestFile()
+	attrs := []Xattr{{Name: "user.mime", Value: "text/plain"}}
+	if err := sb.WriteXattrs(path, inode, attrs); err != nil {
+		t.Fatalf("WriteXattrs: %v", err)
+	}
+	block := inode.I_xattr
+	if block == -1 || sb.S_free_blocks_count != 7 {
+		t.Fatalf("WriteXattrs: I_xattr = %d, bloques libres %d", block, sb.S_free_blocks_count)
+	}
+	attrs = append(attrs, Xattr{Name: "system.x", Value: "1"})
+	if err := sb.WriteXattrs(path, inode, attrs); err != nil {
+		t.Fatalf("WriteXattrs: %v", err)
+	}
+	if inode.I_xattr != block || sb.S_free_blocks_count != 7 {
+		t.Errorf("reescribir movió el bloque de atributos: %d -> %d, bloques libres %d", block, inode.I_xattr, sb.S_free_blocks_count)
+	}
+	read, err := sb.ReadXattrs(path, inode)
+	if err != nil || !reflect.DeepEqual(read, attrs) {
+		t.Errorf("ReadXattrs = %+v, %v, se esperaba %+v", read, err, attrs)
+	}
+
+	if err := sb.WriteXattrs(path, inode, nil); err != nil {
+		t.Fatalf("WriteXattrs vacío: %v", err)
+	}
+	if inode.I_xattr != -1 || sb.S_free_blocks_count != 8 {
+		t.Errorf("WriteXattrs vacío: I_xattr = %d, bloques libres %d", inode.I_xattr, sb.S_free_blocks_count)
+	}
+	if read, err := sb.ReadXattrs(path, inode); err != nil || len(read) != 0 {
+		t.Errorf("ReadXattrs sin bloque = %+v, %v", read, err)
+	}
+
+	inode.I_xattr = 8
+	if _, err := sb.ReadXattrs(path, inode); err == nil {
+		t.Errorf("ReadXattrs con I_xattr fuera del área de bloques: se esperaba error")
+	}
+}
+
+func TestWriteXattrsWithoutFeature(t *testing.T) {
+	sb, path := newTestFS(t, 64, 0, 8)
+	inode := newTestFile()
+	if err := sb.WriteXattrs(path, inode, []Xattr{{Name: "user.a", Value: "1"}}); err == nil {
+		t.Errorf("WriteXattrs sin la característica xattrs: se esperaba error")
+	}
+	if inode.I_xattr != -1 || sb.S_free_blocks_count != 8 {
+		t.Errorf("WriteXattrs sin la característica: I_xattr = %d, bloques libres %d", inode.I_xattr, sb.S_free_blocks_count)
+	}
+}
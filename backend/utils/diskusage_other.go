@@ -0,0 +1,18 @@
+//go:build !unix
+
+package utils
+
+import (
+	"fmt"
+	"os"
+)
+
+// GetDiskUsage devuelve el tamaño aparente del archivo. En este sistema no se puede consultar
+// el espacio reservado, así que se informa el mismo valor.
+func GetDiskUsage(path string) (int64, int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error obteniendo información de '%s': %w", path, err)
+	}
+	return info.Size(), info.Size(), nil
+}
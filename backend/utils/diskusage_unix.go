@@ -0,0 +1,23 @@
+//go:build unix
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// GetDiskUsage devuelve el tamaño aparente del archivo y los bytes realmente reservados en el
+// sistema de archivos del host (menores que el aparente en imágenes dispersas).
+func GetDiskUsage(path string) (int64, int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error obteniendo información de '%s': %w", path, err)
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return info.Size(), info.Size(), nil
+	}
+	return info.Size(), int64(stat.Blocks) * 512, nil // st_blocks siempre cuenta bloques de 512 bytes
+}
@@ -0,0 +1,148 @@
+package utils
+
+import (
+	stores "backend/stores"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Esquemas de IDs de montaje (stores.MountIDScheme)
+const (
+	MountIDLetter = "letter" // prefijo + correlativo + letra del disco (201A, 202A, 201B...)
+	MountIDNumber = "number" // prefijo + número del disco (1-9) + correlativo (2011, 2012, 2021...)
+	MountIDStable = "stable" // prefijo + hash de la firma del disco y el nombre de la partición
+)
+
+// Part_id ocupa 4 bytes en el MBR
+const mountIDSize = 4
+
+const base36 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// ValidateMountIDConfig comprueba que el esquema exista y que el prefijo deje espacio para el resto del ID.
+func ValidateMountIDConfig(prefix string, scheme string) error {
+	if strings.ContainsAny(prefix, " \t\x00") {
+		return fmt.Errorf("prefijo de ID inválido: '%s'", prefix)
+	}
+	switch scheme {
+	case MountIDLetter, MountIDNumber, MountIDStable:
+		// Se necesitan al menos 2 caracteres después del prefijo
+		if len(prefix) > mountIDSize-2 {
+			return fmt.Errorf("el prefijo '%s' es muy largo para el esquema '%s' (máximo %d caracteres)", prefix, scheme, mountIDSize-2)
+		}
+	default:
+		return fmt.Errorf("esquema de ID desconocido: '%s'. Debe ser %s, %s o %s", scheme, MountIDLetter, MountIDNumber, MountIDStable)
+	}
+	return nil
+}
+
+// GenerateMountID crea el ID de montaje para la partición name del disco path según el esquema
+// configurado y devuelve también el correlativo de la partición dentro del disco.
+func GenerateMountID(path string, signature int32, name string) (string, int, error) {
+	prefix, scheme := stores.MountIDPrefix, stores.MountIDScheme
+	if err := ValidateMountIDConfig(prefix, scheme); err != nil {
+		return "", 0, err
+	}
+
+	var id string
+	var correlative int
+	switch scheme {
+	case MountIDLetter:
+		letter, corr, err := GetLetterAndPartitionCorrelative(path)
+		if err != nil {
+			return "", 0, err
+		}
+		id, correlative = fmt.Sprintf("%s%d%s", prefix, corr, letter), corr
+	case MountIDNumber:
+		letter, corr, err := GetLetterAndPartitionCorrelative(path)
+		if err != nil {
+			return "", 0, err
+		}
+		diskNumber := strings.Index(base36, letter) - 9 // A=1, B=2...
+		if diskNumber > 9 {
+			return "", 0, fmt.Errorf("el esquema '%s' admite como máximo 9 discos", scheme)
+		}
+		id, correlative = fmt.Sprintf("%s%d%d", prefix, diskNumber, corr), corr
+	case MountIDStable:
+		var err error
+		id, err = stableMountID(prefix, signature, name)
+		if err != nil {
+			return "", 0, err
+		}
+		pathToPartitionCount[path]++
+		correlative = pathToPartitionCount[path]
+	}
+
+	if len(id) > mountIDSize {
+		return "", 0, fmt.Errorf("el ID '%s' excede los %d caracteres de Part_id (demasiadas particiones montadas en el disco)", id, mountIDSize)
+	}
+	return id, correlative, nil
+}
+
+// Deriva el ID de la firma del disco y el nombre de la partición, así se repite entre sesiones.
+// Si el ID ya está montado (colisión del hash) falla: probar otro valor daría un ID que depende
+// del orden de montaje y dejaría de ser estable.
+func stableMountID(prefix string, signature int32, name string) (string, error) {
+	digits := mountIDSize - len(prefix)
+	space := uint32(1)
+	for i := 0; i < digits; i++ {
+		space *= uint32(len(base36))
+	}
+
+	h := fnv.New32a()
+	binary.Write(h, binary.LittleEndian, signature)
+	h.Write([]byte(name))
+	id := prefix + toBase36(int(h.Sum32()%space), digits)
+
+	if diskPath, taken := stores.MountedPartitions[id]; taken {
+		return "", fmt.Errorf("el ID estable %s de la partición '%s' coincide con el de otra partición ya montada del disco '%s'; "+
+			"desmóntela, cambie el nombre de la partición o use un prefijo más corto (MIA_MOUNT_ID_PREFIX) para tener más dígitos de hash", id, name, diskPath)
+	}
+	return id, nil
+}
+
+func toBase36(value int, digits int) string {
+	buf := make([]byte, digits)
+	for i := digits - 1; i >= 0; i-- {
+		buf[i] = base36[value%len(base36)]
+		value /= len(base36)
+	}
+	return string(buf)
+}
+
+// ReserveMountID valida un ID leído de un MBR contra el esquema configurado y reserva su letra y
+// correlativo para que GenerateMountID no lo repita. Devuelve el correlativo que le corresponde.
+func ReserveMountID(path string, id string, correlative int) (int, error) {
+	prefix, scheme := stores.MountIDPrefix, stores.MountIDScheme
+	quoted := regexp.QuoteMeta(prefix)
+
+	switch scheme {
+	case MountIDLetter:
+		match := regexp.MustCompile(`^` + quoted + `([0-9]+)([A-Z])$`).FindStringSubmatch(id)
+		if match == nil {
+			return 0, fmt.Errorf("'%s' no tiene el formato %s<correlativo><letra>", id, prefix)
+		}
+		corr, _ := strconv.Atoi(match[1])
+		return corr, RestoreLetterAndCorrelative(path, match[2], corr)
+	case MountIDNumber:
+		match := regexp.MustCompile(`^` + quoted + `([1-9])([0-9]+)$`).FindStringSubmatch(id)
+		if match == nil {
+			return 0, fmt.Errorf("'%s' no tiene el formato %s<disco><correlativo>", id, prefix)
+		}
+		diskNumber, _ := strconv.Atoi(match[1])
+		corr, _ := strconv.Atoi(match[2])
+		return corr, RestoreLetterAndCorrelative(path, alphabet[diskNumber-1], corr)
+	case MountIDStable:
+		if !regexp.MustCompile(`^` + quoted + `[0-9A-Z]+$`).MatchString(id) || len(id) != mountIDSize {
+			return 0, fmt.Errorf("'%s' no tiene el formato %s<hash>", id, prefix)
+		}
+		if correlative > pathToPartitionCount[path] {
+			pathToPartitionCount[path] = correlative
+		}
+		return correlative, nil
+	}
+	return 0, fmt.Errorf("esquema de ID desconocido: '%s'", scheme)
+}
@@ -2,7 +2,6 @@ package utils
 
 import (
 	"backend/structures"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"os"
@@ -63,6 +62,39 @@ func GetLetterAndPartitionCorrelative(path string) (string, int, error) {
 	return pathToLetter[path], nextIndex, nil
 }
 
+// RestoreLetterAndCorrelative registra una letra y correlativo ya usados por un path (por ejemplo
+// al reconstruir montajes desde el MBR) para que los siguientes montajes no los repitan
+func RestoreLetterAndCorrelative(path string, letter string, correlative int) error {
+	letterIndex := -1
+	for i, l := range alphabet {
+		if l == letter {
+			letterIndex = i
+			break
+		}
+	}
+	if letterIndex == -1 {
+		return fmt.Errorf("letra inválida: '%s'", letter)
+	}
+
+	if assigned, exists := pathToLetter[path]; exists && assigned != letter {
+		return fmt.Errorf("el disco '%s' ya tiene asignada la letra '%s'", path, assigned)
+	}
+	for otherPath, l := range pathToLetter {
+		if l == letter && otherPath != path {
+			return fmt.Errorf("la letra '%s' ya está asignada al disco '%s'", letter, otherPath)
+		}
+	}
+
+	pathToLetter[path] = letter
+	if correlative > pathToPartitionCount[path] {
+		pathToPartitionCount[path] = correlative
+	}
+	if letterIndex >= nextLetterIndex {
+		nextLetterIndex = letterIndex + 1
+	}
+	return nil
+}
+
 // createParentDirs crea las carpetas padre si no existen
 func CreateParentDirs(path string) error {
 	dir := filepath.Dir(path)
@@ -157,7 +189,7 @@ func AppendToJournal(entryData structures.Information, sb *structures.SuperBlock
 	journalInodeIndex := int32(2)
 	journalInode := &structures.Inode{}
 	journalInodeOffset := int64(sb.S_inode_start + journalInodeIndex*sb.S_inode_size)
-	if err := journalInode.Deserialize(diskPath, journalInodeOffset); err != nil {
+	if err := journalInode.Deserialize(sb, diskPath, journalInodeOffset); err != nil {
 		return fmt.Errorf("appendToJournal: error crítico leyendo inodo journal %d: %w", journalInodeIndex, err)
 	}
 	if journalInode.I_type[0] != '1' {
@@ -165,8 +197,8 @@ func AppendToJournal(entryData structures.Information, sb *structures.SuperBlock
 	}
 
 	// Calcular offset de escritura (final actual del archivo journal)
-	writeOffsetInFile := journalInode.I_size // Offset lógico dentro del archivo
-	entrySize := int32(binary.Size(structures.Journal{}))
+	writeOffsetInFile := int32(journalInode.I_size) // Offset lógico dentro del archivo (el journal es pequeño)
+	entrySize := structures.JournalEntrySize(sb.S_feature_incompat)
 
 	// Encontrar bloque físico y offset dentro del bloque
 	targetBlockLogicalIndex := writeOffsetInFile / sb.S_block_size
@@ -201,11 +233,11 @@ func AppendToJournal(entryData structures.Information, sb *structures.SuperBlock
 		J_count:   writeOffsetInFile/entrySize + 1, // Estimación simple del número de entrada
 		J_content: entryData,                       // Los datos vienen como parámetro
 	}
-	journalEntry.J_content.I_date = float32(time.Now().Unix()) // Poner fecha actual
+	journalEntry.J_content.I_date = time.Now().Unix() // Poner fecha actual
 
 	// Escribir la entrada en el disco
 	fmt.Printf("    Escribiendo entrada journal en offset físico %d (offset lógico %d)\n", physicalWriteOffset, writeOffsetInFile)
-	file, errOpen := os.OpenFile(diskPath, os.O_WRONLY, 0644) // Abrir solo para escribir
+	file, errOpen := structures.OpenDisk(diskPath) // Abrir solo para escribir
 	if errOpen != nil {
 		return fmt.Errorf("appendToJournal: error abriendo disco para escribir journal: %w", errOpen)
 	}
@@ -216,20 +248,25 @@ func AppendToJournal(entryData structures.Information, sb *structures.SuperBlock
 		file.Close()
 		return fmt.Errorf("appendToJournal: error buscando offset %d: %w", physicalWriteOffset, errSeek)
 	}
-	errWrite := binary.Write(file, binary.LittleEndian, &journalEntry)
+	entryBytes, errEncode := journalEntry.Encode(sb.S_feature_incompat)
+	if errEncode != nil {
+		file.Close()
+		return fmt.Errorf("appendToJournal: error codificando entrada: %w", errEncode)
+	}
+	_, errWrite := file.Write(entryBytes)
 	if errWrite != nil {
 		file.Close()
 		return fmt.Errorf("appendToJournal: error escribiendo entrada: %w", errWrite)
 	}
 
 	// Actualizar tamaño y mtime del inodo del journal
-	journalInode.I_size += entrySize
-	journalInode.I_mtime = float32(time.Now().Unix())
+	journalInode.I_size += int64(entrySize)
+	journalInode.I_mtime = time.Now().Unix()
 	// No necesitamos atime aquí
 
 	// Serializar inodo del journal actualizado
 	fmt.Println("    Actualizando inodo del journal...")
-	errSer := journalInode.Serialize(diskPath, journalInodeOffset)
+	errSer := journalInode.Serialize(sb, diskPath, journalInodeOffset)
 	if errSer != nil {
 		return fmt.Errorf("appendToJournal: error crítico guardando inodo journal actualizado: %w", errSer)
 	}